@@ -11,6 +11,8 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"github.com/orkhanm/go-sdk/oauthex"
 )
 
 // TokenInfo holds information from a bearer token.
@@ -54,8 +56,11 @@ func TokenInfoFromContext(ctx context.Context) *TokenInfo {
 
 // RequireBearerToken returns a piece of middleware that verifies a bearer token using the verifier.
 // If verification succeeds, the [TokenInfo] is added to the request's context and the request proceeds.
-// If verification fails, the request fails with a 401 Unauthenticated, and the WWW-Authenticate header
-// is populated to enable [protected resource metadata].
+// If verification fails, the request fails with a 401 Unauthenticated (or a 403 Forbidden if the token
+// lacks a required scope), and the WWW-Authenticate header is populated with a Bearer challenge
+// describing the error, as described in RFC 6750 section 3. If
+// [RequireBearerTokenOptions.ResourceMetadataURL] is set, the challenge also enables
+// [protected resource metadata].
 //
 // [protected resource metadata]: https://datatracker.ietf.org/doc/rfc9728
 func RequireBearerToken(verifier TokenVerifier, opts *RequireBearerTokenOptions) func(http.Handler) http.Handler {
@@ -66,9 +71,7 @@ func RequireBearerToken(verifier TokenVerifier, opts *RequireBearerTokenOptions)
 			tokenInfo, errmsg, code := verify(r, verifier, opts)
 			if code != 0 {
 				if code == http.StatusUnauthorized || code == http.StatusForbidden {
-					if opts != nil && opts.ResourceMetadataURL != "" {
-						w.Header().Add("WWW-Authenticate", "Bearer resource_metadata="+opts.ResourceMetadataURL)
-					}
+					w.Header().Add("WWW-Authenticate", bearerChallenge(errmsg, code, opts).String())
 				}
 				http.Error(w, errmsg, code)
 				return
@@ -79,12 +82,37 @@ func RequireBearerToken(verifier TokenVerifier, opts *RequireBearerTokenOptions)
 	}
 }
 
+// bearerChallenge returns the challenge for a request that failed verification
+// with the given message and status code.
+func bearerChallenge(errmsg string, code int, opts *RequireBearerTokenOptions) *oauthex.BearerChallenge {
+	c := &oauthex.BearerChallenge{}
+	if opts != nil {
+		c.ResourceMetadata = opts.ResourceMetadataURL
+		c.Scope = opts.Scopes
+	}
+	switch {
+	case code == http.StatusForbidden:
+		c.Error = "insufficient_scope"
+		c.ErrorDescription = errmsg
+	case errmsg == errNoBearerToken:
+		// RFC 6750, section 3.1: a request with no authentication information
+		// gets no error code.
+	default:
+		c.Error = "invalid_token"
+		c.ErrorDescription = errmsg
+	}
+	return c
+}
+
+// errNoBearerToken is the message for a request without a bearer token.
+const errNoBearerToken = "no bearer token"
+
 func verify(req *http.Request, verifier TokenVerifier, opts *RequireBearerTokenOptions) (_ *TokenInfo, errmsg string, code int) {
 	// Extract bearer token.
 	authHeader := req.Header.Get("Authorization")
 	fields := strings.Fields(authHeader)
 	if len(fields) != 2 || strings.ToLower(fields[0]) != "bearer" {
-		return nil, "no bearer token", http.StatusUnauthorized
+		return nil, errNoBearerToken, http.StatusUnauthorized
 	}
 
 	// Verify the token and get information from it.
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -76,3 +77,35 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireBearerTokenChallenge(t *testing.T) {
+	verifier := func(_ context.Context, token string, _ *http.Request) (*TokenInfo, error) {
+		if token == "valid" {
+			return &TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+		}
+		return nil, ErrInvalidToken
+	}
+	opts := &RequireBearerTokenOptions{
+		ResourceMetadataURL: "https://example.com/.well-known/oauth-protected-resource",
+		Scopes:              []string{"s1"},
+	}
+	handler := RequireBearerToken(verifier, opts)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for _, tt := range []struct {
+		header string
+		want   string
+	}{
+		{"", `Bearer resource_metadata="https://example.com/.well-known/oauth-protected-resource", scope="s1"`},
+		{"Bearer bad", `Bearer resource_metadata="https://example.com/.well-known/oauth-protected-resource", scope="s1", error="invalid_token", error_description="invalid token"`},
+		{"Bearer valid", `Bearer resource_metadata="https://example.com/.well-known/oauth-protected-resource", scope="s1", error="insufficient_scope", error_description="insufficient scope"`},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("WWW-Authenticate"); got != tt.want {
+			t.Errorf("Authorization %q: got WWW-Authenticate\n%s\nwant\n%s", tt.header, got, tt.want)
+		}
+	}
+}
@@ -27,20 +27,50 @@ type OAuthHandlerArgs struct {
 	// The URL to fetch protected resource metadata, extracted from the WWW-Authenticate header.
 	// Empty if not present or there was an error obtaining it.
 	ResourceMetadataURL string
+	// ResourceURL is the URL of the request that was rejected, without its
+	// query or fragment. It identifies the protected resource, and should match
+	// the resource field of its protected resource metadata.
+	ResourceURL string
+	// Token is a token previously saved to the [HTTPTransportOptions.TokenStore],
+	// or nil if there is none. A handler can use it to resume authorization
+	// without user interaction, for example by passing it to
+	// [oauth2.Config.TokenSource] so that it is refreshed as needed.
+	//
+	// Token is always nil after the server has rejected a token.
+	Token *oauth2.Token
 }
 
 // HTTPTransport is an [http.RoundTripper] that follows the MCP
 // OAuth protocol when it encounters a 401 Unauthorized response.
 type HTTPTransport struct {
 	handler OAuthHandler
-	mu      sync.Mutex // protects opts.Base
 	opts    HTTPTransportOptions
+
+	mu sync.Mutex
+	// ts is the current token source, or nil if authorization has not
+	// yet succeeded.
+	ts oauth2.TokenSource
+	// gen is incremented each time ts is replaced, so that concurrent requests
+	// that observed the same stale source run the OAuth flow only once.
+	gen int
+	// flow, if non-nil, is the authorization flow currently in progress.
+	flow *authFlow
+}
+
+// An authFlow is a single invocation of the OAuthHandler, shared by all
+// requests that need it.
+type authFlow struct {
+	done chan struct{} // closed when the flow completes
+	err  error         // set before done is closed
 }
 
 // NewHTTPTransport returns a new [*HTTPTransport].
 // The handler is invoked when an HTTP request results in a 401 Unauthorized status.
-// It is called only once per transport. Once a TokenSource is obtained, it is used
-// for the lifetime of the transport; subsequent 401s are not processed.
+// Once a TokenSource is obtained, it is used for subsequent requests. If the
+// server later rejects the token (for example, because it was revoked or
+// rotated), or the token source can no longer produce a token, the source is
+// discarded and the handler is invoked again, up to
+// [HTTPTransportOptions.MaxReauthorizations] times per request.
 func NewHTTPTransport(handler OAuthHandler, opts *HTTPTransportOptions) (*HTTPTransport, error) {
 	if handler == nil {
 		return nil, errors.New("handler cannot be nil")
@@ -54,6 +84,11 @@ func NewHTTPTransport(handler OAuthHandler, opts *HTTPTransportOptions) (*HTTPTr
 	if t.opts.Base == nil {
 		t.opts.Base = http.DefaultTransport
 	}
+	if t.opts.MaxReauthorizations == 0 {
+		t.opts.MaxReauthorizations = 1
+	} else if t.opts.MaxReauthorizations < 0 {
+		t.opts.MaxReauthorizations = 0
+	}
 	return t, nil
 }
 
@@ -62,13 +97,21 @@ type HTTPTransportOptions struct {
 	// Base is the [http.RoundTripper] to use.
 	// If nil, [http.DefaultTransport] is used.
 	Base http.RoundTripper
+	// MaxReauthorizations is the maximum number of times a single request
+	// re-runs the OAuth flow after a previously obtained token is rejected,
+	// or after a run that did not authorize it. It defaults to 1. To never
+	// re-authorize, use a negative number.
+	MaxReauthorizations int
+	// TokenStore, if non-nil, persists the tokens produced by the handler's
+	// token source, and supplies a saved token to the handler in
+	// [OAuthHandlerArgs.Token]. Tokens rejected by the server are deleted.
+	TokenStore TokenStore
+	// TokenStoreKey is the key under which tokens are kept in the TokenStore.
+	// If empty, the scheme and host of the request URL are used.
+	TokenStoreKey string
 }
 
 func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.mu.Lock()
-	base := t.opts.Base
-	t.mu.Unlock()
-
 	var (
 		// If haveBody is set, the request has a nontrivial body, and we need avoid
 		// reading (or closing) it multiple times. In that case, bodyBytes is its
@@ -91,45 +134,164 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 	}
 
-	resp, err := base.RoundTrip(req)
-	if err != nil {
-		return nil, err
+	reauths := 0
+	authorized := false // whether the OAuth flow has run for this request
+	for attempt := 0; ; attempt++ {
+		// If we don't have a body, the request is reusable, though it will be cloned
+		// by the base. However, if we've had to read the body, we must clone on
+		// every attempt after the first.
+		if haveBody && attempt > 0 {
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+
+		t.mu.Lock()
+		ts, gen := t.ts, t.gen
+		t.mu.Unlock()
+
+		rt := t.opts.Base
+		if ts != nil {
+			rt = &oauth2.Transport{Base: t.opts.Base, Source: ts}
+		}
+		resp, err := rt.RoundTrip(req)
+
+		var authHeaders []string
+		switch {
+		case err != nil:
+			// A token source that can no longer produce tokens (for example,
+			// because its refresh token was revoked) is stale.
+			var rerr *oauth2.RetrieveError
+			if ts == nil || !errors.As(err, &rerr) {
+				return nil, err
+			}
+		case resp.StatusCode != http.StatusUnauthorized:
+			return resp, nil
+		default:
+			authHeaders = resp.Header[http.CanonicalHeaderKey("WWW-Authenticate")]
+			if ts != nil && !isInvalidToken(authHeaders) {
+				return resp, nil
+			}
+		}
+		// Only the first run of the OAuth flow, made because there was no
+		// token source yet, is free: every other run counts as a
+		// re-authorization, so that a request that keeps failing to authorize
+		// gives up.
+		if ts == nil && !authorized {
+			authorized = true
+		} else {
+			if reauths >= t.opts.MaxReauthorizations {
+				if err != nil {
+					return nil, err
+				}
+				return resp, nil
+			}
+			reauths++
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := t.authorize(req, gen, ts != nil, authHeaders); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// authorize runs the OAuth flow, unless the token source has changed since
+// generation gen was observed, and installs the resulting token source.
+// If stale is set, the previous token was rejected, and is removed from the
+// token store.
+//
+// The mutex is not held while the handler runs. Concurrent callers that need
+// authorization wait for the flow already in progress rather than starting
+// their own.
+func (t *HTTPTransport) authorize(req *http.Request, gen int, stale bool, authHeaders []string) error {
+	ctx := req.Context()
+	t.mu.Lock()
+	if t.gen != gen {
+		// Another request replaced the token source; try again with it.
+		t.mu.Unlock()
+		return nil
 	}
-	if resp.StatusCode != http.StatusUnauthorized {
-		return resp, nil
+	if f := t.flow; f != nil {
+		t.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f := &authFlow{done: make(chan struct{})}
+	t.flow = f
+	// Discard the stale source.
+	t.ts = nil
+	t.mu.Unlock()
+
+	resourceURL := *req.URL
+	resourceURL.RawQuery = ""
+	resourceURL.Fragment = ""
+	args := OAuthHandlerArgs{
+		ResourceMetadataURL: extractResourceMetadataURL(authHeaders),
+		ResourceURL:         resourceURL.String(),
+	}
+	key := t.tokenStoreKey(req)
+	if store := t.opts.TokenStore; store != nil {
+		if stale {
+			store.Delete(ctx, key) // ignore error: at worst the handler sees the token again
+		} else if tok, err := store.Load(ctx, key); err == nil {
+			// Errors other than ErrTokenNotFound are also ignored: an unreadable
+			// store shouldn't prevent authorization.
+			args.Token = tok
+		}
 	}
-	if _, ok := base.(*oauth2.Transport); ok {
-		// We failed to authorize even with a token source; give up.
-		return resp, nil
+	ts, err := t.handler(ctx, args)
+	if err == nil && ts == nil {
+		err = errors.New("OAuth handler returned a nil token source")
+	}
+	if err == nil && t.opts.TokenStore != nil {
+		ts = &persistingTokenSource{src: ts, store: t.opts.TokenStore, key: key}
 	}
 
-	resp.Body.Close()
-	// Try to authorize.
 	t.mu.Lock()
-	defer t.mu.Unlock()
-	// If we don't have a token source, get one by following the OAuth flow.
-	// (We may have obtained one while t.mu was not held above.)
-	// TODO: We hold the lock for the entire OAuth flow. This could be a long
-	// time. Is there a better way?
-	if _, ok := t.opts.Base.(*oauth2.Transport); !ok {
-		authHeaders := resp.Header[http.CanonicalHeaderKey("WWW-Authenticate")]
-		ts, err := t.handler(req.Context(), OAuthHandlerArgs{
-			ResourceMetadataURL: extractResourceMetadataURL(authHeaders),
-		})
-		if err != nil {
-			return nil, err
-		}
-		t.opts.Base = &oauth2.Transport{Base: t.opts.Base, Source: ts}
+	if err == nil {
+		t.ts = ts
 	}
+	t.gen++
+	t.flow = nil
+	t.mu.Unlock()
 
-	// If we don't have a body, the request is reusable, though it will be cloned
-	// by the base. However, if we've had to read the body, we must clone.
-	if haveBody {
-		req = req.Clone(req.Context())
-		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	f.err = err
+	close(f.done)
+	return err
+}
+
+// tokenStoreKey returns the key for tokens used with req.
+func (t *HTTPTransport) tokenStoreKey(req *http.Request) string {
+	if t.opts.TokenStoreKey != "" {
+		return t.opts.TokenStoreKey
 	}
+	return req.URL.Scheme + "://" + req.URL.Host
+}
 
-	return t.opts.Base.RoundTrip(req)
+// isInvalidToken reports whether the WWW-Authenticate headers of a 401
+// response indicate that the token that was sent should be discarded.
+// Per RFC 6750 section 3.1, that is signaled by the "invalid_token" error
+// code; a 401 with no error code is treated the same way, since many
+// servers omit it.
+func isInvalidToken(authHeaders []string) bool {
+	cs, err := oauthex.ParseWWWAuthenticate(authHeaders)
+	if err != nil {
+		return false
+	}
+	for _, c := range cs {
+		if c.Scheme != "bearer" {
+			continue
+		}
+		if e := c.Params["error"]; e != "" && e != "invalid_token" {
+			return false
+		}
+	}
+	return true
 }
 
 func extractResourceMetadataURL(authHeaders []string) string {
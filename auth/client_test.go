@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"golang.org/x/oauth2"
@@ -150,3 +151,108 @@ func TestHTTPTransport(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPTransportReauthorize(t *testing.T) {
+	// The server accepts only the current token, and rejects any other token as
+	// invalid.
+	var validToken atomic.Value
+	validToken.Store("token-1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer "+validToken.Load().(string) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Header.Get("Authorization") != "" {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	newHandler := func(calls *int) OAuthHandler {
+		return func(ctx context.Context, args OAuthHandlerArgs) (oauth2.TokenSource, error) {
+			*calls++
+			return oauth2.StaticTokenSource(&oauth2.Token{
+				AccessToken: fmt.Sprintf("token-%d", *calls),
+				TokenType:   "Bearer",
+			}), nil
+		}
+	}
+
+	t.Run("token rotated", func(t *testing.T) {
+		validToken.Store("token-1")
+		var calls int
+		transport, err := NewHTTPTransport(newHandler(&calls), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: transport}
+		get := func() int {
+			t.Helper()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		if got := get(); got != http.StatusOK {
+			t.Fatalf("first request: got status %d, want %d", got, http.StatusOK)
+		}
+		// Revoke the first token.
+		validToken.Store("token-2")
+		if got := get(); got != http.StatusOK {
+			t.Fatalf("after rotation: got status %d, want %d", got, http.StatusOK)
+		}
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		validToken.Store("token-1")
+		var calls int
+		transport, err := NewHTTPTransport(newHandler(&calls), &HTTPTransportOptions{MaxReauthorizations: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: transport}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		validToken.Store("never")
+		resp, err = client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+		if calls != 1 {
+			t.Errorf("handler called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("nil token source", func(t *testing.T) {
+		validToken.Store("token-1")
+		var calls int
+		transport, err := NewHTTPTransport(func(context.Context, OAuthHandlerArgs) (oauth2.TokenSource, error) {
+			calls++
+			return nil, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: transport}
+		if resp, err := client.Get(server.URL); err == nil {
+			resp.Body.Close()
+			t.Errorf("got status %d, want error", resp.StatusCode)
+		}
+		if calls != 1 {
+			t.Errorf("handler called %d times, want 1", calls)
+		}
+	})
+}
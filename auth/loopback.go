@@ -0,0 +1,286 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	"github.com/orkhanm/go-sdk/oauthex"
+	"golang.org/x/oauth2"
+)
+
+// LoopbackOAuthHandlerOptions are options for [NewLoopbackOAuthHandler].
+type LoopbackOAuthHandlerOptions struct {
+	// ClientID and ClientSecret identify a client that has been registered
+	// with the authorization server, with a redirect URI matching
+	// RedirectPort and CallbackPath.
+	// If ClientID is empty, the client is registered dynamically (RFC 7591)
+	// on each authorization.
+	ClientID     string
+	ClientSecret string
+	// ClientName is the client name sent during dynamic client registration.
+	ClientName string
+	// Scopes are the scopes to request. If empty, the scopes supported by the
+	// protected resource are requested.
+	Scopes []string
+	// RedirectPort is the port of the loopback redirect listener.
+	// If zero, a free port is chosen.
+	RedirectPort int
+	// CallbackPath is the path of the redirect URI. It defaults to "/callback".
+	CallbackPath string
+	// OpenBrowser opens url in the user's browser.
+	// If nil, the operating system's default browser is used.
+	OpenBrowser func(url string) error
+	// HTTPClient is used for metadata, registration and token requests.
+	// If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+	// MetadataCache, if non-nil, caches protected resource and authorization
+	// server metadata across authorizations.
+	MetadataCache *oauthex.MetadataCache
+}
+
+// NewLoopbackOAuthHandler returns an [OAuthHandler] that performs the MCP
+// client authorization flow using the user's browser:
+//
+//  1. It retrieves the protected resource metadata and the metadata of the
+//     first authorization server listed there.
+//  2. If no client ID is configured, it registers a client dynamically.
+//  3. It listens for the authorization response on a loopback address
+//     (RFC 8252, section 7.3), and opens the browser at the authorization
+//     endpoint, using PKCE.
+//  4. It exchanges the authorization code for a token.
+//
+// Both the authorization and token requests carry a resource indicator
+// (RFC 8707) identifying the protected resource.
+//
+// The returned token source refreshes the token as needed.
+//
+// If the handler is given a saved token in [OAuthHandlerArgs.Token] and
+// ClientID is set, the browser flow is skipped, and the saved token is
+// refreshed as needed instead.
+func NewLoopbackOAuthHandler(opts *LoopbackOAuthHandlerOptions) OAuthHandler {
+	var o LoopbackOAuthHandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.CallbackPath == "" {
+		o.CallbackPath = "/callback"
+	}
+	if o.OpenBrowser == nil {
+		o.OpenBrowser = openBrowser
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = http.DefaultClient
+	}
+	return func(ctx context.Context, args OAuthHandlerArgs) (oauth2.TokenSource, error) {
+		return o.authorize(ctx, args)
+	}
+}
+
+func (o *LoopbackOAuthHandlerOptions) authorize(ctx context.Context, args OAuthHandlerArgs) (oauth2.TokenSource, error) {
+	var (
+		prm *oauthex.ProtectedResourceMetadata
+		err error
+	)
+	switch {
+	case o.MetadataCache != nil && args.ResourceMetadataURL != "":
+		prm, err = o.MetadataCache.GetProtectedResourceMetadata(ctx, args.ResourceMetadataURL, args.ResourceURL, o.HTTPClient)
+	case o.MetadataCache != nil:
+		prm, err = o.MetadataCache.GetProtectedResourceMetadataFromID(ctx, args.ResourceURL, o.HTTPClient)
+	case args.ResourceMetadataURL != "":
+		prm, err = oauthex.GetProtectedResourceMetadata(ctx, args.ResourceMetadataURL, args.ResourceURL, o.HTTPClient)
+	default:
+		prm, err = oauthex.GetProtectedResourceMetadataFromID(ctx, args.ResourceURL, o.HTTPClient)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Bind the token to the resource (RFC 8707), as the MCP spec requires.
+	resource, err := oauthex.ResourceIndicator(args.ResourceURL, prm)
+	if err != nil {
+		return nil, err
+	}
+	if len(prm.AuthorizationServers) == 0 {
+		return nil, fmt.Errorf("protected resource %q lists no authorization servers", prm.Resource)
+	}
+	var asm *oauthex.AuthServerMeta
+	if o.MetadataCache != nil {
+		asm, err = o.MetadataCache.GetAuthServerMeta(ctx, prm.AuthorizationServers[0], o.HTTPClient)
+	} else {
+		asm, err = oauthex.GetAuthServerMeta(ctx, prm.AuthorizationServers[0], o.HTTPClient)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &oauth2.Config{
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  asm.AuthorizationEndpoint,
+			TokenURL: asm.TokenEndpoint,
+		},
+		Scopes: o.Scopes,
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = prm.ScopesSupported
+	}
+	// The token source outlives ctx, which belongs to a single request. Its
+	// refresh requests carry the resource indicator too.
+	tsCtx := context.WithValue(context.Background(), oauth2.HTTPClient, refreshClient(o.HTTPClient, cfg.Endpoint.TokenURL, resource))
+	if args.Token != nil && cfg.ClientID != "" {
+		return cfg.TokenSource(tsCtx, args.Token), nil
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", o.RedirectPort))
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	cfg.RedirectURL = fmt.Sprintf("http://%s%s", l.Addr(), o.CallbackPath)
+
+	if cfg.ClientID == "" {
+		if asm.RegistrationEndpoint == "" {
+			return nil, fmt.Errorf("no client ID, and authorization server %q does not support dynamic registration", asm.Issuer)
+		}
+		reg, err := oauthex.RegisterClient(ctx, asm.RegistrationEndpoint, &oauthex.ClientRegistrationMetadata{
+			RedirectURIs:            []string{cfg.RedirectURL},
+			TokenEndpointAuthMethod: "none",
+			GrantTypes:              []string{"authorization_code", "refresh_token"},
+			ResponseTypes:           []string{"code"},
+			ClientName:              o.ClientName,
+		}, o.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientID = reg.ClientID
+		cfg.ClientSecret = reg.ClientSecret
+	}
+
+	state := oauthex.GenerateState()
+	verifier := oauthex.GenerateVerifier()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(o.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case !oauthex.CheckState(q.Get("state"), state):
+			// Not the response to our request; ignore it.
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization failed: %s (%s)", q.Get("error"), q.Get("error_description"))
+			http.Error(w, "Authorization failed. You may close this window.", http.StatusBadRequest)
+		case q.Get("code") == "":
+			res.err = errors.New("authorization response has no code")
+			http.Error(w, "Authorization failed. You may close this window.", http.StatusBadRequest)
+		default:
+			res.code = q.Get("code")
+			fmt.Fprintln(w, "Authorization complete. You may close this window.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l) // ignore error: it always returns one when shut down
+	defer srv.Close()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauthex.ResourceOption(resource))
+	if err := o.OpenBrowser(authURL); err != nil {
+		return nil, fmt.Errorf("opening browser: %w", err)
+	}
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	tok, err := cfg.Exchange(context.WithValue(ctx, oauth2.HTTPClient, o.HTTPClient), res.code, oauth2.VerifierOption(verifier), oauthex.ResourceOption(resource))
+	if err != nil {
+		return nil, err
+	}
+	return cfg.TokenSource(tsCtx, tok), nil
+}
+
+// refreshClient returns a copy of c that adds the resource parameter
+// (RFC 8707) to refresh token requests sent to tokenURL. The token sources of oauth2.Config send those requests
+// without it, and provide no way to add parameters.
+func refreshClient(c *http.Client, tokenURL, resource string) *http.Client {
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	rc := *c
+	rc.Transport = &resourceTransport{base: base, tokenURL: tokenURL, resource: resource}
+	return &rc
+}
+
+// A resourceTransport adds a resource parameter to refresh token requests.
+// See [refreshClient].
+type resourceTransport struct {
+	base     http.RoundTripper
+	tokenURL string
+	resource string
+}
+
+func (t *resourceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || req.URL.String() != t.tokenURL || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err == nil && form.Get("grant_type") == "refresh_token" && !form.Has("resource") {
+		form.Set("resource", t.resource)
+		body = []byte(form.Encode())
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return t.base.RoundTrip(req)
+}
+
+// openBrowser opens url in the default browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // reap the process; ignore error
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/orkhanm/go-sdk/oauthex"
+	"golang.org/x/oauth2"
+)
+
+// newFakeAuthServer returns a TLS server that acts as both an MCP resource at
+// /mcp and the authorization server that protects it.
+func newFakeAuthServer(t *testing.T) *httptest.Server {
+	const accessToken = "fake-access-token"
+	var (
+		challenge   string
+		redirectURI string
+	)
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	writeJSON := func(w http.ResponseWriter, code int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			challenge := &oauthex.BearerChallenge{ResourceMetadata: server.URL + ProtectedResourceMetadataPath + "/mcp"}
+			w.Header().Set("WWW-Authenticate", challenge.String())
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	mux.Handle(ProtectedResourceMetadataPath+"/mcp", ProtectedResourceMetadataHandler(&oauthex.ProtectedResourceMetadata{
+		AuthorizationServers: []string{server.URL},
+		ScopesSupported:      []string{"read"},
+	}))
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, &oauthex.AuthServerMeta{
+			Issuer:                        server.URL,
+			AuthorizationEndpoint:         server.URL + "/authorize",
+			TokenEndpoint:                 server.URL + "/token",
+			RegistrationEndpoint:          server.URL + "/register",
+			CodeChallengeMethodsSupported: []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		var meta oauthex.ClientRegistrationMetadata
+		json.NewDecoder(r.Body).Decode(&meta)
+		writeJSON(w, http.StatusCreated, &oauthex.ClientRegistrationResponse{
+			ClientRegistrationMetadata: meta,
+			ClientID:                   "fake-client",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != "fake-client" || q.Get("code_challenge_method") != "S256" || q.Get("scope") != "read" || q.Get("resource") != server.URL+"/mcp" {
+			http.Error(w, "invalid_request", http.StatusBadRequest)
+			return
+		}
+		challenge = q.Get("code_challenge")
+		redirectURI = q.Get("redirect_uri")
+		http.Redirect(w, r, redirectURI+"?code=fake-code&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") == "refresh_token" {
+			if r.Form.Get("refresh_token") != "fake-refresh-token" || r.Form.Get("resource") != server.URL+"/mcp" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+		} else if r.Form.Get("code") != "fake-code" ||
+			r.Form.Get("redirect_uri") != redirectURI ||
+			r.Form.Get("resource") != server.URL+"/mcp" ||
+			!oauthex.VerifyS256Challenge(r.Form.Get("code_verifier"), challenge) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  accessToken,
+			"token_type":    "Bearer",
+			"refresh_token": "fake-refresh-token",
+			"expires_in":    3600,
+		})
+	})
+	return server
+}
+
+func TestLoopbackOAuthHandler(t *testing.T) {
+	server := newFakeAuthServer(t)
+	client := server.Client()
+
+	var opened int
+	handler := NewLoopbackOAuthHandler(&LoopbackOAuthHandlerOptions{
+		HTTPClient: client,
+		// Simulate the user's browser, which follows the redirect back to the
+		// loopback listener.
+		OpenBrowser: func(u string) error {
+			opened++
+			resp, err := client.Get(u)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("browser: got status %s", resp.Status)
+			}
+			return nil
+		},
+	})
+	transport, err := NewHTTPTransport(handler, &HTTPTransportOptions{Base: client.Transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL + "/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if opened != 1 {
+		t.Errorf("browser opened %d times, want 1", opened)
+	}
+}
+
+func TestLoopbackOAuthHandlerRefresh(t *testing.T) {
+	server := newFakeAuthServer(t)
+	handler := NewLoopbackOAuthHandler(&LoopbackOAuthHandlerOptions{
+		HTTPClient: server.Client(),
+		ClientID:   "fake-client",
+		OpenBrowser: func(string) error {
+			return errors.New("browser opened for a saved token")
+		},
+	})
+	// An expired saved token is refreshed, with the resource indicator.
+	ts, err := handler(context.Background(), OAuthHandlerArgs{
+		ResourceURL: server.URL + "/mcp",
+		Token:       &oauth2.Token{AccessToken: "expired", RefreshToken: "fake-refresh-token", Expiry: time.Now().Add(-time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "fake-access-token" {
+		t.Errorf("got access token %q, want the refreshed one", tok.AccessToken)
+	}
+}
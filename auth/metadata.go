@@ -0,0 +1,99 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/orkhanm/go-sdk/oauthex"
+)
+
+// ProtectedResourceMetadataPath is the well-known path prefix for protected
+// resource metadata, from section 3 of RFC 9728.
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadataURL returns the URL at which a client looks for the
+// protected resource metadata of the resource identified by resourceURL: the
+// well-known path is inserted between the host and the path (RFC 9728,
+// section 3.1). For example, the metadata of
+//
+//	https://example.com/mcp
+//
+// is found at
+//
+//	https://example.com/.well-known/oauth-protected-resource/mcp
+//
+// The result is suitable for [RequireBearerTokenOptions.ResourceMetadataURL].
+func ProtectedResourceMetadataURL(resourceURL string) (string, error) {
+	u, err := url.Parse(resourceURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = ProtectedResourceMetadataPath + strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// ProtectedResourceMetadataHandler returns an [http.Handler] that serves
+// metadata as a protected resource metadata document (RFC 9728).
+//
+// If metadata.Resource is empty, the resource identifier is derived from each
+// request: it is the request's URL with the well-known path prefix removed,
+// using the https scheme if the request was made over TLS, and http otherwise.
+// For example, a request for
+//
+//	https://example.com/.well-known/oauth-protected-resource/mcp
+//
+// produces a document for the resource https://example.com/mcp. Servers
+// behind a TLS-terminating proxy should set Resource explicitly.
+//
+// The handler should be installed at the path returned by
+// [ProtectedResourceMetadataURL]. Since the document is public, the handler
+// allows cross-origin requests, so that browser-based clients can read it.
+func ProtectedResourceMetadataHandler(metadata *oauthex.ProtectedResourceMetadata) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		prm := *metadata
+		if prm.Resource == "" {
+			prm.Resource = resourceFromMetadataRequest(r)
+		}
+		data, err := json.Marshal(&prm)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// resourceFromMetadataRequest returns the identifier of the resource whose
+// metadata is requested by r.
+func resourceFromMetadataRequest(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   r.Host,
+		Path:   strings.TrimPrefix(r.URL.Path, ProtectedResourceMetadataPath),
+	}
+	return u.String()
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/orkhanm/go-sdk/oauthex"
+)
+
+func TestProtectedResourceMetadataURL(t *testing.T) {
+	for _, tt := range []struct {
+		resource, want string
+	}{
+		{"https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"},
+		{"https://example.com/", "https://example.com/.well-known/oauth-protected-resource"},
+		{"https://example.com", "https://example.com/.well-known/oauth-protected-resource"},
+	} {
+		got, err := ProtectedResourceMetadataURL(tt.resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("ProtectedResourceMetadataURL(%q) = %q, want %q", tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestProtectedResourceMetadataHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(ProtectedResourceMetadataPath+"/", ProtectedResourceMetadataHandler(&oauthex.ProtectedResourceMetadata{
+		AuthorizationServers: []string{"https://auth.example.com"},
+		ScopesSupported:      []string{"read", "write"},
+	}))
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	metadataURL, err := ProtectedResourceMetadataURL(server.URL + "/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.Client().Get(metadataURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	var got oauthex.ProtectedResourceMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := oauthex.ProtectedResourceMetadata{
+		Resource:             server.URL + "/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		ScopesSupported:      []string{"read", "write"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("metadata mismatch (-want +got):\n%s", diff)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, metadataURL, nil)
+	resp, err = server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+)
+
+// MTLSClientConfig returns a TLS configuration for a client that presents
+// cert to the server, and trusts servers whose certificates chain to roots.
+// If roots is nil, the system roots are used.
+//
+// Use it with [NewMTLSHTTPClient] to connect to an MCP server that requires
+// client certificates.
+func MTLSClientConfig(cert tls.Certificate, roots *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// NewMTLSHTTPClient returns an [http.Client] that uses cfg for TLS, and is
+// otherwise configured like [http.DefaultClient]. It is suitable as the
+// HTTPClient of an mcp.StreamableClientTransport or mcp.SSEClientTransport.
+func NewMTLSHTTPClient(cfg *tls.Config) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+	return &http.Client{Transport: t}
+}
+
+// MTLSServerConfig returns a TLS configuration for a server that presents
+// cert, and requires clients to present a certificate that chains to
+// clientCAs.
+//
+// Use it as the TLSConfig of the [http.Server] serving an MCP handler wrapped
+// with [RequireClientCertificate].
+func MTLSServerConfig(cert tls.Certificate, clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// A CertificateVerifier checks a client certificate that has already been
+// verified by the TLS stack, and extracts information from it. The
+// certificate is the leaf of the first verified chain.
+// If the client is not allowed, it should return an error that unwraps to
+// [ErrInvalidToken].
+type CertificateVerifier func(ctx context.Context, cert *x509.Certificate, req *http.Request) (*TokenInfo, error)
+
+// RequireClientCertificate returns a piece of middleware that authenticates
+// requests using verified TLS client certificates, as an alternative to
+// bearer tokens in deployments that use mutual TLS.
+//
+// The server must be configured to verify client certificates, for example
+// with [MTLSServerConfig]; requests without a verified certificate fail with
+// a 401 Unauthorized. Otherwise, the verifier maps the certificate to a
+// [TokenInfo], which is added to the request's context as with
+// [RequireBearerToken], so that it is available to MCP handlers.
+//
+// If verifier is nil, every verified certificate is accepted, with a
+// TokenInfo that expires with the certificate and records its subject and
+// serial number in Extra under the keys "subject" and "serial".
+func RequireClientCertificate(verifier CertificateVerifier) func(http.Handler) http.Handler {
+	if verifier == nil {
+		verifier = defaultCertificateVerifier
+	}
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				http.Error(w, "no verified client certificate", http.StatusUnauthorized)
+				return
+			}
+			cert := r.TLS.VerifiedChains[0][0]
+			tokenInfo, err := verifier(r.Context(), cert, r)
+			if err != nil {
+				code := http.StatusInternalServerError
+				if errors.Is(err, ErrInvalidToken) {
+					code = http.StatusUnauthorized
+				}
+				http.Error(w, err.Error(), code)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), tokenInfoKey{}, tokenInfo))
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+func defaultCertificateVerifier(_ context.Context, cert *x509.Certificate, _ *http.Request) (*TokenInfo, error) {
+	return &TokenInfo{
+		Expiration: cert.NotAfter,
+		Extra: map[string]any{
+			"subject": cert.Subject.String(),
+			"serial":  cert.SerialNumber.String(),
+		},
+	}, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestCA returns a pool containing a new CA, and a function that issues
+// client certificates signed by it.
+func newTestCA(t *testing.T) (*x509.CertPool, func(commonName string) tls.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	serial := int64(1)
+	issue := func(commonName string) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serial++
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: commonName},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+	return pool, issue
+}
+
+func TestRequireClientCertificate(t *testing.T) {
+	pool, issue := newTestCA(t)
+
+	verifier := func(_ context.Context, cert *x509.Certificate, _ *http.Request) (*TokenInfo, error) {
+		if cert.Subject.CommonName != "alice" {
+			return nil, fmt.Errorf("%w: unknown client %q", ErrInvalidToken, cert.Subject.CommonName)
+		}
+		return &TokenInfo{Scopes: []string{"read"}, Expiration: cert.NotAfter}, nil
+	}
+	handler := RequireClientCertificate(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, TokenInfoFromContext(r.Context()).Scopes)
+	}))
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	get := func(client *http.Client) (int, string) {
+		t.Helper()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get(NewMTLSHTTPClient(MTLSClientConfig(issue("alice"), roots)))
+	if code != http.StatusOK || body != "[read]" {
+		t.Errorf("with certificate: got (%d, %q), want (200, %q)", code, body, "[read]")
+	}
+	// Without a client certificate, the middleware rejects the request.
+	code, _ = get(server.Client())
+	if code != http.StatusUnauthorized {
+		t.Errorf("without certificate: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	// A verified certificate can still be rejected by the verifier.
+	code, _ = get(NewMTLSHTTPClient(MTLSClientConfig(issue("bob"), roots)))
+	if code != http.StatusUnauthorized {
+		t.Errorf("unknown client: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a [TokenStore] when it has no token for a key.
+var ErrTokenNotFound = errors.New("token not found")
+
+// A TokenStore persists OAuth tokens, so that a client can reuse its access
+// and refresh tokens across process restarts instead of repeating an
+// interactive authorization flow.
+//
+// Tokens are stored under a key, which typically identifies the server the
+// token grants access to.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type TokenStore interface {
+	// Load returns the token stored under key.
+	//
+	// Returns ErrTokenNotFound if there is no such token.
+	Load(ctx context.Context, key string) (*oauth2.Token, error)
+
+	// Save stores tok under key, replacing any existing token.
+	Save(ctx context.Context, key string, tok *oauth2.Token) error
+
+	// Delete removes the token stored under key.
+	// It is not an error to delete a token that does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// FileTokenStore is a [TokenStore] that keeps all tokens in a single JSON
+// file. The file is created with permissions that allow only the current
+// user to read it.
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex // serializes access to the file
+}
+
+// NewFileTokenStore returns a [*FileTokenStore] that stores tokens in the file
+// at path. The file and its parent directory are created when the first
+// token is saved.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load implements [TokenStore.Load].
+func (s *FileTokenStore) Load(_ context.Context, key string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	toks, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := toks[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return tok, nil
+}
+
+// Save implements [TokenStore.Save].
+func (s *FileTokenStore) Save(_ context.Context, key string, tok *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	toks, err := s.read()
+	if err != nil {
+		return err
+	}
+	toks[key] = tok
+	return s.write(toks)
+}
+
+// Delete implements [TokenStore.Delete].
+func (s *FileTokenStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	toks, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := toks[key]; !ok {
+		return nil
+	}
+	delete(toks, key)
+	return s.write(toks)
+}
+
+// read returns the contents of the file. A missing file is treated as empty.
+func (s *FileTokenStore) read() (map[string]*oauth2.Token, error) {
+	toks := make(map[string]*oauth2.Token)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return toks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &toks); err != nil {
+		return nil, fmt.Errorf("reading token file %s: %w", s.path, err)
+	}
+	return toks, nil
+}
+
+// write replaces the contents of the file with toks.
+// It writes to a temporary file first, so that a crash cannot leave a
+// partially written file behind.
+func (s *FileTokenStore) write(toks map[string]*oauth2.Token) error {
+	data, err := json.Marshal(toks)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // ignore error; fails after a successful rename
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// CreateTemp uses mode 0600, so the token file is private to the user.
+	return os.Rename(f.Name(), s.path)
+}
+
+// A Keyring is a store of secrets provided by the operating system, such as
+// the macOS Keychain, the Windows Credential Manager, or the Secret Service
+// on Linux.
+//
+// Its methods match the functions of the github.com/zalando/go-keyring
+// package, so a thin adapter around that package (or a similar one) can be
+// used with [KeyringTokenStore] without this module depending on it.
+type Keyring interface {
+	// Get returns the secret for the given service and user.
+	// It must return an error that unwraps to [ErrTokenNotFound] if there
+	// is no such secret.
+	Get(service, user string) (string, error)
+	// Set stores the secret for the given service and user.
+	Set(service, user, secret string) error
+	// Delete removes the secret for the given service and user.
+	Delete(service, user string) error
+}
+
+// KeyringTokenStore is a [TokenStore] that keeps tokens in a [Keyring].
+// Each token is stored as a JSON-encoded secret, under the store's service
+// name and the token's key.
+type KeyringTokenStore struct {
+	keyring Keyring
+	service string
+}
+
+// NewKeyringTokenStore returns a [*KeyringTokenStore] that stores tokens in
+// the given keyring under the given service name.
+func NewKeyringTokenStore(keyring Keyring, service string) *KeyringTokenStore {
+	return &KeyringTokenStore{keyring: keyring, service: service}
+}
+
+// Load implements [TokenStore.Load].
+func (s *KeyringTokenStore) Load(_ context.Context, key string) (*oauth2.Token, error) {
+	secret, err := s.keyring.Get(s.service, key)
+	if err != nil {
+		return nil, err
+	}
+	var tok oauth2.Token
+	if err := json.Unmarshal([]byte(secret), &tok); err != nil {
+		return nil, fmt.Errorf("decoding token for %q: %w", key, err)
+	}
+	return &tok, nil
+}
+
+// Save implements [TokenStore.Save].
+func (s *KeyringTokenStore) Save(_ context.Context, key string, tok *oauth2.Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	return s.keyring.Set(s.service, key, string(data))
+}
+
+// Delete implements [TokenStore.Delete].
+func (s *KeyringTokenStore) Delete(_ context.Context, key string) error {
+	if err := s.keyring.Delete(s.service, key); err != nil && !errors.Is(err, ErrTokenNotFound) {
+		return err
+	}
+	return nil
+}
+
+// A persistingTokenSource saves each new token produced by its underlying
+// source to a TokenStore.
+type persistingTokenSource struct {
+	src   oauth2.TokenSource
+	store TokenStore
+	key   string
+
+	mu   sync.Mutex
+	last *oauth2.Token // last token successfully saved
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil || s.last.AccessToken != tok.AccessToken || s.last.RefreshToken != tok.RefreshToken {
+		// Failing to persist the token should not fail the request: the token
+		// is still good for this process. Leave last unchanged, so that we try
+		// again next time.
+		if err := s.store.Save(context.Background(), s.key, tok); err == nil {
+			s.last = tok
+		}
+	}
+	return tok, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sub", "tokens.json")
+	store := NewFileTokenStore(path)
+
+	if _, err := store.Load(ctx, "k"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Load on empty store: got %v, want ErrTokenNotFound", err)
+	}
+	want := &oauth2.Token{AccessToken: "a", RefreshToken: "r", TokenType: "Bearer"}
+	if err := store.Save(ctx, "k", want); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("token file has permissions %v, want no group or other access", perm)
+	}
+
+	// A new store for the same file sees the saved token.
+	got, err := NewFileTokenStore(path).Load(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AccessToken != want.AccessToken || got.RefreshToken != want.RefreshToken {
+		t.Errorf("Load: got %+v, want %+v", got, want)
+	}
+
+	if err := store.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "k"); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+	if _, err := store.Load(ctx, "k"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load after Delete: got %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestHTTPTransportTokenStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer saved" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	var gotTokens []*oauth2.Token
+	handler := func(ctx context.Context, args OAuthHandlerArgs) (oauth2.TokenSource, error) {
+		gotTokens = append(gotTokens, args.Token)
+		if args.Token != nil {
+			return oauth2.StaticTokenSource(args.Token), nil
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "saved", TokenType: "Bearer"}), nil
+	}
+
+	// Simulate two runs of a program by using two transports.
+	for range 2 {
+		transport, err := NewHTTPTransport(handler, &HTTPTransportOptions{TokenStore: store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	}
+	if len(gotTokens) != 2 {
+		t.Fatalf("handler called %d times, want 2", len(gotTokens))
+	}
+	if gotTokens[0] != nil {
+		t.Errorf("first run: handler got token %+v, want nil", gotTokens[0])
+	}
+	if gotTokens[1] == nil || gotTokens[1].AccessToken != "saved" {
+		t.Errorf("second run: handler got token %+v, want the saved token", gotTokens[1])
+	}
+}
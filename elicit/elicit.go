@@ -0,0 +1,311 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package elicit builds schemas for MCP elicitation requests.
+//
+// The MCP specification restricts the requested schema of an elicitation to a
+// flat object whose properties are strings, numbers, integers, booleans or
+// string enums, each with a small set of allowed keywords. A [Schema] can only
+// express such schemas: options that don't apply to a property's type are
+// rejected at compile time.
+//
+//	schema, err := elicit.NewSchema().
+//		String("email", elicit.Format(elicit.FormatEmail), elicit.Required()).
+//		Integer("age", elicit.Min(0)).
+//		Boolean("subscribe", elicit.DefaultBool(true)).
+//		Enum("plan", []string{"free", "pro"}, elicit.EnumNames("Free", "Pro")).
+//		Build()
+//
+// The result can be used as [mcp.ElicitParams.RequestedSchema].
+package elicit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// A Schema builds an elicitation schema.
+// Create one with [NewSchema], add properties with its methods, and call
+// [Schema.Build] to produce the JSON schema.
+type Schema struct {
+	props []*property
+}
+
+type property struct {
+	name     string
+	schema   *jsonschema.Schema
+	required bool
+	err      error // first error from an option
+}
+
+// NewSchema returns an empty Schema.
+func NewSchema() *Schema {
+	return &Schema{}
+}
+
+// String adds a string property.
+func (s *Schema) String(name string, opts ...StringOption) *Schema {
+	p := s.add(name, "string")
+	for _, o := range opts {
+		o.applyString(p)
+	}
+	return s
+}
+
+// Number adds a number property.
+func (s *Schema) Number(name string, opts ...NumberOption) *Schema {
+	p := s.add(name, "number")
+	for _, o := range opts {
+		o.applyNumber(p)
+	}
+	return s
+}
+
+// Integer adds an integer property.
+func (s *Schema) Integer(name string, opts ...NumberOption) *Schema {
+	p := s.add(name, "integer")
+	for _, o := range opts {
+		o.applyNumber(p)
+	}
+	return s
+}
+
+// Boolean adds a boolean property.
+func (s *Schema) Boolean(name string, opts ...BooleanOption) *Schema {
+	p := s.add(name, "boolean")
+	for _, o := range opts {
+		o.applyBoolean(p)
+	}
+	return s
+}
+
+// Enum adds a string property whose value must be one of values.
+func (s *Schema) Enum(name string, values []string, opts ...EnumOption) *Schema {
+	p := s.add(name, "string")
+	for _, v := range values {
+		p.schema.Enum = append(p.schema.Enum, v)
+	}
+	if len(values) == 0 {
+		p.setErr(errors.New("no enum values"))
+	}
+	for _, o := range opts {
+		o.applyEnum(p)
+	}
+	return s
+}
+
+func (s *Schema) add(name, typ string) *property {
+	p := &property{name: name, schema: &jsonschema.Schema{Type: typ}}
+	s.props = append(s.props, p)
+	return p
+}
+
+// Build returns the JSON schema for s.
+// It reports an error if the schema is invalid in ways that can't be
+// detected at compile time, such as a duplicate property name or a minimum
+// greater than the maximum.
+func (s *Schema) Build() (*jsonschema.Schema, error) {
+	out := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema),
+	}
+	for _, p := range s.props {
+		if err := p.check(); err != nil {
+			return nil, fmt.Errorf("elicit: property %q: %w", p.name, err)
+		}
+		if _, ok := out.Properties[p.name]; ok {
+			return nil, fmt.Errorf("elicit: duplicate property %q", p.name)
+		}
+		out.Properties[p.name] = p.schema
+		if p.required {
+			out.Required = append(out.Required, p.name)
+		}
+	}
+	return out, nil
+}
+
+func (p *property) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *property) check() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.name == "" {
+		return errors.New("empty name")
+	}
+	s := p.schema
+	if s.MinLength != nil && s.MaxLength != nil && *s.MaxLength < *s.MinLength {
+		return fmt.Errorf("maxLength %d less than minLength %d", *s.MaxLength, *s.MinLength)
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Maximum < *s.Minimum {
+		return fmt.Errorf("maximum %g less than minimum %g", *s.Maximum, *s.Minimum)
+	}
+	if names, ok := s.Extra["enumNames"].([]string); ok && len(names) != len(s.Enum) {
+		return fmt.Errorf("%d enum values but %d enumNames", len(s.Enum), len(names))
+	}
+	if len(s.Enum) > 0 && s.Default != nil {
+		var def string
+		if err := json.Unmarshal(s.Default, &def); err != nil || !slices.Contains(s.Enum, any(def)) {
+			return fmt.Errorf("default %s is not an enum value", s.Default)
+		}
+	}
+	return nil
+}
+
+// A StringOption configures a property added with [Schema.String].
+type StringOption interface{ applyString(*property) }
+
+// A NumberOption configures a property added with [Schema.Number] or
+// [Schema.Integer].
+type NumberOption interface{ applyNumber(*property) }
+
+// A BooleanOption configures a property added with [Schema.Boolean].
+type BooleanOption interface{ applyBoolean(*property) }
+
+// An EnumOption configures a property added with [Schema.Enum].
+type EnumOption interface{ applyEnum(*property) }
+
+// An Option configures a property of any type.
+type Option func(*property)
+
+func (o Option) applyString(p *property)  { o(p) }
+func (o Option) applyNumber(p *property)  { o(p) }
+func (o Option) applyBoolean(p *property) { o(p) }
+func (o Option) applyEnum(p *property)    { o(p) }
+
+// Required marks the property as required.
+func Required() Option {
+	return func(p *property) { p.required = true }
+}
+
+// Title sets the title of the property, for display to the user.
+func Title(title string) Option {
+	return func(p *property) { p.schema.Title = title }
+}
+
+// Description sets the description of the property.
+func Description(desc string) Option {
+	return func(p *property) { p.schema.Description = desc }
+}
+
+type stringOption func(*property)
+
+func (o stringOption) applyString(p *property) { o(p) }
+
+// A StringFormat is a format allowed for string properties.
+type StringFormat string
+
+// The string formats allowed by the MCP specification.
+const (
+	FormatEmail    StringFormat = "email"
+	FormatURI      StringFormat = "uri"
+	FormatDate     StringFormat = "date"
+	FormatDateTime StringFormat = "date-time"
+)
+
+// Format sets the format of a string property.
+func Format(f StringFormat) StringOption {
+	return stringOption(func(p *property) {
+		switch f {
+		case FormatEmail, FormatURI, FormatDate, FormatDateTime:
+			p.schema.Format = string(f)
+		default:
+			p.setErr(fmt.Errorf("unsupported format %q", f))
+		}
+	})
+}
+
+// MinLength sets the minimum length of a string property.
+func MinLength(n int) StringOption {
+	return stringOption(func(p *property) {
+		if n < 0 {
+			p.setErr(fmt.Errorf("negative minLength %d", n))
+		}
+		p.schema.MinLength = &n
+	})
+}
+
+// MaxLength sets the maximum length of a string property.
+func MaxLength(n int) StringOption {
+	return stringOption(func(p *property) {
+		if n < 0 {
+			p.setErr(fmt.Errorf("negative maxLength %d", n))
+		}
+		p.schema.MaxLength = &n
+	})
+}
+
+type numberOption func(*property)
+
+func (o numberOption) applyNumber(p *property) { o(p) }
+
+// Min sets the minimum of a number or integer property.
+func Min(x float64) NumberOption {
+	return numberOption(func(p *property) { p.schema.Minimum = &x })
+}
+
+// Max sets the maximum of a number or integer property.
+func Max(x float64) NumberOption {
+	return numberOption(func(p *property) { p.schema.Maximum = &x })
+}
+
+// DefaultNumber sets the default value of a number or integer property.
+func DefaultNumber(x float64) NumberOption {
+	return numberOption(func(p *property) { p.setDefault(x) })
+}
+
+type booleanOption func(*property)
+
+func (o booleanOption) applyBoolean(p *property) { o(p) }
+
+// DefaultBool sets the default value of a boolean property.
+func DefaultBool(b bool) BooleanOption {
+	return booleanOption(func(p *property) { p.setDefault(b) })
+}
+
+type enumOption func(*property)
+
+func (o enumOption) applyEnum(p *property) { o(p) }
+
+// EnumNames sets display names for the values of an enum property.
+// There must be one name for each value.
+func EnumNames(names ...string) EnumOption {
+	return enumOption(func(p *property) {
+		p.schema.Extra = map[string]any{"enumNames": names}
+	})
+}
+
+type defaultStringOption func(*property)
+
+func (o defaultStringOption) applyString(p *property) { o(p) }
+func (o defaultStringOption) applyEnum(p *property)   { o(p) }
+
+// A StringEnumOption configures a property added with [Schema.String] or
+// [Schema.Enum].
+type StringEnumOption interface {
+	StringOption
+	EnumOption
+}
+
+// DefaultString sets the default value of a string or enum property.
+func DefaultString(s string) StringEnumOption {
+	return defaultStringOption(func(p *property) { p.setDefault(s) })
+}
+
+func (p *property) setDefault(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	p.schema.Default = data
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package elicit_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/orkhanm/go-sdk/elicit"
+	"github.com/orkhanm/go-sdk/mcp"
+)
+
+func TestBuild(t *testing.T) {
+	got, err := elicit.NewSchema().
+		String("email", elicit.Format(elicit.FormatEmail), elicit.Required(), elicit.Title("Email")).
+		String("name", elicit.MinLength(1), elicit.MaxLength(50), elicit.DefaultString("anon")).
+		Integer("age", elicit.Min(0), elicit.Max(150)).
+		Number("score", elicit.DefaultNumber(0.5)).
+		Boolean("subscribe", elicit.DefaultBool(true), elicit.Description("Receive updates")).
+		Enum("color", []string{"red", "green"}, elicit.DefaultString("red"), elicit.Required()).
+		Enum("plan", []string{"free", "pro"}, elicit.EnumNames("Free", "Pro")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"email":     {Type: "string", Format: "email", Title: "Email"},
+			"name":      {Type: "string", MinLength: jsonschema.Ptr(1), MaxLength: jsonschema.Ptr(50), Default: json.RawMessage(`"anon"`)},
+			"age":       {Type: "integer", Minimum: jsonschema.Ptr(0.0), Maximum: jsonschema.Ptr(150.0)},
+			"score":     {Type: "number", Default: json.RawMessage(`0.5`)},
+			"subscribe": {Type: "boolean", Default: json.RawMessage(`true`), Description: "Receive updates"},
+			"color":     {Type: "string", Enum: []any{"red", "green"}, Default: json.RawMessage(`"red"`)},
+			"plan":      {Type: "string", Enum: []any{"free", "pro"}, Extra: map[string]any{"enumNames": []string{"Free", "Pro"}}},
+		},
+		Required: []string{"email", "color"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		schema *elicit.Schema
+		want   string
+	}{
+		{"duplicate", elicit.NewSchema().String("a").Boolean("a"), "duplicate"},
+		{"empty name", elicit.NewSchema().String(""), "empty name"},
+		{"format", elicit.NewSchema().String("a", elicit.Format("phone")), "unsupported format"},
+		{"lengths", elicit.NewSchema().String("a", elicit.MinLength(5), elicit.MaxLength(2)), "maxLength"},
+		{"negative length", elicit.NewSchema().String("a", elicit.MinLength(-1)), "negative"},
+		{"range", elicit.NewSchema().Number("a", elicit.Min(5), elicit.Max(2)), "maximum"},
+		{"no values", elicit.NewSchema().Enum("a", nil), "no enum values"},
+		{"enum names", elicit.NewSchema().Enum("a", []string{"x", "y"}, elicit.EnumNames("X")), "enumNames"},
+		{"enum default", elicit.NewSchema().Enum("a", []string{"x"}, elicit.DefaultString("y")), "not an enum value"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.schema.Build()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+// TestElicit checks that built schemas are accepted by the client.
+func TestElicit(t *testing.T) {
+	ctx := context.Background()
+	schema, err := elicit.NewSchema().
+		String("email", elicit.Format(elicit.FormatEmail), elicit.Required()).
+		Integer("age", elicit.Min(0)).
+		Enum("color", []string{"red", "green"}, elicit.EnumNames("Red", "Green")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "v0.0.1"}, nil)
+	c := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, &mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			choices, err := req.Params.EnumChoices("color")
+			if err != nil {
+				return nil, err
+			}
+			if len(choices) != 2 || choices[1].Label != "Green" {
+				t.Errorf("got choices %v, want Red and Green", choices)
+			}
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"email": "a@example.com", "age": 3, "color": "red"}}, nil
+		},
+	})
+	t1, t2 := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, t1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := c.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	res, err := ss.Elicit(ctx, &mcp.ElicitParams{Message: "who are you?", RequestedSchema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Action != "accept" {
+		t.Errorf("got action %q, want accept", res.Action)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// The mcpbench command benchmarks tool calls on an MCP server, reporting
+// latency percentiles and error rates.
+//
+// Usage: mcpbench [flags] <URL>
+//
+// or
+//
+//	mcpbench [flags] <command> [<args>]
+//
+// For example:
+//
+//	mcpbench -tool=greet -args='{"name": "foo"}' -sessions=10 -rate=50 http://localhost:8080
+//	mcpbench -tool=greet -payload=1024 go run ./examples/server/hello
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/orkhanm/go-sdk/mcp"
+	"github.com/orkhanm/go-sdk/mcpbench"
+)
+
+var (
+	duration = flag.Duration("duration", 10*time.Second, "duration of the benchmark")
+	sessions = flag.Int("sessions", 1, "number of concurrent sessions")
+	rate     = flag.Float64("rate", 0, "tool calls per second, per session; if 0, call as fast as possible")
+	timeout  = flag.Duration("timeout", 0, "if set, timeout of each call")
+	tool     = flag.String("tool", "", "tool to call")
+	jsonArgs = flag.String("args", "", "JSON object of arguments to pass")
+	payload  = flag.Int("payload", 0, "if set, size in bytes of an additional string argument")
+	argName  = flag.String("payload_arg", "payload", "name of the payload argument")
+)
+
+func main() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintln(out, "Usage: mcpbench [flags] <URL>")
+		fmt.Fprintln(out, "       mcpbench [flags] <command> [<args>]")
+		fmt.Fprintln(out, "Benchmark tool calls on a streamable HTTP or stdio server (CTRL-C to end early)")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 || *tool == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	w := &mcpbench.Workload{
+		Sessions:        *sessions,
+		Rate:            *rate,
+		Duration:        *duration,
+		Timeout:         *timeout,
+		Tool:            *tool,
+		PayloadSize:     *payload,
+		PayloadArgument: *argName,
+	}
+	if *jsonArgs != "" {
+		if err := json.Unmarshal([]byte(*jsonArgs), &w.Arguments); err != nil {
+			log.Fatalf("invalid -args: %v", err)
+		}
+	}
+
+	newTransport := func() (mcp.Transport, error) {
+		if len(args) == 1 && (strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://")) {
+			return &mcp.StreamableClientTransport{Endpoint: args[0]}, nil
+		}
+		return &mcp.CommandTransport{Command: exec.Command(args[0], args[1:]...)}, nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := mcpbench.Run(ctx, newTransport, w)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(report)
+}
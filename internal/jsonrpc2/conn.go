@@ -67,6 +67,7 @@ type Connection struct {
 
 	writer  Writer
 	handler Handler
+	direct  bool // from [ConnectionConfig.Direct]
 
 	onInternalError func(error)
 	onDone          func()
@@ -186,7 +187,8 @@ func (s *inFlightState) shuttingDown(errClosing error) error {
 type incomingRequest struct {
 	*Request // the request being processed
 	ctx      context.Context
-	cancel   context.CancelFunc
+	cancel   context.CancelCauseFunc
+	received time.Time // when the request was read
 }
 
 // Bind returns the options unmodified.
@@ -203,6 +205,12 @@ type ConnectionConfig struct {
 	Bind            func(*Connection) Handler // required
 	OnDone          func()                    // optional
 	OnInternalError func(error)               // optional
+
+	// Direct, if set, means that the Writer delivers messages to a peer in
+	// the same process, without encoding them. The params and results of
+	// outgoing messages are then not marshaled: the peer receives them as Go
+	// values in the Extra field of messages, available from [DirectValue].
+	Direct bool // optional
 }
 
 // NewConnection creates a new [Connection] object and starts processing
@@ -214,6 +222,7 @@ func NewConnection(ctx context.Context, cfg ConnectionConfig) *Connection {
 		state:           inFlightState{closer: cfg.Closer},
 		done:            make(chan struct{}),
 		writer:          cfg.Writer,
+		direct:          cfg.Direct,
 		onDone:          cfg.OnDone,
 		onInternalError: cfg.OnInternalError,
 	}
@@ -312,9 +321,14 @@ func (c *Connection) Notify(ctx context.Context, method string, params any) (err
 		return err
 	}
 
-	notify, err := NewNotification(method, params)
-	if err != nil {
-		return fmt.Errorf("marshaling notify parameters: %v", err)
+	var notify *Request
+	if c.direct {
+		notify = &Request{Method: method, Extra: &directValue{params}}
+	} else {
+		notify, err = NewNotification(method, params)
+		if err != nil {
+			return fmt.Errorf("marshaling notify parameters: %v", err)
+		}
 	}
 
 	return c.write(ctx, notify)
@@ -337,10 +351,18 @@ func (c *Connection) Call(ctx context.Context, method string, params any) *Async
 	// written successfully and the call is awaiting a response (to be provided by
 	// the readIncoming goroutine).
 
-	call, err := NewCall(ac.id, method, params)
-	if err != nil {
-		ac.retire(&Response{ID: id, Error: fmt.Errorf("marshaling call parameters: %w", err)})
-		return ac
+	var (
+		call *Request
+		err  error
+	)
+	if c.direct {
+		call = &Request{ID: id, Method: method, Extra: &directValue{params}}
+	} else {
+		call, err = NewCall(ac.id, method, params)
+		if err != nil {
+			ac.retire(&Response{ID: id, Error: fmt.Errorf("marshaling call parameters: %w", err)})
+			return ac
+		}
 	}
 
 	c.updateInFlight(func(s *inFlightState) {
@@ -462,6 +484,9 @@ func (ac *AsyncCall) Await(ctx context.Context, result any) error {
 	if result == nil {
 		return nil
 	}
+	if v, ok := DirectValue(ac.response); ok {
+		return assignDirect(v, result)
+	}
 	return json.Unmarshal(ac.response.Result, result)
 }
 
@@ -472,13 +497,37 @@ func (ac *AsyncCall) Await(ctx context.Context, result any) error {
 // will not cause any messages that have not arrived yet with that ID to be
 // cancelled.
 func (c *Connection) Cancel(id ID) {
+	c.CancelCause(id, nil)
+}
+
+// CancelCause is like [Connection.Cancel], but sets the cause of the
+// cancellation of the Context, as reported by [context.Cause].
+func (c *Connection) CancelCause(id ID, cause error) {
 	var req *incomingRequest
 	c.updateInFlight(func(s *inFlightState) {
 		req = s.incomingByID[id]
 	})
 	if req != nil {
-		req.cancel()
+		req.cancel(cause)
+	}
+}
+
+// CancelReceivedBefore cancels the Contexts of the inbound calls that were
+// received before t and are still being handled, with the given cause. It
+// returns the number of calls cancelled.
+func (c *Connection) CancelReceivedBefore(t time.Time, cause error) int {
+	var reqs []*incomingRequest
+	c.updateInFlight(func(s *inFlightState) {
+		for _, req := range s.incomingByID {
+			if req.received.Before(t) {
+				reqs = append(reqs, req)
+			}
+		}
+	})
+	for _, req := range reqs {
+		req.cancel(cause)
 	}
+	return len(reqs)
 }
 
 // Wait blocks until the connection is fully closed, but does not close it.
@@ -575,11 +624,12 @@ func (c *Connection) readIncoming(ctx context.Context, reader Reader, preempter
 func (c *Connection) acceptRequest(ctx context.Context, msg *Request, preempter Preempter) {
 	// In theory notifications cannot be cancelled, but we build them a cancel
 	// context anyway.
-	reqCtx, cancel := context.WithCancel(ctx)
+	reqCtx, cancel := context.WithCancelCause(ctx)
 	req := &incomingRequest{
-		Request: msg,
-		ctx:     reqCtx,
-		cancel:  cancel,
+		Request:  msg,
+		ctx:      reqCtx,
+		cancel:   cancel,
+		received: time.Now(),
 	}
 
 	// If the request is a call, add it to the incoming map so it can be
@@ -718,7 +768,19 @@ func (c *Connection) processResult(from any, req *incomingRequest, result any, e
 			err = c.internalErrorf("%#v returned a nil result and nil error for a %q Request that requires a Response", from, req.Method)
 		}
 
-		response, respErr := NewResponse(req.ID, result, err)
+		var (
+			response *Response
+			respErr  error
+		)
+		if c.direct {
+			response = &Response{ID: req.ID, Extra: &directValue{result}}
+			if err != nil {
+				// Deliver the error as the peer would decode it from the wire.
+				response.Error = toWireError(err)
+			}
+		} else {
+			response, respErr = NewResponse(req.ID, result, err)
+		}
 
 		// The caller could theoretically reuse the request's ID as soon as we've
 		// sent the response, so ensure that it is removed from the incoming map
@@ -747,7 +809,7 @@ func (c *Connection) processResult(from any, req *incomingRequest, result any, e
 	}
 
 	// Cancel the request to free any associated resources.
-	req.cancel()
+	req.cancel(nil)
 	c.updateInFlight(func(s *inFlightState) {
 		if s.incoming == 0 {
 			panic("jsonrpc2: processResult called when incoming count is already zero")
@@ -791,7 +853,7 @@ func (c *Connection) write(ctx context.Context, msg Message) error {
 			if s.writeErr == nil {
 				s.writeErr = err
 				for _, r := range s.incomingByID {
-					r.cancel()
+					r.cancel(nil)
 				}
 			}
 		})
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // ID is a Request identifier, which is defined by the spec to be a string, integer, or null.
@@ -143,6 +144,10 @@ func toWireError(err error) *WireError {
 }
 
 func EncodeMessage(msg Message) ([]byte, error) {
+	msg, err := wireMessage(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling jsonrpc message: %w", err)
+	}
 	wire := wireCombined{VersionTag: wireVersion}
 	msg.marshal(&wire)
 	data, err := json.Marshal(&wire)
@@ -156,6 +161,10 @@ func EncodeMessage(msg Message) ([]byte, error) {
 // TODO(rfindley): refactor so that this concern is handled independently.
 // Perhaps we should pass in a json.Encoder?
 func EncodeIndent(msg Message, prefix, indent string) ([]byte, error) {
+	msg, err := wireMessage(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling jsonrpc message: %w", err)
+	}
 	wire := wireCombined{VersionTag: wireVersion}
 	msg.marshal(&wire)
 	data, err := json.MarshalIndent(&wire, prefix, indent)
@@ -200,6 +209,69 @@ func DecodeMessage(data []byte) (Message, error) {
 	return resp, nil
 }
 
+// DirectValue returns the params of a request, or the result of a response,
+// that was written by a direct connection without marshaling it (see
+// [ConnectionConfig.Direct]). It reports false for other messages, whose
+// params or result are in their Params or Result fields.
+func DirectValue(msg Message) (v any, ok bool) {
+	var extra any
+	switch msg := msg.(type) {
+	case *Request:
+		extra = msg.Extra
+	case *Response:
+		extra = msg.Extra
+	}
+	if d, ok := extra.(*directValue); ok {
+		return d.v, true
+	}
+	return nil, false
+}
+
+// A directValue is the Extra of a message written by a direct connection. It
+// holds the params or result of the message, which is otherwise unset.
+type directValue struct {
+	v any
+}
+
+// wireMessage returns msg, or a copy of it with the value of a direct message
+// marshaled into its Params or Result.
+func wireMessage(msg Message) (Message, error) {
+	v, ok := DirectValue(msg)
+	if !ok {
+		return msg, nil
+	}
+	data, err := marshalToRaw(v)
+	if err != nil {
+		return nil, err
+	}
+	switch m := msg.(type) {
+	case *Request:
+		return &Request{ID: m.ID, Method: m.Method, Params: data}, nil
+	case *Response:
+		return &Response{ID: m.ID, Result: data, Error: m.Error}, nil
+	}
+	panic("unreachable")
+}
+
+// assignDirect stores the value of a direct message in result, as
+// json.Unmarshal would store its encoding. If v is a non-nil pointer of the
+// type of result, the value that it points to is copied (shallowly).
+// Otherwise, v is converted through JSON.
+func assignDirect(v, result any) error {
+	if v != nil {
+		rv, vv := reflect.ValueOf(result), reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer && rv.Type() == vv.Type() && !rv.IsNil() && !vv.IsNil() {
+			rv.Elem().Set(vv.Elem())
+			return nil
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
 func marshalToRaw(obj any) (json.RawMessage, error) {
 	if obj == nil {
 		return nil, nil
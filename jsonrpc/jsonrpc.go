@@ -37,3 +37,25 @@ func EncodeMessage(msg Message) ([]byte, error) {
 func DecodeMessage(data []byte) (Message, error) {
 	return jsonrpc2.DecodeMessage(data)
 }
+
+// A Codec encodes and decodes JSON-RPC messages to and from their wire format.
+//
+// The standard MCP transports always use [JSONCodec], as the specification
+// requires. Custom transports may use another Codec, for example CBOR or
+// MessagePack, when both peers agree on it. The Params of a Request and the
+// Result of a Response are JSON, so such a Codec transcodes them.
+type Codec interface {
+	// Encode serializes a message.
+	Encode(Message) ([]byte, error)
+	// Decode deserializes a message, returning a Request or Response.
+	Decode([]byte) (Message, error)
+}
+
+// JSONCodec is the [Codec] for the JSON wire format, implemented by
+// [EncodeMessage] and [DecodeMessage].
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(msg Message) ([]byte, error)  { return EncodeMessage(msg) }
+func (jsonCodec) Decode(data []byte) (Message, error) { return DecodeMessage(data) }
@@ -0,0 +1,59 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// AuthzInput describes a request that is subject to authorization by
+// [ServerOptions.Authorize].
+type AuthzInput struct {
+	// Session is the session on which the request was received.
+	Session *ServerSession
+	// Method is the MCP method: "tools/call", "resources/read" or "prompts/get".
+	Method string
+	// Target is the name of the tool or prompt, or the URI of the resource.
+	Target string
+	// Arguments holds the tool or prompt arguments, as JSON. It is nil for
+	// resources/read, or if no arguments were provided.
+	Arguments json.RawMessage
+	// Extra holds information from the transport, such as the verified
+	// bearer token and the HTTP header. It may be nil.
+	Extra *RequestExtra
+}
+
+// A Decision is the result of an authorization check.
+type Decision struct {
+	// Allow reports whether the request may proceed.
+	Allow bool
+	// Reason optionally explains a denial. It is returned to the client.
+	Reason string
+}
+
+// authorize calls the Authorize option, if any, and returns a non-nil error
+// if the request should not proceed.
+func (s *Server) authorize(ctx context.Context, in *AuthzInput) error {
+	if s.opts.Authorize == nil {
+		return nil
+	}
+	d, err := s.opts.Authorize(ctx, in)
+	if err != nil {
+		return fmt.Errorf("authorizing %s %q: %w", in.Method, in.Target, err)
+	}
+	if d.Allow {
+		return nil
+	}
+	s.opts.Logger.Info("request denied", "method", in.Method, "target", in.Target, "reason", d.Reason)
+	msg := fmt.Sprintf("%s %q: permission denied", in.Method, in.Target)
+	if d.Reason != "" {
+		msg += ": " + d.Reason
+	}
+	return jsonrpc2.NewError(CodeForbidden, msg)
+}
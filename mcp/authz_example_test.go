@@ -0,0 +1,101 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/orkhanm/go-sdk/mcp"
+)
+
+// evaluator is the shape of a policy engine query, such as an OPA
+// rego.PreparedEvalQuery wrapped to return a single boolean result:
+//
+//	query, _ := rego.New(rego.Query("data.mcp.allow"), rego.Module("mcp.rego", src)).PrepareForEval(ctx)
+//	eval := func(ctx context.Context, input map[string]any) (bool, error) {
+//		rs, err := query.Eval(ctx, rego.EvalInput(input))
+//		if err != nil {
+//			return false, err
+//		}
+//		return rs.Allowed(), nil
+//	}
+type evaluator func(ctx context.Context, input map[string]any) (bool, error)
+
+// policyAuthorizer adapts a policy evaluator to [mcp.ServerOptions.Authorize].
+func policyAuthorizer(eval evaluator) func(context.Context, *mcp.AuthzInput) (mcp.Decision, error) {
+	return func(ctx context.Context, in *mcp.AuthzInput) (mcp.Decision, error) {
+		input := map[string]any{
+			"method": in.Method,
+			"target": in.Target,
+		}
+		if in.Arguments != nil {
+			var args any
+			if err := json.Unmarshal(in.Arguments, &args); err != nil {
+				return mcp.Decision{}, err
+			}
+			input["arguments"] = args
+		}
+		if in.Extra != nil && in.Extra.TokenInfo != nil {
+			input["scopes"] = in.Extra.TokenInfo.Scopes
+		}
+		allow, err := eval(ctx, input)
+		if err != nil {
+			return mcp.Decision{}, err
+		}
+		if !allow {
+			return mcp.Decision{Reason: "denied by policy"}, nil
+		}
+		return mcp.Decision{Allow: true}, nil
+	}
+}
+
+func ExampleServerOptions_authorize() {
+	ctx := context.Background()
+
+	// A stand-in for a policy such as:
+	//
+	//	package mcp
+	//	default allow := false
+	//	allow if input.target == "greet"
+	eval := func(_ context.Context, input map[string]any) (bool, error) {
+		return input["target"] == "greet", nil
+	}
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "v0.0.1"}, &mcp.ServerOptions{
+		Authorize: policyAuthorizer(eval),
+	})
+	handler := func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ran " + req.Params.Name}}}, nil
+	}
+	schema := json.RawMessage(`{"type":"object"}`)
+	s.AddTool(&mcp.Tool{Name: "greet", InputSchema: schema}, handler)
+	s.AddTool(&mcp.Tool{Name: "delete", InputSchema: schema}, handler)
+
+	c := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0.0.1"}, nil)
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, t1, nil); err != nil {
+		log.Fatal(err)
+	}
+	cs, err := c.Connect(ctx, t2, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cs.Close()
+
+	for _, name := range []string{"greet", "delete"} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name})
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(res.Content[0].(*mcp.TextContent).Text)
+	}
+	// Output:
+	// ran greet
+	// calling "tools/call": tools/call "delete": permission denied: denied by policy
+}
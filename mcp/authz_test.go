@@ -0,0 +1,80 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAuthorize(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		inputs []AuthzInput
+	)
+	authorize := func(_ context.Context, in *AuthzInput) (Decision, error) {
+		mu.Lock()
+		inputs = append(inputs, AuthzInput{Method: in.Method, Target: in.Target, Arguments: in.Arguments})
+		mu.Unlock()
+		switch in.Target {
+		case "greet", "code_review":
+			return Decision{Allow: true}, nil
+		case "info://broken":
+			return Decision{}, errors.New("policy unavailable")
+		default:
+			return Decision{Reason: "not in policy"}, nil
+		}
+	}
+	server := NewServer(testImpl, &ServerOptions{Authorize: authorize})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		AddTool(s, greetTool(), sayHi)
+		AddTool(s, &Tool{Name: "secret"}, sayHi)
+		s.AddPrompt(codeReviewPrompt, codReviewPromptHandler)
+		read := func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+			return &ReadResourceResult{Contents: []*ResourceContents{{Text: "x"}}}, nil
+		}
+		s.AddResource(&Resource{URI: "info://secret"}, read)
+		s.AddResource(&Resource{URI: "info://broken"}, read)
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}}); err != nil {
+		t.Errorf("allowed tool: %v", err)
+	}
+	if _, err := cs.GetPrompt(ctx, &GetPromptParams{Name: "code_review", Arguments: map[string]string{"Code": "x"}}); err != nil {
+		t.Errorf("allowed prompt: %v", err)
+	}
+	_, err := cs.CallTool(ctx, &CallToolParams{Name: "secret", Arguments: map[string]any{}})
+	if got := errorCode(err); got != CodeForbidden {
+		t.Errorf("denied tool: got code %d, want %d (err: %v)", got, CodeForbidden, err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "not in policy") {
+		t.Errorf("denied tool: got %v, want reason in error", err)
+	}
+	_, err = cs.ReadResource(ctx, &ReadResourceParams{URI: "info://secret"})
+	if got := errorCode(err); got != CodeForbidden {
+		t.Errorf("denied resource: got code %d, want %d (err: %v)", got, CodeForbidden, err)
+	}
+	if _, err := cs.ReadResource(ctx, &ReadResourceParams{URI: "info://broken"}); err == nil {
+		t.Error("resource with failing policy: got nil error")
+	}
+
+	want := []AuthzInput{
+		{Method: methodCallTool, Target: "greet", Arguments: []byte(`{"Name":"user"}`)},
+		{Method: methodGetPrompt, Target: "code_review", Arguments: []byte(`{"Code":"x"}`)},
+		{Method: methodCallTool, Target: "secret", Arguments: []byte(`{}`)},
+		{Method: methodReadResource, Target: "info://secret"},
+		{Method: methodReadResource, Target: "info://broken"},
+	}
+	if diff := cmp.Diff(want, inputs); diff != "" {
+		t.Errorf("Authorize inputs mismatch (-want +got):\n%s", diff)
+	}
+}
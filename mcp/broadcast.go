@@ -0,0 +1,64 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Broadcast sends the notification with the given method and params to every
+// session connected to the server, concurrently. The method must be a
+// notification method, such as "notifications/message".
+//
+// Broadcast waits for all sends to complete. It returns an error joining the
+// errors of the sessions for which sending failed, each of which mentions
+// the session ID. A failure for one session does not prevent sending to
+// the others.
+func (s *Server) Broadcast(ctx context.Context, method string, params Params) error {
+	if !strings.HasPrefix(method, "notifications/") {
+		return fmt.Errorf("Broadcast: %q is not a notification method", method)
+	}
+	return s.forEachSession(func(ss *ServerSession) error {
+		return handleNotify(ctx, method, newServerRequest(ss, params))
+	})
+}
+
+// LogToAll sends a log message to every connected session, as with
+// [ServerSession.Log]. Sessions whose log level is above that of the
+// message, or that have not set a log level, do not receive it.
+//
+// Errors are reported as with [Server.Broadcast].
+func (s *Server) LogToAll(ctx context.Context, params *LoggingMessageParams) error {
+	return s.forEachSession(func(ss *ServerSession) error {
+		return ss.Log(ctx, params)
+	})
+}
+
+// forEachSession calls f concurrently for each session, and joins the
+// resulting errors.
+func (s *Server) forEachSession(f func(*ServerSession) error) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for ss := range s.Sessions() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(ss); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("session %q: %w", ss.ID(), err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
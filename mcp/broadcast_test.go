@@ -0,0 +1,96 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer(testImpl, nil)
+	errFail := errors.New("fail")
+	var failing *ServerSession
+	s.AddSendingMiddleware(func(h MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if req.GetSession() == failing && strings.HasPrefix(method, "notifications/") {
+				return nil, errFail
+			}
+			return h(ctx, method, req)
+		}
+	})
+
+	const n = 3
+	var (
+		chans    []chan string
+		sessions []*ServerSession
+	)
+	for i := range n {
+		msgs := make(chan string, 10)
+		chans = append(chans, msgs)
+		c := NewClient(testImpl, &ClientOptions{
+			LoggingMessageHandler: func(_ context.Context, req *LoggingMessageRequest) {
+				msgs <- req.Params.Data.(string)
+			},
+		})
+		cs, ss, cleanup := basicClientServerConnection(t, c, s, nil)
+		defer cleanup()
+		sessions = append(sessions, ss)
+		if i > 0 { // the first session never sets a log level
+			if err := cs.SetLoggingLevel(ctx, &SetLoggingLevelParams{Level: "info"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	receive := func(i int) string {
+		t.Helper()
+		select {
+		case m := <-chans[i]:
+			return m
+		case <-time.After(time.Second):
+			return "<none>"
+		}
+	}
+
+	if err := s.LogToAll(ctx, &LoggingMessageParams{Level: "info", Data: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < n; i++ {
+		if got := receive(i); got != "hello" {
+			t.Errorf("session %d: got %q, want %q", i, got, "hello")
+		}
+	}
+
+	// Broadcast sends regardless of log level.
+	if err := s.Broadcast(ctx, notificationLoggingMessage, &LoggingMessageParams{Level: "debug", Data: "all"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		if got := receive(i); got != "all" {
+			t.Errorf("session %d: got %q, want %q", i, got, "all")
+		}
+	}
+
+	// A failure for one session is reported, and the others still receive
+	// the notification.
+	failing = sessions[1]
+	err := s.Broadcast(ctx, notificationLoggingMessage, &LoggingMessageParams{Level: "info", Data: "partial"})
+	if !errors.Is(err, errFail) {
+		t.Errorf("got error %v, want %v", err, errFail)
+	}
+	for _, i := range []int{0, 2} {
+		if got := receive(i); got != "partial" {
+			t.Errorf("session %d: got %q, want %q", i, got, "partial")
+		}
+	}
+
+	if err := s.Broadcast(ctx, "tools/list", nil); err == nil {
+		t.Error("Broadcast of a request method succeeded")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// CaptureDirection is the direction of a captured message, relative to the
+// side of the connection that was captured.
+type CaptureDirection string
+
+const (
+	CaptureRead  CaptureDirection = "read"  // the message was received
+	CaptureWrite CaptureDirection = "write" // the message was sent
+)
+
+// A CaptureEntry records one message read or written by a connection, as
+// captured by [LoggingTransport.Capture].
+type CaptureEntry struct {
+	Time      time.Time        `json:"time"`
+	Direction CaptureDirection `json:"direction"`
+	SessionID string           `json:"sessionId,omitempty"`
+	// Message is the raw JSON-RPC message. It is empty if the read or write
+	// failed.
+	Message json.RawMessage `json:"message,omitempty"`
+	// Error is the error of a failed read or write. Since writes are captured
+	// before they are made, a failed write is captured as the attempted
+	// message followed by an entry reporting the error.
+	Error string `json:"error,omitempty"`
+}
+
+// ReadCapture reads a capture written by [LoggingTransport.Capture].
+func ReadCapture(r io.Reader) ([]*CaptureEntry, error) {
+	var entries []*CaptureEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		e := new(CaptureEntry)
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return nil, fmt.Errorf("capture line %d: %w", line, err)
+		}
+		switch e.Direction {
+		case CaptureRead, CaptureWrite:
+		default:
+			return nil, fmt.Errorf("capture line %d: invalid direction %q", line, e.Direction)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// A ReplayTransport is a [Transport] that replays a capture, standing in for
+// the peer of the captured connection. Use it on the same side as the
+// capture: a capture of a client is replayed by connecting a client to a
+// ReplayTransport.
+//
+// The connection's reads return the captured reads in order. Each read is
+// delivered only after the connection has written at least as many messages
+// as preceded it in the capture, so that responses follow their requests.
+// Written messages are counted, not compared. Failed reads in the capture
+// are skipped, and failed writes are not counted.
+//
+// After the last captured read, reads block until all captured writes have
+// been made, and then return io.EOF.
+type ReplayTransport struct {
+	Entries []*CaptureEntry
+}
+
+// Connect implements the [Transport] interface.
+func (t *ReplayTransport) Connect(context.Context) (Connection, error) {
+	c := &replayConn{
+		changed: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	writes := 0
+	for _, e := range t.Entries {
+		if e.Error != "" && e.Direction == CaptureWrite && writes > 0 {
+			// The attempted message was captured before the write failed: don't
+			// count it, either in total or for reads captured since the attempt.
+			writes--
+			for i := range c.reads {
+				c.reads[i].after = min(c.reads[i].after, writes)
+			}
+		}
+		if e.Error != "" || len(e.Message) == 0 {
+			continue
+		}
+		switch e.Direction {
+		case CaptureRead:
+			msg, err := jsonrpc.DecodeMessage(e.Message)
+			if err != nil {
+				return nil, fmt.Errorf("replaying %s: %w", e.Message, err)
+			}
+			c.reads = append(c.reads, replayRead{msg: msg, after: writes})
+		case CaptureWrite:
+			writes++
+		}
+	}
+	c.totalWrites = writes
+	return c, nil
+}
+
+type replayRead struct {
+	msg   jsonrpc.Message
+	after int // number of writes that must precede this read
+}
+
+type replayConn struct {
+	reads       []replayRead // remaining reads; accessed only by Read, which is serialized
+	totalWrites int
+
+	mu      sync.Mutex
+	writes  int
+	changed chan struct{} // signaled (non-blocking) on each write
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *replayConn) SessionID() string { return "" }
+
+func (c *replayConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		want := c.totalWrites
+		if len(c.reads) > 0 {
+			want = c.reads[0].after
+		}
+		c.mu.Lock()
+		ready := c.writes >= want
+		c.mu.Unlock()
+		if ready {
+			if len(c.reads) == 0 {
+				return nil, io.EOF
+			}
+			msg := c.reads[0].msg
+			c.reads = c.reads[1:]
+			return msg, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, io.EOF
+		case <-c.changed:
+		}
+	}
+}
+
+func (c *replayConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closed:
+		return ErrConnectionClosed
+	default:
+	}
+	c.mu.Lock()
+	c.writes++
+	c.mu.Unlock()
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (c *replayConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+func TestCaptureReplay(t *testing.T) {
+	ctx := context.Background()
+
+	// Capture a client session against a real server.
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	ct, st := NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+
+	var capture bytes.Buffer
+	client := NewClient(testImpl, nil)
+	cs, err := client.Connect(ctx, &LoggingTransport{Transport: ct, Capture: &capture}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callGreet := func(cs *ClientSession) *CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	wantRes := callGreet(cs)
+	cs.Close()
+
+	entries, err := ReadCapture(&capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []CaptureDirection
+	for _, e := range entries {
+		if e.Error == "" {
+			dirs = append(dirs, e.Direction)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("entry %s has no time", e.Message)
+		}
+	}
+	// The capture begins with the initialization handshake, and every request
+	// after it has a response.
+	wantDirs := []CaptureDirection{CaptureWrite, CaptureRead, CaptureWrite}
+	if len(dirs) < len(wantDirs) {
+		t.Fatalf("captured %d messages, want at least %d", len(dirs), len(wantDirs))
+	}
+	if diff := cmp.Diff(wantDirs, dirs[:len(wantDirs)]); diff != "" {
+		t.Fatalf("captured handshake mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(slices.Repeat([]CaptureDirection{CaptureWrite, CaptureRead}, (len(dirs)-3)/2), dirs[3:]); diff != "" {
+		t.Fatalf("captured calls mismatch (-want +got):\n%s", diff)
+	}
+
+	// Replay the capture to a new client, without a server.
+	cs2, err := NewClient(testImpl, nil).Connect(ctx, &ReplayTransport{Entries: entries}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs2.Close()
+	if got := cs2.InitializeResult().ServerInfo.Name; got != testImpl.Name {
+		t.Errorf("replayed server name = %q, want %q", got, testImpl.Name)
+	}
+	want, _ := json.Marshal(wantRes)
+	got, _ := json.Marshal(callGreet(cs2))
+	if !bytes.Equal(got, want) {
+		t.Errorf("replayed result = %s, want %s", got, want)
+	}
+}
+
+func TestReadCaptureErrors(t *testing.T) {
+	for _, input := range []string{
+		"not json\n",
+		`{"direction":"sideways","message":{}}` + "\n",
+	} {
+		if _, err := ReadCapture(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadCapture(%q) succeeded unexpectedly", input)
+		}
+	}
+}
+
+func TestReplayFailedWrite(t *testing.T) {
+	// A capture in which the first of two writes failed.
+	input := `{"direction":"write","message":{"jsonrpc":"2.0","id":1,"method":"ping"}}
+{"direction":"write","error":"broken pipe"}
+{"direction":"write","message":{"jsonrpc":"2.0","id":2,"method":"ping"}}
+{"direction":"read","message":{"jsonrpc":"2.0","id":2,"result":{}}}
+`
+	entries, err := ReadCapture(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := (&ReplayTransport{Entries: entries}).Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.Write(context.Background(), &jsonrpc.Request{ID: jsonrpc2.Int64ID(2), Method: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	// The response is delivered after the one successful write.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if resp, ok := msg.(*jsonrpc.Response); !ok || resp.ID != jsonrpc2.Int64ID(2) {
+		t.Errorf("Read: got %+v, want the response to request 2", msg)
+	}
+	if _, err := conn.Read(ctx); err != io.EOF {
+		t.Errorf("Read at end of capture: got %v, want io.EOF", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"iter"
+	"maps"
 	"slices"
 	"sync"
 	"sync/atomic"
@@ -77,6 +78,40 @@ type ClientOptions struct {
 	// If the peer fails to respond to pings originating from the keepalive check,
 	// the session is automatically closed.
 	KeepAlive time.Duration
+	// If non-nil, KeepAliveOptions tunes the keepalive check, for example to
+	// tolerate some failed pings before closing the session.
+	KeepAliveOptions *KeepAliveOptions
+	// If positive, RequestTimeout bounds each request that the client sends,
+	// such as CallTool, when the context passed to it has no deadline, so
+	// that an unresponsive server cannot block the caller forever.
+	RequestTimeout time.Duration
+	// TransportMiddleware wraps the transport passed to [Client.Connect], to
+	// add behavior such as compression or metrics to every connection. The
+	// first middleware is the outermost.
+	TransportMiddleware []TransportMiddleware
+	// If non-nil, ContentFilter is applied to sampling requests and results,
+	// and to elicitation messages. See [FilterPoint].
+	ContentFilter ContentFilter
+	// If set, a progress token is generated and attached to each outgoing
+	// request whose params don't already have one, so that servers can
+	// always report progress. Use [WithProgressHandler] to receive the
+	// progress of a particular call.
+	AutoProgressTokens bool
+	// If set, requests sent with a context that has a deadline carry a
+	// deadline hint, so that servers can return before the deadline.
+	// See [DeadlineHintKey].
+	DeadlineHints bool
+	// If set, properties missing from accepted elicitation content are set
+	// to their defaults from the requested schema before the content is
+	// validated and returned to the server. Defaults of required properties
+	// are not applied.
+	//
+	// Servers apply defaults to elicitation results regardless of this
+	// setting; see [ServerSession.Elicit].
+	ApplyElicitationDefaults bool
+	// If non-nil, Hedging configures hedging of slow requests without side
+	// effects, such as list requests. See [HedgingOptions].
+	Hedging *HedgingOptions
 }
 
 // bind implements the binder[*ClientSession] interface, so that Clients can
@@ -112,6 +147,16 @@ func (e unsupportedProtocolVersionError) Error() string {
 	return fmt.Sprintf("unsupported protocol version: %q", e.version)
 }
 
+// A resumedConnection is a client Connection that may resume a session that
+// was initialized by an earlier connection, such as a
+// [StreamableClientTransport] connection with Resume set.
+type resumedConnection interface {
+	Connection
+	// resumedState returns the result of the session's initialization, or
+	// nil if the connection does not resume a session.
+	resumedState() *InitializeResult
+}
+
 // ClientSessionOptions is reserved for future use.
 type ClientSessionOptions struct{}
 
@@ -130,15 +175,39 @@ func (c *Client) capabilities() *ClientCapabilities {
 // Connect begins an MCP session by connecting to a server over the given
 // transport. The resulting session is initialized, and ready to use.
 //
+// If the transport resumes an existing session, as a
+// [StreamableClientTransport] with Resume set does, the session is not
+// initialized again. Instead, Connect pings the server, and fails if the
+// server no longer has the session.
+//
 // Typically, it is the responsibility of the client to close the connection
 // when it is no longer needed. However, if the connection is closed by the
 // server, calls or notifications will return an error wrapping
 // [ErrConnectionClosed].
 func (c *Client) Connect(ctx context.Context, t Transport, _ *ClientSessionOptions) (cs *ClientSession, err error) {
-	cs, err = connect(ctx, t, c, (*clientSessionState)(nil), nil)
+	cs, err = connect(ctx, wrapTransport(t, c.opts.TransportMiddleware), c, (*clientSessionState)(nil), nil)
 	if err != nil {
 		return nil, err
 	}
+	if rc, ok := asConnection[resumedConnection](cs.mcpConn); ok {
+		if res := rc.resumedState(); res != nil {
+			// The session is already initialized. Check that the server still
+			// has it, so that the failure to resume it is reported here
+			// rather than lost in the background.
+			if err := cs.Ping(ctx, nil); err != nil {
+				_ = cs.Close()
+				return nil, fmt.Errorf("resuming session %q: %w", cs.ID(), err)
+			}
+			cs.state.InitializeResult = res
+			if hc, ok := asConnection[clientConnection](cs.mcpConn); ok {
+				hc.sessionUpdated(cs.state)
+			}
+			if c.opts.KeepAlive > 0 {
+				cs.startKeepalive(c.opts.KeepAlive)
+			}
+			return cs, nil
+		}
+	}
 
 	params := &InitializeParams{
 		ProtocolVersion: latestProtocolVersion,
@@ -155,7 +224,7 @@ func (c *Client) Connect(ctx context.Context, t Transport, _ *ClientSessionOptio
 		return nil, unsupportedProtocolVersionError{res.ProtocolVersion}
 	}
 	cs.state.InitializeResult = res
-	if hc, ok := cs.mcpConn.(clientConnection); ok {
+	if hc, ok := asConnection[clientConnection](cs.mcpConn); ok {
 		hc.sessionUpdated(cs.state)
 	}
 	req2 := &initializedClientRequest{Session: cs, Params: &InitializedParams{}}
@@ -192,6 +261,8 @@ type ClientSession struct {
 	// No mutex is (currently) required to guard the session state, because it is
 	// only set synchronously during Client.Connect.
 	state clientSessionState
+
+	progress clientProgress
 }
 
 type clientSessionState struct {
@@ -200,8 +271,17 @@ type clientSessionState struct {
 
 func (cs *ClientSession) InitializeResult() *InitializeResult { return cs.state.InitializeResult }
 
+// ProtocolVersion returns the protocol version negotiated with the server, or
+// the empty string if the session is not initialized.
+func (cs *ClientSession) ProtocolVersion() string {
+	if res := cs.state.InitializeResult; res != nil {
+		return res.ProtocolVersion
+	}
+	return ""
+}
+
 func (cs *ClientSession) ID() string {
-	if c, ok := cs.mcpConn.(hasSessionID); ok {
+	if c, ok := asConnection[hasSessionID](cs.mcpConn); ok {
 		return c.SessionID()
 	}
 	return ""
@@ -238,7 +318,7 @@ func (cs *ClientSession) Wait() error {
 
 // startKeepalive starts the keepalive mechanism for this client session.
 func (cs *ClientSession) startKeepalive(interval time.Duration) {
-	startKeepalive(cs, interval, &cs.keepaliveCancel)
+	startKeepalive(cs, interval, cs.client.opts.KeepAliveOptions, &cs.keepaliveCancel)
 }
 
 // AddRoots adds the given roots to the client,
@@ -290,21 +370,60 @@ func (c *Client) listRoots(_ context.Context, req *ListRootsRequest) (*ListRoots
 func (c *Client) createMessage(ctx context.Context, req *CreateMessageRequest) (*CreateMessageResult, error) {
 	if c.opts.CreateMessageHandler == nil {
 		// TODO: wrap or annotate this error? Pick a standard code?
-		return nil, jsonrpc2.NewError(codeUnsupportedMethod, "client does not support CreateMessage")
+		return nil, jsonrpc2.NewError(CodeUnsupportedMethod, "client does not support CreateMessage")
+	}
+	f := c.opts.ContentFilter
+	if f == nil {
+		return c.opts.CreateMessageHandler(ctx, req)
+	}
+	params := *req.Params
+	params.Messages = make([]*SamplingMessage, len(req.Params.Messages))
+	for i, m := range req.Params.Messages {
+		content, err := filterContent(ctx, f, FilterSamplingRequest, m.Content)
+		if err != nil {
+			return nil, err
+		}
+		params.Messages[i] = &SamplingMessage{Content: content, Role: m.Role}
 	}
-	return c.opts.CreateMessageHandler(ctx, req)
+	req2 := *req
+	req2.Params = &params
+	res, err := c.opts.CreateMessageHandler(ctx, &req2)
+	if err != nil || res == nil {
+		return res, err
+	}
+	res2 := *res
+	if res2.Content, err = filterContent(ctx, f, FilterSamplingResult, res.Content); err != nil {
+		return nil, err
+	}
+	return &res2, nil
 }
 
 func (c *Client) elicit(ctx context.Context, req *ElicitRequest) (*ElicitResult, error) {
 	if c.opts.ElicitationHandler == nil {
 		// TODO: wrap or annotate this error? Pick a standard code?
-		return nil, jsonrpc2.NewError(codeUnsupportedMethod, "client does not support elicitation")
+		return nil, jsonrpc2.NewError(CodeUnsupportedMethod, "client does not support elicitation")
 	}
 
 	// Validate that the requested schema only contains top-level properties without nesting
 	schema, err := validateElicitSchema(req.Params.RequestedSchema)
 	if err != nil {
-		return nil, jsonrpc2.NewError(codeInvalidParams, err.Error())
+		return nil, jsonrpc2.NewError(CodeInvalidParams, err.Error())
+	}
+
+	if f := c.opts.ContentFilter; f != nil {
+		fc, err := filterContent(ctx, f, FilterElicitationMessage, &TextContent{Text: req.Params.Message})
+		if err != nil {
+			return nil, err
+		}
+		tc, ok := fc.(*TextContent)
+		if !ok {
+			return nil, fmt.Errorf("%s filter returned %T, want *TextContent", FilterElicitationMessage, fc)
+		}
+		params := *req.Params
+		params.Message = tc.Text
+		req2 := *req
+		req2.Params = &params
+		req = &req2
 	}
 
 	res, err := c.opts.ElicitationHandler(ctx, req)
@@ -312,6 +431,18 @@ func (c *Client) elicit(ctx context.Context, req *ElicitRequest) (*ElicitResult,
 		return nil, err
 	}
 
+	if schema != nil && c.opts.ApplyElicitationDefaults && res.Action == "accept" {
+		resolved, err := schema.Resolve(nil)
+		if err != nil {
+			return nil, jsonrpc2.NewError(CodeInvalidParams, fmt.Sprintf("failed to resolve requested schema: %v", err))
+		}
+		res2 := *res
+		if err := applyElicitDefaults(resolved, &res2); err != nil {
+			return nil, err
+		}
+		res = &res2
+	}
+
 	// Validate elicitation result content against requested schema
 	if schema != nil && res.Content != nil {
 		// TODO: is this the correct behavior if validation fails?
@@ -319,11 +450,11 @@ func (c *Client) elicit(ctx context.Context, req *ElicitRequest) (*ElicitResult,
 		// this code to the server?
 		resolved, err := schema.Resolve(nil)
 		if err != nil {
-			return nil, jsonrpc2.NewError(codeInvalidParams, fmt.Sprintf("failed to resolve requested schema: %v", err))
+			return nil, jsonrpc2.NewError(CodeInvalidParams, fmt.Sprintf("failed to resolve requested schema: %v", err))
 		}
 
 		if err := resolved.Validate(res.Content); err != nil {
-			return nil, jsonrpc2.NewError(codeInvalidParams, fmt.Sprintf("elicitation result content does not match requested schema: %v", err))
+			return nil, jsonrpc2.NewError(CodeInvalidParams, fmt.Sprintf("elicitation result content does not match requested schema: %v", err))
 		}
 	}
 
@@ -363,6 +494,23 @@ func validateElicitSchema(wireSchema any) (*jsonschema.Schema, error) {
 	return schema, nil
 }
 
+// applyElicitDefaults sets the properties of res.Content that are missing to
+// their defaults in the requested schema. It does not modify the original
+// content map.
+func applyElicitDefaults(resolved *jsonschema.Resolved, res *ElicitResult) error {
+	content := maps.Clone(res.Content)
+	if content == nil {
+		content = make(map[string]any)
+	}
+	if err := resolved.ApplyDefaults(&content); err != nil {
+		return fmt.Errorf("applying elicitation defaults: %w", err)
+	}
+	if len(content) > 0 {
+		res.Content = content
+	}
+	return nil
+}
+
 // validateElicitProperty validates a single property in an elicitation schema.
 func validateElicitProperty(propName string, propSchema *jsonschema.Schema) error {
 	// Check if this property has nested properties (not allowed)
@@ -391,19 +539,10 @@ func validateElicitStringProperty(propName string, propSchema *jsonschema.Schema
 		if propSchema.Type != "" && propSchema.Type != "string" {
 			return fmt.Errorf("elicit schema property %q has enum values but type is %q, enums are only supported for string type", propName, propSchema.Type)
 		}
-		// Enum values themselves are validated by the JSON schema library
-		// Validate enumNames if present - must match enum length
-		if propSchema.Extra != nil {
-			if enumNamesRaw, exists := propSchema.Extra["enumNames"]; exists {
-				// Type check enumNames - should be a slice
-				if enumNamesSlice, ok := enumNamesRaw.([]any); ok {
-					if len(enumNamesSlice) != len(propSchema.Enum) {
-						return fmt.Errorf("elicit schema property %q has %d enum values but %d enumNames, they must match", propName, len(propSchema.Enum), len(enumNamesSlice))
-					}
-				} else {
-					return fmt.Errorf("elicit schema property %q has invalid enumNames type, must be an array", propName)
-				}
-			}
+		// Enum values themselves are validated by the JSON schema library.
+		// Validate enumNames if present: they must match the enum values.
+		if _, err := EnumNames(propSchema); err != nil {
+			return fmt.Errorf("elicit schema property %q has %v", propName, err)
 		}
 		return nil
 	}
@@ -587,6 +726,9 @@ func (cs *ClientSession) ListTools(ctx context.Context, params *ListToolsParams)
 // CallTool calls the tool with the given parameters.
 //
 // The params.Arguments can be any value that marshals into a JSON object.
+//
+// If the server reports that the tool's output did not match its output
+// schema, the error is an [*OutputValidationError].
 func (cs *ClientSession) CallTool(ctx context.Context, params *CallToolParams) (*CallToolResult, error) {
 	if params == nil {
 		params = new(CallToolParams)
@@ -595,7 +737,11 @@ func (cs *ClientSession) CallTool(ctx context.Context, params *CallToolParams) (
 		// Avoid sending nil over the wire.
 		params.Arguments = map[string]any{}
 	}
-	return handleSend[*CallToolResult](ctx, methodCallTool, newClientRequest(cs, orZero[Params](params)))
+	res, err := handleSend[*CallToolResult](ctx, methodCallTool, newClientRequest(cs, orZero[Params](params)))
+	if err != nil {
+		return nil, outputValidationError(params.Name, err)
+	}
+	return res, nil
 }
 
 func (cs *ClientSession) SetLoggingLevel(ctx context.Context, params *SetLoggingLevelParams) error {
@@ -672,9 +818,18 @@ func (c *Client) callLoggingHandler(ctx context.Context, req *LoggingMessageRequ
 }
 
 func (cs *ClientSession) callProgressNotificationHandler(ctx context.Context, params *ProgressNotificationParams) (Result, error) {
+	if token, ok := cs.progress.resolve(params.ProgressToken); ok {
+		// Progress of a hedged attempt: report it with the caller's token.
+		p := *params
+		p.ProgressToken = token
+		params = &p
+	}
 	if h := cs.client.opts.ProgressNotificationHandler; h != nil {
 		h(ctx, clientRequestFor(cs, params))
 	}
+	if h := cs.progress.handler(params.ProgressToken); h != nil {
+		h(ctx, clientRequestFor(cs, params))
+	}
 	return nil, nil
 }
 
@@ -16,13 +16,21 @@ import (
 var defaultTerminateDuration = 5 * time.Second // mutable for testing
 
 // A CommandTransport is a [Transport] that runs a command and communicates
-// with it over stdin/stdout, using newline-delimited JSON.
+// with it over stdin/stdout, using newline-delimited JSON or the framing
+// selected by its Framing field.
 type CommandTransport struct {
 	Command *exec.Cmd
 	// TerminateDuration controls how long Close waits after closing stdin
 	// for the process to exit before sending SIGTERM.
 	// If zero or negative, the default of 5s is used.
 	TerminateDuration time.Duration
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// read from the command's stdout. A larger message fails the connection
+	// with an error wrapping [ErrMessageTooLarge], before it is read in full.
+	MaxMessageSize int
+	// Framing selects how messages are delimited. If zero, messages are
+	// newline-delimited.
+	Framing Framing
 }
 
 // Connect starts the command, and connects to it over stdin/stdout.
@@ -43,7 +51,7 @@ func (t *CommandTransport) Connect(ctx context.Context) (Connection, error) {
 	if td <= 0 {
 		td = defaultTerminateDuration
 	}
-	return newIOConn(&pipeRWC{t.Command, stdout, stdin, td}), nil
+	return newIOConn(&pipeRWC{t.Command, stdout, stdin, td}, t.MaxMessageSize, t.Framing), nil
 }
 
 // A pipeRWC is an io.ReadWriteCloser that communicates with a subprocess over
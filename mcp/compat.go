@@ -0,0 +1,165 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// This file adapts outgoing messages to peers that speak an older version of
+// the protocol, as determined by the negotiated version. Fields that an older
+// peer doesn't know are generally ignored, so only values that it would
+// reject or misinterpret are changed:
+//
+//   - Content types introduced after the negotiated version, audio
+//     (2025-03-26) and resource links (2025-06-18), are replaced by text
+//     describing them.
+//   - The "completions" server capability, introduced in 2025-03-26, is not
+//     advertised to older clients, for which completion needs no capability.
+//
+// Values are copied before they are changed, since they may belong to the
+// caller.
+
+// downgradeResult returns res, adapted for a peer that speaks the given
+// protocol version.
+func downgradeResult(version string, res Result) Result {
+	if version == "" || version >= latestProtocolVersion {
+		return res
+	}
+	switch r := res.(type) {
+	case *InitializeResult:
+		if r != nil && version < protocolVersion20250326 && r.Capabilities != nil && r.Capabilities.Completions != nil {
+			r2 := *r
+			caps := *r.Capabilities
+			caps.Completions = nil
+			r2.Capabilities = &caps
+			return &r2
+		}
+	case *CallToolResult:
+		if r == nil {
+			break
+		}
+		if content, ok := downgradeContents(version, r.Content); ok {
+			r2 := *r
+			r2.Content = content
+			return &r2
+		}
+	case *GetPromptResult:
+		if r == nil {
+			break
+		}
+		if msgs, ok := downgradeMessages(version, r.Messages, func(m *PromptMessage) *Content { return &m.Content }); ok {
+			r2 := *r
+			r2.Messages = msgs
+			return &r2
+		}
+	case *CreateMessageResult:
+		if r == nil {
+			break
+		}
+		if c, ok := downgradeContent(version, r.Content); ok {
+			r2 := *r
+			r2.Content = c
+			return &r2
+		}
+	}
+	return res
+}
+
+// downgradeParams returns the params of an outgoing request, adapted for a
+// peer that speaks the given protocol version.
+func downgradeParams(version string, params Params) Params {
+	if version == "" || version >= latestProtocolVersion {
+		return params
+	}
+	if p, ok := params.(*CreateMessageParams); ok && p != nil {
+		if msgs, ok := downgradeMessages(version, p.Messages, func(m *SamplingMessage) *Content { return &m.Content }); ok {
+			p2 := *p
+			p2.Messages = msgs
+			return &p2
+		}
+	}
+	return params
+}
+
+// downgradeMessages downgrades the content of each message, which is
+// accessed with content. It reports whether any message changed, in which
+// case it returns a copy of msgs.
+func downgradeMessages[M any](version string, msgs []*M, content func(*M) *Content) ([]*M, bool) {
+	var out []*M
+	for i, m := range msgs {
+		if m == nil {
+			continue
+		}
+		c, ok := downgradeContent(version, *content(m))
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(msgs)
+		}
+		m2 := *m
+		*content(&m2) = c
+		out[i] = &m2
+	}
+	return out, out != nil
+}
+
+// downgradeContents downgrades each of the contents. It reports whether any
+// changed, in which case it returns a copy of cs.
+func downgradeContents(version string, cs []Content) ([]Content, bool) {
+	var out []Content
+	for i, c := range cs {
+		c2, ok := downgradeContent(version, c)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(cs)
+		}
+		out[i] = c2
+	}
+	return out, out != nil
+}
+
+// downgradeContent reports whether the content c is unknown in the given
+// protocol version, and if so returns text content describing it.
+func downgradeContent(version string, c Content) (Content, bool) {
+	switch c := c.(type) {
+	case *AudioContent:
+		if version < protocolVersion20250326 {
+			return &TextContent{
+				Text:        fmt.Sprintf("[audio content of type %q omitted]", c.MIMEType),
+				Annotations: c.Annotations,
+			}, true
+		}
+	case *ResourceLink:
+		if version < protocolVersion20250618 {
+			text := c.URI
+			if c.Name != "" {
+				text = fmt.Sprintf("%s: %s", c.Name, c.URI)
+			}
+			return &TextContent{Text: text, Annotations: c.Annotations}, true
+		}
+	}
+	return nil, false
+}
+
+// parseEndpointEvent returns the session endpoint from the data of an SSE
+// "endpoint" event. Some servers send the endpoint as a JSON string, or with
+// surrounding whitespace.
+func parseEndpointEvent(data []byte) string {
+	raw := strings.TrimSpace(string(data))
+	if strings.HasPrefix(raw, `"`) {
+		var s string
+		if err := json.Unmarshal([]byte(raw), &s); err == nil {
+			return s
+		}
+	}
+	return raw
+}
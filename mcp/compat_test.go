@@ -0,0 +1,89 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestDowngradeForOlderPeers(t *testing.T) {
+	content := []Content{
+		&TextContent{Text: "text"},
+		&AudioContent{Data: []byte("a"), MIMEType: "audio/wav"},
+		&ResourceLink{URI: "file:///f", Name: "f"},
+	}
+	for _, test := range []struct {
+		version  string
+		want     []Content
+		wantComp bool // advertises the completions capability
+	}{
+		{latestProtocolVersion, content, true},
+		{protocolVersion20250326, []Content{
+			&TextContent{Text: "text"},
+			&AudioContent{Data: []byte("a"), MIMEType: "audio/wav"},
+			&TextContent{Text: "f: file:///f"},
+		}, true},
+		{protocolVersion20241105, []Content{
+			&TextContent{Text: "text"},
+			&TextContent{Text: `[audio content of type "audio/wav" omitted]`},
+			&TextContent{Text: "f: file:///f"},
+		}, false},
+	} {
+		t.Run(test.version, func(t *testing.T) {
+			server := NewServer(testImpl, &ServerOptions{
+				CompletionHandler: func(context.Context, *CompleteRequest) (*CompleteResult, error) {
+					return &CompleteResult{}, nil
+				},
+			})
+			server.AddTool(&Tool{Name: "media", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+				return &CallToolResult{Content: content}, nil
+			})
+			client := NewClient(testImpl, nil)
+			client.AddSendingMiddleware(func(next MethodHandler) MethodHandler {
+				return func(ctx context.Context, method string, req Request) (Result, error) {
+					if p, ok := req.GetParams().(*InitializeParams); ok {
+						p.ProtocolVersion = test.version
+					}
+					return next(ctx, method, req)
+				}
+			})
+			cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+			defer cleanup()
+
+			if got := cs.InitializeResult().Capabilities.Completions != nil; got != test.wantComp {
+				t.Errorf("completions capability: got %t, want %t", got, test.wantComp)
+			}
+			res, err := cs.CallTool(context.Background(), &CallToolParams{Name: "media"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, res.Content); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+			// The server's values are not modified.
+			if _, ok := content[1].(*AudioContent); !ok {
+				t.Error("tool result was modified")
+			}
+		})
+	}
+}
+
+func TestParseEndpointEvent(t *testing.T) {
+	for _, test := range []struct{ in, want string }{
+		{"/messages?sessionid=1", "/messages?sessionid=1"},
+		{" /messages?sessionid=1\n", "/messages?sessionid=1"},
+		{`"/messages?sessionid=1"`, "/messages?sessionid=1"},
+		{`"\/messages?sessionid=1"`, "/messages?sessionid=1"},
+		{`"unterminated`, `"unterminated`},
+	} {
+		if got := parseEndpointEvent([]byte(test.in)); got != test.want {
+			t.Errorf("parseEndpointEvent(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -8,9 +8,21 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // A Content is a [TextContent], [ImageContent], [AudioContent],
@@ -80,6 +92,108 @@ func (c *ImageContent) fromWire(wire *wireContent) {
 	c.Annotations = wire.Annotations
 }
 
+// ImageOptions configures the ImageContent constructors.
+type ImageOptions struct {
+	// MaxBytes is the maximum size of the encoded image.
+	// If zero, the size is not limited.
+	MaxBytes int
+	// JPEGQuality is the quality used when encoding JPEG images with
+	// [ImageContentFromImage], from 1 to 100. If zero, [jpeg.DefaultQuality]
+	// is used.
+	JPEGQuality int
+}
+
+// ErrImageTooLarge is returned by the ImageContent constructors when an
+// image exceeds [ImageOptions.MaxBytes].
+var ErrImageTooLarge = errors.New("image too large")
+
+// NewImageContent returns an ImageContent holding data.
+// If mimeType is empty, it is detected from the contents of data.
+func NewImageContent(data []byte, mimeType string) *ImageContent {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return &ImageContent{Data: data, MIMEType: mimeType}
+}
+
+// ImageContentFromReader returns an ImageContent holding all the data read
+// from r. If mimeType is empty, it is detected from the data.
+func ImageContentFromReader(r io.Reader, mimeType string, opts *ImageOptions) (*ImageContent, error) {
+	if opts != nil && opts.MaxBytes > 0 {
+		r = io.LimitReader(r, int64(opts.MaxBytes)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkImageSize(len(data), opts); err != nil {
+		return nil, err
+	}
+	return NewImageContent(data, mimeType), nil
+}
+
+// ImageContentFromFile returns an ImageContent holding the contents of the
+// named file. Its MIME type is derived from the file extension, or if that
+// is not recognized, from the contents of the file.
+func ImageContentFromFile(name string, opts *ImageOptions) (*ImageContent, error) {
+	if opts != nil && opts.MaxBytes > 0 {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkImageSize(int(fi.Size()), opts); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewImageContent(data, mimeTypeByExtension(name, "image/")), nil
+}
+
+// ImageContentFromImage encodes img in the given format, which must be
+// "png", "jpeg" or "gif", and returns an ImageContent holding the result.
+func ImageContentFromImage(img image.Image, format string, opts *ImageOptions) (*ImageContent, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		quality := jpeg.DefaultQuality
+		if opts != nil && opts.JPEGQuality != 0 {
+			quality = opts.JPEGQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		return nil, fmt.Errorf("unsupported image format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", format, err)
+	}
+	if err := checkImageSize(buf.Len(), opts); err != nil {
+		return nil, err
+	}
+	return &ImageContent{Data: buf.Bytes(), MIMEType: "image/" + format}, nil
+}
+
+func checkImageSize(n int, opts *ImageOptions) error {
+	if opts != nil && opts.MaxBytes > 0 && n > opts.MaxBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, opts.MaxBytes)
+	}
+	return nil
+}
+
+// Decode decodes the image data. It supports the PNG, JPEG and GIF formats,
+// as well as any other formats registered with the [image] package.
+// The returned string is the format name, as with [image.Decode].
+func (c *ImageContent) Decode() (image.Image, string, error) {
+	return image.Decode(bytes.NewReader(c.Data))
+}
+
 // AudioContent contains base64-encoded audio data.
 type AudioContent struct {
 	Data        []byte
@@ -111,6 +225,90 @@ func (c *AudioContent) fromWire(wire *wireContent) {
 	c.Annotations = wire.Annotations
 }
 
+// NewAudioContent returns an AudioContent holding data.
+// If mimeType is empty, it is detected from the contents of data.
+func NewAudioContent(data []byte, mimeType string) *AudioContent {
+	if mimeType == "" {
+		mimeType = detectAudioType(data)
+	}
+	return &AudioContent{Data: data, MIMEType: mimeType}
+}
+
+// AudioContentFromReader returns an AudioContent holding all the data read
+// from r. If mimeType is empty, it is detected from the data.
+func AudioContentFromReader(r io.Reader, mimeType string) (*AudioContent, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewAudioContent(data, mimeType), nil
+}
+
+// AudioContentFromFile returns an AudioContent holding the contents of the
+// named file. Its MIME type is derived from the file extension, or if that
+// is not recognized, from the contents of the file.
+func AudioContentFromFile(name string) (*AudioContent, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mimeTypeByExtension(name, "audio/")
+	if mimeType == "" {
+		mimeType = detectAudioType(data)
+	}
+	return &AudioContent{Data: data, MIMEType: mimeType}, nil
+}
+
+// mediaTypesByExtension maps common audio and image file extensions to MIME
+// types. It supplements [mime.TypeByExtension], whose results vary by system.
+var mediaTypesByExtension = map[string]string{
+	".aac":  "audio/aac",
+	".flac": "audio/flac",
+	".gif":  "image/gif",
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".m4a":  "audio/mp4",
+	".mp3":  "audio/mpeg",
+	".oga":  "audio/ogg",
+	".ogg":  "audio/ogg",
+	".opus": "audio/opus",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".wav":  "audio/wav",
+	".weba": "audio/webm",
+	".webp": "image/webp",
+}
+
+// mimeTypeByExtension returns the MIME type for the extension of name, if it
+// has the given prefix (for example "audio/"). Otherwise it returns "".
+func mimeTypeByExtension(name, prefix string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	t, ok := mediaTypesByExtension[ext]
+	if !ok {
+		t, _, _ = mime.ParseMediaType(mime.TypeByExtension(ext))
+	}
+	if !strings.HasPrefix(t, prefix) {
+		return ""
+	}
+	return t
+}
+
+// detectAudioType returns the MIME type of the audio data, using
+// [http.DetectContentType] for formats that it does not recognize.
+func detectAudioType(data []byte) string {
+	switch {
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")):
+		return "audio/wav"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte("ID3")), len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return "audio/mpeg"
+	}
+	return http.DetectContentType(data)
+}
+
 // Custom wire format to ensure required fields are always included, even when empty.
 type imageAudioWire struct {
 	Type        string       `json:"type"`
@@ -165,6 +363,9 @@ type EmbeddedResource struct {
 }
 
 func (c *EmbeddedResource) MarshalJSON() ([]byte, error) {
+	if c.Resource == nil {
+		return nil, errors.New("EmbeddedResource missing Resource")
+	}
 	return json.Marshal(&wireContent{
 		Type:        "resource",
 		Resource:    c.Resource,
@@ -179,6 +380,47 @@ func (c *EmbeddedResource) fromWire(wire *wireContent) {
 	c.Annotations = wire.Annotations
 }
 
+// NewEmbeddedTextResource returns an EmbeddedResource holding the text
+// contents of the resource with the given URI.
+func NewEmbeddedTextResource(uri, mimeType, text string) *EmbeddedResource {
+	return &EmbeddedResource{Resource: &ResourceContents{URI: uri, MIMEType: mimeType, Text: text}}
+}
+
+// NewEmbeddedBlobResource returns an EmbeddedResource holding the binary
+// contents of the resource with the given URI.
+func NewEmbeddedBlobResource(uri, mimeType string, blob []byte) *EmbeddedResource {
+	if blob == nil {
+		blob = []byte{} // distinguish from a text resource
+	}
+	return &EmbeddedResource{Resource: &ResourceContents{URI: uri, MIMEType: mimeType, Blob: blob}}
+}
+
+// URI returns the URI of the embedded resource, or "" if there is none.
+func (c *EmbeddedResource) URI() string {
+	if c.Resource == nil {
+		return ""
+	}
+	return c.Resource.URI
+}
+
+// Text returns the text of the embedded resource. The second result reports
+// whether the resource holds text, rather than binary data.
+func (c *EmbeddedResource) Text() (string, bool) {
+	if c.Resource == nil || c.Resource.Blob != nil {
+		return "", false
+	}
+	return c.Resource.Text, true
+}
+
+// Blob returns the binary data of the embedded resource. The second result
+// reports whether the resource holds binary data, rather than text.
+func (c *EmbeddedResource) Blob() ([]byte, bool) {
+	if c.Resource == nil || c.Resource.Blob == nil {
+		return nil, false
+	}
+	return c.Resource.Blob, true
+}
+
 // ResourceContents contains the contents of a specific resource or
 // sub-resource.
 type ResourceContents struct {
@@ -282,3 +524,17 @@ func contentFromWire(wire *wireContent, allow map[string]bool) (Content, error)
 	}
 	return nil, fmt.Errorf("internal error: unrecognized content type %s", wire.Type)
 }
+
+// SetLastModified sets the LastModified field to t, in ISO 8601 format.
+func (a *Annotations) SetLastModified(t time.Time) {
+	a.LastModified = t.UTC().Format(time.RFC3339)
+}
+
+// LastModifiedTime parses the LastModified field. It returns the zero time
+// and a nil error if the field is empty.
+func (a *Annotations) LastModifiedTime() (time.Time, error) {
+	if a.LastModified == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, a.LastModified)
+}
@@ -5,9 +5,17 @@
 package mcp_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/orkhanm/go-sdk/mcp"
@@ -192,3 +200,267 @@ func TestEmbeddedResource(t *testing.T) {
 		}
 	}
 }
+
+func TestAudioContentConstructors(t *testing.T) {
+	wav := append([]byte("RIFF\x00\x00\x00\x00WAVEfmt "), make([]byte, 16)...)
+	flac := []byte("fLaC\x00\x00\x00\x22")
+	mp3 := []byte("ID3\x04\x00\x00\x00\x00\x00\x00")
+
+	for _, test := range []struct {
+		name     string
+		got      *mcp.AudioContent
+		wantType string
+	}{
+		{"wav", mcp.NewAudioContent(wav, ""), "audio/wav"},
+		{"flac", mcp.NewAudioContent(flac, ""), "audio/flac"},
+		{"mp3", mcp.NewAudioContent(mp3, ""), "audio/mpeg"},
+		{"explicit", mcp.NewAudioContent(mp3, "audio/x-custom"), "audio/x-custom"},
+	} {
+		if test.got.MIMEType != test.wantType {
+			t.Errorf("%s: got MIME type %q, want %q", test.name, test.got.MIMEType, test.wantType)
+		}
+	}
+
+	ac, err := mcp.AudioContentFromReader(bytes.NewReader(wav), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&mcp.AudioContent{Data: wav, MIMEType: "audio/wav"}, ac); diff != "" {
+		t.Errorf("AudioContentFromReader mismatch (-want +got):\n%s", diff)
+	}
+
+	dir := t.TempDir()
+	for _, test := range []struct {
+		file     string
+		data     []byte
+		wantType string
+	}{
+		{"speech.mp3", mp3, "audio/mpeg"},
+		{"speech.ogg", wav, "audio/ogg"}, // extension wins over contents
+		{"speech.bin", flac, "audio/flac"},
+	} {
+		name := filepath.Join(dir, test.file)
+		if err := os.WriteFile(name, test.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ac, err := mcp.AudioContentFromFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ac.MIMEType != test.wantType {
+			t.Errorf("%s: got MIME type %q, want %q", test.file, ac.MIMEType, test.wantType)
+		}
+
+		// Check that the content survives a round trip.
+		data, err := json.Marshal(&mcp.CallToolResult{Content: []mcp.Content{ac}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res mcp.CallToolResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(ac, res.Content[0]); diff != "" {
+			t.Errorf("%s: round trip mismatch (-want +got):\n%s", test.file, diff)
+		}
+	}
+	if _, err := mcp.AudioContentFromFile(filepath.Join(dir, "missing.wav")); err == nil {
+		t.Error("AudioContentFromFile(missing): got nil error")
+	}
+
+	// AudioContent values, not just pointers, use the wire format.
+	data, err := json.Marshal(mcp.AudioContent{Data: []byte("a"), MIMEType: "audio/wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"type":"audio","mimeType":"audio/wav","data":"YQ=="}`; got != want {
+		t.Errorf("json.Marshal(AudioContent) = %s, want %s", got, want)
+	}
+}
+
+func TestImageContentConstructors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	for _, format := range []string{"png", "jpeg", "gif"} {
+		ic, err := mcp.ImageContentFromImage(img, format, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "image/" + format; ic.MIMEType != want {
+			t.Errorf("%s: got MIME type %q, want %q", format, ic.MIMEType, want)
+		}
+		if got := mcp.NewImageContent(ic.Data, "").MIMEType; got != ic.MIMEType {
+			t.Errorf("%s: detected MIME type %q, want %q", format, got, ic.MIMEType)
+		}
+
+		// Round trip through JSON, and decode on the other side.
+		data, err := json.Marshal(&mcp.CallToolResult{Content: []mcp.Content{ic}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res mcp.CallToolResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatal(err)
+		}
+		decoded, gotFormat, err := res.Content[0].(*mcp.ImageContent).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotFormat != format || decoded.Bounds() != img.Bounds() {
+			t.Errorf("%s: decoded %s image with bounds %v, want %v", format, gotFormat, decoded.Bounds(), img.Bounds())
+		}
+	}
+	if _, err := mcp.ImageContentFromImage(img, "bmp", nil); err == nil {
+		t.Error("ImageContentFromImage(bmp): got nil error")
+	}
+
+	pngContent, err := mcp.ImageContentFromImage(img, "png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	name := filepath.Join(dir, "pic.png")
+	if err := os.WriteFile(name, pngContent.Data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ic, err := mcp.ImageContentFromFile(name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(pngContent, ic); diff != "" {
+		t.Errorf("ImageContentFromFile mismatch (-want +got):\n%s", diff)
+	}
+
+	small := &mcp.ImageOptions{MaxBytes: 10}
+	if _, err := mcp.ImageContentFromFile(name, small); !errors.Is(err, mcp.ErrImageTooLarge) {
+		t.Errorf("ImageContentFromFile: got %v, want ErrImageTooLarge", err)
+	}
+	if _, err := mcp.ImageContentFromReader(bytes.NewReader(pngContent.Data), "", small); !errors.Is(err, mcp.ErrImageTooLarge) {
+		t.Errorf("ImageContentFromReader: got %v, want ErrImageTooLarge", err)
+	}
+	if _, err := mcp.ImageContentFromImage(img, "png", small); !errors.Is(err, mcp.ErrImageTooLarge) {
+		t.Errorf("ImageContentFromImage: got %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestEmbeddedResourceHelpers(t *testing.T) {
+	ann := &mcp.Annotations{
+		Audience:     []mcp.Role{"user", "assistant"},
+		LastModified: "2025-01-12T15:00:58Z",
+		Priority:     0.5,
+	}
+	text := mcp.NewEmbeddedTextResource("file:///notes.txt", "text/plain", "hello")
+	text.Annotations = ann
+	blob := mcp.NewEmbeddedBlobResource("file:///empty.bin", "application/octet-stream", nil)
+	blob.Meta = mcp.Meta{"key": "value"}
+
+	if got, ok := text.Text(); !ok || got != "hello" {
+		t.Errorf("text.Text() = %q, %t, want %q, true", got, ok, "hello")
+	}
+	if _, ok := text.Blob(); ok {
+		t.Error("text.Blob() reported binary data")
+	}
+	if got, ok := blob.Blob(); !ok || len(got) != 0 {
+		t.Errorf("blob.Blob() = %v, %t, want empty, true", got, ok)
+	}
+	if _, ok := blob.Text(); ok {
+		t.Error("blob.Text() reported text")
+	}
+	if got := blob.URI(); got != "file:///empty.bin" {
+		t.Errorf("blob.URI() = %q", got)
+	}
+
+	// Both variants must survive a round trip in prompt messages and tool
+	// results, including URIs, annotations and metadata.
+	for _, c := range []*mcp.EmbeddedResource{text, blob} {
+		data, err := json.Marshal(&mcp.GetPromptResult{Messages: []*mcp.PromptMessage{{Role: "user", Content: c}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pres mcp.GetPromptResult
+		if err := json.Unmarshal(data, &pres); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(c, pres.Messages[0].Content); diff != "" {
+			t.Errorf("prompt message round trip mismatch (-want +got):\n%s", diff)
+		}
+
+		data, err = json.Marshal(&mcp.CallToolResult{Content: []mcp.Content{c}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tres mcp.CallToolResult
+		if err := json.Unmarshal(data, &tres); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(c, tres.Content[0]); diff != "" {
+			t.Errorf("tool result round trip mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if _, err := json.Marshal(&mcp.EmbeddedResource{}); err == nil {
+		t.Error("marshaling EmbeddedResource with no Resource: got nil error")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	ann := &mcp.Annotations{Audience: []mcp.Role{mcp.RoleUser}, Priority: 0.25}
+	ann.SetLastModified(time.Date(2025, 1, 12, 15, 0, 58, 0, time.UTC))
+	if ann.LastModified != "2025-01-12T15:00:58Z" {
+		t.Errorf("LastModified = %q", ann.LastModified)
+	}
+	if got, err := ann.LastModifiedTime(); err != nil || !got.Equal(time.Date(2025, 1, 12, 15, 0, 58, 0, time.UTC)) {
+		t.Errorf("LastModifiedTime() = %v, %v", got, err)
+	}
+
+	// Annotations must survive listing and reading features.
+	ctx := context.Background()
+	s := mcp.NewServer(&mcp.Implementation{Name: "server"}, nil)
+	s.AddResource(&mcp.Resource{URI: "file:///r", Name: "r", Annotations: ann},
+		func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: "file:///r", Text: "r"}}}, nil
+		})
+	s.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: "file:///t/{x}", Name: "t", Annotations: ann}, nil)
+	s.AddTool(&mcp.Tool{Name: "tool", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.TextContent{Text: "for the user", Annotations: ann},
+				&mcp.ResourceLink{URI: "file:///r", Name: "r", Annotations: ann},
+			}}, nil
+		})
+	ct, st := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	resources, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ann, resources.Resources[0].Annotations); diff != "" {
+		t.Errorf("ListResources mismatch (-want +got):\n%s", diff)
+	}
+	templates, err := cs.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ann, templates.ResourceTemplates[0].Annotations); diff != "" {
+		t.Errorf("ListResourceTemplates mismatch (-want +got):\n%s", diff)
+	}
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "tool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ann, res.Content[0].(*mcp.TextContent).Annotations); diff != "" {
+		t.Errorf("TextContent mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ann, res.Content[1].(*mcp.ResourceLink).Annotations); diff != "" {
+		t.Errorf("ResourceLink mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrTokenBudgetExhausted is returned by [Conversation.Send] when the
+// conversation's token budget has been used up.
+var ErrTokenBudgetExhausted = errors.New("sampling token budget exhausted")
+
+// A Conversation is a sequence of sampling requests to a client that share a
+// message history. Each call to [Conversation.Send] sends the history so far
+// followed by a new user message, and on success appends both the user
+// message and the sampled response to the history.
+//
+// A Conversation is typically used within a single tool call, by tools that
+// consult the client's model several times. Create one with
+// [ServerSession.NewConversation]. It is safe for concurrent use, though
+// concurrent sends are serialized.
+type Conversation struct {
+	session *ServerSession
+	params  CreateMessageParams // template for each request
+	budget  int64
+
+	mu       sync.Mutex
+	messages []*SamplingMessage
+	used     int64
+}
+
+// ConversationOptions configures a [Conversation].
+type ConversationOptions struct {
+	// Params is the template for each sampling request, providing the system
+	// prompt, model preferences and so on. Its Messages are the initial
+	// history of the conversation.
+	Params *CreateMessageParams
+	// If positive, TokenBudget limits the number of tokens that may be
+	// requested over the whole conversation. Each request is charged its
+	// MaxTokens, since clients do not report the number of tokens actually
+	// used; the last request is shortened to fit the budget.
+	TokenBudget int64
+}
+
+// NewConversation returns a new Conversation with the client of ss.
+func (ss *ServerSession) NewConversation(opts *ConversationOptions) *Conversation {
+	c := &Conversation{session: ss}
+	if opts != nil {
+		if opts.Params != nil {
+			c.params = *opts.Params
+			c.messages = slices.Clone(opts.Params.Messages)
+			c.params.Messages = nil
+		}
+		c.budget = opts.TokenBudget
+	}
+	return c
+}
+
+// Messages returns a copy of the conversation history.
+func (c *Conversation) Messages() []*SamplingMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.messages)
+}
+
+// Remaining returns the number of tokens left in the conversation's budget,
+// or -1 if it has no budget.
+func (c *Conversation) Remaining() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.budget <= 0 {
+		return -1
+	}
+	return c.budget - c.used
+}
+
+// Send sends the conversation history followed by a user message with the
+// given content, and returns the client's response.
+func (c *Conversation) Send(ctx context.Context, content Content) (*CreateMessageResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	params := c.params
+	if c.budget > 0 {
+		remaining := c.budget - c.used
+		if remaining <= 0 {
+			return nil, ErrTokenBudgetExhausted
+		}
+		if params.MaxTokens <= 0 || params.MaxTokens > remaining {
+			params.MaxTokens = remaining
+		}
+	}
+	msg := &SamplingMessage{Role: RoleUser, Content: content}
+	params.Messages = append(slices.Clip(c.messages), msg)
+	res, err := c.session.CreateMessage(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+	c.used += params.MaxTokens
+	role := res.Role
+	if role == "" {
+		role = RoleAssistant
+	}
+	c.messages = append(c.messages, msg, &SamplingMessage{Role: role, Content: res.Content})
+	return res, nil
+}
+
+// SendText is like [Conversation.Send], but sends and receives text.
+// It returns an error if the response is not text.
+func (c *Conversation) SendText(ctx context.Context, text string) (string, error) {
+	res, err := c.Send(ctx, &TextContent{Text: text})
+	if err != nil {
+		return "", err
+	}
+	tc, ok := res.Content.(*TextContent)
+	if !ok {
+		return "", fmt.Errorf("sampling response has content type %T, want text", res.Content)
+	}
+	return tc.Text, nil
+}
+
+// SendJSON is like [Conversation.SendText], but decodes the response text as
+// JSON into a value of type T. A Markdown code fence around the JSON, as
+// models often produce, is ignored.
+//
+// The prompt should ask for a response in the desired format.
+func SendJSON[T any](ctx context.Context, c *Conversation, text string) (T, error) {
+	var v T
+	resp, err := c.SendText(ctx, text)
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal([]byte(trimCodeFence(resp)), &v); err != nil {
+		return v, fmt.Errorf("decoding sampling response: %w", err)
+	}
+	return v, nil
+}
+
+// trimCodeFence removes a Markdown code fence, such as "```json", that
+// surrounds s.
+func trimCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	rest, ok := strings.CutPrefix(s, "```")
+	if !ok {
+		return s
+	}
+	body, ok := strings.CutSuffix(rest, "```")
+	if !ok {
+		return s
+	}
+	// Drop the info string, such as "json", on the opening line.
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	}
+	return strings.TrimSpace(body)
+}
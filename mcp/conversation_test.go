@@ -0,0 +1,88 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConversation(t *testing.T) {
+	var maxTokens []int64
+	client := NewClient(testImpl, &ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *CreateMessageRequest) (*CreateMessageResult, error) {
+			maxTokens = append(maxTokens, req.Params.MaxTokens)
+			last := req.Params.Messages[len(req.Params.Messages)-1].Content.(*TextContent).Text
+			var reply string
+			if last == "json" {
+				reply = fmt.Sprintf("```json\n{\"messages\": %d}\n```", len(req.Params.Messages))
+			} else {
+				reply = fmt.Sprintf("%d messages, last %q", len(req.Params.Messages), last)
+			}
+			return &CreateMessageResult{Model: "m", Role: RoleAssistant, Content: &TextContent{Text: reply}}, nil
+		},
+	})
+	_, ss, cleanup := basicClientServerConnection(t, client, nil, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	conv := ss.NewConversation(&ConversationOptions{
+		Params: &CreateMessageParams{
+			MaxTokens: 100,
+			Messages:  []*SamplingMessage{{Role: RoleUser, Content: &TextContent{Text: "context"}}},
+		},
+		TokenBudget: 250,
+	})
+	got, err := conv.SendText(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `2 messages, last "a"`; got != want {
+		t.Errorf("first response: got %q, want %q", got, want)
+	}
+	type count struct {
+		Messages int `json:"messages"`
+	}
+	c, err := SendJSON[count](ctx, conv, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Messages != 4 {
+		t.Errorf("JSON response: got %d messages, want 4", c.Messages)
+	}
+	if got := len(conv.Messages()); got != 5 {
+		t.Errorf("history has %d messages, want 5", got)
+	}
+	if _, err := conv.SendText(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := conv.Remaining(); got != 0 {
+		t.Errorf("Remaining() = %d, want 0", got)
+	}
+	if _, err := conv.SendText(ctx, "c"); !errors.Is(err, ErrTokenBudgetExhausted) {
+		t.Errorf("over budget: got %v, want ErrTokenBudgetExhausted", err)
+	}
+	// The last request is shortened to fit the budget.
+	if diff := cmp.Diff([]int64{100, 100, 50}, maxTokens); diff != "" {
+		t.Errorf("MaxTokens mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTrimCodeFence(t *testing.T) {
+	for _, test := range []struct{ in, want string }{
+		{`{"a":1}`, `{"a":1}`},
+		{"```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"  ```\n[1]\n```  ", `[1]`},
+		{"```unterminated", "```unterminated"},
+	} {
+		if got := trimCodeFence(test.in); got != test.want {
+			t.Errorf("trimCodeFence(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
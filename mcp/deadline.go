@@ -0,0 +1,109 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// DeadlineHintKey is the key in the _meta field of request params that holds
+// a deadline hint: the number of milliseconds the sender is prepared to wait
+// for a response, measured from when the request was sent.
+//
+// A deadline hint is advisory. It lets a server stop work in time to return
+// a partial result, rather than have the request cancelled.
+const DeadlineHintKey = "go-sdk/timeoutMs"
+
+// SetDeadlineHint sets a deadline hint in the params' Meta field.
+// See [DeadlineHintKey].
+func SetDeadlineHint(p Params, timeout time.Duration) {
+	m := p.GetMeta()
+	if m == nil {
+		m = map[string]any{}
+		p.SetMeta(m)
+	}
+	m[DeadlineHintKey] = max(0, timeout.Milliseconds())
+}
+
+// DeadlineHint returns the deadline hint from the params' Meta field, if
+// there is a valid one. See [DeadlineHintKey]. A hint longer than the
+// longest Duration is reported as the longest Duration.
+func DeadlineHint(p Params) (time.Duration, bool) {
+	var ms float64
+	switch v := p.GetMeta()[DeadlineHintKey].(type) {
+	case float64: // decoded from JSON
+		ms = v
+	case int64:
+		ms = float64(v)
+	case int:
+		ms = float64(v)
+	default:
+		return 0, false
+	}
+	if !(ms >= 0) { // also rejects NaN
+		return 0, false
+	}
+	// A hint too long for a Duration is as good as unbounded.
+	if ms >= math.MaxInt64/float64(time.Millisecond) {
+		return math.MaxInt64, true
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
+
+// A DeadlinePolicy controls how a server applies the deadline hints of
+// incoming requests. See [ServerOptions.DeadlineHints].
+type DeadlinePolicy struct {
+	// Max bounds the deadline hints that are honored: longer hints are
+	// shortened to Max. Zero means no bound.
+	Max time.Duration
+	// Margin is how long before the hinted deadline the handler's context
+	// expires, leaving time to send a response that arrives before the client
+	// gives up. If zero, a tenth of the hint is used.
+	Margin time.Duration
+}
+
+// applyDeadlineHint returns a context with a deadline derived from the
+// deadline hint in params, if any, according to the server's policy.
+func (ss *ServerSession) applyDeadlineHint(ctx context.Context, params Params) (context.Context, context.CancelFunc) {
+	policy := ss.server.opts.DeadlineHints
+	if policy == nil || params == nil {
+		return ctx, func() {}
+	}
+	timeout, ok := DeadlineHint(params)
+	if !ok {
+		return ctx, func() {}
+	}
+	if policy.Max > 0 {
+		timeout = min(timeout, policy.Max)
+	}
+	margin := policy.Margin
+	if margin == 0 {
+		margin = timeout / 10
+	}
+	return context.WithTimeout(ctx, max(0, timeout-margin))
+}
+
+// attachDeadlineHint adds a deadline hint to the non-nil params of an
+// outgoing request if the client is configured to do so and ctx has a
+// deadline. It returns the params to send, which are a copy if a hint was
+// added.
+func (cs *ClientSession) attachDeadlineHint(ctx context.Context, method string, params Params) Params {
+	rp, ok := params.(RequestParams)
+	if !ok || method == methodInitialize || !cs.client.opts.DeadlineHints {
+		return params
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return params
+	}
+	if _, ok := DeadlineHint(rp); ok {
+		return params
+	}
+	p := copyParams(rp)
+	SetDeadlineHint(p, time.Until(deadline))
+	return p
+}
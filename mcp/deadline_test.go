@@ -0,0 +1,93 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDeadlineHints(t *testing.T) {
+	client := NewClient(testImpl, &ClientOptions{DeadlineHints: true})
+	server := NewServer(testImpl, &ServerOptions{
+		DeadlineHints: &DeadlinePolicy{Max: 10 * time.Second, Margin: time.Second},
+	})
+	type result struct {
+		remaining time.Duration
+		ok        bool
+	}
+	results := make(chan result, 1)
+	AddTool(server, &Tool{Name: "work"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		deadline, ok := ctx.Deadline()
+		results <- result{time.Until(deadline), ok}
+		return nil, nil, nil
+	})
+	cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+	defer cleanup()
+
+	for _, test := range []struct {
+		name    string
+		timeout time.Duration // if zero, no deadline
+		hint    time.Duration // if non-zero, set explicitly
+		min     time.Duration
+		max     time.Duration // if zero, no deadline is expected
+	}{
+		{name: "no deadline"},
+		{name: "deadline", timeout: 5 * time.Second, min: 3 * time.Second, max: 4 * time.Second},
+		{name: "bounded", timeout: time.Minute, min: 8 * time.Second, max: 9 * time.Second},
+		{name: "explicit", timeout: time.Minute, hint: 3 * time.Second, min: time.Second, max: 2 * time.Second},
+		{name: "huge", timeout: time.Minute, hint: math.MaxInt64, min: 8 * time.Second, max: 9 * time.Second},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+			params := &CallToolParams{Name: "work"}
+			if test.hint > 0 {
+				SetDeadlineHint(params, test.hint)
+			}
+			if _, err := cs.CallTool(ctx, params); err != nil {
+				t.Fatal(err)
+			}
+			if test.hint == 0 && params.Meta != nil {
+				t.Errorf("caller's params were modified: %v", params.Meta)
+			}
+			got := <-results
+			if test.max == 0 {
+				if got.ok {
+					t.Errorf("got deadline in %v, want none", got.remaining)
+				}
+				return
+			}
+			if !got.ok || got.remaining < test.min || got.remaining > test.max {
+				t.Errorf("got deadline in %v (ok=%t), want between %v and %v", got.remaining, got.ok, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestDeadlineHint(t *testing.T) {
+	p := &CallToolParams{}
+	if _, ok := DeadlineHint(p); ok {
+		t.Error("empty params: got a hint")
+	}
+	SetDeadlineHint(p, 1500*time.Millisecond)
+	if d, ok := DeadlineHint(p); !ok || d != 1500*time.Millisecond {
+		t.Errorf("DeadlineHint = %v, %t, want 1.5s, true", d, ok)
+	}
+	p.Meta[DeadlineHintKey] = 1e17 // out of range for a Duration
+	if d, ok := DeadlineHint(p); !ok || d != math.MaxInt64 {
+		t.Errorf("DeadlineHint = %v, %t, want the longest Duration, true", d, ok)
+	}
+	p.Meta[DeadlineHintKey] = "soon"
+	if _, ok := DeadlineHint(p); ok {
+		t.Error("invalid hint: got ok")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// A DirectTransport is a [Transport] that connects a client and server in
+// the same process, passing params and results between them as Go values,
+// without marshaling them to JSON. It is intended for embedding servers in
+// applications, where serialization would be pure overhead.
+//
+// DirectTransports should be constructed using [NewDirectTransports], which
+// returns two transports connected to each other.
+//
+// Values are shared between the client and server, not copied: neither side
+// may modify params or results after sending or receiving them. Results are
+// received as shallow copies, but the slices, maps and pointers that they
+// hold are shared. Fields of type any, such as
+// [CallToolResult.StructuredContent], hold the Go values that the sender
+// set, rather than their JSON decoding. Tool arguments are still marshaled,
+// since tool handlers receive them as JSON (see [CallToolParamsRaw]).
+//
+// Params and results of another type than the receiver expects, as when a
+// middleware substitutes its own, are converted through JSON. If the
+// connection is wrapped, as by a [LoggingTransport], messages are marshaled
+// as usual.
+type DirectTransport struct {
+	conn *directConn
+}
+
+// Connect implements the [Transport] interface.
+func (t *DirectTransport) Connect(context.Context) (Connection, error) {
+	return t.conn, nil
+}
+
+// NewDirectTransports returns two [DirectTransport] objects that connect to
+// each other.
+//
+// As with [NewInMemoryTransports], the transports are symmetrical, and
+// servers must be connected before clients.
+func NewDirectTransports() (*DirectTransport, *DirectTransport) {
+	c1 := &directConn{incoming: make(chan jsonrpc.Message), closed: make(chan struct{})}
+	c2 := &directConn{incoming: make(chan jsonrpc.Message), closed: make(chan struct{})}
+	c1.peer, c2.peer = c2, c1
+	return &DirectTransport{conn: c1}, &DirectTransport{conn: c2}
+}
+
+// A directConnection is a Connection that delivers messages to its peer
+// without encoding them, so that their params and results need not be
+// marshaled. See [jsonrpc2.ConnectionConfig.Direct].
+type directConnection interface {
+	Connection
+	direct()
+}
+
+// A directConn is one end of a pair of connections created by
+// [NewDirectTransports]. Messages written to one end are read from the other.
+type directConn struct {
+	peer      *directConn
+	incoming  chan jsonrpc.Message
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *directConn) direct() {}
+
+func (c *directConn) SessionID() string { return "" }
+
+func (c *directConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, io.EOF
+	case msg := <-c.incoming:
+		return msg, nil
+	}
+}
+
+func (c *directConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return ErrConnectionClosed
+	case c.peer.incoming <- msg:
+		return nil
+	}
+}
+
+// Close closes both ends of the connection, as with [net.Pipe].
+func (c *directConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.peer.closeOnce.Do(func() { close(c.peer.closed) })
+	return nil
+}
+
+// A directConverter is a Params type that the receiver of a method expects as
+// another type. Its convertDirect method converts it for receipt on a direct
+// connection.
+type directConverter interface {
+	convertDirect() (Params, error)
+}
+
+// convertDirect implements [directConverter]. Tool handlers receive their
+// arguments as JSON.
+func (p *CallToolParams) convertDirect() (Params, error) {
+	if p == nil {
+		return (*CallToolParamsRaw)(nil), nil
+	}
+	raw := &CallToolParamsRaw{Meta: p.Meta, Name: p.Name}
+	if p.Arguments != nil {
+		args, err := json.Marshal(p.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		raw.Arguments = args
+	}
+	return raw, nil
+}
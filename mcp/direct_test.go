@@ -0,0 +1,129 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+type directResult struct {
+	Ch chan int // can't be marshaled
+}
+
+func TestDirectTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	structured := &directResult{Ch: make(chan int)}
+	server.AddTool(&Tool{Name: "direct", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		return &CallToolResult{Content: []Content{}, StructuredContent: structured}, nil
+	})
+	cancelled := make(chan error, 1)
+	server.AddTool(&Tool{Name: "hang", InputSchema: &jsonschema.Schema{Type: "object"}}, func(ctx context.Context, _ *CallToolRequest) (*CallToolResult, error) {
+		<-ctx.Done()
+		cancelled <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+	st, ct := NewDirectTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(testImpl, nil)
+	client.AddRoots(&Root{URI: "file:///root"})
+	cs, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool(greet): got %q, want %q", got, "hi user")
+	}
+
+	// Results are not marshaled: the client receives the server's values.
+	res, err = cs.CallTool(ctx, &CallToolParams{Name: "direct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StructuredContent != structured {
+		t.Errorf("CallTool(direct): got structured content %v, want the server's value", res.StructuredContent)
+	}
+
+	// Errors are delivered as from the wire.
+	if _, err := cs.GetPrompt(ctx, &GetPromptParams{Name: "missing"}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("GetPrompt(missing): got %v, want unknown prompt error", err)
+	}
+
+	// The server can make requests of the client.
+	roots, err := ss.ListRoots(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots.Roots) != 1 || roots.Roots[0].URI != "file:///root" {
+		t.Errorf("ListRoots: got %v, want the client's root", roots.Roots)
+	}
+
+	// Cancellation is delivered.
+	callCtx, callCancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		callCancel()
+	}()
+	if _, err := cs.CallTool(callCtx, &CallToolParams{Name: "hang"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CallTool(hang): got %v, want context.Canceled", err)
+	}
+	var cerr *cancelledError
+	if cause := <-cancelled; !errors.As(cause, &cerr) {
+		t.Errorf("hanging tool cancelled with cause %v, want cancellation by the peer", cause)
+	}
+
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitSession(t, ctx, ss)
+}
+
+func TestDirectTransportLogging(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	st, ct := NewDirectTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Wrapped connections marshal their messages.
+	var buf bytes.Buffer
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &LoggingTransport{Transport: ct, Writer: &buf}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool: got %q, want %q", got, "hi user")
+	}
+	for _, want := range []string{`"name":"greet"`, `"text":"hi user"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log does not contain %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import "net/http"
+
+// A DualProtocolHandler is an http.Handler that serves both the streamable
+// HTTP transport and the legacy HTTP+SSE transport of protocol version
+// 2024-11-05 on the same URL, so that servers can keep supporting older
+// clients while migrating to the streamable transport.
+//
+// Following the backwards compatibility guidance of the specification, a
+// request is served by the [SSEHandler] if it is a GET request without an
+// Mcp-Session-Id header, which opens an SSE session, or a POST request with a
+// sessionid query parameter, which sends a message to an SSE session. Other
+// requests are served by the [StreamableHTTPHandler]. Since streamable
+// clients start with a POST request, and legacy clients with a GET request,
+// each kind of client reaches its transport.
+type DualProtocolHandler struct {
+	streamable *StreamableHTTPHandler
+	sse        *SSEHandler
+}
+
+// DualProtocolOptions configures a [DualProtocolHandler].
+type DualProtocolOptions struct {
+	// Streamable configures the handler of streamable HTTP requests.
+	Streamable *StreamableHTTPOptions
+	// SSE configures the handler of HTTP+SSE requests.
+	SSE *SSEOptions
+}
+
+// NewDualProtocolHandler returns a new [DualProtocolHandler] that creates
+// sessions of both transports with the servers returned by getServer, as
+// for [NewStreamableHTTPHandler] and [NewSSEHandler].
+func NewDualProtocolHandler(getServer func(*http.Request) *Server, opts *DualProtocolOptions) *DualProtocolHandler {
+	if opts == nil {
+		opts = new(DualProtocolOptions)
+	}
+	return &DualProtocolHandler{
+		streamable: NewStreamableHTTPHandler(getServer, opts.Streamable),
+		sse:        NewSSEHandler(getServer, opts.SSE),
+	}
+}
+
+func (h *DualProtocolHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if isSSERequest(req) {
+		h.sse.ServeHTTP(w, req)
+		return
+	}
+	h.streamable.ServeHTTP(w, req)
+}
+
+// isSSERequest reports whether req belongs to the HTTP+SSE transport. See
+// [DualProtocolHandler].
+func isSSERequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet:
+		return req.Header.Get(sessionIDHeader) == ""
+	case http.MethodPost:
+		return req.URL.Query().Has("sessionid")
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDualProtocolHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	handler := NewDualProtocolHandler(func(*http.Request) *Server { return server }, nil)
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	transports := map[string]Transport{
+		"streamable": &StreamableClientTransport{Endpoint: httpServer.URL},
+		"sse":        &SSEClientTransport{Endpoint: httpServer.URL},
+	}
+	for name, transport := range transports {
+		t.Run(name, func(t *testing.T) {
+			cs, err := NewClient(testImpl, nil).Connect(ctx, transport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+			res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+				t.Errorf("CallTool: got %q, want %q", got, "hi user")
+			}
+		})
+	}
+}
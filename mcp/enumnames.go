@@ -0,0 +1,109 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// enumNamesKeyword is the non-standard schema keyword holding display names
+// for enum values, used by elicitation schemas.
+const enumNamesKeyword = "enumNames"
+
+// EnumNames returns the display names of the enum values of s, held in its
+// "enumNames" keyword. The i'th name labels the i'th value of s.Enum.
+//
+// If s has no enumNames, EnumNames returns nil, nil. It returns an error if
+// the enumNames are not an array of strings, or if their number differs from
+// the number of enum values.
+func EnumNames(s *jsonschema.Schema) ([]string, error) {
+	raw, ok := s.Extra[enumNamesKeyword]
+	if !ok {
+		return nil, nil
+	}
+	var names []string
+	switch raw := raw.(type) {
+	case []string:
+		names = raw
+	case []any:
+		for _, n := range raw {
+			name, ok := n.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string enumNames value %v", n)
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid enumNames type, must be an array")
+	}
+	if len(names) != len(s.Enum) {
+		return nil, fmt.Errorf("%d enum values but %d enumNames, they must match", len(s.Enum), len(names))
+	}
+	return names, nil
+}
+
+// SetEnumNames sets the display names of the enum values of s.
+// It reports an error if the number of names differs from the number of
+// enum values. If names is nil, the enumNames are removed.
+func SetEnumNames(s *jsonschema.Schema, names []string) error {
+	if names == nil {
+		delete(s.Extra, enumNamesKeyword)
+		return nil
+	}
+	if len(names) != len(s.Enum) {
+		return fmt.Errorf("%d enum values but %d enumNames, they must match", len(s.Enum), len(names))
+	}
+	if s.Extra == nil {
+		s.Extra = make(map[string]any)
+	}
+	s.Extra[enumNamesKeyword] = names
+	return nil
+}
+
+// An EnumChoice is an enum value together with its display label.
+type EnumChoice struct {
+	Value any
+	// Label is the display name of the value, from the schema's enumNames.
+	// If there are no enumNames, it is the value formatted with %v.
+	Label string
+}
+
+// EnumChoices returns the choices for an enum schema, in order, for
+// presentation to a user. It returns nil if s is not an enum.
+func EnumChoices(s *jsonschema.Schema) ([]EnumChoice, error) {
+	names, err := EnumNames(s)
+	if err != nil {
+		return nil, err
+	}
+	var choices []EnumChoice
+	for i, v := range s.Enum {
+		c := EnumChoice{Value: v, Label: fmt.Sprint(v)}
+		if names != nil {
+			c.Label = names[i]
+		}
+		choices = append(choices, c)
+	}
+	return choices, nil
+}
+
+// EnumChoices returns the choices for the named enum property of the
+// requested schema. It is meant for clients presenting an elicitation to a
+// user.
+func (p *ElicitParams) EnumChoices(property string) ([]EnumChoice, error) {
+	if p.RequestedSchema == nil {
+		return nil, fmt.Errorf("no requested schema")
+	}
+	var schema *jsonschema.Schema
+	if err := remarshal(p.RequestedSchema, &schema); err != nil {
+		return nil, err
+	}
+	ps := schema.Properties[property]
+	if ps == nil {
+		return nil, fmt.Errorf("no property %q in requested schema", property)
+	}
+	return EnumChoices(ps)
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestEnumNames(t *testing.T) {
+	s := &jsonschema.Schema{Type: "string", Enum: []any{"lo", "hi"}}
+	if names, err := EnumNames(s); names != nil || err != nil {
+		t.Errorf("no enumNames: got %v, %v, want nil, nil", names, err)
+	}
+	if err := SetEnumNames(s, []string{"Low"}); err == nil {
+		t.Error("SetEnumNames with too few names: got nil error")
+	}
+	if err := SetEnumNames(s, []string{"Low", "High"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The names survive a round trip through JSON, as on the client.
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s2 *jsonschema.Schema
+	if err := json.Unmarshal(data, &s2); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*jsonschema.Schema{s, s2} {
+		got, err := EnumChoices(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []EnumChoice{{"lo", "Low"}, {"hi", "High"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("EnumChoices mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if err := SetEnumNames(s, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := EnumChoices(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []EnumChoice{{"lo", "lo"}, {"hi", "hi"}}; !cmp.Equal(want, got) {
+		t.Errorf("EnumChoices without names: got %v, want %v", got, want)
+	}
+
+	s.Extra = map[string]any{"enumNames": []any{"Low", 2}}
+	if _, err := EnumNames(s); err == nil {
+		t.Error("non-string enumNames: got nil error")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// Error codes used by the SDK.
+//
+// Codes from -32768 to -32000 are reserved by JSON-RPC. The SDK defines its
+// own codes between -31999 and -31000. Applications should define their codes
+// in the vendor range, from [MinVendorCode] to [MaxVendorCode], and register
+// them with [RegisterErrorCode].
+const (
+	// JSON-RPC error codes.
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	// The error code for invalid parameters
+	CodeInvalidParams = -32602
+	// The error code for internal errors
+	CodeInternalError = -32603
+
+	// The error code if a resource does not exist. See [ResourceNotFoundError].
+	CodeResourceNotFound = -32002
+
+	// The error code if the method exists and was called properly, but the peer does not support it.
+	CodeUnsupportedMethod = -31001
+	// The error code if a request is denied by [ServerOptions.Authorize].
+	CodeForbidden = -31002
+	// The error code if a tool call exceeds its quota. See [QuotaOptions].
+	CodeQuotaExceeded = -31003
+	// The error code if the output of a tool does not match its output schema.
+	// See [OutputValidationError].
+	CodeOutputValidation = -31004
+)
+
+// The vendor range of error codes, for application-defined errors.
+const (
+	MinVendorCode = -30999
+	MaxVendorCode = -30000
+)
+
+// errorCodes maps error codes to their names.
+var errorCodes = struct {
+	mu    sync.Mutex
+	names map[int64]string
+}{
+	names: map[int64]string{
+		CodeParseError:        "ParseError",
+		CodeInvalidRequest:    "InvalidRequest",
+		CodeMethodNotFound:    "MethodNotFound",
+		CodeInvalidParams:     "InvalidParams",
+		CodeInternalError:     "InternalError",
+		CodeResourceNotFound:  "ResourceNotFound",
+		CodeUnsupportedMethod: "UnsupportedMethod",
+		CodeForbidden:         "Forbidden",
+		CodeQuotaExceeded:     "QuotaExceeded",
+		CodeOutputValidation:  "OutputValidation",
+	},
+}
+
+// RegisterErrorCode registers a name for an application-defined error code,
+// for use by [ErrorCodeName]. It is typically called from an init function.
+//
+// RegisterErrorCode panics if code is outside the vendor range, from
+// [MinVendorCode] to [MaxVendorCode], or is already registered.
+func RegisterErrorCode(code int64, name string) {
+	if code < MinVendorCode || code > MaxVendorCode {
+		panic(fmt.Sprintf("RegisterErrorCode: code %d is outside the vendor range [%d, %d]", code, MinVendorCode, MaxVendorCode))
+	}
+	errorCodes.mu.Lock()
+	defer errorCodes.mu.Unlock()
+	if old, ok := errorCodes.names[code]; ok {
+		panic(fmt.Sprintf("RegisterErrorCode: code %d is already registered as %q", code, old))
+	}
+	errorCodes.names[code] = name
+}
+
+// ErrorCodeName returns the name of an error code defined by JSON-RPC or the
+// SDK, or registered with [RegisterErrorCode]. It reports false if the code is
+// unknown.
+func ErrorCodeName(code int64) (string, bool) {
+	errorCodes.mu.Lock()
+	defer errorCodes.mu.Unlock()
+	name, ok := errorCodes.names[code]
+	return name, ok
+}
+
+// NewError returns an error with the given code and message, which is sent
+// to the peer as a JSON-RPC error when returned from a handler. If data is
+// non-nil, its JSON encoding becomes the data of the error.
+func NewError(code int64, message string, data any) error {
+	werr := &jsonrpc2.WireError{Code: code, Message: message}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("marshaling data of error %d: %w", code, err)
+		}
+		werr.Data = raw
+	}
+	return werr
+}
+
+// ErrorCode returns the code of err, if err is or wraps a JSON-RPC error,
+// such as one returned by a call to the peer or created by [NewError].
+func ErrorCode(err error) (int64, bool) {
+	var werr *jsonrpc2.WireError
+	if errors.As(err, &werr) {
+		return werr.Code, true
+	}
+	return 0, false
+}
+
+// ErrorData unmarshals the data of err into v, if err is or wraps a JSON-RPC
+// error with data. It reports whether there was data to unmarshal.
+func ErrorData(err error, v any) (bool, error) {
+	var werr *jsonrpc2.WireError
+	if !errors.As(err, &werr) || len(werr.Data) == 0 {
+		return false, nil
+	}
+	return true, json.Unmarshal(werr.Data, v)
+}
+
+// IsTemporary reports whether err is due to a condition that is expected to
+// clear without intervention, such as a timeout, an overloaded or rate
+// limiting server, or an exceeded quota.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, jsonrpc2.ErrServerOverloaded) {
+		return true
+	}
+	if code, ok := ErrorCode(err); ok && code == CodeQuotaExceeded {
+		return true
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+	var herr *httpStatusError
+	if errors.As(err, &herr) {
+		switch herr.code {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether a request that failed with err may succeed if
+// it is sent again, possibly over a new connection. In addition to
+// [IsTemporary] errors, this includes closed connections and network errors
+// such as refused or reset connections.
+//
+// Errors in the request itself, such as invalid parameters or unknown
+// methods, denied requests, and cancellation are not retryable. Tool errors,
+// which are reported in [CallToolResult.IsError] rather than as Go errors,
+// are not classified.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if IsTemporary(err) {
+		return true
+	}
+	if errors.Is(err, ErrConnectionClosed) ||
+		errors.Is(err, jsonrpc2.ErrServerClosing) ||
+		errors.Is(err, jsonrpc2.ErrClientClosing) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	if _, ok := ErrorCode(err); ok {
+		// Other JSON-RPC errors are answers from the peer.
+		return false
+	}
+	var oerr *net.OpError
+	return errors.As(err, &oerr) && oerr.Op == "dial"
+}
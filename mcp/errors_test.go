@@ -0,0 +1,160 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+func TestErrorCodeRegistry(t *testing.T) {
+	for code, want := range map[int64]string{
+		CodeInvalidParams:    "InvalidParams",
+		CodeResourceNotFound: "ResourceNotFound",
+		CodeForbidden:        "Forbidden",
+	} {
+		if got, ok := ErrorCodeName(code); !ok || got != want {
+			t.Errorf("ErrorCodeName(%d) = %q, %t, want %q", code, got, ok, want)
+		}
+	}
+	if _, ok := ErrorCodeName(MaxVendorCode); ok {
+		t.Errorf("ErrorCodeName(%d) succeeded before registration", MaxVendorCode)
+	}
+	RegisterErrorCode(MaxVendorCode, "Test")
+	defer func() {
+		errorCodes.mu.Lock()
+		delete(errorCodes.names, MaxVendorCode)
+		errorCodes.mu.Unlock()
+	}()
+	if got, ok := ErrorCodeName(MaxVendorCode); !ok || got != "Test" {
+		t.Errorf("ErrorCodeName(%d) = %q, %t, want Test", MaxVendorCode, got, ok)
+	}
+
+	for _, code := range []int64{MaxVendorCode, MinVendorCode - 1, MaxVendorCode + 1, CodeForbidden} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterErrorCode(%d) did not panic", code)
+				}
+			}()
+			RegisterErrorCode(code, "Bad")
+		}()
+	}
+}
+
+func TestNewError(t *testing.T) {
+	const codeOutOfStock = MinVendorCode
+	type stock struct {
+		Item string `json:"item"`
+	}
+	server := NewServer(testImpl, nil)
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		s.AddTool(&Tool{Name: "buy", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+			return nil, NewError(codeOutOfStock, "out of stock", stock{Item: "widget"})
+		})
+	})
+	defer cleanup()
+
+	_, err := cs.CallTool(context.Background(), &CallToolParams{Name: "buy"})
+	if code, ok := ErrorCode(err); !ok || code != codeOutOfStock {
+		t.Fatalf("ErrorCode(%v) = %d, %t, want %d", err, code, ok, codeOutOfStock)
+	}
+	var got stock
+	if ok, err := ErrorData(err, &got); !ok || err != nil || got.Item != "widget" {
+		t.Errorf("ErrorData = %t, %v, data %+v, want widget", ok, err, got)
+	}
+
+	if _, ok := ErrorCode(errors.New("plain")); ok {
+		t.Error("ErrorCode of a plain error succeeded")
+	}
+	wrapped := fmt.Errorf("wrapped: %w", NewError(CodeForbidden, "no", nil))
+	if code, ok := ErrorCode(wrapped); !ok || code != CodeForbidden {
+		t.Errorf("ErrorCode(%v) = %d, %t, want %d", wrapped, code, ok, CodeForbidden)
+	}
+	if ok, _ := ErrorData(wrapped, new(any)); ok {
+		t.Error("ErrorData of an error without data reported data")
+	}
+	if err := NewError(MinVendorCode, "bad data", func() {}); err == nil {
+		t.Error("NewError with unmarshalable data returned nil")
+	} else if _, ok := ErrorCode(err); ok {
+		t.Errorf("NewError with unmarshalable data returned a JSON-RPC error: %v", err)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorClassification(t *testing.T) {
+	for _, test := range []struct {
+		name                 string
+		err                  error
+		temporary, retryable bool
+	}{
+		{"nil", nil, false, false},
+		{"plain", errors.New("boom"), false, false},
+		{"deadline", fmt.Errorf("calling: %w", context.DeadlineExceeded), true, true},
+		{"canceled", context.Canceled, false, false},
+		{"net timeout", &net.OpError{Op: "read", Err: timeoutError{}}, true, true},
+		{"overloaded", jsonrpc2.ErrServerOverloaded, true, true},
+		{"quota", NewError(CodeQuotaExceeded, "quota exceeded", nil), true, true},
+		{"503", fmt.Errorf("broken session: %w", &httpStatusError{code: 503, status: "503 Service Unavailable"}), true, true},
+		{"400", &httpStatusError{code: 400, status: "400 Bad Request"}, false, false},
+		{"closed", fmt.Errorf("%w: calling %q: EOF", ErrConnectionClosed, "tools/call"), false, true},
+		{"refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false, true},
+		{"dial", &net.OpError{Op: "dial", Err: errors.New("no such host")}, false, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, false, true},
+		{"invalid params", NewError(CodeInvalidParams, "bad", nil), false, false},
+		{"forbidden", NewError(CodeForbidden, "no", nil), false, false},
+		{"validation", &OutputValidationError{Tool: "t", Validation: &ValidationError{}}, false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsTemporary(test.err); got != test.temporary {
+				t.Errorf("IsTemporary(%v) = %t, want %t", test.err, got, test.temporary)
+			}
+			if got := IsRetryable(test.err); got != test.retryable {
+				t.Errorf("IsRetryable(%v) = %t, want %t", test.err, got, test.retryable)
+			}
+		})
+	}
+}
+
+func TestErrorClassificationStreamable(t *testing.T) {
+	ctx := context.Background()
+
+	// Connecting to a dead endpoint is retryable.
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	_, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: dead.URL}, nil)
+	if err == nil || !IsRetryable(err) {
+		t.Errorf("Connect to dead endpoint: got %v, want a retryable error", err)
+	}
+
+	// So is a call after the server goes away.
+	server := NewServer(testImpl, nil)
+	httpServer := httptest.NewServer(NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil))
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	httpServer.CloseClientConnections()
+	httpServer.Close()
+	if _, err := cs.ListTools(ctx, nil); err == nil || !IsRetryable(err) {
+		t.Errorf("ListTools on dead endpoint: got %v, want a retryable error", err)
+	}
+}
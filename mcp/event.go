@@ -10,16 +10,20 @@ package mcp
 import (
 	"bufio"
 	"bytes"
+	"container/heap"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
 	"maps"
+	"math"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // If true, MemoryEventStore will do frequent validation to check invariants, slowing it down.
@@ -29,14 +33,15 @@ const validateMemoryEventStore = false
 // An Event is a server-sent event.
 // See https://developer.mozilla.org/en-US/docs/Web/API/Server-sent_events/Using_server-sent_events#fields.
 type Event struct {
-	Name string // the "event" field
-	ID   string // the "id" field
-	Data []byte // the "data" field
+	Name  string        // the "event" field
+	ID    string        // the "id" field
+	Data  []byte        // the "data" field
+	Retry time.Duration // the "retry" field, in whole milliseconds; 0 if unset
 }
 
 // Empty reports whether the Event is empty.
 func (e Event) Empty() bool {
-	return e.Name == "" && e.ID == "" && len(e.Data) == 0
+	return e.Name == "" && e.ID == "" && len(e.Data) == 0 && e.Retry == 0
 }
 
 // writeEvent writes the event to w, and flushes.
@@ -48,6 +53,9 @@ func writeEvent(w io.Writer, evt Event) (int, error) {
 	if evt.ID != "" {
 		fmt.Fprintf(&b, "id: %s\n", evt.ID)
 	}
+	if evt.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", evt.Retry.Milliseconds())
+	}
 	fmt.Fprintf(&b, "data: %s\n\n", string(evt.Data))
 	n, err := w.Write(b.Bytes())
 	if f, ok := w.(http.Flusher); ok {
@@ -56,16 +64,71 @@ func writeEvent(w io.Writer, evt Event) (int, error) {
 	return n, err
 }
 
-// scanEvents iterates SSE events in the given scanner. The iterated error is
+// writeComment writes an SSE comment line to w, and flushes. Clients ignore
+// comments, but they keep idle connections alive.
+func writeComment(w io.Writer, comment string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", comment)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return err
+}
+
+// writeRetry writes an SSE event with only a "retry" field to w, and flushes.
+// It sets the client's reconnection delay without dispatching an event.
+func writeRetry(w io.Writer, retry time.Duration) error {
+	_, err := fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds())
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return err
+}
+
+// DefaultMaxEventSize is the default maximum size of a server-sent event read
+// by a client. See [StreamableClientTransport.MaxEventSize].
+const DefaultMaxEventSize = 1 << 20 // 1 MiB
+
+// ErrEventTooLarge is the error that fails a client connection when the
+// server sends an event larger than the transport's MaxEventSize.
+var ErrEventTooLarge = errors.New("server-sent event too large")
+
+// An eventScanner reads SSE events with limits on their size.
+type eventScanner struct {
+	// maxEventSize is the maximum size of the lines of an event, including
+	// their field names. If zero, DefaultMaxEventSize is used.
+	maxEventSize int
+	// bufferSize is the initial size of the read buffer.
+	// If zero, it is chosen by [bufio.Scanner].
+	bufferSize int
+}
+
+// scanEvents iterates SSE events in the given scanner, with the default
+// limits. See [eventScanner.scan].
+func scanEvents(r io.Reader) iter.Seq2[Event, error] {
+	return eventScanner{}.scan(r)
+}
+
+// scan iterates SSE events in the given scanner. The iterated error is
 // terminal: if encountered, the stream is corrupt or broken and should no
-// longer be used.
+// longer be used. If an event is too large, the error wraps
+// [ErrEventTooLarge].
 //
 // TODO(rfindley): consider a different API here that makes failure modes more
 // apparent.
-func scanEvents(r io.Reader) iter.Seq2[Event, error] {
+func (s eventScanner) scan(r io.Reader) iter.Seq2[Event, error] {
+	maxEventSize := s.maxEventSize
+	if maxEventSize <= 0 {
+		maxEventSize = DefaultMaxEventSize
+	}
 	scanner := bufio.NewScanner(r)
-	const maxTokenSize = 1 * 1024 * 1024 // 1 MiB max line size
-	scanner.Buffer(nil, maxTokenSize)
+	var buf []byte
+	if s.bufferSize > 0 {
+		buf = make([]byte, 0, min(s.bufferSize, maxEventSize))
+	}
+	// A line can be no longer than an event. bufio.Scanner needs room for the
+	// line terminator, which may be "\r\n".
+	scanner.Buffer(buf, maxEventSize+2)
+	tooLarge := fmt.Errorf("%w: exceeds %d bytes", ErrEventTooLarge, maxEventSize)
 
 	// TODO: investigate proper behavior when events are out of order, or have
 	// non-standard names.
@@ -73,6 +136,7 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 		eventKey = []byte("event")
 		idKey    = []byte("id")
 		dataKey  = []byte("data")
+		retryKey = []byte("retry")
 	)
 
 	return func(yield func(Event, error) bool) {
@@ -81,13 +145,15 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 		//
 		//  - `key: value` line records.
 		//  - Consecutive `data: ...` fields are joined with newlines.
-		//  - Unrecognized fields are ignored. Since we only care about 'event', 'id', and
-		//   'data', these are the only three we consider.
+		//  - Unrecognized fields are ignored. Since we only care about 'event', 'id',
+		//   'data' and 'retry', these are the only four we consider.
+		//  - 'retry' values that are not integers are ignored.
 		//  - Lines starting with ":" are ignored.
 		//  - Records are terminated with two consecutive newlines.
 		var (
 			evt     Event
 			dataBuf *bytes.Buffer // if non-nil, preceding field was also data
+			size    int           // of the lines of evt so far
 		)
 		flushData := func() {
 			if dataBuf != nil {
@@ -104,8 +170,13 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 					return
 				}
 				evt = Event{}
+				size = 0
 				continue
 			}
+			if size += len(line); size > maxEventSize {
+				yield(Event{}, tooLarge)
+				return
+			}
 			before, after, found := bytes.Cut(line, []byte{':'})
 			if !found {
 				yield(Event{}, fmt.Errorf("malformed line in SSE stream: %q", string(line)))
@@ -119,6 +190,10 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 				evt.Name = strings.TrimSpace(string(after))
 			case bytes.Equal(before, idKey):
 				evt.ID = strings.TrimSpace(string(after))
+			case bytes.Equal(before, retryKey):
+				if ms, err := strconv.ParseUint(strings.TrimSpace(string(after)), 10, 32); err == nil && ms > 0 {
+					evt.Retry = time.Duration(ms) * time.Millisecond
+				}
 			case bytes.Equal(before, dataKey):
 				data := bytes.TrimSpace(after)
 				if dataBuf != nil {
@@ -132,7 +207,7 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 		}
 		if err := scanner.Err(); err != nil {
 			if errors.Is(err, bufio.ErrTooLong) {
-				err = fmt.Errorf("event exceeded max line length of %d", maxTokenSize)
+				err = tooLarge
 			}
 			if !yield(Event{}, err) {
 				return
@@ -183,14 +258,15 @@ type EventStore interface {
 }
 
 // A dataList is a list of []byte.
-// The zero dataList is ready to use.
 type dataList struct {
 	size  int // total size of data bytes
 	first int // the stream index of the first element in data
 	data  [][]byte
+	seqs  []uint64 // the store-wide append order of each element of data
+	index int      // index in the streamHeap of its session, or -1 if empty
 }
 
-func (dl *dataList) appendData(d []byte) {
+func (dl *dataList) appendData(d []byte, seq uint64) {
 	// If we allowed empty data, we would consume memory without incrementing the size.
 	// We could of course account for that, but we keep it simple and assume there is no
 	// empty data.
@@ -198,6 +274,7 @@ func (dl *dataList) appendData(d []byte) {
 		panic("empty data item")
 	}
 	dl.data = append(dl.data, d)
+	dl.seqs = append(dl.seqs, seq)
 	dl.size += len(d)
 }
 
@@ -211,16 +288,95 @@ func (dl *dataList) removeFirst() int {
 	dl.size -= r
 	dl.data[0] = nil // help GC
 	dl.data = dl.data[1:]
+	dl.seqs = dl.seqs[1:]
 	dl.first++
 	return r
 }
 
 // A MemoryEventStore is an [EventStore] backed by memory.
+//
+// The store retains at most [MemoryEventStore.MaxBytes] bytes of data across
+// all sessions. When it is full, it evicts the oldest data of the session
+// that retains the most, so that a session producing many events evicts its
+// own events before those of other sessions.
 type MemoryEventStore struct {
 	mu       sync.Mutex
-	maxBytes int                             // max total size of all data
-	nBytes   int                             // current total size of all data
-	store    map[string]map[string]*dataList // session ID -> stream ID -> *dataList
+	maxBytes int                       // max total size of all data
+	nBytes   int                       // current total size of all data
+	seq      uint64                    // number of data items appended
+	sessions map[string]*sessionEvents // by session ID
+	bySize   sessionHeap               // sessions, in eviction order
+}
+
+// A sessionEvents holds the streams of a session in a [MemoryEventStore].
+type sessionEvents struct {
+	streams map[string]*dataList // by stream ID
+	size    int                  // total size of the data of all streams
+	index   int                  // index in MemoryEventStore.bySize
+	oldest  streamHeap           // non-empty streams, by age of their first item
+}
+
+// oldestSeq returns the append order of the oldest data item of the session.
+func (se *sessionEvents) oldestSeq() uint64 {
+	if len(se.oldest) == 0 {
+		return math.MaxUint64
+	}
+	return se.oldest[0].seqs[0]
+}
+
+// A sessionHeap is a heap of sessions in eviction order: those that retain
+// the most data first, and among those, the one with the oldest data.
+type sessionHeap []*sessionEvents
+
+func (h sessionHeap) Len() int { return len(h) }
+func (h sessionHeap) Less(i, j int) bool {
+	if h[i].size != h[j].size {
+		return h[i].size > h[j].size
+	}
+	return h[i].oldestSeq() < h[j].oldestSeq()
+}
+func (h sessionHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *sessionHeap) Push(x any) {
+	se := x.(*sessionEvents)
+	se.index = len(*h)
+	*h = append(*h, se)
+}
+func (h *sessionHeap) Pop() any {
+	old := *h
+	se := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	se.index = -1
+	return se
+}
+
+// A streamHeap is a min-heap of non-empty streams by the append order of
+// their first data item.
+type streamHeap []*dataList
+
+func (h streamHeap) Len() int           { return len(h) }
+func (h streamHeap) Less(i, j int) bool { return h[i].seqs[0] < h[j].seqs[0] }
+func (h streamHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *streamHeap) Push(x any) {
+	dl := x.(*dataList)
+	dl.index = len(*h)
+	*h = append(*h, dl)
+}
+func (h *streamHeap) Pop() any {
+	old := *h
+	dl := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	dl.index = -1
+	return dl
 }
 
 // MemoryEventStoreOptions are options for a [MemoryEventStore].
@@ -249,7 +405,25 @@ func (s *MemoryEventStore) SetMaxBytes(n int) {
 	default:
 		s.maxBytes = n
 	}
-	s.purge()
+	s.purge(0)
+}
+
+// Bytes returns the number of bytes of data that the store currently retains.
+func (s *MemoryEventStore) Bytes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nBytes
+}
+
+// SessionBytes returns the number of bytes of data that the store currently
+// retains for the given session.
+func (s *MemoryEventStore) SessionBytes(sessionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if se := s.sessions[sessionID]; se != nil {
+		return se.size
+	}
+	return 0
 }
 
 const defaultMaxBytes = 10 << 20 // 10 MiB
@@ -259,7 +433,7 @@ const defaultMaxBytes = 10 << 20 // 10 MiB
 func NewMemoryEventStore(opts *MemoryEventStoreOptions) *MemoryEventStore {
 	return &MemoryEventStore{
 		maxBytes: defaultMaxBytes,
-		store:    make(map[string]map[string]*dataList),
+		sessions: make(map[string]*sessionEvents),
 	}
 }
 
@@ -272,33 +446,41 @@ func (s *MemoryEventStore) Open(_ context.Context, sessionID, streamID string) e
 	return nil
 }
 
-// init is an internal helper function that ensures the nested map structure for a
-// given sessionID and streamID exists, creating it if necessary. It returns the
-// dataList associated with the specified IDs.
+// init is an internal helper function that ensures the data structures for a
+// given sessionID and streamID exist, creating them if necessary. It returns the
+// session and the dataList associated with the specified IDs.
 // Requires s.mu.
-func (s *MemoryEventStore) init(sessionID, streamID string) *dataList {
-	streamMap, ok := s.store[sessionID]
+func (s *MemoryEventStore) init(sessionID, streamID string) (*sessionEvents, *dataList) {
+	se, ok := s.sessions[sessionID]
 	if !ok {
-		streamMap = make(map[string]*dataList)
-		s.store[sessionID] = streamMap
+		se = &sessionEvents{streams: make(map[string]*dataList)}
+		s.sessions[sessionID] = se
+		heap.Push(&s.bySize, se)
 	}
-	dl, ok := streamMap[streamID]
+	dl, ok := se.streams[streamID]
 	if !ok {
-		dl = &dataList{}
-		streamMap[streamID] = dl
+		dl = &dataList{index: -1}
+		se.streams[streamID] = dl
 	}
-	return dl
+	return se, dl
 }
 
 // Append implements [EventStore.Append] by recording data in memory.
 func (s *MemoryEventStore) Append(_ context.Context, sessionID, streamID string, data []byte) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	dl := s.init(sessionID, streamID)
-	// Purge before adding, so at least the current data item will be present.
-	// (That could result in nBytes > maxBytes, but we'll live with that.)
-	s.purge()
-	dl.appendData(data)
+	se, dl := s.init(sessionID, streamID)
+	// Make room before adding, so at least the current data item will be present.
+	// (If it is larger than maxBytes, that results in nBytes > maxBytes, but
+	// we'll live with that.)
+	s.purge(len(data))
+	s.seq++
+	dl.appendData(data, s.seq)
+	if dl.index < 0 {
+		heap.Push(&se.oldest, dl)
+	}
+	se.size += len(data)
+	heap.Fix(&s.bySize, se.index)
 	s.nBytes += len(data)
 	return nil
 }
@@ -314,11 +496,11 @@ func (s *MemoryEventStore) After(_ context.Context, sessionID, streamID string,
 	copyData := func() ([][]byte, error) {
 		s.mu.Lock()
 		defer s.mu.Unlock()
-		streamMap, ok := s.store[sessionID]
+		se, ok := s.sessions[sessionID]
 		if !ok {
 			return nil, fmt.Errorf("MemoryEventStore.After: unknown session ID %q", sessionID)
 		}
-		dl, ok := streamMap[streamID]
+		dl, ok := se.streams[streamID]
 		if !ok {
 			return nil, fmt.Errorf("MemoryEventStore.After: unknown stream ID %v in session %q", streamID, sessionID)
 		}
@@ -348,34 +530,36 @@ func (s *MemoryEventStore) After(_ context.Context, sessionID, streamID string,
 func (s *MemoryEventStore) SessionClosed(_ context.Context, sessionID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for _, dl := range s.store[sessionID] {
-		s.nBytes -= dl.size
+	if se, ok := s.sessions[sessionID]; ok {
+		s.nBytes -= se.size
+		heap.Remove(&s.bySize, se.index)
+		delete(s.sessions, sessionID)
 	}
-	delete(s.store, sessionID)
 	s.validate()
 	return nil
 }
 
-// purge removes data until no more than s.maxBytes bytes are in use.
+// purge removes data until there is room for extra more bytes without
+// exceeding s.maxBytes, or the store is empty. It evicts the oldest data of
+// the session that retains the most, breaking ties in favor of the session
+// with the oldest data.
 // It must be called with s.mu held.
-func (s *MemoryEventStore) purge() {
-	// Remove the first element of every dataList until below the max.
-	for s.nBytes > s.maxBytes {
-		changed := false
-		for _, sm := range s.store {
-			for _, dl := range sm {
-				if dl.size > 0 {
-					r := dl.removeFirst()
-					if r > 0 {
-						changed = true
-						s.nBytes -= r
-					}
-				}
-			}
+func (s *MemoryEventStore) purge(extra int) {
+	for s.nBytes+extra > s.maxBytes && len(s.bySize) > 0 {
+		se := s.bySize[0]
+		if se.size == 0 {
+			break // empty
 		}
-		if !changed {
-			panic("no progress during purge")
+		dl := se.oldest[0]
+		r := dl.removeFirst()
+		if len(dl.data) == 0 {
+			heap.Pop(&se.oldest)
+		} else {
+			heap.Fix(&se.oldest, 0)
 		}
+		se.size -= r
+		heap.Fix(&s.bySize, 0)
+		s.nBytes -= r
 	}
 	s.validate()
 }
@@ -388,12 +572,29 @@ func (s *MemoryEventStore) validate() {
 	}
 	// Check that we're accounting for the size correctly.
 	n := 0
-	for _, sm := range s.store {
-		for _, dl := range sm {
+	for _, se := range s.sessions {
+		sn, nonEmpty := 0, 0
+		for _, dl := range se.streams {
 			for _, d := range dl.data {
-				n += len(d)
+				sn += len(d)
+			}
+			if len(dl.data) > 0 {
+				nonEmpty++
+				if dl.index < 0 || se.oldest[dl.index] != dl {
+					panic("non-empty stream missing from heap")
+				}
 			}
 		}
+		if sn != se.size {
+			panic("session sizes don't add up")
+		}
+		if nonEmpty != len(se.oldest) {
+			panic("stream heap has empty streams")
+		}
+		if s.bySize[se.index] != se {
+			panic("session missing from heap")
+		}
+		n += sn
 	}
 	if n != s.nBytes {
 		panic("sizes don't add up")
@@ -406,11 +607,11 @@ func (s *MemoryEventStore) debugString() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	var b strings.Builder
-	for i, sess := range slices.Sorted(maps.Keys(s.store)) {
+	for i, sess := range slices.Sorted(maps.Keys(s.sessions)) {
 		if i > 0 {
 			fmt.Fprintf(&b, "; ")
 		}
-		sm := s.store[sess]
+		sm := s.sessions[sess].streams
 		for i, sid := range slices.Sorted(maps.Keys(sm)) {
 			if i > 0 {
 				fmt.Fprintf(&b, "; ")
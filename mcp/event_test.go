@@ -6,6 +6,7 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -49,6 +50,14 @@ func TestScanEvents(t *testing.T) {
 				{Data: []byte("hello")},
 			},
 		},
+		{
+			name:  "retry",
+			input: "retry: 3000\n\nretry: soon\ndata: hello\n\n",
+			want: []Event{
+				{Retry: 3 * time.Second},
+				{Data: []byte("hello")},
+			},
+		},
 		{
 			name:    "malformed line",
 			input:   "invalid line\n\n",
@@ -97,6 +106,44 @@ func TestScanEvents(t *testing.T) {
 				if g, w := string(got[i].Data), string(tt.want[i].Data); g != w {
 					t.Errorf("event %d: data = %q, want %q", i, g, w)
 				}
+				if g, w := got[i].Retry, tt.want[i].Retry; g != w {
+					t.Errorf("event %d: retry = %v, want %v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestScanEventsLimits(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		input   string
+		scanner eventScanner
+		want    int // number of events read before the error, if any
+		wantErr bool
+	}{
+		{"within limit", "data: 12345\n\ndata: 67890\n\n", eventScanner{maxEventSize: 11}, 2, false},
+		{"long line", "data: 12345\n\ndata: 678901\n\n", eventScanner{maxEventSize: 11}, 1, true},
+		{"many lines", "data: 1\ndata: 2\n\n", eventScanner{maxEventSize: 10}, 0, true},
+		{"small buffer", "data: " + strings.Repeat("x", 100) + "\n\n", eventScanner{bufferSize: 8}, 1, false},
+		{"default", "data: " + strings.Repeat("x", DefaultMaxEventSize) + "\n\n", eventScanner{}, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				n   int
+				err error
+			)
+			for _, err = range test.scanner.scan(strings.NewReader(test.input)) {
+				if err != nil {
+					break
+				}
+				n++
+			}
+			if n != test.want {
+				t.Errorf("got %d events, want %d", n, test.want)
+			}
+			if test.wantErr != errors.Is(err, ErrEventTooLarge) {
+				t.Errorf("got error %v, want ErrEventTooLarge: %t", err, test.wantErr)
 			}
 		})
 	}
@@ -148,11 +195,13 @@ func TestMemoryEventStoreState(t *testing.T) {
 				appendEvent(s, "S1", "1", "d3")
 				appendEvent(s, "S2", "8", "d4")
 				// We are using 8 bytes (d1,d2, d3, d4).
-				// To purge 6, we remove the first of each stream, leaving only d3.
+				// To purge 6, we evict from S1, the largest session, until it
+				// is no larger than S2, and then evict the oldest data, leaving
+				// only d4.
 				s.SetMaxBytes(2)
 			},
 			// The other streams remain, because we may add to them.
-			"S1 1 first=1 d3; S1 2 first=1; S2 8 first=1",
+			"S1 1 first=2; S1 2 first=1; S2 8 first=0 d4",
 			2,
 		},
 		{
@@ -165,7 +214,7 @@ func TestMemoryEventStoreState(t *testing.T) {
 				s.SetMaxBytes(2)
 				// Up to here, identical to the "purge" case.
 				// Each of these additions will result in a purge.
-				appendEvent(s, "S1", "2", "d5") // remove d3
+				appendEvent(s, "S1", "2", "d5") // remove d4
 				appendEvent(s, "S1", "2", "d6") // remove d5
 			},
 			"S1 1 first=2; S1 2 first=2 d6; S2 8 first=1",
@@ -185,7 +234,7 @@ func TestMemoryEventStoreState(t *testing.T) {
 				appendEvent(s, "S1", "2", "d6")
 			},
 			// The other streams remain, because we may add to them.
-			"S1 1 first=1 d3; S1 2 first=1 d5 d6; S2 8 first=1",
+			"S1 1 first=2; S1 2 first=1 d5 d6; S2 8 first=0 d4",
 			6,
 		},
 	} {
@@ -203,10 +252,40 @@ func TestMemoryEventStoreState(t *testing.T) {
 	}
 }
 
+func TestMemoryEventStoreFairEviction(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryEventStore(nil)
+	s.SetMaxBytes(20)
+	s.Append(ctx, "quiet", "1", []byte("q1"))
+	s.Append(ctx, "quiet", "2", []byte("q2"))
+	// A noisy session fills the store, but only evicts its own events.
+	for i := range 100 {
+		s.Append(ctx, "noisy", "1", fmt.Appendf(nil, "n%d", i%10))
+	}
+	if got, want := s.SessionBytes("quiet"), 4; got != want {
+		t.Errorf("quiet session retains %d bytes, want %d", got, want)
+	}
+	if got, want := s.SessionBytes("noisy"), 16; got != want {
+		t.Errorf("noisy session retains %d bytes, want %d", got, want)
+	}
+	if got, want := s.Bytes(), 20; got != want {
+		t.Errorf("store retains %d bytes, want %d", got, want)
+	}
+	s.SessionClosed(ctx, "noisy")
+	if got, want := s.Bytes(), 4; got != want {
+		t.Errorf("after close, store retains %d bytes, want %d", got, want)
+	}
+	// Within a session, the oldest data is evicted first, whatever its stream.
+	s.SetMaxBytes(2)
+	if got, want := s.debugString(), "quiet 1 first=1; quiet 2 first=0 q2"; got != want {
+		t.Errorf("after shrinking, got %q, want %q", got, want)
+	}
+}
+
 func TestMemoryEventStoreAfter(t *testing.T) {
 	ctx := context.Background()
 	s := NewMemoryEventStore(nil)
-	s.SetMaxBytes(4)
+	s.SetMaxBytes(6)
 	s.Append(ctx, "S1", "1", []byte("d1"))
 	s.Append(ctx, "S1", "1", []byte("d2"))
 	s.Append(ctx, "S1", "1", []byte("d3"))
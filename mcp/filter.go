@@ -0,0 +1,104 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// A FilterPoint identifies where a [ContentFilter] is applied.
+type FilterPoint string
+
+const (
+	// FilterSamplingRequest is applied by the client to each message of an
+	// incoming sampling request, before it is passed to
+	// [ClientOptions.CreateMessageHandler].
+	FilterSamplingRequest FilterPoint = "sampling/request"
+	// FilterSamplingResult is applied by the client to the content of a
+	// sampling result, before it is returned to the server.
+	FilterSamplingResult FilterPoint = "sampling/result"
+	// FilterElicitationMessage is applied by the client to the message of an
+	// incoming elicitation request, as a [TextContent], before it is passed to
+	// [ClientOptions.ElicitationHandler].
+	FilterElicitationMessage FilterPoint = "elicitation/message"
+	// FilterToolResult is applied by the server to each content block of a
+	// tool result, before it is returned to the client.
+	FilterToolResult FilterPoint = "tools/result"
+	// FilterToolStructuredResult is applied by the server to the structured
+	// content of a tool result, as a [TextContent] holding its JSON encoding,
+	// before it is returned to the client. The filter must return a
+	// TextContent holding valid JSON.
+	FilterToolStructuredResult FilterPoint = "tools/result/structured"
+)
+
+// A ContentFilter inspects content at well-defined points in the flow of
+// messages between client and server, for example to integrate a moderation
+// service.
+//
+// FilterContent returns the content to use in place of c, which may be c
+// itself. To block the content, it returns a non-nil error; the operation
+// then fails with an error wrapping [ErrContentBlocked].
+type ContentFilter interface {
+	FilterContent(ctx context.Context, point FilterPoint, c Content) (Content, error)
+}
+
+// ContentFilterFunc is an adapter to allow the use of an ordinary function
+// as a [ContentFilter].
+type ContentFilterFunc func(ctx context.Context, point FilterPoint, c Content) (Content, error)
+
+// FilterContent calls f(ctx, point, c).
+func (f ContentFilterFunc) FilterContent(ctx context.Context, point FilterPoint, c Content) (Content, error) {
+	return f(ctx, point, c)
+}
+
+// filterContent applies f to c, wrapping any error.
+func filterContent(ctx context.Context, f ContentFilter, point FilterPoint, c Content) (Content, error) {
+	c2, err := f.FilterContent(ctx, point, c)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrContentBlocked, point, err)
+	}
+	return c2, nil
+}
+
+// filterStructured applies f to the JSON encoding of the structured content
+// sc, returning sc itself if the filter left it unchanged.
+func filterStructured(ctx context.Context, f ContentFilter, point FilterPoint, sc any) (any, error) {
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshaling structured content: %v", point, err)
+	}
+	c, err := filterContent(ctx, f, point, &TextContent{Text: string(data)})
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := c.(*TextContent)
+	if !ok {
+		return nil, fmt.Errorf("%s filter returned %T, want *TextContent", point, c)
+	}
+	if tc.Text == string(data) {
+		return sc, nil
+	}
+	if !json.Valid([]byte(tc.Text)) {
+		return nil, fmt.Errorf("%s filter returned invalid JSON", point)
+	}
+	return json.RawMessage(tc.Text), nil
+}
+
+// filterContents applies f to each element of cs, returning a new slice.
+func filterContents(ctx context.Context, f ContentFilter, point FilterPoint, cs []Content) ([]Content, error) {
+	if cs == nil {
+		return nil, nil
+	}
+	out := make([]Content, len(cs))
+	for i, c := range cs {
+		var err error
+		if out[i], err = filterContent(ctx, f, point, c); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// upperFilter upper-cases text, blocks text containing "forbidden", and
+// records the points at which it was called.
+type upperFilter struct {
+	mu     sync.Mutex
+	points []FilterPoint
+}
+
+func (f *upperFilter) FilterContent(_ context.Context, point FilterPoint, c Content) (Content, error) {
+	f.mu.Lock()
+	f.points = append(f.points, point)
+	f.mu.Unlock()
+	tc, ok := c.(*TextContent)
+	if !ok {
+		return c, nil
+	}
+	if strings.Contains(tc.Text, "forbidden") {
+		return nil, errors.New("flagged")
+	}
+	return &TextContent{Text: strings.ToUpper(tc.Text)}, nil
+}
+
+func TestContentFilter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("server", func(t *testing.T) {
+		f := &upperFilter{}
+		server := NewServer(testImpl, &ServerOptions{ContentFilter: f})
+		cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+			AddTool(s, greetTool(), sayHi)
+		})
+		defer cleanup()
+
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := res.Content[0].(*TextContent).Text, "HI USER"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "forbidden"}}); err == nil {
+			t.Error("blocked tool result: got nil error")
+		}
+	})
+
+	t.Run("structured", func(t *testing.T) {
+		f := &upperFilter{}
+		server := NewServer(testImpl, &ServerOptions{ContentFilter: f})
+		server.AddTool(&Tool{Name: "echo", InputSchema: &jsonschema.Schema{Type: "object"}}, func(_ context.Context, req *CallToolRequest) (*CallToolResult, error) {
+			var args struct{ Msg string }
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, err
+			}
+			return &CallToolResult{StructuredContent: map[string]any{"msg": args.Msg}}, nil
+		})
+		cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+		defer cleanup()
+
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: "echo", Arguments: map[string]any{"Msg": "hi"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]any{"MSG": "HI"}, res.StructuredContent); diff != "" {
+			t.Errorf("structured content mismatch (-want +got):\n%s", diff)
+		}
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "echo", Arguments: map[string]any{"Msg": "forbidden"}}); err == nil {
+			t.Error("blocked structured content: got nil error")
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		f := &upperFilter{}
+		var gotSampling, gotElicit string
+		client := NewClient(testImpl, &ClientOptions{
+			ContentFilter: f,
+			CreateMessageHandler: func(_ context.Context, req *CreateMessageRequest) (*CreateMessageResult, error) {
+				gotSampling = req.Params.Messages[0].Content.(*TextContent).Text
+				return &CreateMessageResult{Model: "m", Role: "assistant", Content: &TextContent{Text: "answer"}}, nil
+			},
+			ElicitationHandler: func(_ context.Context, req *ElicitRequest) (*ElicitResult, error) {
+				gotElicit = req.Params.Message
+				return &ElicitResult{Action: "decline"}, nil
+			},
+		})
+		_, ss, cleanup := basicClientServerConnection(t, client, nil, nil)
+		defer cleanup()
+
+		res, err := ss.CreateMessage(ctx, &CreateMessageParams{Messages: []*SamplingMessage{{Role: "user", Content: &TextContent{Text: "question"}}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotSampling != "QUESTION" {
+			t.Errorf("sampling request: got %q, want %q", gotSampling, "QUESTION")
+		}
+		if got := res.Content.(*TextContent).Text; got != "ANSWER" {
+			t.Errorf("sampling result: got %q, want %q", got, "ANSWER")
+		}
+		if _, err := ss.Elicit(ctx, &ElicitParams{Message: "name?"}); err != nil {
+			t.Fatal(err)
+		}
+		if gotElicit != "NAME?" {
+			t.Errorf("elicitation message: got %q, want %q", gotElicit, "NAME?")
+		}
+		if _, err := ss.Elicit(ctx, &ElicitParams{Message: "forbidden"}); err == nil {
+			t.Error("blocked elicitation: got nil error")
+		}
+
+		want := []FilterPoint{FilterSamplingRequest, FilterSamplingResult, FilterElicitationMessage, FilterElicitationMessage}
+		if diff := cmp.Diff(want, f.points); diff != "" {
+			t.Errorf("filter points mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
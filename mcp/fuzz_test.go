@@ -0,0 +1,134 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/orkhanm/go-sdk/wiretest"
+)
+
+// This file holds fuzz tests of the parsers of the SDK. Run one with, for
+// example:
+//
+//	go test -fuzz=FuzzReadBatch ./mcp
+//
+// Without -fuzz, only the seed inputs are run.
+
+// addMessageSeeds adds seed inputs for message decoding: the inputs of the
+// message tests, and every message of the wire corpus, alone and as a batch.
+func addMessageSeeds(f *testing.F) {
+	for _, s := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"test","params":{}}`,
+		"{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"test\",\"params\":{}}\n",
+		`{"jsonrpc":"2.0","id":1,"method":"test","params":{}},`,
+		`[{"jsonrpc":"2.0","id":1,"method":"test1"},{"jsonrpc":"2.0","id":2,"method":"test2"}]`,
+		`[{"jsonrpc":"2.0","id":1,"method":"test1"},{"jsonrpc":"2.0","id":1,"method":"test2"}]`,
+		`[]`,
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"bad"}}`,
+	} {
+		f.Add([]byte(s))
+	}
+	for _, v := range wiretest.Versions() {
+		for _, m := range wiretest.Methods(v) {
+			for _, kind := range []wiretest.Kind{wiretest.Request, wiretest.Result} {
+				if msg, err := wiretest.Message(v, m, kind); err == nil {
+					f.Add(msg)
+					f.Add([]byte("[" + string(msg) + "]"))
+				}
+			}
+		}
+	}
+}
+
+func FuzzReadBatch(f *testing.F) {
+	addMessageSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msgs, isBatch, err := readBatch(data)
+		if err != nil {
+			return
+		}
+		if len(msgs) == 0 || (!isBatch && len(msgs) != 1) {
+			t.Fatalf("readBatch(%q) = %d messages, batch: %t", data, len(msgs), isBatch)
+		}
+		for _, msg := range msgs {
+			if msg == nil {
+				t.Fatalf("readBatch(%q) returned a nil message", data)
+			}
+		}
+	})
+}
+
+func FuzzIOConnRead(f *testing.F) {
+	addMessageSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, version := range []string{protocolVersion20241105, protocolVersion20250618} {
+			conn := newIOConn(rwc{rc: io.NopCloser(strings.NewReader(string(data)))}, 0, 0)
+			conn.sessionUpdated(ServerSessionState{InitializeParams: &InitializeParams{ProtocolVersion: version}})
+			for range 100 {
+				msg, err := conn.Read(context.Background())
+				if err != nil {
+					break
+				}
+				if msg == nil {
+					t.Fatalf("Read of %q returned a nil message", data)
+				}
+			}
+			conn.Close()
+		}
+	})
+}
+
+func FuzzParseEventID(f *testing.F) {
+	for _, s := range []string{"0_0", "1_1", "_1", "1234_5678", "", "_", "1_", "1_a", "1_-1", "a_b_1"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, eventID string) {
+		sid, idx, ok := parseEventID(eventID)
+		if !ok {
+			return
+		}
+		if idx < 0 {
+			t.Fatalf("parseEventID(%q) returned negative index %d", eventID, idx)
+		}
+		sid2, idx2, ok := parseEventID(formatEventID(sid, idx))
+		if !ok || sid2 != sid || idx2 != idx {
+			t.Fatalf("parseEventID(%q) = %q, %d, which does not round trip", eventID, sid, idx)
+		}
+	})
+}
+
+func FuzzScanEvents(f *testing.F) {
+	for _, s := range []string{
+		"event: message\nid: 1\ndata: hello\n\n",
+		"data: line 1\ndata: line 2\n\n",
+		"data: first\n\nevent: second\ndata: second\n\n",
+		"data: hello",
+		"invalid line\n\n",
+		"data: 12345\n\ndata: 678901\n\n",
+		": comment\nretry: 1000\ndata:x\r\n\r\n",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		const maxEventSize = 64
+		total := 0
+		for e, err := range (eventScanner{maxEventSize: maxEventSize, bufferSize: 16}).scan(strings.NewReader(input)) {
+			if err != nil {
+				return
+			}
+			if len(e.Data) > maxEventSize {
+				t.Fatalf("event data of %d bytes exceeds the limit of %d", len(e.Data), maxEventSize)
+			}
+			total += len(e.Name) + len(e.ID) + len(e.Data)
+			if total > len(input) {
+				t.Fatalf("events of %q hold more bytes than the input", input)
+			}
+		}
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// HedgingOptions configures hedging of slow requests by a client. See
+// [ClientOptions.Hedging].
+//
+// A hedged request that has not received a response after Delay is sent a
+// second time, and the first response to either attempt is used. The other
+// attempt is then cancelled. Hedging trades extra load on the server for
+// lower tail latency, and is only safe for requests without side effects.
+type HedgingOptions struct {
+	// Delay is how long to wait for a response before sending the second
+	// attempt. If it is not positive, requests are not hedged.
+	Delay time.Duration
+	// Methods lists the methods to hedge. If empty, the list methods
+	// ("tools/list", "prompts/list", "resources/list" and
+	// "resources/templates/list") and "resources/read" are hedged.
+	//
+	// Only methods without side effects should be listed.
+	Methods []string
+}
+
+// defaultHedgedMethods are the methods hedged if [HedgingOptions.Methods] is
+// empty.
+var defaultHedgedMethods = []string{
+	methodListTools,
+	methodListPrompts,
+	methodListResources,
+	methodListResourceTemplates,
+	methodReadResource,
+}
+
+// errHedgeSuperseded is the cause of cancellation of the losing attempt of a
+// hedged request.
+var errHedgeSuperseded = errors.New("hedged request superseded")
+
+// hedged reports whether the client hedges requests for method.
+func (cs *ClientSession) hedged(method string) bool {
+	h := cs.client.opts.Hedging
+	if h == nil || h.Delay <= 0 {
+		return false
+	}
+	methods := h.Methods
+	if len(methods) == 0 {
+		methods = defaultHedgedMethods
+	}
+	return slices.Contains(methods, method)
+}
+
+// hedgedCall calls method, sending a second attempt if the first has not
+// completed after the hedging delay. It returns the result of whichever
+// attempt succeeds first, and cancels the other. If an attempt fails while
+// the other is in progress, it waits for the other; if both fail, it returns
+// the error of the first to fail.
+//
+// Each attempt sends its own copy of params. If params has a progress token,
+// the second attempt uses a new token, and its progress notifications are
+// reported with the original one.
+func (cs *ClientSession) hedgedCall(ctx context.Context, method string, params Params, newResult func() Result) (Result, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(errHedgeSuperseded)
+
+	type outcome struct {
+		res Result
+		err error
+	}
+	outcomes := make(chan outcome, 2)
+	attempt := func(params Params, done func()) {
+		defer done()
+		res := newResult()
+		err := call(ctx, cs.conn, method, params, res)
+		outcomes <- outcome{res, err}
+	}
+	go attempt(cs.hedgeParams(params, false))
+	pending := 1
+
+	timer := time.NewTimer(cs.client.opts.Hedging.Delay)
+	defer timer.Stop()
+	var firstErr error
+	for {
+		select {
+		case o := <-outcomes:
+			pending--
+			if o.err == nil {
+				return o.res, nil
+			}
+			if firstErr == nil {
+				firstErr = o.err
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+		case <-timer.C:
+			go attempt(cs.hedgeParams(params, true))
+			pending++
+		}
+	}
+}
+
+// hedgeParams returns a copy of params for an attempt of a hedged request,
+// and a function to call when the attempt completes. The params of the
+// second attempt have their own progress token, if params has one.
+func (cs *ClientSession) hedgeParams(params Params, second bool) (Params, func()) {
+	rp, ok := params.(RequestParams)
+	if !ok || isNilParams(params) {
+		return params, func() {}
+	}
+	p := copyParams(rp)
+	if token := rp.GetProgressToken(); second && token != nil {
+		alias, done := cs.progress.alias(token)
+		p.SetProgressToken(alias)
+		return p, done
+	}
+	return p, func() {}
+}
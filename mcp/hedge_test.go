@@ -0,0 +1,174 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedging(t *testing.T) {
+	var (
+		lists, calls atomic.Int32
+		cancelled    = make(chan struct{})
+	)
+	server := NewServer(testImpl, nil)
+	// The first tools/list request stalls until it is cancelled; later ones
+	// succeed. Tool calls are slow.
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			switch method {
+			case methodListTools:
+				if lists.Add(1) == 1 {
+					<-ctx.Done()
+					close(cancelled)
+					return nil, ctx.Err()
+				}
+			case methodCallTool:
+				calls.Add(1)
+				time.Sleep(50 * time.Millisecond)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	client := NewClient(testImpl, &ClientOptions{Hedging: &HedgingOptions{Delay: 10 * time.Millisecond}})
+	cs, _, cleanup := basicClientServerConnection(t, client, server, func(s *Server) {
+		AddTool(s, &Tool{Name: "greet"}, sayHi)
+	})
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tools) != 1 {
+		t.Errorf("got %d tools, want 1", len(res.Tools))
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("server received %d tools/list requests, want 2", got)
+	}
+	select {
+	case <-cancelled:
+	case <-ctx.Done():
+		t.Fatal("stalled attempt was not cancelled")
+	}
+
+	// Tool calls are not hedged by default.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server received %d tools/call requests, want 1", got)
+	}
+}
+
+func TestHedgedMethods(t *testing.T) {
+	for _, test := range []struct {
+		opts   *HedgingOptions
+		method string
+		want   bool
+	}{
+		{nil, methodListTools, false},
+		{&HedgingOptions{}, methodListTools, false},
+		{&HedgingOptions{Delay: time.Second}, methodListTools, true},
+		{&HedgingOptions{Delay: time.Second}, methodReadResource, true},
+		{&HedgingOptions{Delay: time.Second}, methodCallTool, false},
+		{&HedgingOptions{Delay: time.Second, Methods: []string{methodGetPrompt}}, methodGetPrompt, true},
+		{&HedgingOptions{Delay: time.Second, Methods: []string{methodGetPrompt}}, methodListTools, false},
+	} {
+		cs := &ClientSession{client: NewClient(testImpl, &ClientOptions{Hedging: test.opts})}
+		if got := cs.hedged(test.method); got != test.want {
+			t.Errorf("hedged(%q) with %+v = %t, want %t", test.method, test.opts, got, test.want)
+		}
+	}
+}
+
+func TestHedgingFirstFailure(t *testing.T) {
+	var lists atomic.Int32
+	server := NewServer(testImpl, nil)
+	// The first tools/list request fails after the second is sent, which
+	// succeeds later.
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodListTools {
+				if lists.Add(1) == 1 {
+					time.Sleep(30 * time.Millisecond)
+					return nil, errors.New("failed")
+				}
+				time.Sleep(60 * time.Millisecond)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	client := NewClient(testImpl, &ClientOptions{Hedging: &HedgingOptions{Delay: 10 * time.Millisecond}})
+	cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+	defer cleanup()
+	if _, err := cs.ListTools(context.Background(), nil); err != nil {
+		t.Fatalf("ListTools: %v, want the result of the second attempt", err)
+	}
+}
+
+func TestHedgingProgress(t *testing.T) {
+	var (
+		lists     atomic.Int32
+		tokens    = make(chan any, 2)
+		delivered = make(chan struct{})
+		cancelled = make(chan struct{})
+	)
+	server := NewServer(testImpl, nil)
+	// The first tools/list request stalls; the second reports progress.
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method != methodListTools {
+				return next(ctx, method, req)
+			}
+			token := req.GetParams().(*ListToolsParams).GetProgressToken()
+			tokens <- token
+			if lists.Add(1) == 1 {
+				<-ctx.Done()
+				close(cancelled)
+				return nil, ctx.Err()
+			}
+			ss := req.GetSession().(*ServerSession)
+			if err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: token, Progress: 1}); err != nil {
+				return nil, err
+			}
+			select {
+			case <-delivered:
+			case <-time.After(5 * time.Second):
+			}
+			return next(ctx, method, req)
+		}
+	})
+	client := NewClient(testImpl, &ClientOptions{Hedging: &HedgingOptions{Delay: 10 * time.Millisecond}})
+	cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+	defer cleanup()
+
+	var got []any
+	ctx := WithProgressHandler(context.Background(), func(_ context.Context, req *ProgressNotificationClientRequest) {
+		got = append(got, req.Params.ProgressToken)
+		close(delivered)
+	})
+	params := &ListToolsParams{}
+	params.SetProgressToken("tok")
+	if _, err := cs.ListTools(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if t1, t2 := <-tokens, <-tokens; t1 != "tok" || t2 == nil || t2 == t1 {
+		t.Errorf("attempts had progress tokens %v and %v, want tok and another", t1, t2)
+	}
+	if len(got) != 1 || got[0] != "tok" {
+		t.Errorf("progress reported with tokens %v, want [tok]", got)
+	}
+	if params.GetProgressToken() != "tok" {
+		t.Errorf("caller's params were modified: %+v", params.Meta)
+	}
+	<-cancelled
+}
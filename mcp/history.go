@@ -0,0 +1,82 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// A ToolCallRecord describes a tool call handled by a [ServerSession].
+// See [ServerOptions.ToolCallHistorySize].
+type ToolCallRecord struct {
+	Name     string        // the name of the tool
+	Start    time.Time     // when the call started
+	Duration time.Duration // how long the call took
+	// IsError reports whether the call failed, either with a protocol error,
+	// for example because it was not authorized, or with a tool error.
+	IsError bool
+	// ArgumentsHash is the hex-encoded SHA-256 hash of the call's arguments,
+	// in compact JSON form. It identifies calls with the same arguments
+	// without recording the arguments themselves.
+	ArgumentsHash string
+}
+
+// A toolCallHistory holds the most recent tool calls of a session.
+type toolCallHistory struct {
+	mu      sync.Mutex
+	records []ToolCallRecord // a ring buffer
+	next    int              // the index of the next record to write
+}
+
+// add records r, keeping at most size records.
+func (h *toolCallHistory) add(size int, r ToolCallRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.records) < size {
+		h.records = append(h.records, r)
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+}
+
+// ToolCallHistory returns the most recent tool calls of the session, oldest
+// first. Calls are recorded when they complete, and only if
+// [ServerOptions.ToolCallHistorySize] is positive.
+func (ss *ServerSession) ToolCallHistory() []ToolCallRecord {
+	h := &ss.toolCalls
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append(append([]ToolCallRecord(nil), h.records[h.next:]...), h.records[:h.next]...)
+}
+
+// recordToolCall records a tool call that started at start in the server's
+// statistics, and in the session's history if the server keeps one.
+func (ss *ServerSession) recordToolCall(params *CallToolParamsRaw, start time.Time, res *CallToolResult, err error) {
+	failed := err != nil || (res != nil && res.IsError)
+	ss.server.stats.recordTool(params.Name, start, failed)
+	size := ss.server.opts.ToolCallHistorySize
+	if size <= 0 {
+		return
+	}
+	var buf bytes.Buffer
+	args := []byte(params.Arguments)
+	if json.Compact(&buf, args) == nil {
+		args = buf.Bytes()
+	}
+	sum := sha256.Sum256(args)
+	ss.toolCalls.add(size, ToolCallRecord{
+		Name:          params.Name,
+		Start:         start,
+		Duration:      time.Since(start),
+		IsError:       failed,
+		ArgumentsHash: hex.EncodeToString(sum[:]),
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestToolCallHistory(t *testing.T) {
+	server := NewServer(testImpl, &ServerOptions{ToolCallHistorySize: 3})
+	type args struct {
+		N    int  `json:"n"`
+		Fail bool `json:"fail,omitempty"`
+	}
+	AddTool(server, &Tool{Name: "a"}, func(_ context.Context, _ *CallToolRequest, in args) (*CallToolResult, any, error) {
+		if in.Fail {
+			return nil, nil, errors.New("failed")
+		}
+		return nil, nil, nil
+	})
+	AddTool(server, &Tool{Name: "b"}, func(context.Context, *CallToolRequest, args) (*CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+	cs, ss, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, p := range []*CallToolParams{
+		{Name: "a", Arguments: args{N: 1}},
+		{Name: "b", Arguments: args{N: 2}},
+		{Name: "a", Arguments: args{N: 3, Fail: true}},
+		{Name: "b", Arguments: args{N: 2}},
+	} {
+		if _, err := cs.CallTool(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := ss.ToolCallHistory()
+	type summary struct {
+		Name    string
+		IsError bool
+	}
+	var got []summary
+	for _, r := range history {
+		got = append(got, summary{r.Name, r.IsError})
+		if r.Start.IsZero() || r.Duration < 0 || len(r.ArgumentsHash) != 64 {
+			t.Errorf("bad record %+v", r)
+		}
+	}
+	// The first call was evicted.
+	want := []summary{{"b", false}, {"a", true}, {"b", false}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("history mismatch (-want +got):\n%s", diff)
+	}
+	if history[0].ArgumentsHash != history[2].ArgumentsHash {
+		t.Error("calls with the same arguments have different hashes")
+	}
+	if history[0].ArgumentsHash == history[1].ArgumentsHash {
+		t.Error("calls with different arguments have the same hash")
+	}
+}
@@ -312,7 +312,7 @@ func TestEndToEnd(t *testing.T) {
 		} {
 			rres, err := cs.ReadResource(ctx, &ReadResourceParams{URI: tt.uri})
 			if err != nil {
-				if code := errorCode(err); code == codeResourceNotFound {
+				if code := errorCode(err); code == CodeResourceNotFound {
 					if tt.mimeType != "" {
 						t.Errorf("%s: not found but expected it to be", tt.uri)
 					}
@@ -735,6 +735,56 @@ func TestCancellation(t *testing.T) {
 	}
 }
 
+func TestCancellationReason(t *testing.T) {
+	var (
+		start   = make(chan struct{})
+		reasons = make(chan string, 2)
+	)
+	slowTool := func(ctx context.Context, req *CallToolRequest, args any) (*CallToolResult, any, error) {
+		start <- struct{}{}
+		<-ctx.Done()
+		reason, ok := CancellationReason(ctx)
+		if !ok {
+			reason = "<none>"
+		}
+		reasons <- reason
+		return nil, nil, nil
+	}
+	handled := make(chan *CancelledParams, 1)
+	server := NewServer(testImpl, &ServerOptions{
+		CancelledHandler: func(_ context.Context, req *CancelledRequest) {
+			handled <- req.Params
+		},
+	})
+	AddTool(server, &Tool{Name: "slow", InputSchema: &jsonschema.Schema{Type: "object"}}, slowTool)
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go cs.CallTool(ctx, &CallToolParams{Name: "slow"})
+	<-start
+	cancel(errors.New("user pressed stop"))
+	// Both the tool and the CancelledHandler see the reason.
+	for range 2 {
+		var got string
+		select {
+		case r := <-reasons:
+			got = r
+		case p := <-handled:
+			got = p.Reason
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for cancellation")
+		}
+		if got != "user pressed stop" {
+			t.Errorf("got reason %q, want %q", got, "user pressed stop")
+		}
+	}
+
+	if _, ok := CancellationReason(context.Background()); ok {
+		t.Error("CancellationReason of uncancelled context: got ok")
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	ctx := context.Background()
 	ct, st := NewInMemoryTransports()
@@ -985,8 +1035,8 @@ func TestElicitationUnsupportedMethod(t *testing.T) {
 	if err == nil {
 		t.Error("expected error when ElicitationHandler is not provided, got nil")
 	}
-	if code := errorCode(err); code != codeUnsupportedMethod {
-		t.Errorf("got error code %d, want %d (CodeUnsupportedMethod)", code, codeUnsupportedMethod)
+	if code := errorCode(err); code != CodeUnsupportedMethod {
+		t.Errorf("got error code %d, want %d (CodeUnsupportedMethod)", code, CodeUnsupportedMethod)
 	}
 	if !strings.Contains(err.Error(), "does not support elicitation") {
 		t.Errorf("error should mention unsupported elicitation, got: %v", err)
@@ -1333,8 +1383,8 @@ func TestElicitationSchemaValidation(t *testing.T) {
 				t.Errorf("expected error for invalid schema %q, got nil", tc.name)
 				return
 			}
-			if code := errorCode(err); code != codeInvalidParams {
-				t.Errorf("got error code %d, want %d (CodeInvalidParams)", code, codeInvalidParams)
+			if code := errorCode(err); code != CodeInvalidParams {
+				t.Errorf("got error code %d, want %d (CodeInvalidParams)", code, CodeInvalidParams)
 			}
 			if !strings.Contains(err.Error(), tc.expectedError) {
 				t.Errorf("error message %q does not contain expected text %q", err.Error(), tc.expectedError)
@@ -1381,6 +1431,72 @@ func TestElicitationProgressToken(t *testing.T) {
 	}
 }
 
+func TestElicitationDefaults(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"name":      {Type: "string"},
+			"subscribe": {Type: "boolean", Default: json.RawMessage("true")},
+			"plan":      {Type: "string", Enum: []any{"free", "pro"}, Default: json.RawMessage(`"free"`)},
+		},
+		Required: []string{"name"},
+	}
+	withDefaults := map[string]any{"name": "x", "subscribe": false, "plan": "free"}
+	for _, applyOnClient := range []bool{false, true} {
+		ctx := context.Background()
+		ct, st := NewInMemoryTransports()
+		s := NewServer(testImpl, nil)
+		ss, err := s.Connect(ctx, st, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ss.Close()
+
+		handlerContent := map[string]any{"name": "x", "subscribe": false}
+		c := NewClient(testImpl, &ClientOptions{
+			ApplyElicitationDefaults: applyOnClient,
+			ElicitationHandler: func(context.Context, *ElicitRequest) (*ElicitResult, error) {
+				return &ElicitResult{Action: "accept", Content: handlerContent}, nil
+			},
+		})
+		// Record the content that the client sends.
+		var sent map[string]any
+		c.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+			return func(ctx context.Context, method string, req Request) (Result, error) {
+				res, err := next(ctx, method, req)
+				if res, ok := res.(*ElicitResult); ok {
+					sent = res.Content
+				}
+				return res, err
+			}
+		})
+		cs, err := c.Connect(ctx, ct, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+
+		res, err := ss.Elicit(ctx, &ElicitParams{Message: "subscribe?", RequestedSchema: schema})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The server applies defaults regardless of the client.
+		if diff := cmp.Diff(withDefaults, res.Content); diff != "" {
+			t.Errorf("applyOnClient=%t: result mismatch (-want +got):\n%s", applyOnClient, diff)
+		}
+		wantSent := handlerContent
+		if applyOnClient {
+			wantSent = withDefaults
+		}
+		if diff := cmp.Diff(wantSent, sent); diff != "" {
+			t.Errorf("applyOnClient=%t: sent content mismatch (-want +got):\n%s", applyOnClient, diff)
+		}
+		if _, ok := handlerContent["plan"]; ok {
+			t.Errorf("applyOnClient=%t: handler content was modified", applyOnClient)
+		}
+	}
+}
+
 func TestElicitationCapabilityDeclaration(t *testing.T) {
 	ctx := context.Background()
 
@@ -1453,8 +1569,8 @@ func TestElicitationCapabilityDeclaration(t *testing.T) {
 		if err == nil {
 			t.Error("expected UnsupportedMethod error when no capability declared")
 		}
-		if code := errorCode(err); code != codeUnsupportedMethod {
-			t.Errorf("got error code %d, want %d (CodeUnsupportedMethod)", code, codeUnsupportedMethod)
+		if code := errorCode(err); code != CodeUnsupportedMethod {
+			t.Errorf("got error code %d, want %d (CodeUnsupportedMethod)", code, CodeUnsupportedMethod)
 		}
 	})
 }
@@ -1507,6 +1623,128 @@ func TestKeepAliveFailure(t *testing.T) {
 	t.Errorf("expected connection to be closed by keepalive, but it wasn't. Last error: %v", err)
 }
 
+func TestKeepAliveOptions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The server fails pings while failPings is positive, decrementing it.
+	var failPings atomic.Int32
+	s := NewServer(testImpl, nil)
+	s.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodPing && failPings.Add(-1) >= 0 {
+				return nil, errors.New("unavailable")
+			}
+			return next(ctx, method, req)
+		}
+	})
+	failures := make(chan int, 10)
+	c := NewClient(testImpl, &ClientOptions{
+		KeepAlive: 20 * time.Millisecond,
+		KeepAliveOptions: &KeepAliveOptions{
+			MaxFailures: 3,
+			Jitter:      0.5,
+			OnFailure: func(_ Session, err error, n int) {
+				failures <- n
+			},
+		},
+	})
+	cs, _, cleanup := basicClientServerConnection(t, c, s, nil)
+	defer cleanup()
+
+	// Two failures in a row don't close the session, and a successful ping
+	// resets the count.
+	failPings.Store(2)
+	for _, want := range []int{1, 2} {
+		if got := <-failures; got != want {
+			t.Fatalf("OnFailure: got %d failures, want %d", got, want)
+		}
+	}
+	for failPings.Load() >= 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatalf("session closed after two failures: %v", err)
+	}
+
+	// Three failures close it.
+	failPings.Store(1000)
+	for _, want := range []int{1, 2, 3} {
+		if got := <-failures; got != want {
+			t.Fatalf("OnFailure: got %d failures, want %d", got, want)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		cs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("session not closed after three failures")
+	}
+}
+
+func TestKeepaliveWait(t *testing.T) {
+	const interval = time.Second
+	for _, jitter := range []float64{0, 0.2, 1, 100} {
+		lo, hi := interval-interval/2, interval+interval/2
+		if jitter < maxKeepaliveJitter {
+			d := time.Duration(jitter * float64(interval))
+			lo, hi = interval-d, interval+d
+		}
+		for range 1000 {
+			if got := keepaliveWait(interval, jitter); got < lo || got > hi {
+				t.Fatalf("keepaliveWait(%v, %v) = %v, want between %v and %v", interval, jitter, got, lo, hi)
+			}
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	// Both peers block on requests until they are cancelled.
+	s := NewServer(testImpl, &ServerOptions{RequestTimeout: timeout})
+	AddTool(s, &Tool{Name: "block"}, func(ctx context.Context, req *CallToolRequest, args map[string]any) (*CallToolResult, any, error) {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	})
+	AddTool(s, &Tool{Name: "sample"}, func(ctx context.Context, req *CallToolRequest, args map[string]any) (*CallToolResult, any, error) {
+		// The server bounds its request, although the context has no deadline.
+		_, err := req.Session.CreateMessage(context.Background(), &CreateMessageParams{})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, fmt.Errorf("CreateMessage: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		return &CallToolResult{}, nil, nil
+	})
+	c := NewClient(testImpl, &ClientOptions{
+		RequestTimeout: timeout,
+		CreateMessageHandler: func(ctx context.Context, _ *CreateMessageRequest) (*CreateMessageResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	cs, _, cleanup := basicClientServerConnection(t, c, s, nil)
+	defer cleanup()
+
+	// The client bounds its request, although the context has no deadline.
+	_, err := cs.CallTool(context.Background(), &CallToolParams{Name: "block"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CallTool(block): got %v, want %v", err, context.DeadlineExceeded)
+	}
+	// A deadline of the context takes precedence.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*timeout)
+	defer cancel()
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "sample"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Errorf("CallTool(sample): %v", res.Content[0].(*TextContent).Text)
+	}
+}
+
 func TestAddTool_DuplicateNoPanicAndNoDuplicate(t *testing.T) {
 	// Adding the same tool pointer twice should not panic and should not
 	// produce duplicates in the server's tool list.
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// originAllowed reports whether the Origin header of req, if any, is allowed
+// by validate, or if validate is nil, by the list of allowed origins. If
+// neither is set, any origin is allowed.
+//
+// Requests without an Origin header are always allowed: browsers send the
+// header with cross-origin requests, which are the concern of DNS rebinding
+// attacks, and other clients usually don't send it.
+func originAllowed(req *http.Request, allowed []string, validate func(origin string) bool) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if validate != nil {
+		return validate(origin)
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalOrigin reports whether origin is that of a page served from the
+// local host: localhost, or a loopback IP address, with any scheme and port.
+// It can be used as the ValidateOrigin option of [StreamableHTTPOptions],
+// [SSEOptions] or [WebSocketOptions] to protect a locally hosted server from
+// DNS rebinding and cross-site WebSocket attacks.
+func LocalOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
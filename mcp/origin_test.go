@@ -0,0 +1,74 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalOrigin(t *testing.T) {
+	for origin, want := range map[string]bool{
+		"http://localhost:3000":  true,
+		"https://LOCALHOST":      true,
+		"http://127.0.0.1:8080":  true,
+		"http://[::1]:8080":      true,
+		"http://127.1.2.3":       true,
+		"http://example.com":     false,
+		"http://localhost.evil":  false,
+		"http://192.168.0.1:80":  false,
+		"null":                   false,
+		"":                       false,
+		"localhost:3000":         false,
+		"http://10.0.0.1/x?q=1":  false,
+		"file:///etc/passwd":     false,
+		"http://[::ffff:7f00:1]": true,
+	} {
+		if got := LocalOrigin(origin); got != want {
+			t.Errorf("LocalOrigin(%q) = %t, want %t", origin, got, want)
+		}
+	}
+}
+
+func TestOriginValidation(t *testing.T) {
+	getServer := func(*http.Request) *Server { return NewServer(testImpl, nil) }
+	handlers := map[string]http.Handler{
+		"streamable allowed": NewStreamableHTTPHandler(getServer, &StreamableHTTPOptions{AllowedOrigins: []string{"http://localhost:3000"}}),
+		"streamable local":   NewStreamableHTTPHandler(getServer, &StreamableHTTPOptions{ValidateOrigin: LocalOrigin}),
+		"sse allowed":        NewSSEHandler(getServer, &SSEOptions{AllowedOrigins: []string{"http://localhost:3000"}}),
+		"sse local":          NewSSEHandler(getServer, &SSEOptions{ValidateOrigin: LocalOrigin}),
+		"websocket allowed":  NewWebSocketHandler(getServer, &WebSocketOptions{AllowedOrigins: []string{"http://localhost:3000"}}),
+		"websocket local":    NewWebSocketHandler(getServer, &WebSocketOptions{ValidateOrigin: LocalOrigin}),
+	}
+	for name, h := range handlers {
+		t.Run(name, func(t *testing.T) {
+			for origin, wantForbidden := range map[string]bool{
+				"":                        false,
+				"http://localhost:3000":   false,
+				"http://attacker.example": true,
+			} {
+				req := httptest.NewRequest(http.MethodDelete, "/", nil)
+				if origin != "" {
+					req.Header.Set("Origin", origin)
+				}
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if got := rec.Code == http.StatusForbidden; got != wantForbidden {
+					t.Errorf("origin %q: got status %d, want forbidden %t", origin, rec.Code, wantForbidden)
+				}
+			}
+		})
+	}
+	// Without options, any origin is allowed.
+	h := NewStreamableHTTPHandler(getServer, nil)
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	req.Header.Set("Origin", "http://attacker.example")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code == http.StatusForbidden {
+		t.Errorf("without options: got status %d", rec.Code)
+	}
+}
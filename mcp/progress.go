@@ -0,0 +1,280 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProgressInterval is the default minimum interval between progress
+// notifications sent by a [ProgressReporter].
+const DefaultProgressInterval = 200 * time.Millisecond
+
+// ProgressOptions configures a [ProgressReporter].
+type ProgressOptions struct {
+	// Total is the total amount of progress required, if known.
+	// Zero means unknown.
+	Total float64
+	// MinInterval is the minimum interval between notifications.
+	// If zero, [DefaultProgressInterval] is used. If negative, notifications
+	// are not rate limited.
+	MinInterval time.Duration
+}
+
+// A ProgressReporter sends progress notifications for a request.
+//
+// A ProgressReporter rate-limits its notifications: a report that arrives
+// too soon after the previous notification is held back, and sent with the
+// next notification, by [ProgressReporter.Flush], or when progress reaches
+// the total. If the client did not ask for progress by supplying a progress
+// token, reports are discarded.
+//
+// A ProgressReporter is safe for concurrent use.
+type ProgressReporter struct {
+	session  *ServerSession
+	token    any
+	total    float64
+	interval time.Duration
+	now      func() time.Time // for testing
+
+	mu       sync.Mutex
+	reported bool // progress has been reported
+	progress float64
+	message  string
+	lastSent time.Time // when the last notification was sent
+	pending  bool      // progress has been reported but not sent
+}
+
+// NewProgressReporter returns a ProgressReporter for the given request, such
+// as a [CallToolRequest].
+func NewProgressReporter[P RequestParams](req *ServerRequest[P], opts *ProgressOptions) *ProgressReporter {
+	r := &ProgressReporter{
+		session:  req.Session,
+		token:    req.Params.GetProgressToken(),
+		interval: DefaultProgressInterval,
+		now:      time.Now,
+	}
+	if opts != nil {
+		r.total = opts.Total
+		if opts.MinInterval != 0 {
+			r.interval = opts.MinInterval
+		}
+	}
+	return r
+}
+
+// Enabled reports whether the client asked for progress notifications.
+func (r *ProgressReporter) Enabled() bool {
+	return r.token != nil && r.session != nil
+}
+
+// Percent returns the progress so far as a percentage of the total, or -1
+// if the total is unknown.
+func (r *ProgressReporter) Percent() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.total <= 0 {
+		return -1
+	}
+	return min(100, 100*r.progress/r.total)
+}
+
+// Report records the progress so far, with an optional message, and
+// notifies the client unless rate limited. Progress must increase with each
+// report; reports that don't increase progress are ignored.
+func (r *ProgressReporter) Report(ctx context.Context, progress float64, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report(ctx, progress, message)
+}
+
+// Add is like [ProgressReporter.Report], but increments the progress by
+// delta.
+func (r *ProgressReporter) Add(ctx context.Context, delta float64, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report(ctx, r.progress+delta, message)
+}
+
+// report implements Report. r.mu must be held.
+func (r *ProgressReporter) report(ctx context.Context, progress float64, message string) error {
+	if r.reported && progress <= r.progress {
+		return nil
+	}
+	r.reported = true
+	r.progress = progress
+	r.message = message
+	r.pending = true
+	if r.total > 0 && progress >= r.total {
+		return r.send(ctx)
+	}
+	if r.interval > 0 && r.now().Sub(r.lastSent) < r.interval {
+		return nil
+	}
+	return r.send(ctx)
+}
+
+// Flush sends any progress that was held back by rate limiting.
+// Call it when the operation finishes.
+func (r *ProgressReporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.pending {
+		return nil
+	}
+	return r.send(ctx)
+}
+
+// send notifies the client of the current progress.
+// r.mu must be held.
+func (r *ProgressReporter) send(ctx context.Context) error {
+	r.pending = false
+	r.lastSent = r.now()
+	if !r.Enabled() {
+		return nil
+	}
+	return r.session.NotifyProgress(ctx, &ProgressNotificationParams{
+		ProgressToken: r.token,
+		Progress:      r.progress,
+		Total:         r.total,
+		Message:       r.message,
+	})
+}
+
+// A ProgressHandler handles progress notifications for a single call.
+// See [WithProgressHandler].
+type ProgressHandler func(context.Context, *ProgressNotificationClientRequest)
+
+type progressHandlerKey struct{}
+
+// WithProgressHandler returns a context that, when passed to a
+// [ClientSession] method that sends a request, asks the server for progress
+// notifications and delivers those for that request to h. If the request
+// params don't already have a progress token, one is generated.
+//
+// h is called in addition to [ClientOptions.ProgressNotificationHandler], and
+// only while the call is in progress. Since notifications are handled
+// asynchronously, one sent by the server just before its response may arrive
+// after the call has returned, and is then not delivered to h.
+func WithProgressHandler(ctx context.Context, h ProgressHandler) context.Context {
+	return context.WithValue(ctx, progressHandlerKey{}, h)
+}
+
+// clientProgress tracks the progress tokens of a client session's outgoing
+// requests.
+type clientProgress struct {
+	next     atomic.Int64 // for generating tokens
+	mu       sync.Mutex
+	handlers map[any]ProgressHandler // by progress token
+	aliases  map[any]any             // caller's tokens, by token of hedged attempts
+}
+
+// attachProgressToken adds a progress token to the params of an outgoing
+// request, if the client is configured to do so or ctx has a
+// [ProgressHandler]. If params is nil, newParams is used to create empty
+// params to hold the token. It returns the params to send, which are a copy
+// if a token was added, and a function to call when the request completes.
+func (cs *ClientSession) attachProgressToken(ctx context.Context, method string, params Params, newParams func() Params) (Params, func()) {
+	h, _ := ctx.Value(progressHandlerKey{}).(ProgressHandler)
+	if method == methodInitialize || (h == nil && !cs.client.opts.AutoProgressTokens) {
+		return params, func() {}
+	}
+	if isNilParams(params) && newParams != nil {
+		params = newParams()
+	}
+	rp, ok := params.(RequestParams)
+	if !ok {
+		return params, func() {}
+	}
+	token := rp.GetProgressToken()
+	if token == nil {
+		token = cs.progress.newToken()
+		p := copyParams(rp)
+		p.SetProgressToken(token)
+		params = p
+	}
+	if h == nil {
+		return params, func() {}
+	}
+	p := &cs.progress
+	p.mu.Lock()
+	if p.handlers == nil {
+		p.handlers = make(map[any]ProgressHandler)
+	}
+	key := progressKey(token)
+	p.handlers[key] = h
+	p.mu.Unlock()
+	return params, func() {
+		p.mu.Lock()
+		delete(p.handlers, key)
+		p.mu.Unlock()
+	}
+}
+
+// isNilParams reports whether params is nil or a nil pointer.
+func isNilParams(params Params) bool {
+	if params == nil {
+		return true
+	}
+	v := reflect.ValueOf(params)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// newToken generates a progress token.
+func (p *clientProgress) newToken() string {
+	// Use strings, since numbers may not survive a round trip unchanged.
+	return "progress-" + strconv.FormatInt(p.next.Add(1), 10)
+}
+
+// alias returns a new progress token that stands for token, for another
+// attempt of the same request, and a function that removes the alias.
+func (p *clientProgress) alias(token any) (any, func()) {
+	alias := p.newToken()
+	p.mu.Lock()
+	if p.aliases == nil {
+		p.aliases = make(map[any]any)
+	}
+	p.aliases[alias] = token
+	p.mu.Unlock()
+	return alias, func() {
+		p.mu.Lock()
+		delete(p.aliases, alias)
+		p.mu.Unlock()
+	}
+}
+
+// resolve returns the token that the given token is an alias of, if any.
+func (p *clientProgress) resolve(token any) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.aliases[progressKey(token)]
+	return t, ok
+}
+
+// handler returns the handler for the given progress token, or nil.
+func (p *clientProgress) handler(token any) ProgressHandler {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.handlers[progressKey(token)]
+}
+
+// progressKey returns the token as it will be decoded from a progress
+// notification: integer tokens become float64.
+func progressKey(token any) any {
+	switch t := token.(type) {
+	case int:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	}
+	return token
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProgressReporter(t *testing.T) {
+	notes := make(chan *ProgressNotificationParams, 100)
+	client := NewClient(testImpl, &ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+			notes <- req.Params
+		},
+	})
+	var (
+		clock   = time.Unix(0, 0)
+		enabled []bool
+		percent float64
+	)
+	cs, _, cleanup := basicClientServerConnection(t, client, nil, func(s *Server) {
+		AddTool(s, &Tool{Name: "work"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+			r := NewProgressReporter(req, &ProgressOptions{Total: 10, MinInterval: time.Second})
+			r.now = func() time.Time { return clock }
+			enabled = append(enabled, r.Enabled())
+			for i := range 5 {
+				if err := r.Add(ctx, 1, ""); err != nil {
+					return nil, nil, err
+				}
+				if i == 2 {
+					clock = clock.Add(time.Second) // the next report is sent
+				}
+			}
+			percent = r.Percent()
+			// Not sent: progress must increase.
+			if err := r.Report(ctx, 4, "backwards"); err != nil {
+				return nil, nil, err
+			}
+			if err := r.Flush(ctx); err != nil {
+				return nil, nil, err
+			}
+			if err := r.Report(ctx, 10, "done"); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, nil
+		})
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	params := &CallToolParams{Name: "work"}
+	params.SetProgressToken("tok")
+	res, err := cs.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool failed: %v", res.Content[0].(*TextContent).Text)
+	}
+	var got []*ProgressNotificationParams
+	for range 4 {
+		select {
+		case n := <-notes:
+			got = append(got, n)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out; got %d notifications", len(got))
+		}
+	}
+	want := []*ProgressNotificationParams{
+		{ProgressToken: "tok", Progress: 1, Total: 10},
+		{ProgressToken: "tok", Progress: 4, Total: 10},
+		{ProgressToken: "tok", Progress: 5, Total: 10}, // flushed
+		{ProgressToken: "tok", Progress: 10, Total: 10, Message: "done"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+	if percent != 50 {
+		t.Errorf("Percent() = %g, want 50", percent)
+	}
+
+	// Without a progress token, reports are discarded.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]bool{true, false}, enabled); diff != "" {
+		t.Errorf("Enabled mismatch (-want +got):\n%s", diff)
+	}
+	select {
+	case n := <-notes:
+		t.Errorf("got unexpected notification %+v", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClientProgressTokens(t *testing.T) {
+	client := NewClient(testImpl, &ClientOptions{AutoProgressTokens: true})
+	delivered := make(chan struct{})
+	var enabled []bool
+	cs, _, cleanup := basicClientServerConnection(t, client, nil, func(s *Server) {
+		AddTool(s, &Tool{Name: "work"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+			r := NewProgressReporter(req, &ProgressOptions{Total: 2, MinInterval: -1})
+			enabled = append(enabled, r.Enabled())
+			if !r.Enabled() {
+				return nil, nil, nil
+			}
+			for i := range 2 {
+				if err := r.Report(ctx, float64(i+1), ""); err != nil {
+					return nil, nil, err
+				}
+			}
+			// Wait for delivery, so that the notifications aren't handled
+			// after the call returns.
+			select {
+			case <-delivered:
+			case <-time.After(5 * time.Second):
+				return nil, nil, context.DeadlineExceeded
+			}
+			return nil, nil, nil
+		})
+	})
+	defer cleanup()
+
+	var got []float64
+	ctx := WithProgressHandler(context.Background(), func(_ context.Context, req *ProgressNotificationClientRequest) {
+		got = append(got, req.Params.Progress)
+		if len(got) == 2 {
+			close(delivered)
+		}
+	})
+	params := &CallToolParams{Name: "work"}
+	res, err := cs.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool failed: %v", res.Content[0].(*TextContent).Text)
+	}
+	if diff := cmp.Diff([]float64{1, 2}, got); diff != "" {
+		t.Errorf("progress mismatch (-want +got):\n%s", diff)
+	}
+	if params.GetProgressToken() != nil || params.Meta != nil {
+		t.Errorf("caller's params were modified: %+v", params.Meta)
+	}
+
+	// With AutoProgressTokens, requests without a handler have a token too.
+	delivered = make(chan struct{})
+	close(delivered)
+	if _, err := cs.CallTool(context.Background(), &CallToolParams{Name: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]bool{true, true}, enabled); diff != "" {
+		t.Errorf("Enabled mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientProgressTokensNilParams(t *testing.T) {
+	client := NewClient(testImpl, nil)
+	var tokens []any
+	cs, _, cleanup := basicClientServerConnection(t, client, nil, func(s *Server) {
+		s.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+			return func(ctx context.Context, method string, req Request) (Result, error) {
+				if p, ok := req.GetParams().(*ListToolsParams); ok && method == methodListTools {
+					var token any
+					if p != nil {
+						token = p.GetProgressToken()
+					}
+					tokens = append(tokens, token)
+				}
+				return next(ctx, method, req)
+			}
+		})
+	})
+	defer cleanup()
+
+	ctx := WithProgressHandler(context.Background(), func(context.Context, *ProgressNotificationClientRequest) {})
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.ListTools(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0] == nil || tokens[1] != nil {
+		t.Errorf("got progress tokens %v, want one for the first request only", tokens)
+	}
+}
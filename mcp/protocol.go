@@ -21,11 +21,12 @@ type Annotations struct {
 	// Describes who the intended customer of this object or data is.
 	//
 	// It can include multiple entries to indicate content useful for multiple
-	// audiences (e.g., []Role{"user", "assistant"}).
+	// audiences (e.g., []Role{RoleUser, RoleAssistant}).
 	Audience []Role `json:"audience,omitempty"`
 	// The moment the resource was last modified, as an ISO 8601 formatted string.
 	//
 	// Should be an ISO 8601 formatted string (e.g., "2025-01-12T15:00:58Z").
+	// See also [Annotations.SetLastModified] and [Annotations.LastModifiedTime].
 	//
 	// Examples: last activity timestamp in an open file, timestamp when the
 	// resource was attached, etc.
@@ -35,6 +36,9 @@ type Annotations struct {
 	// A value of 1 means "most important," and indicates that the data is
 	// effectively required, while 0 means "least important," and indicates that the
 	// data is entirely optional.
+	//
+	// Because the field is omitted when zero, a priority of 0 is
+	// indistinguishable from no priority.
 	Priority float64 `json:"priority,omitempty"`
 }
 
@@ -410,6 +414,23 @@ type InitializeResult struct {
 
 func (*InitializeResult) isResult() {}
 
+// ServerDisplayName returns the name to show users for the server.
+// See [Implementation.DisplayName].
+func (r *InitializeResult) ServerDisplayName() string {
+	if r == nil {
+		return ""
+	}
+	return r.ServerInfo.DisplayName()
+}
+
+// ServerWebsiteURL returns the URL of the server's website, if any.
+func (r *InitializeResult) ServerWebsiteURL() string {
+	if r == nil || r.ServerInfo == nil {
+		return ""
+	}
+	return r.ServerInfo.WebsiteURL
+}
+
 type InitializedParams struct {
 	// This property is reserved by the protocol to allow clients and servers to
 	// attach additional metadata to their responses.
@@ -827,6 +848,12 @@ type ResourceTemplate struct {
 // The sender or recipient of messages and data in a conversation.
 type Role string
 
+// Roles defined by the protocol.
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
 // Represents a root directory or file that the server can operate on.
 type Root struct {
 	// See [specification/2025-06-18/basic/index#general-fields] for notes on _meta
@@ -1083,6 +1110,10 @@ func (*ElicitResult) isResult() {}
 // An Implementation describes the name and version of an MCP implementation, with an optional
 // title for UI representation.
 type Implementation struct {
+	// This property is reserved by the protocol to allow clients and servers to
+	// attach additional metadata, such as vendor information, to their
+	// implementation info.
+	Meta `json:"_meta,omitempty"`
 	// Intended for programmatic or logical use, but used as a display name in past
 	// specs or fallback (if title isn't present).
 	Name string `json:"name"`
@@ -1090,6 +1121,23 @@ type Implementation struct {
 	// easily understood, even by those unfamiliar with domain-specific terminology.
 	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
+	// An optional human-readable description of what this implementation does.
+	Description string `json:"description,omitempty"`
+	// An optional URL of the website for this implementation.
+	WebsiteURL string `json:"websiteUrl,omitempty"`
+}
+
+// DisplayName returns the name to show users for the implementation: its
+// Title if set, and otherwise its Name. It returns the empty string for a
+// nil Implementation.
+func (i *Implementation) DisplayName() string {
+	if i == nil {
+		return ""
+	}
+	if i.Title != "" {
+		return i.Title
+	}
+	return i.Name
 }
 
 // Present if the server supports argument autocompletion suggestions.
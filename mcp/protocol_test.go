@@ -531,3 +531,30 @@ func TestContentUnmarshal(t *testing.T) {
 	var gotpm PromptMessage
 	roundtrip(pm, &gotpm)
 }
+
+func TestImplementationInfo(t *testing.T) {
+	impl := &Implementation{
+		Meta:        Meta{"vendor": "example"},
+		Name:        "weather",
+		Title:       "Weather Service",
+		Version:     "v1.2.3",
+		Description: "Forecasts and alerts",
+		WebsiteURL:  "https://example.com/weather",
+	}
+	server := NewServer(impl, nil)
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+	res := cs.InitializeResult()
+	if diff := cmp.Diff(impl, res.ServerInfo); diff != "" {
+		t.Errorf("ServerInfo mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := res.ServerDisplayName(), "Weather Service"; got != want {
+		t.Errorf("ServerDisplayName() = %q, want %q", got, want)
+	}
+	if got, want := res.ServerWebsiteURL(), impl.WebsiteURL; got != want {
+		t.Errorf("ServerWebsiteURL() = %q, want %q", got, want)
+	}
+	if got := (&Implementation{Name: "n"}).DisplayName(); got != "n" {
+		t.Errorf("DisplayName without title = %q, want %q", got, "n")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// ProxyOptions configures a proxy server created with [NewProxy].
+type ProxyOptions struct {
+	// Upstream returns the transport used to connect to the upstream server
+	// on behalf of a downstream session. It is called once for each
+	// downstream session, when the session is initialized, and must not be
+	// nil.
+	//
+	// The request's Extra holds information from the downstream transport,
+	// such as the HTTP header, so Upstream can be used to forward or inject
+	// credentials, for example with a [StreamableClientTransport] whose
+	// HTTPClient adds an Authorization header.
+	Upstream func(context.Context, *InitializeServerRequest) (Transport, error)
+	// If non-nil, ToolName maps the name of an upstream tool to the name
+	// exposed to downstream clients. UpstreamToolName must then be set to its
+	// inverse.
+	ToolName func(upstream string) string
+	// UpstreamToolName maps the name of a tool called by a downstream client
+	// to the name of the upstream tool.
+	UpstreamToolName func(name string) string
+	// If non-nil, RewriteRequest is called with each downstream request
+	// before it is forwarded upstream. It may modify the request's params in
+	// place, for example to add credentials to their _meta. If it returns a
+	// non-nil error, the request fails with that error.
+	RewriteRequest func(ctx context.Context, method string, req Request) error
+	// ServerOptions configures the proxy server.
+	ServerOptions *ServerOptions
+}
+
+// NewProxy returns a [Server] that forwards the MCP sessions it accepts to an
+// upstream server.
+//
+// Each downstream session is paired with an upstream session, connected with
+// [ProxyOptions.Upstream] when the downstream session is initialized. The
+// proxy reports the upstream server's capabilities and instructions, and
+// forwards requests and notifications in both directions, so that sampling,
+// elicitation and roots requests from the upstream server reach the
+// downstream client. Pings are answered by the proxy itself. Closing either
+// session closes the other.
+//
+// The proxy identifies itself as impl to both its clients and the upstream
+// server. Since the upstream session is created on initialization, the proxy
+// must be served with stateful sessions.
+func NewProxy(impl *Implementation, opts *ProxyOptions) *Server {
+	if opts == nil || opts.Upstream == nil {
+		panic("nil ProxyOptions.Upstream")
+	}
+	if (opts.ToolName == nil) != (opts.UpstreamToolName == nil) {
+		panic("ProxyOptions.ToolName and UpstreamToolName must be set together")
+	}
+	p := &proxy{
+		impl:      impl,
+		opts:      *opts,
+		upstreams: make(map[*ServerSession]*ClientSession),
+	}
+	s := NewServer(impl, opts.ServerOptions)
+	s.AddReceivingMiddleware(p.middleware)
+	return s
+}
+
+// ErrNoUpstream is returned by a proxy server for requests on a session that
+// has no upstream session, such as a session that was not initialized by
+// the proxy.
+var ErrNoUpstream = errors.New("no upstream session")
+
+type proxy struct {
+	impl *Implementation
+	opts ProxyOptions
+
+	mu        sync.Mutex
+	upstreams map[*ServerSession]*ClientSession
+}
+
+// upstream returns the upstream session of ss, or nil.
+func (p *proxy) upstream(ss *ServerSession) *ClientSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.upstreams[ss]
+}
+
+func (p *proxy) middleware(next MethodHandler) MethodHandler {
+	return func(ctx context.Context, method string, req Request) (Result, error) {
+		ss := req.GetSession().(*ServerSession)
+		switch method {
+		case methodInitialize:
+			return p.initialize(ctx, next, req.(*InitializeServerRequest))
+		case methodPing, notificationInitialized, notificationCancelled:
+			return next(ctx, method, req)
+		}
+		cs := p.upstream(ss)
+		if cs == nil {
+			return nil, fmt.Errorf("%s: %w", method, ErrNoUpstream)
+		}
+		if p.opts.RewriteRequest != nil {
+			if err := p.opts.RewriteRequest(ctx, method, req); err != nil {
+				return nil, err
+			}
+		}
+		params := req.GetParams()
+		if method == methodCallTool && p.opts.UpstreamToolName != nil {
+			if ps, ok := params.(*CallToolParamsRaw); ok {
+				ps.Name = p.opts.UpstreamToolName(ps.Name)
+			}
+		}
+		res, err := forward(ctx, cs, method, params)
+		if err != nil {
+			return nil, err
+		}
+		if method == methodListTools && p.opts.ToolName != nil {
+			if r, ok := res.(*ListToolsResult); ok {
+				for _, t := range r.Tools {
+					t.Name = p.opts.ToolName(t.Name)
+				}
+			}
+		}
+		return res, nil
+	}
+}
+
+// initialize initializes the downstream session, then connects it to a new
+// upstream session and reports the upstream server's capabilities.
+func (p *proxy) initialize(ctx context.Context, next MethodHandler, req *InitializeServerRequest) (Result, error) {
+	res, err := next(ctx, methodInitialize, req)
+	if err != nil {
+		return nil, err
+	}
+	ss := req.Session
+	if cs := p.upstream(ss); cs != nil {
+		// A repeated initialize: keep the existing upstream session.
+		return p.initializeResult(res.(*InitializeResult), cs), nil
+	}
+	t, err := p.opts.Upstream(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting upstream: %w", err)
+	}
+	client := NewClient(p.impl, p.clientOptions(req.Params))
+	client.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			switch method {
+			case methodPing, notificationCancelled:
+				return next(ctx, method, req)
+			}
+			return forward(ctx, ss, method, req.GetParams())
+		}
+	})
+	// The upstream session outlives the initialize request, and some
+	// transports bind the connection to the context passed to Connect.
+	cs, err := client.Connect(context.WithoutCancel(ctx), t, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting upstream: %w", err)
+	}
+	p.mu.Lock()
+	p.upstreams[ss] = cs
+	p.mu.Unlock()
+	go func() {
+		ss.Wait()
+		p.mu.Lock()
+		delete(p.upstreams, ss)
+		p.mu.Unlock()
+		cs.Close()
+	}()
+	go func() {
+		cs.Wait()
+		ss.Close()
+	}()
+	return p.initializeResult(res.(*InitializeResult), cs), nil
+}
+
+// initializeResult replaces the capabilities and instructions of res with
+// those of the upstream server.
+func (p *proxy) initializeResult(res *InitializeResult, cs *ClientSession) *InitializeResult {
+	up := cs.InitializeResult()
+	res.Capabilities = up.Capabilities
+	res.Instructions = up.Instructions
+	return res
+}
+
+// clientOptions returns options for an upstream client that advertises the
+// same sampling and elicitation capabilities as the downstream client.
+// The handlers are never called, since the client's receiving middleware
+// forwards the requests downstream.
+func (p *proxy) clientOptions(params *InitializeParams) *ClientOptions {
+	opts := &ClientOptions{}
+	if params.Capabilities == nil {
+		return opts
+	}
+	if params.Capabilities.Sampling != nil {
+		opts.CreateMessageHandler = func(context.Context, *CreateMessageRequest) (*CreateMessageResult, error) {
+			return nil, jsonrpc2.ErrNotHandled
+		}
+	}
+	if params.Capabilities.Elicitation != nil {
+		opts.ElicitationHandler = func(context.Context, *ElicitRequest) (*ElicitResult, error) {
+			return nil, jsonrpc2.ErrNotHandled
+		}
+	}
+	return opts
+}
+
+// forward sends the method with the given params to the peer of s, using
+// the session's sending method handler.
+func forward(ctx context.Context, s Session, method string, params Params) (Result, error) {
+	return s.sendingMethodHandler()(ctx, method, newRequest(s, params))
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+
+	// The upstream server has a tool that asks the client for a sample, and
+	// reports the credential injected by the proxy.
+	upstream := NewServer(testImpl, &ServerOptions{Instructions: "upstream"})
+	AddTool(upstream, &Tool{Name: "greet"}, func(ctx context.Context, req *CallToolRequest, args map[string]any) (*CallToolResult, any, error) {
+		res, err := req.Session.CreateMessage(ctx, &CreateMessageParams{})
+		if err != nil {
+			return nil, nil, err
+		}
+		text := res.Content.(*TextContent).Text
+		token, _ := req.Params.Meta["go-sdk/token"].(string)
+		return &CallToolResult{Content: []Content{&TextContent{Text: text + " " + token}}}, nil, nil
+	})
+	var upstreamSessions []*ServerSession
+	proxy := NewProxy(&Implementation{Name: "proxy", Version: "v1"}, &ProxyOptions{
+		Upstream: func(ctx context.Context, req *InitializeServerRequest) (Transport, error) {
+			ct, st := NewInMemoryTransports()
+			ss, err := upstream.Connect(ctx, st, nil)
+			if err != nil {
+				return nil, err
+			}
+			upstreamSessions = append(upstreamSessions, ss)
+			return ct, nil
+		},
+		ToolName:         func(name string) string { return "up_" + name },
+		UpstreamToolName: func(name string) string { return strings.TrimPrefix(name, "up_") },
+		RewriteRequest: func(ctx context.Context, method string, req Request) error {
+			if method == methodCallTool {
+				req.GetParams().SetMeta(map[string]any{"go-sdk/token": "secret"})
+			}
+			return nil
+		},
+	})
+
+	client := NewClient(testImpl, &ClientOptions{
+		CreateMessageHandler: func(context.Context, *CreateMessageRequest) (*CreateMessageResult, error) {
+			return &CreateMessageResult{Model: "m", Role: "assistant", Content: &TextContent{Text: "hello"}}, nil
+		},
+	})
+	cs, _, cleanup := basicClientServerConnection(t, client, proxy, nil)
+
+	res := cs.InitializeResult()
+	if got, want := res.Instructions, "upstream"; got != want {
+		t.Errorf("Instructions = %q, want %q", got, want)
+	}
+	if res.Capabilities.Tools == nil {
+		t.Error("proxy does not report the upstream tools capability")
+	}
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "up_greet" {
+		t.Fatalf("ListTools: got %v, want one tool named up_greet", tools.Tools)
+	}
+	got, err := cs.CallTool(ctx, &CallToolParams{Name: "up_greet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := got.Content[0].(*TextContent).Text; text != "hello secret" {
+		t.Errorf("CallTool: got %q, want %q", text, "hello secret")
+	}
+
+	// Closing the downstream session closes the upstream session.
+	cleanup()
+	if len(upstreamSessions) != 1 {
+		t.Fatalf("got %d upstream sessions, want 1", len(upstreamSessions))
+	}
+	upstreamSessions[0].Wait()
+}
+
+func TestProxyNoUpstream(t *testing.T) {
+	ctx := context.Background()
+	proxy := NewProxy(testImpl, &ProxyOptions{
+		Upstream: func(context.Context, *InitializeServerRequest) (Transport, error) {
+			t.Fatal("Upstream called")
+			return nil, nil
+		},
+	})
+	ct, st := NewInMemoryTransports()
+	// A session that is already initialized has no upstream session.
+	ss, err := proxy.Connect(ctx, st, &ServerSessionOptions{
+		State: &ServerSessionState{
+			InitializeParams: &InitializeParams{ProtocolVersion: latestProtocolVersion},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	conn, err := ct.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.Write(ctx, &jsonrpc.Request{ID: jsonrpc2.Int64ID(1), Method: methodListTools, Params: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok || resp.Error == nil || !strings.Contains(resp.Error.Error(), ErrNoUpstream.Error()) {
+		t.Errorf("ListTools: got %+v, want error containing %q", msg, ErrNoUpstream)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// PruneOptions configures the periodic pruning of abandoned sessions,
+// streams and requests, which otherwise hold memory and goroutines until
+// their client returns, if it ever does. See [ServerOptions.Prune] and
+// [StreamableHTTPOptions.Prune].
+type PruneOptions struct {
+	// Interval is how often to prune. If it is not positive, nothing is
+	// pruned.
+	Interval time.Duration
+	// If positive, sessions older than MaxSessionAge are closed.
+	MaxSessionAge time.Duration
+	// If positive, MaxIdleStreams bounds the number of idle streams of a
+	// streamable session: streams with unanswered requests that no HTTP
+	// response is delivering, as when a client disconnected without resuming
+	// them. The streams that have been idle longest are dropped, and their
+	// requests cancelled, until the bound is met. Other transports have no
+	// such streams.
+	MaxIdleStreams int
+	// If positive, requests that have been handled for longer than
+	// MaxRequestAge are considered orphaned, and their contexts are
+	// cancelled.
+	MaxRequestAge time.Duration
+}
+
+// errPruned is the cause of the cancellation of requests by pruning.
+var errPruned = errors.New("request pruned")
+
+// A streamPruner is a connection with streams that can be pruned.
+type streamPruner interface {
+	// pruneIdleStreams drops idle streams in excess of max, and returns the
+	// IDs of their unanswered requests.
+	pruneIdleStreams(max int) []jsonrpc.ID
+}
+
+// prune applies the stream and request policies of opts to the session. It
+// reports whether the session is older than opts.MaxSessionAge, in which case
+// the caller should close it.
+func (ss *ServerSession) prune(opts *PruneOptions, now time.Time) (expired bool) {
+	if opts.MaxRequestAge > 0 {
+		if n := ss.conn.CancelReceivedBefore(now.Add(-opts.MaxRequestAge), errPruned); n > 0 {
+			ss.server.opts.Logger.Info("cancelled orphaned requests", "session_id", ss.ID(), "count", n)
+		}
+	}
+	if c, ok := asConnection[streamPruner](ss.mcpConn); ok && opts.MaxIdleStreams > 0 {
+		for _, id := range c.pruneIdleStreams(opts.MaxIdleStreams) {
+			ss.conn.CancelCause(id, errPruned)
+		}
+	}
+	return opts.MaxSessionAge > 0 && now.Sub(ss.created) > opts.MaxSessionAge
+}
+
+// startPruning starts pruning the session according to opts, until the
+// session is disconnected.
+func (ss *ServerSession) startPruning(opts *PruneOptions) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// As with keepalive, assign cancel before starting the goroutine.
+	ss.pruneCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if ss.prune(opts, now) {
+					ss.server.opts.Logger.Info("closing expired session", "session_id", ss.ID())
+					_ = ss.Close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// startPruningLocked starts pruning the sessions of the handler, if pruning
+// is configured and not already running. Pruning stops when the handler has
+// no sessions.
+//
+// h.mu must be held.
+func (h *StreamableHTTPHandler) startPruningLocked() {
+	opts := h.options()
+	if h.pruning || len(h.sessions) == 0 || opts.Stateless || opts.Prune == nil || opts.Prune.Interval <= 0 {
+		return
+	}
+	h.pruning = true
+	go h.pruneSessions(opts.Prune.Interval)
+}
+
+// pruneSessions periodically prunes the sessions of the handler, with the
+// current [StreamableHTTPOptions.Prune].
+func (h *StreamableHTTPHandler) pruneSessions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		h.mu.Lock()
+		opts := h.options().Prune
+		if len(h.sessions) == 0 || opts == nil || opts.Interval <= 0 {
+			h.pruning = false
+			h.mu.Unlock()
+			return
+		}
+		// Sessions remove themselves from h.sessions when closed, so we can't
+		// terminate them while holding the lock.
+		infos := slices.Collect(maps.Values(h.sessions))
+		h.mu.Unlock()
+
+		if opts.Interval != interval { // see Reconfigure
+			interval = opts.Interval
+			ticker.Reset(interval)
+		}
+		for _, info := range infos {
+			if info.session.prune(opts, now) {
+				opts := h.options()
+				opts.Logger.Info("closing expired session", "session_id", info.session.ID())
+				info.terminate(SessionExpired)
+			}
+		}
+	}
+}
+
+// pruneIdleStreams implements [streamPruner].
+func (c *streamableServerConn) pruneIdleStreams(max int) []jsonrpc.ID {
+	c.mu.Lock()
+	streams := slices.Collect(maps.Values(c.streams))
+	c.mu.Unlock()
+
+	type idleStream struct {
+		s     *stream
+		since time.Time
+	}
+	var idle []idleStream
+	for _, s := range streams {
+		if s.id == "" {
+			continue // the standalone SSE stream has no requests
+		}
+		s.mu.Lock()
+		if s.deliver == nil && !s.doneLocked() {
+			idle = append(idle, idleStream{s, s.idleSince})
+		}
+		s.mu.Unlock()
+	}
+	if len(idle) <= max {
+		return nil
+	}
+	slices.SortFunc(idle, func(a, b idleStream) int { return a.since.Compare(b.since) })
+
+	var ids []jsonrpc.ID
+	for _, is := range idle[:len(idle)-max] {
+		s := is.s
+		s.mu.Lock()
+		if s.deliver != nil {
+			// The stream was resumed in the meantime.
+			s.mu.Unlock()
+			continue
+		}
+		c.mu.Lock()
+		for id := range s.requests {
+			ids = append(ids, id)
+			// Responses to the requests are now rejected, as for a closed
+			// stream.
+			delete(c.requestStreams, id)
+		}
+		delete(c.streams, s.id)
+		c.mu.Unlock()
+		s.requests = nil
+		s.mu.Unlock()
+		c.logger.Info("dropped idle stream", "session_id", c.sessionID, "stream_id", s.id)
+	}
+	return ids
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+func TestPruneSessionAge(t *testing.T) {
+	server := NewServer(testImpl, &ServerOptions{
+		Prune: &PruneOptions{Interval: 10 * time.Millisecond, MaxSessionAge: 50 * time.Millisecond},
+	})
+	_, ss, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+
+	done := make(chan struct{})
+	go func() {
+		ss.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired session was not closed")
+	}
+}
+
+func TestPruneRequests(t *testing.T) {
+	causes := make(chan error, 1)
+	server := NewServer(testImpl, &ServerOptions{
+		Prune: &PruneOptions{Interval: 10 * time.Millisecond, MaxRequestAge: 50 * time.Millisecond},
+	})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		AddTool(s, &Tool{Name: "hang"}, func(ctx context.Context, _ *CallToolRequest, _ map[string]any) (*CallToolResult, any, error) {
+			<-ctx.Done()
+			causes <- context.Cause(ctx)
+			return nil, nil, ctx.Err()
+		})
+	})
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "hang"})
+	if err == nil && !res.IsError {
+		t.Error("orphaned tool call succeeded")
+	}
+	if cause := <-causes; !errors.Is(cause, errPruned) {
+		t.Errorf("request cancelled with cause %v, want %v", cause, errPruned)
+	}
+}
+
+func TestPruneIdleStreams(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	started := make(chan struct{})
+	causes := make(chan error, 2)
+	release := make(chan struct{})
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "hang"}, func(ctx context.Context, _ *CallToolRequest, _ map[string]any) (*CallToolResult, any, error) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			causes <- context.Cause(ctx)
+		case <-release:
+		}
+		return &CallToolResult{}, nil, nil
+	})
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		Prune: &PruneOptions{Interval: 10 * time.Millisecond, MaxIdleStreams: 1},
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+	defer handler.closeAll()
+	defer close(release)
+
+	post := func(ctx context.Context, sessionID string, msg jsonrpc.Message) (string, error) {
+		out := make(chan jsonrpc.Message, 10)
+		r := streamableRequest{method: http.MethodPost, messages: []jsonrpc.Message{msg}}
+		id, _, _, err := r.do(ctx, httpServer.URL, sessionID, out)
+		return id, err
+	}
+	sessionID, err := post(ctx, "", req(1, methodInitialize, &InitializeParams{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := post(ctx, sessionID, req(0, notificationInitialized, &InitializedParams{})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Abandon two tool calls, by disconnecting once they have started. Only
+	// the stream of the first should be dropped.
+	for i := range 2 {
+		callCtx, callCancel := context.WithCancel(ctx)
+		go post(callCtx, sessionID, req(int64(i+2), methodCallTool, &CallToolParams{Name: "hang"}))
+		<-started
+		callCancel()
+		time.Sleep(20 * time.Millisecond) // order the streams by idle time
+	}
+	select {
+	case cause := <-causes:
+		if !errors.Is(cause, errPruned) {
+			t.Errorf("request cancelled with cause %v, want %v", cause, errPruned)
+		}
+	case <-ctx.Done():
+		t.Fatal("idle stream was not pruned")
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case cause := <-causes:
+		t.Errorf("second request cancelled with cause %v, want no cancellation", cause)
+	default:
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// A Quota limits the number of calls to a tool that each principal may make
+// in a window of time.
+type Quota struct {
+	// Limit is the maximum number of calls in a window.
+	Limit int
+	// Window is the length of a window. Windows are consecutive intervals of
+	// this length, starting at the Unix epoch, so all principals' windows
+	// reset at the same time.
+	Window time.Duration
+}
+
+// QuotaOptions configures per-principal quotas on tool calls.
+// See [ServerOptions.Quotas].
+type QuotaOptions struct {
+	// Tools holds the quotas of individual tools, by name. A nil quota means
+	// the tool is not limited.
+	Tools map[string]*Quota
+	// Default is the quota of tools that are not in Tools. If nil, such tools
+	// are not limited.
+	Default *Quota
+	// Principal returns the principal that a call counts against, such as the
+	// user identified by the request's bearer token (see
+	// [RequestExtra.TokenInfo]). If nil, the principal is the session ID, or,
+	// for sessions without an ID such as stdio sessions, the session itself.
+	// Stateless servers should set Principal, since each of their requests
+	// has a session of its own.
+	Principal func(context.Context, *CallToolRequest) string
+	// Store counts calls. If nil, calls are counted in memory, separately by
+	// each server. To enforce quotas across server instances, use a store that
+	// shares its counts, for example by using the same backend as the
+	// [SessionStore].
+	Store QuotaStore
+}
+
+// A QuotaStore counts calls against quotas.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type QuotaStore interface {
+	// Increment atomically increments the count for key and returns the new
+	// count. A key that doesn't exist has a count of zero. The key may be
+	// discarded after the given expiration time.
+	Increment(ctx context.Context, key string, expires time.Time) (int, error)
+}
+
+// InMemoryQuotaStore is a [QuotaStore] that holds counts in memory.
+type InMemoryQuotaStore struct {
+	mu     sync.Mutex
+	counts map[string]*quotaCount
+	byExp  quotaHeap // counts ordered by expiration, for discarding them
+}
+
+type quotaCount struct {
+	key     string
+	n       int
+	expires time.Time
+}
+
+// NewInMemoryQuotaStore returns a new [InMemoryQuotaStore].
+func NewInMemoryQuotaStore() *InMemoryQuotaStore {
+	return &InMemoryQuotaStore{counts: make(map[string]*quotaCount)}
+}
+
+// Increment implements [QuotaStore.Increment].
+func (s *InMemoryQuotaStore) Increment(_ context.Context, key string, expires time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	// Discard expired counts, so that the map doesn't grow without bound.
+	for len(s.byExp) > 0 && !now.Before(s.byExp[0].expires) {
+		c := heap.Pop(&s.byExp).(*quotaCount)
+		if s.counts[c.key] == c {
+			delete(s.counts, c.key)
+		}
+	}
+	c, ok := s.counts[key]
+	if !ok {
+		c = &quotaCount{key: key, expires: expires}
+		s.counts[key] = c
+		heap.Push(&s.byExp, c)
+	}
+	c.n++
+	return c.n, nil
+}
+
+// A quotaHeap is a min-heap of counts by expiration time.
+type quotaHeap []*quotaCount
+
+func (h quotaHeap) Len() int           { return len(h) }
+func (h quotaHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h quotaHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *quotaHeap) Push(x any)        { *h = append(*h, x.(*quotaCount)) }
+func (h *quotaHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return c
+}
+
+// QuotaExceededData is the data of the error returned for a tool call that
+// exceeds its quota. The error has code -31003.
+type QuotaExceededData struct {
+	Tool    string    `json:"tool"`
+	Limit   int       `json:"limit"`
+	ResetAt time.Time `json:"resetAt"` // when the quota resets
+}
+
+func (o *QuotaOptions) validate() error {
+	check := func(q *Quota) error {
+		if q != nil && (q.Window <= 0 || q.Limit < 0) {
+			return fmt.Errorf("invalid quota %+v", *q)
+		}
+		return nil
+	}
+	if err := check(o.Default); err != nil {
+		return err
+	}
+	for name, q := range o.Tools {
+		if err := check(q); err != nil {
+			return fmt.Errorf("tool %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// checkQuota counts a call to the requested tool against its quota, and
+// returns a non-nil error if the quota is exceeded.
+func (s *Server) checkQuota(ctx context.Context, req *CallToolRequest) error {
+	o := s.opts.Quotas
+	if o == nil {
+		return nil
+	}
+	q, ok := o.Tools[req.Params.Name]
+	if !ok {
+		q = o.Default
+	}
+	if q == nil {
+		return nil
+	}
+	principal := req.Session.ID()
+	if principal == "" {
+		principal = "session-" + strconv.FormatInt(req.Session.serial, 10)
+	}
+	if o.Principal != nil {
+		principal = o.Principal(ctx, req)
+	}
+	now := time.Now()
+	start := now.Truncate(q.Window)
+	reset := start.Add(q.Window)
+	key := req.Params.Name + "\x00" + principal + "\x00" + strconv.FormatInt(start.UnixNano(), 10)
+	n, err := o.Store.Increment(ctx, key, reset)
+	if err != nil {
+		return fmt.Errorf("checking quota of tool %q: %w", req.Params.Name, err)
+	}
+	if n <= q.Limit {
+		return nil
+	}
+	data, err := json.Marshal(QuotaExceededData{Tool: req.Params.Name, Limit: q.Limit, ResetAt: reset})
+	if err != nil {
+		return err
+	}
+	return &jsonrpc2.WireError{
+		Code:    CodeQuotaExceeded,
+		Message: fmt.Sprintf("quota of tool %q exceeded: %d calls per %v; resets at %s", req.Params.Name, q.Limit, q.Window, reset.UTC().Format(time.RFC3339)),
+		Data:    data,
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+func TestQuotas(t *testing.T) {
+	store := NewInMemoryQuotaStore()
+	newServer := func() *Server {
+		s := NewServer(testImpl, &ServerOptions{
+			Quotas: &QuotaOptions{
+				Default: &Quota{Limit: 2, Window: time.Hour},
+				Tools:   map[string]*Quota{"free": nil},
+				Principal: func(context.Context, *CallToolRequest) string {
+					return "alice"
+				},
+				Store: store,
+			},
+		})
+		for _, name := range []string{"limited", "free"} {
+			AddTool(s, &Tool{Name: name}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+				return nil, nil, nil
+			})
+		}
+		return s
+	}
+	ctx := context.Background()
+	// Two servers sharing a store share quotas.
+	cs1, _, cleanup1 := basicClientServerConnection(t, nil, newServer(), nil)
+	defer cleanup1()
+	cs2, _, cleanup2 := basicClientServerConnection(t, nil, newServer(), nil)
+	defer cleanup2()
+
+	for _, cs := range []*ClientSession{cs1, cs2} {
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "limited"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := cs1.CallTool(ctx, &CallToolParams{Name: "limited"})
+	var wireErr *jsonrpc2.WireError
+	if !errors.As(err, &wireErr) || wireErr.Code != CodeQuotaExceeded {
+		t.Fatalf("third call: got %v, want quota exceeded error", err)
+	}
+	var data QuotaExceededData
+	if err := json.Unmarshal(wireErr.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Tool != "limited" || data.Limit != 2 || !data.ResetAt.After(time.Now()) {
+		t.Errorf("got error data %+v", data)
+	}
+	for range 3 {
+		if _, err := cs1.CallTool(ctx, &CallToolParams{Name: "free"}); err != nil {
+			t.Errorf("unlimited tool: %v", err)
+		}
+	}
+}
+
+func TestQuotasDefaultPrincipal(t *testing.T) {
+	// In-memory sessions have no ID, so each counts as its own principal.
+	store := NewInMemoryQuotaStore()
+	newServer := func() *Server {
+		s := NewServer(testImpl, &ServerOptions{
+			Quotas: &QuotaOptions{Default: &Quota{Limit: 1, Window: time.Hour}, Store: store},
+		})
+		AddTool(s, &Tool{Name: "limited"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+			return nil, nil, nil
+		})
+		return s
+	}
+	ctx := context.Background()
+	cs1, ss1, cleanup1 := basicClientServerConnection(t, nil, newServer(), nil)
+	defer cleanup1()
+	cs2, _, cleanup2 := basicClientServerConnection(t, nil, newServer(), nil)
+	defer cleanup2()
+	if ss1.ID() != "" {
+		t.Fatalf("session has ID %q, want none", ss1.ID())
+	}
+	for _, cs := range []*ClientSession{cs1, cs2} {
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "limited"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cs1.CallTool(ctx, &CallToolParams{Name: "limited"}); err == nil {
+		t.Error("second call of a session: got nil error, want quota exceeded")
+	}
+}
+
+func TestInMemoryQuotaStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemoryQuotaStore()
+	past := time.Now().Add(-time.Second)
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := s.Increment(ctx, key, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Expired counts are discarded, and restart at zero.
+	n, err := s.Increment(ctx, "a", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("count of expired key: got %d, want 1", n)
+	}
+	if len(s.counts) != 1 || len(s.byExp) != 1 {
+		t.Errorf("got %d counts and %d heap entries, want 1", len(s.counts), len(s.byExp))
+	}
+	if n, _ := s.Increment(ctx, "a", time.Now().Add(time.Hour)); n != 2 {
+		t.Errorf("second count: got %d, want 2", n)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A RedactionMatcher reports the spans of text that must not leave the
+// server, as successive pairs of byte offsets in the style of
+// [regexp.Regexp.FindAllStringIndex]. Spans may overlap.
+//
+// A RedactionMatcher may wrap a regular expression (see [RegexpMatcher]) or
+// an arbitrary detector, such as a secret scanner or PII classifier.
+type RedactionMatcher func(text string) [][]int
+
+// RegexpMatcher returns a RedactionMatcher that matches re.
+func RegexpMatcher(re *regexp.Regexp) RedactionMatcher {
+	return func(text string) [][]int { return re.FindAllStringIndex(text, -1) }
+}
+
+// RedactionOptions configures [RedactionMiddleware].
+type RedactionOptions struct {
+	// Matchers find sensitive text.
+	Matchers []RedactionMatcher
+	// Replacement is substituted for each match.
+	// If empty, "[REDACTED]" is used.
+	Replacement string
+	// If Block is set, results that contain a match are not sent at all:
+	// the request instead fails with an error wrapping [ErrContentBlocked].
+	Block bool
+}
+
+// ErrContentBlocked is returned when content is withheld, either by
+// [RedactionMiddleware] with [RedactionOptions.Block] set, or by a
+// [ContentFilter].
+var ErrContentBlocked = errors.New("content blocked")
+
+// RedactionMiddleware returns server receiving middleware that scans the
+// results of "tools/call", "resources/read" and "prompts/get" against
+// opts.Matchers, and redacts or blocks matching text.
+//
+// Text content, the text of embedded resources, resource contents and the
+// string values of structured content are scanned. Binary data is not.
+// Results returned by handlers are not modified in place: redacted results
+// are copies.
+//
+// Install it with [Server.AddReceivingMiddleware].
+func RedactionMiddleware(opts *RedactionOptions) Middleware {
+	r := &redactor{replacement: "[REDACTED]"}
+	if opts != nil {
+		r.matchers = opts.Matchers
+		r.block = opts.Block
+		if opts.Replacement != "" {
+			r.replacement = opts.Replacement
+		}
+	}
+	return func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			res, err := next(ctx, method, req)
+			if err != nil || res == nil {
+				return res, err
+			}
+			var (
+				res2    Result
+				changed bool
+			)
+			switch res := res.(type) {
+			case *CallToolResult:
+				r2, c, err := r.callToolResult(res)
+				if err != nil {
+					return nil, err
+				}
+				res2, changed = &r2, c
+			case *ReadResourceResult:
+				r2 := *res
+				r2.Contents, changed = r.resourceContents(res.Contents)
+				res2 = &r2
+			case *GetPromptResult:
+				r2 := *res
+				r2.Messages, changed = r.promptMessages(res.Messages)
+				res2 = &r2
+			}
+			if !changed {
+				return res, nil
+			}
+			if r.block {
+				return nil, fmt.Errorf("%w: %s result matched a redaction rule", ErrContentBlocked, method)
+			}
+			return res2, nil
+		}
+	}
+}
+
+type redactor struct {
+	matchers    []RedactionMatcher
+	replacement string
+	block       bool
+}
+
+// redact returns text with all matches replaced, and reports whether there
+// were any matches.
+func (r *redactor) redact(text string) (string, bool) {
+	var spans [][]int
+	for _, m := range r.matchers {
+		for _, s := range m(text) {
+			// Empty matches, such as those of "x*", redact nothing.
+			if s[1] > s[0] {
+				spans = append(spans, s)
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return text, false
+	}
+	slices.SortFunc(spans, func(a, b []int) int { return a[0] - b[0] })
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s[1] <= last {
+			continue // contained in a previous span
+		}
+		if s[0] >= last {
+			b.WriteString(text[last:s[0]])
+			b.WriteString(r.replacement)
+		}
+		last = s[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+func (r *redactor) callToolResult(res *CallToolResult) (CallToolResult, bool, error) {
+	res2 := *res
+	var changed bool
+	res2.Content, changed = r.contents(res.Content)
+	if res.StructuredContent != nil {
+		sc, scChanged, err := r.structured(res.StructuredContent)
+		if err != nil {
+			return res2, false, err
+		}
+		if scChanged {
+			res2.StructuredContent = sc
+			changed = true
+		}
+	}
+	return res2, changed, nil
+}
+
+// contents returns a copy of cs with text redacted, or cs itself if there
+// were no matches.
+func (r *redactor) contents(cs []Content) ([]Content, bool) {
+	var out []Content
+	for i, c := range cs {
+		c2, changed := r.content(c)
+		if changed && out == nil {
+			out = slices.Clone(cs)
+		}
+		if out != nil {
+			out[i] = c2
+		}
+	}
+	if out == nil {
+		return cs, false
+	}
+	return out, true
+}
+
+func (r *redactor) content(c Content) (Content, bool) {
+	switch c := c.(type) {
+	case *TextContent:
+		if text, changed := r.redact(c.Text); changed {
+			c2 := *c
+			c2.Text = text
+			return &c2, true
+		}
+	case *EmbeddedResource:
+		if c.Resource != nil {
+			if rc, changed := r.resourceContent(c.Resource); changed {
+				c2 := *c
+				c2.Resource = rc
+				return &c2, true
+			}
+		}
+	}
+	return c, false
+}
+
+func (r *redactor) resourceContent(rc *ResourceContents) (*ResourceContents, bool) {
+	if text, changed := r.redact(rc.Text); changed {
+		rc2 := *rc
+		rc2.Text = text
+		return &rc2, true
+	}
+	return rc, false
+}
+
+func (r *redactor) resourceContents(rcs []*ResourceContents) ([]*ResourceContents, bool) {
+	var out []*ResourceContents
+	for i, rc := range rcs {
+		rc2, changed := r.resourceContent(rc)
+		if changed && out == nil {
+			out = slices.Clone(rcs)
+		}
+		if out != nil {
+			out[i] = rc2
+		}
+	}
+	if out == nil {
+		return rcs, false
+	}
+	return out, true
+}
+
+func (r *redactor) promptMessages(msgs []*PromptMessage) ([]*PromptMessage, bool) {
+	var out []*PromptMessage
+	for i, m := range msgs {
+		c, changed := r.content(m.Content)
+		if changed && out == nil {
+			out = slices.Clone(msgs)
+		}
+		if changed {
+			m2 := *m
+			m2.Content = c
+			out[i] = &m2
+		}
+	}
+	if out == nil {
+		return msgs, false
+	}
+	return out, true
+}
+
+// structured redacts the string values in the JSON encoding of v.
+// If there were matches, it returns the redacted JSON as a json.RawMessage.
+func (r *redactor) structured(v any) (any, bool, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshaling structured content: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // preserve numbers exactly
+	var x any
+	if err := dec.Decode(&x); err != nil {
+		return nil, false, fmt.Errorf("unmarshaling structured content: %w", err)
+	}
+	x, changed := r.walk(x)
+	if !changed {
+		return v, false, nil
+	}
+	data, err = json.Marshal(x)
+	if err != nil {
+		return nil, false, err
+	}
+	return json.RawMessage(data), true, nil
+}
+
+func (r *redactor) walk(x any) (any, bool) {
+	var changed bool
+	switch x := x.(type) {
+	case string:
+		return r.redact(x)
+	case []any:
+		for i, e := range x {
+			e2, c := r.walk(e)
+			x[i] = e2
+			changed = changed || c
+		}
+	case map[string]any:
+		for k, e := range x {
+			e2, c := r.walk(e)
+			x[k] = e2
+			changed = changed || c
+		}
+	}
+	return x, changed
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedact(t *testing.T) {
+	r := &redactor{
+		matchers: []RedactionMatcher{
+			RegexpMatcher(regexp.MustCompile(`sk-[a-z0-9]+`)),
+			RegexpMatcher(regexp.MustCompile(`secret`)),
+			func(text string) [][]int {
+				if i := strings.Index(text, "sk-abc"); i >= 0 {
+					return [][]int{{i, i + len("sk-abc")}} // overlaps the first matcher
+				}
+				return nil
+			},
+		},
+		replacement: "***",
+	}
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"nothing here", "nothing here"},
+		{"key=sk-abc123", "key=***"},
+		{"a secret and sk-x and secret", "a *** and *** and ***"},
+	} {
+		got, changed := r.redact(test.in)
+		if got != test.want || changed != (test.in != test.want) {
+			t.Errorf("redact(%q) = %q, %t, want %q", test.in, got, changed, test.want)
+		}
+	}
+}
+
+func TestRedactEmptyMatches(t *testing.T) {
+	r := &redactor{
+		matchers:    []RedactionMatcher{RegexpMatcher(regexp.MustCompile(`x*`))},
+		replacement: "***",
+	}
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"axxbxc", "a***b***c"},
+	} {
+		got, changed := r.redact(test.in)
+		if got != test.want || changed != (test.in != test.want) {
+			t.Errorf("redact(%q) = %q, %t, want %q", test.in, got, changed, test.want)
+		}
+	}
+}
+
+func TestRedactionMiddleware(t *testing.T) {
+	secret := RegexpMatcher(regexp.MustCompile(`sk-[a-z0-9]+`))
+	shared := &ResourceContents{URI: "info://key", Text: "key: sk-abc123"}
+
+	type out struct {
+		Key   string `json:"key"`
+		Count int    `json:"count"`
+	}
+	config := func(s *Server) {
+		AddTool(s, &Tool{Name: "key"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, out, error) {
+			return nil, out{Key: "sk-abc123", Count: 1}, nil
+		})
+		s.AddResource(&Resource{URI: "info://key"}, func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+			return &ReadResourceResult{Contents: []*ResourceContents{shared}}, nil
+		})
+		s.AddPrompt(&Prompt{Name: "clean"}, func(context.Context, *GetPromptRequest) (*GetPromptResult, error) {
+			return &GetPromptResult{Messages: []*PromptMessage{{Role: "user", Content: &TextContent{Text: "hello"}}}}, nil
+		})
+	}
+
+	t.Run("redact", func(t *testing.T) {
+		server := NewServer(testImpl, nil)
+		server.AddReceivingMiddleware(RedactionMiddleware(&RedactionOptions{Matchers: []RedactionMatcher{secret}}))
+		cs, _, cleanup := basicClientServerConnection(t, nil, server, config)
+		defer cleanup()
+		ctx := context.Background()
+
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: "key"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := res.Content[0].(*TextContent).Text, `{"count":1,"key":"[REDACTED]"}`; got != want {
+			t.Errorf("text content: got %s, want %s", got, want)
+		}
+		var gotOut out
+		data, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(data, &gotOut); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(out{Key: "[REDACTED]", Count: 1}, gotOut); diff != "" {
+			t.Errorf("structured content mismatch (-want +got):\n%s", diff)
+		}
+
+		rres, err := cs.ReadResource(ctx, &ReadResourceParams{URI: "info://key"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := rres.Contents[0].Text, "key: [REDACTED]"; got != want {
+			t.Errorf("resource: got %q, want %q", got, want)
+		}
+		if shared.Text != "key: sk-abc123" {
+			t.Errorf("handler result was modified in place: %q", shared.Text)
+		}
+
+		if _, err := cs.GetPrompt(ctx, &GetPromptParams{Name: "clean"}); err != nil {
+			t.Errorf("clean prompt: %v", err)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		server := NewServer(testImpl, nil)
+		var blockErr error
+		server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+			return func(ctx context.Context, method string, req Request) (Result, error) {
+				res, err := next(ctx, method, req)
+				if err != nil {
+					blockErr = err
+				}
+				return res, err
+			}
+		}, RedactionMiddleware(&RedactionOptions{Matchers: []RedactionMatcher{secret}, Block: true}))
+		cs, _, cleanup := basicClientServerConnection(t, nil, server, config)
+		defer cleanup()
+		ctx := context.Background()
+
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "key"}); err == nil {
+			t.Error("CallTool: got nil error, want blocked")
+		}
+		if !errors.Is(blockErr, ErrContentBlocked) {
+			t.Errorf("got error %v, want ErrContentBlocked", blockErr)
+		}
+		if _, err := cs.GetPrompt(ctx, &GetPromptParams{Name: "clean"}); err != nil {
+			t.Errorf("clean prompt: %v", err)
+		}
+	})
+}
@@ -8,9 +8,11 @@ package mcp
 
 type (
 	CallToolRequest                   = ServerRequest[*CallToolParamsRaw]
+	CancelledRequest                  = ServerRequest[*CancelledParams]
 	CompleteRequest                   = ServerRequest[*CompleteParams]
 	GetPromptRequest                  = ServerRequest[*GetPromptParams]
 	InitializedRequest                = ServerRequest[*InitializedParams]
+	InitializeServerRequest           = ServerRequest[*InitializeParams]
 	ListPromptsRequest                = ServerRequest[*ListPromptsParams]
 	ListResourcesRequest              = ServerRequest[*ListResourcesParams]
 	ListResourceTemplatesRequest      = ServerRequest[*ListResourceTemplatesParams]
@@ -41,12 +41,86 @@ type ResourceHandler func(context.Context, *ReadResourceRequest) (*ReadResourceR
 // not be found.
 func ResourceNotFoundError(uri string) error {
 	return &jsonrpc2.WireError{
-		Code:    codeResourceNotFound,
+		Code:    CodeResourceNotFound,
 		Message: "Resource not found",
 		Data:    json.RawMessage(fmt.Sprintf(`{"uri":%q}`, uri)),
 	}
 }
 
+// ResourceFilterKey is the key in the _meta field of resources/list params
+// that holds a [ResourceFilter]. Servers of this SDK return only the
+// resources that match the filter. Other servers may ignore it, so clients
+// that must not see other resources should also check [ResourceFilter.Match].
+const ResourceFilterKey = "go-sdk/resourceFilter"
+
+// A ResourceFilter selects resources to list. See [ResourceFilterKey].
+type ResourceFilter struct {
+	// If non-empty, URIPrefix selects resources whose URI begins with it.
+	URIPrefix string `json:"uriPrefix,omitempty"`
+	// If non-empty, MIMEType selects resources with the given MIME type,
+	// ignoring parameters. A MIME type of the form "type/*" selects all
+	// subtypes of the type.
+	MIMEType string `json:"mimeType,omitempty"`
+}
+
+// Match reports whether r is selected by the filter.
+func (f *ResourceFilter) Match(r *Resource) bool {
+	if !strings.HasPrefix(r.URI, f.URIPrefix) {
+		return false
+	}
+	if f.MIMEType == "" {
+		return true
+	}
+	mt, _, _ := strings.Cut(r.MIMEType, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+	want := strings.ToLower(f.MIMEType)
+	if typ, ok := strings.CutSuffix(want, "/*"); ok {
+		return strings.HasPrefix(mt, typ+"/")
+	}
+	return mt == want
+}
+
+// SetResourceFilter sets a resource filter in the params' Meta field.
+// See [ResourceFilterKey].
+func SetResourceFilter(p *ListResourcesParams, f *ResourceFilter) {
+	m := p.GetMeta()
+	if m == nil {
+		m = map[string]any{}
+		p.SetMeta(m)
+	}
+	v := map[string]any{}
+	if f.URIPrefix != "" {
+		v["uriPrefix"] = f.URIPrefix
+	}
+	if f.MIMEType != "" {
+		v["mimeType"] = f.MIMEType
+	}
+	m[ResourceFilterKey] = v
+}
+
+// ResourceFilterFrom returns the resource filter from the params' Meta
+// field, if there is a valid one. See [ResourceFilterKey].
+func ResourceFilterFrom(p *ListResourcesParams) (*ResourceFilter, bool) {
+	if p == nil {
+		return nil, false
+	}
+	v, ok := p.GetMeta()[ResourceFilterKey].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	var f ResourceFilter
+	for key, ptr := range map[string]*string{"uriPrefix": &f.URIPrefix, "mimeType": &f.MIMEType} {
+		if x, ok := v[key]; ok {
+			s, ok := x.(string)
+			if !ok {
+				return nil, false
+			}
+			*ptr = s
+		}
+	}
+	return &f, true
+}
+
 // readFileResource reads from the filesystem at a URI relative to dirFilepath, respecting
 // the roots.
 // dirFilepath and rootFilepaths are absolute filesystem paths.
@@ -5,8 +5,10 @@
 package mcp
 
 import (
+	"context"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -130,3 +132,64 @@ func TestTemplateMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestResourceFilter(t *testing.T) {
+	server := NewServer(testImpl, &ServerOptions{PageSize: 2})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		for _, r := range []*Resource{
+			{URI: "file:///docs/a.md", MIMEType: "text/markdown"},
+			{URI: "file:///docs/b.txt", MIMEType: "text/plain"},
+			{URI: "file:///docs/c.md", MIMEType: "text/markdown; charset=utf-8"},
+			{URI: "file:///img/d.png", MIMEType: "image/png"},
+			{URI: "file:///notes/e.md", MIMEType: "text/markdown"},
+			{URI: "file:///docs/f.md", MIMEType: "TEXT/MARKDOWN"},
+		} {
+			s.AddResource(r, nil)
+		}
+	})
+	defer cleanup()
+
+	for _, test := range []struct {
+		filter *ResourceFilter
+		want   []string
+	}{
+		{nil, []string{"file:///docs/a.md", "file:///docs/b.txt", "file:///docs/c.md", "file:///docs/f.md", "file:///img/d.png", "file:///notes/e.md"}},
+		{&ResourceFilter{MIMEType: "text/markdown"}, []string{"file:///docs/a.md", "file:///docs/c.md", "file:///docs/f.md", "file:///notes/e.md"}},
+		{&ResourceFilter{URIPrefix: "file:///docs/"}, []string{"file:///docs/a.md", "file:///docs/b.txt", "file:///docs/c.md", "file:///docs/f.md"}},
+		{&ResourceFilter{URIPrefix: "file:///docs/", MIMEType: "text/markdown"}, []string{"file:///docs/a.md", "file:///docs/c.md", "file:///docs/f.md"}},
+		{&ResourceFilter{MIMEType: "image/*"}, []string{"file:///img/d.png"}},
+		{&ResourceFilter{MIMEType: "video/mp4"}, nil},
+	} {
+		params := &ListResourcesParams{}
+		if test.filter != nil {
+			SetResourceFilter(params, test.filter)
+		}
+		var got []string
+		for r, err := range cs.Resources(context.Background(), params) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r.URI)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("filter %+v: got %v, want %v", test.filter, got, test.want)
+		}
+	}
+}
+
+func TestResourceFilterFrom(t *testing.T) {
+	for _, meta := range []Meta{
+		nil,
+		{ResourceFilterKey: "text/plain"},
+		{ResourceFilterKey: map[string]any{"mimeType": 1}},
+	} {
+		if f, ok := ResourceFilterFrom(&ListResourcesParams{Meta: meta}); ok {
+			t.Errorf("ResourceFilterFrom(%v) = %+v, want none", meta, f)
+		}
+	}
+	p := &ListResourcesParams{}
+	SetResourceFilter(p, &ResourceFilter{URIPrefix: "file:///"})
+	if f, ok := ResourceFilterFrom(p); !ok || *f != (ResourceFilter{URIPrefix: "file:///"}) {
+		t.Errorf("ResourceFilterFrom after SetResourceFilter = %+v, %t", f, ok)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// An OverflowPolicy determines what happens to a tool result that exceeds
+// its [ResultLimit].
+type OverflowPolicy int
+
+const (
+	// OverflowError replaces the result with a tool error describing the
+	// overflow.
+	OverflowError OverflowPolicy = iota
+	// OverflowTruncate drops content that does not fit, truncating the text
+	// block that crosses the limit, and appends a text notice describing the
+	// truncation.
+	OverflowTruncate
+	// OverflowSpill replaces the content with the [ResourceLink] returned by
+	// [ResultLimit.Spill].
+	OverflowSpill
+)
+
+// A ResultLimit caps the size of the content of a [CallToolResult].
+//
+// The size of a result is the total size of its Content: the length of text,
+// of image and audio data, and of the text or blob of embedded resources.
+// StructuredContent is not counted, and is never altered.
+type ResultLimit struct {
+	// MaxBytes is the maximum size of the result content.
+	// If zero, results are not limited.
+	MaxBytes int
+	// Policy determines what happens to results larger than MaxBytes.
+	Policy OverflowPolicy
+	// Spill stores an oversized result, for example as a resource on the
+	// server, and returns a link to it. It is required for [OverflowSpill].
+	Spill func(context.Context, *CallToolRequest, *CallToolResult) (*ResourceLink, error)
+}
+
+func (l *ResultLimit) validate() error {
+	switch l.Policy {
+	case OverflowError, OverflowTruncate:
+	case OverflowSpill:
+		if l.Spill == nil {
+			return fmt.Errorf("OverflowSpill requires Spill")
+		}
+	default:
+		return fmt.Errorf("unknown overflow policy %d", l.Policy)
+	}
+	if l.MaxBytes < 0 {
+		return fmt.Errorf("negative MaxBytes %d", l.MaxBytes)
+	}
+	return nil
+}
+
+// resultLimit returns the limit that applies to the named tool, or nil.
+func (s *Server) resultLimit(tool string) *ResultLimit {
+	if l, ok := s.opts.ToolResultLimits[tool]; ok {
+		return l
+	}
+	return s.opts.ResultLimit
+}
+
+// applyResultLimit enforces the limit l on res, returning the result to send.
+func applyResultLimit(ctx context.Context, l *ResultLimit, req *CallToolRequest, res *CallToolResult) (*CallToolResult, error) {
+	if l == nil || l.MaxBytes == 0 || res == nil {
+		return res, nil
+	}
+	size := 0
+	for _, c := range res.Content {
+		size += contentSize(c)
+	}
+	if size <= l.MaxBytes {
+		return res, nil
+	}
+	res2 := *res
+	switch l.Policy {
+	case OverflowError:
+		res2 = CallToolResult{}
+		res2.setError(fmt.Errorf("tool %q result too large: %d bytes exceeds limit of %d", req.Params.Name, size, l.MaxBytes))
+	case OverflowTruncate:
+		res2.Content = truncateContent(res.Content, l.MaxBytes)
+		res2.Content = append(res2.Content, &TextContent{
+			Text: fmt.Sprintf("[result truncated: %d bytes exceeds limit of %d]", size, l.MaxBytes),
+		})
+	case OverflowSpill:
+		link, err := l.Spill(ctx, req, res)
+		if err != nil {
+			return nil, fmt.Errorf("spilling tool %q result: %w", req.Params.Name, err)
+		}
+		res2.Content = []Content{link}
+	}
+	return &res2, nil
+}
+
+// contentSize returns the size of c, for the purpose of result limits.
+func contentSize(c Content) int {
+	switch c := c.(type) {
+	case *TextContent:
+		return len(c.Text)
+	case *ImageContent:
+		return len(c.Data)
+	case *AudioContent:
+		return len(c.Data)
+	case *EmbeddedResource:
+		if c.Resource != nil {
+			return len(c.Resource.Text) + len(c.Resource.Blob)
+		}
+	}
+	return 0
+}
+
+// truncateContent returns the longest prefix of cs that fits in limit bytes.
+// If the first block that doesn't fit is text, it is truncated to fit.
+func truncateContent(cs []Content, limit int) []Content {
+	var out []Content
+	for _, c := range cs {
+		n := contentSize(c)
+		if n <= limit {
+			out = append(out, c)
+			limit -= n
+			continue
+		}
+		if tc, ok := c.(*TextContent); ok && limit > 0 {
+			text := tc.Text[:limit]
+			// Don't split a UTF-8 sequence.
+			for len(text) > 0 && !utf8.RuneStart(tc.Text[len(text)]) {
+				text = text[:len(text)-1]
+			}
+			tc2 := *tc
+			tc2.Text = text
+			out = append(out, &tc2)
+		}
+		break
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestTruncateContent(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		in    []Content
+		limit int
+		want  []Content
+	}{
+		{
+			"fits",
+			[]Content{&TextContent{Text: "abc"}, &TextContent{Text: "de"}},
+			5,
+			[]Content{&TextContent{Text: "abc"}, &TextContent{Text: "de"}},
+		},
+		{
+			"split text",
+			[]Content{&TextContent{Text: "abc"}, &TextContent{Text: "defg"}, &TextContent{Text: "h"}},
+			5,
+			[]Content{&TextContent{Text: "abc"}, &TextContent{Text: "de"}},
+		},
+		{
+			"utf8 boundary",
+			[]Content{&TextContent{Text: "aé"}}, // é is two bytes
+			2,
+			[]Content{&TextContent{Text: "a"}},
+		},
+		{
+			"drop binary",
+			[]Content{&TextContent{Text: "ab"}, &ImageContent{Data: []byte("xyz")}, &TextContent{Text: "c"}},
+			4,
+			[]Content{&TextContent{Text: "ab"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := truncateContent(test.in, test.limit)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResultLimit(t *testing.T) {
+	big := strings.Repeat("x", 100)
+	handler := func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: big}}}, nil
+	}
+	spill := func(_ context.Context, req *CallToolRequest, res *CallToolResult) (*ResourceLink, error) {
+		return &ResourceLink{URI: "spill://" + req.Params.Name, Name: req.Params.Name}, nil
+	}
+	server := NewServer(testImpl, &ServerOptions{
+		ResultLimit: &ResultLimit{MaxBytes: 10},
+		ToolResultLimits: map[string]*ResultLimit{
+			"truncate":  {MaxBytes: 10, Policy: OverflowTruncate},
+			"spill":     {MaxBytes: 10, Policy: OverflowSpill, Spill: spill},
+			"unlimited": nil,
+		},
+	})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		for _, name := range []string{"default", "truncate", "spill", "unlimited"} {
+			s.AddTool(&Tool{Name: name, InputSchema: &jsonschema.Schema{Type: "object"}}, handler)
+		}
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	call := func(name string) *CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := call("default"); !res.IsError {
+		t.Errorf("default: got IsError false, want true")
+	}
+	res := call("truncate")
+	if len(res.Content) != 2 || res.Content[0].(*TextContent).Text != big[:10] {
+		t.Errorf("truncate: got %v", res.Content)
+	} else if notice := res.Content[1].(*TextContent).Text; !strings.Contains(notice, "truncated") {
+		t.Errorf("truncate: got notice %q", notice)
+	}
+	res = call("spill")
+	if diff := cmp.Diff([]Content{&ResourceLink{URI: "spill://spill", Name: "spill"}}, res.Content); diff != "" {
+		t.Errorf("spill mismatch (-want +got):\n%s", diff)
+	}
+	if res := call("unlimited"); res.Content[0].(*TextContent).Text != big {
+		t.Errorf("unlimited: result was altered")
+	}
+}
+
+func TestResultLimitValidation(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewServer with OverflowSpill and no Spill did not panic")
+		}
+	}()
+	NewServer(testImpl, &ServerOptions{ResultLimit: &ResultLimit{MaxBytes: 1, Policy: OverflowSpill}})
+}
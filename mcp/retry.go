@@ -0,0 +1,148 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy decides whether and when a [StreamableClientTransport]
+// retries a failed HTTP request. See [StreamableClientTransport.RetryPolicy].
+//
+// Two kinds of request are retried: GET requests that reconnect a stream,
+// after a network error or an error status; and POST requests, only after a
+// status that means the server did not process the request: 429 Too Many
+// Requests, or 503 Service Unavailable with a Retry-After header. After a
+// network error, or a status such as 502 Bad Gateway or 504 Gateway Timeout,
+// the server may have received the request, and retrying a non-idempotent
+// request such as tools/call could run it twice.
+type RetryPolicy interface {
+	// Retry reports whether to make the given attempt to retry a request,
+	// counting from 1, and how long to wait before it. The previous attempt
+	// failed with either a response with a non-2xx status, or an error. If
+	// the request is a stream whose reconnection has not been attempted yet,
+	// err is the error that interrupted the stream, or [io.EOF] if the server
+	// ended it.
+	//
+	// The body of resp must not be read.
+	Retry(attempt int, resp *http.Response, err error) (delay time.Duration, ok bool)
+}
+
+// DefaultRetryableStatus lists the HTTP status codes retried by a
+// [BackoffPolicy] with no RetryableStatus. POST requests are only retried
+// after some of them; see [RetryPolicy].
+var DefaultRetryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// BackoffPolicy is a [RetryPolicy] with exponential backoff and jitter, that
+// honors Retry-After headers.
+type BackoffPolicy struct {
+	// MaxRetries is the maximum number of consecutive retries of a request.
+	// If zero, 5 is used. To disable retries, use a negative number.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. If zero, one second is
+	// used.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, before jitter. If zero, 30
+	// seconds is used. A Retry-After header may ask for a longer delay.
+	MaxDelay time.Duration
+	// GrowFactor is the factor by which the delay increases after each
+	// retry. If zero, 1.5 is used. Values less than 1 are treated as 1.
+	GrowFactor float64
+	// Jitter is the largest random fraction of the delay to add to it, to
+	// spread out the retries of many clients. If zero, 1 is used, so that the
+	// delay is up to doubled. To disable jitter, use a negative number.
+	Jitter float64
+	// RetryableStatus lists the HTTP status codes to retry. If nil,
+	// [DefaultRetryableStatus] is used. Network errors are always retryable.
+	RetryableStatus []int
+	// IgnoreRetryAfter disables waiting for the delay that the server asks
+	// for with a Retry-After header, when it is longer than the backoff.
+	IgnoreRetryAfter bool
+}
+
+// Retry implements [RetryPolicy].
+func (p *BackoffPolicy) Retry(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt > retries(p.MaxRetries, 5) {
+		return 0, false
+	}
+	if resp != nil {
+		status := p.RetryableStatus
+		if status == nil {
+			status = DefaultRetryableStatus
+		}
+		if !slices.Contains(status, resp.StatusCode) {
+			return 0, false
+		}
+	}
+	b := reconnectPolicy{
+		initialDelay: reconnectInitialDelay,
+		maxDelay:     reconnectMaxDelay,
+		growFactor:   reconnectGrowFactor,
+	}
+	if p.BaseDelay > 0 {
+		b.initialDelay = p.BaseDelay
+	}
+	if p.MaxDelay > 0 {
+		b.maxDelay = p.MaxDelay
+	}
+	if p.GrowFactor != 0 {
+		b.growFactor = max(1, p.GrowFactor)
+	}
+	delay := b.backoff(attempt)
+	jitter := p.Jitter
+	if jitter == 0 {
+		jitter = 1
+	}
+	if jitter > 0 && delay > 0 {
+		delay += time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	if resp != nil && !p.IgnoreRetryAfter {
+		if d, ok := retryAfter(resp, time.Now()); ok {
+			delay = max(delay, d)
+		}
+	}
+	return delay, true
+}
+
+// postRetryable reports whether a POST request that failed with resp may be
+// retried, because its status guarantees that the server did not process it.
+func postRetryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of resp,
+// which is either a number of seconds or a date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(0, t.Sub(now)), true
+	}
+	return 0, false
+}
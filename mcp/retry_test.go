@@ -0,0 +1,154 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoffPolicy(t *testing.T) {
+	status := func(code int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: code, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+	errNet := errors.New("network error")
+	p := &BackoffPolicy{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: 5 * time.Second, GrowFactor: 2, Jitter: -1}
+
+	for _, test := range []struct {
+		name      string
+		policy    *BackoffPolicy
+		attempt   int
+		resp      *http.Response
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{"first", p, 1, nil, time.Second, true},
+		{"growth", p, 3, nil, 4 * time.Second, true},
+		{"cap", p, 4, nil, 5 * time.Second, true},
+		{"exhausted", p, 5, nil, 0, false},
+		{"retryable status", p, 1, status(http.StatusServiceUnavailable, ""), time.Second, true},
+		{"other status", p, 1, status(http.StatusBadRequest, ""), 0, false},
+		{"retry after", p, 1, status(http.StatusTooManyRequests, "10"), 10 * time.Second, true},
+		{"short retry after", p, 2, status(http.StatusTooManyRequests, "1"), 2 * time.Second, true},
+		{"ignore retry after", &BackoffPolicy{BaseDelay: time.Second, Jitter: -1, IgnoreRetryAfter: true}, 1, status(http.StatusTooManyRequests, "10"), time.Second, true},
+		{"custom status", &BackoffPolicy{BaseDelay: time.Second, Jitter: -1, RetryableStatus: []int{http.StatusConflict}}, 1, status(http.StatusConflict, ""), time.Second, true},
+		{"not custom status", &BackoffPolicy{RetryableStatus: []int{http.StatusConflict}}, 1, status(http.StatusServiceUnavailable, ""), 0, false},
+		{"no retries", &BackoffPolicy{MaxRetries: -1}, 1, nil, 0, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var err error
+			if test.resp == nil {
+				err = errNet
+			}
+			delay, ok := test.policy.Retry(test.attempt, test.resp, err)
+			if delay != test.wantDelay || ok != test.wantOK {
+				t.Errorf("Retry(%d) = %v, %t; want %v, %t", test.attempt, delay, ok, test.wantDelay, test.wantOK)
+			}
+		})
+	}
+
+	// With the default jitter, delays are up to doubled.
+	for range 10 {
+		delay, _ := (&BackoffPolicy{BaseDelay: time.Second}).Retry(1, nil, errNet)
+		if delay < time.Second || delay > 2*time.Second {
+			t.Errorf("jittered delay %v, want between 1s and 2s", delay)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	} {
+		resp := &http.Response{Header: http.Header{"Retry-After": {test.header}}}
+		got, ok := retryAfter(resp, now)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %t; want %v, %t", test.header, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestStreamableClientRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, nil)
+	// The server is unavailable for the first two POST requests.
+	var posts, code atomic.Int32
+	code.Store(http.StatusServiceUnavailable)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && posts.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "unavailable", int(code.Load()))
+			return
+		}
+		handler.ServeHTTP(w, req)
+	}))
+	defer httpServer.Close()
+
+	// Without a retry policy, POST requests are not retried.
+	if _, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil); err == nil {
+		t.Fatal("Connect to unavailable server succeeded")
+	}
+
+	posts.Store(0)
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:    httpServer.URL,
+		RetryPolicy: &BackoffPolicy{BaseDelay: time.Millisecond, Jitter: -1},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := posts.Load(); got < 3 {
+		t.Errorf("server received %d POST requests, want at least 3", got)
+	}
+
+	// Retries are bounded.
+	posts.Store(-100)
+	if err := cs.Ping(ctx, nil); err == nil {
+		t.Error("Ping of unavailable server succeeded")
+	}
+
+	// POST requests are not retried after a status that doesn't guarantee
+	// that the server did not process them.
+	posts.Store(3)
+	cs2, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:    httpServer.URL,
+		RetryPolicy: &BackoffPolicy{BaseDelay: time.Millisecond, Jitter: -1},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs2.Close()
+	posts.Store(1)
+	code.Store(http.StatusBadGateway)
+	if err := cs2.Ping(ctx, nil); err == nil {
+		t.Error("Ping through failing gateway succeeded")
+	}
+	if got := posts.Load(); got != 2 {
+		t.Errorf("server received %d POST requests after 502, want 1", got-1)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// rootsCache holds the roots of a client, as reported by "roots/list".
+type rootsCache struct {
+	mu    sync.Mutex
+	roots []*Root
+	valid bool
+	gen   uint64 // incremented on each invalidation
+}
+
+// invalidate discards the cached roots.
+func (c *rootsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roots = nil
+	c.valid = false
+	c.gen++
+}
+
+// Roots returns the client's roots.
+//
+// If the client supports notifications of changes to its roots, the roots are
+// cached: they are fetched from the client with [ServerSession.ListRoots] the
+// first time Roots is called, and again after the client reports a change.
+// The cache is invalidated before [ServerOptions.RootsListChangedHandler] is
+// called, so the handler can call Roots to obtain the new roots. If the client
+// doesn't report changes, Roots calls ListRoots every time.
+//
+// The caller must not modify the returned roots.
+func (ss *ServerSession) Roots(ctx context.Context) ([]*Root, error) {
+	cacheable := false
+	if params := ss.InitializeParams(); params != nil && params.Capabilities != nil {
+		cacheable = params.Capabilities.Roots.ListChanged
+	}
+	c := &ss.roots
+	c.mu.Lock()
+	if cacheable && c.valid {
+		defer c.mu.Unlock()
+		return slices.Clone(c.roots), nil
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	res, err := ss.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		c.mu.Lock()
+		// Don't cache roots that may have changed while we fetched them.
+		if c.gen == gen {
+			c.roots = res.Roots
+			c.valid = true
+		}
+		c.mu.Unlock()
+	}
+	return slices.Clone(res.Roots), nil
+}
+
+// ContainingRoot returns the client root that contains the absolute file
+// path, or nil if no root does. Roots that are not file URIs are ignored.
+//
+// ContainingRoot checks path lexically, after cleaning it; see
+// [filepath.Clean]. It does not resolve symbolic links.
+func (ss *ServerSession) ContainingRoot(ctx context.Context, path string) (*Root, error) {
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path %q is not absolute", path)
+	}
+	roots, err := ss.Roots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roots {
+		dir, err := fileRoot(r)
+		if err != nil {
+			continue
+		}
+		if underDir(dir, path) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// underDir reports whether the absolute path is dir or is within it.
+func underDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// ErrOutsideRoots is returned by [ServerSession.ResolvePath] for a path that
+// is not within any of the client's roots.
+var ErrOutsideRoots = errors.New("path is outside the client's roots")
+
+// ResolvePath checks that a file path or "file" URI, such as a tool
+// argument, refers to a location within one of the client's roots, and
+// returns its absolute file path with symbolic links resolved. Filesystem
+// tools should operate on the returned path.
+//
+// The path must be absolute. Symbolic links are resolved in both the path
+// and the roots, so a link within a root that points outside of it is
+// rejected, as is a path that escapes its root with ".." elements. The path
+// need not exist: links are resolved in its longest existing prefix.
+//
+// If the path is not within a root, ResolvePath returns an error wrapping
+// [ErrOutsideRoots]. If the client has no file roots, every path is outside
+// of them.
+func (ss *ServerSession) ResolvePath(ctx context.Context, pathOrURI string) (string, error) {
+	path := pathOrURI
+	if u, err := url.Parse(pathOrURI); err == nil && u.Scheme == "file" {
+		path = filepath.FromSlash(u.Path)
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("path %q is not absolute", pathOrURI)
+	}
+	resolved, err := resolveExisting(path)
+	if err != nil {
+		return "", err
+	}
+	roots, err := ss.Roots(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing roots: %w", err)
+	}
+	for _, r := range roots {
+		dir, err := fileRoot(r)
+		if err != nil {
+			continue
+		}
+		if dir, err = resolveExisting(dir); err != nil {
+			continue
+		}
+		if underDir(dir, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%q: %w", pathOrURI, ErrOutsideRoots)
+}
+
+// resolveExisting returns the cleaned absolute path with symbolic links
+// resolved in its longest existing prefix. It fails if the path passes
+// through a dangling link.
+func resolveExisting(path string) (string, error) {
+	path = filepath.Clean(path)
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		// A dangling link can't be resolved, but creating a file through it
+		// would create its target.
+		if _, lerr := os.Lstat(path); lerr == nil {
+			return "", fmt.Errorf("%s: dangling symbolic link", path)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRootsCache(t *testing.T) {
+	client := NewClient(testImpl, nil)
+	var lists atomic.Int32
+	client.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodListRoots {
+				lists.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	root := t.TempDir()
+	client.AddRoots(&Root{URI: "file://" + filepath.ToSlash(root)})
+
+	changed := make(chan []*Root, 1)
+	server := NewServer(testImpl, &ServerOptions{
+		RootsListChangedHandler: func(ctx context.Context, req *RootsListChangedRequest) {
+			// The cache is invalid by the time the handler runs.
+			roots, err := req.Session.Roots(ctx)
+			if err != nil {
+				t.Error(err)
+			}
+			changed <- roots
+		},
+	})
+	_, ss, cleanup := basicClientServerConnection(t, client, server, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	for range 2 {
+		roots, err := ss.Roots(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(roots) != 1 {
+			t.Fatalf("got %d roots, want 1", len(roots))
+		}
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("roots/list called %d times, want 1", got)
+	}
+
+	r, err := ss.ContainingRoot(ctx, filepath.Join(root, "a", "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil {
+		t.Error("path under root: got nil root")
+	}
+	for _, path := range []string{filepath.Join(root, "..", "x"), filepath.Dir(root)} {
+		if r, err := ss.ContainingRoot(ctx, path); err != nil || r != nil {
+			t.Errorf("ContainingRoot(%q) = %v, %v, want nil, nil", path, r, err)
+		}
+	}
+	if _, err := ss.ContainingRoot(ctx, "relative"); err == nil {
+		t.Error("relative path: got nil error")
+	}
+
+	other := t.TempDir()
+	client.AddRoots(&Root{URI: "file://" + filepath.ToSlash(other)})
+	if roots := <-changed; len(roots) != 2 {
+		t.Errorf("after change: got %d roots, want 2", len(roots))
+	}
+	if r, err := ss.ContainingRoot(ctx, filepath.Join(other, "f")); err != nil || r == nil {
+		t.Errorf("new root: got %v, %v, want root", r, err)
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("roots/list called %d times, want 2", got)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	mustSymlink := func(target, link string) {
+		t.Helper()
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mustSymlink(outside, filepath.Join(root, "escape"))
+	mustSymlink(filepath.Join(root, "sub"), filepath.Join(root, "inside"))
+	mustSymlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling"))
+
+	client := NewClient(testImpl, nil)
+	client.AddRoots(&Root{URI: "file://" + filepath.ToSlash(root)})
+	_, ss, cleanup := basicClientServerConnection(t, client, nil, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path string
+		want string // empty if outside the roots
+	}{
+		{filepath.Join(root, "sub", "f.txt"), filepath.Join(realRoot, "sub", "f.txt")},
+		{"file://" + filepath.ToSlash(filepath.Join(root, "new", "f.txt")), filepath.Join(realRoot, "new", "f.txt")},
+		{filepath.Join(root, "inside", "f.txt"), filepath.Join(realRoot, "sub", "f.txt")},
+		{root, realRoot},
+		{filepath.Join(root, "..", filepath.Base(outside)), ""},
+		{filepath.Join(root, "escape", "f.txt"), ""},
+		{outside, ""},
+	} {
+		got, err := ss.ResolvePath(ctx, test.path)
+		if test.want == "" {
+			if !errors.Is(err, ErrOutsideRoots) {
+				t.Errorf("ResolvePath(%q) = %q, %v, want ErrOutsideRoots", test.path, got, err)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ResolvePath(%q) = %q, %v, want %q", test.path, got, err, test.want)
+		}
+	}
+	if _, err := ss.ResolvePath(ctx, filepath.Join(root, "dangling")); err == nil {
+		t.Error("dangling link: got nil error")
+	}
+	if _, err := ss.ResolvePath(ctx, "relative/path"); err == nil {
+		t.Error("relative path: got nil error")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// An ArgumentSanitizer normalizes or rejects the arguments of a tool call.
+// See [ServerOptions.SanitizeArguments].
+//
+// The args map holds the validated arguments, with schema defaults applied.
+// The sanitizer may modify it in place, for example to trim strings or to
+// canonicalize paths against the client's roots. To reject the call, it
+// returns an error, typically an [*ArgumentError].
+type ArgumentSanitizer func(ctx context.Context, req *CallToolRequest, args map[string]any) error
+
+// An ArgumentError reports an invalid tool argument.
+//
+// When returned by an [ArgumentSanitizer], it is sent to the client as an
+// "invalid params" error whose data identifies the argument.
+type ArgumentError struct {
+	// Name is the name of the offending argument.
+	Name string
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("argument %q: %s", e.Name, e.Reason)
+}
+
+// sanitizeArguments runs the sanitizer on the JSON object data, and returns
+// the resulting JSON.
+func sanitizeArguments(ctx context.Context, sanitize ArgumentSanitizer, req *CallToolRequest, data json.RawMessage) (json.RawMessage, error) {
+	args := make(map[string]any)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &args); err != nil {
+			return nil, fmt.Errorf("%w: %v", jsonrpc2.ErrInvalidParams, err)
+		}
+	}
+	if err := sanitize(ctx, req, args); err != nil {
+		var aerr *ArgumentError
+		if errors.As(err, &aerr) {
+			data, _ := json.Marshal(map[string]string{"argument": aerr.Name, "reason": aerr.Reason})
+			return nil, &jsonrpc2.WireError{
+				Code:    CodeInvalidParams,
+				Message: fmt.Sprintf("tool %q: %v", req.Params.Name, aerr),
+				Data:    data,
+			}
+		}
+		var werr *jsonrpc2.WireError
+		if errors.As(err, &werr) {
+			return nil, werr
+		}
+		return nil, fmt.Errorf("%w: tool %q: %v", jsonrpc2.ErrInvalidParams, req.Params.Name, err)
+	}
+	return json.Marshal(args)
+}
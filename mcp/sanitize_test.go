@@ -0,0 +1,57 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+func TestSanitizeArguments(t *testing.T) {
+	sanitize := func(_ context.Context, req *CallToolRequest, args map[string]any) error {
+		name, _ := args["Name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "root" {
+			return &ArgumentError{Name: "Name", Reason: "reserved"}
+		}
+		args["Name"] = name
+		return nil
+	}
+	server := NewServer(testImpl, &ServerOptions{SanitizeArguments: sanitize})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		AddTool(s, greetTool(), sayHi)
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "  user "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Content[0].(*TextContent).Text, "hi user"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "root"}})
+	var werr *jsonrpc2.WireError
+	if !errors.As(err, &werr) {
+		t.Fatalf("got %v, want a WireError", err)
+	}
+	if werr.Code != CodeInvalidParams {
+		t.Errorf("got code %d, want %d", werr.Code, CodeInvalidParams)
+	}
+	var data map[string]string
+	if err := json.Unmarshal(werr.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data["argument"] != "Name" || data["reason"] != "reserved" {
+		t.Errorf("got data %v, want argument and reason", data)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// A PointerPolicy controls how pointer-typed struct fields are represented
+// in schemas inferred by [AddTool].
+//
+// By default, a pointer field accepts JSON null, and is required unless its
+// JSON tag has the "omitempty" or "omitzero" option, just like the
+// corresponding non-pointer field. Tools that must distinguish an absent
+// argument from its zero value can use [PointerOptional] or
+// [PointerOptionalNullable].
+type PointerPolicy int
+
+const (
+	// PointerNullable makes pointer fields accept JSON null, with no effect
+	// on whether they are required. This is the default.
+	PointerNullable PointerPolicy = iota + 1
+	// PointerOptional makes pointer fields optional, and does not accept
+	// JSON null: a missing property is the only way to leave a pointer nil.
+	PointerOptional
+	// PointerOptionalNullable makes pointer fields optional, and accept
+	// JSON null.
+	PointerOptionalNullable
+)
+
+// An UnknownFieldPolicy controls whether input schemas inferred by [AddTool]
+// accept properties that do not correspond to a struct field.
+//
+// By default, inferred schemas for structs set "additionalProperties" to
+// false, so that a call with an unknown argument is rejected. Servers whose
+// clients send extra metadata alongside the arguments can use
+// [UnknownFieldsAllow]. Unknown fields are then ignored when the arguments
+// are unmarshaled.
+type UnknownFieldPolicy int
+
+const (
+	// UnknownFieldsReject rejects input with unknown properties.
+	// This is the default.
+	UnknownFieldsReject UnknownFieldPolicy = iota + 1
+	// UnknownFieldsAllow accepts input with unknown properties.
+	UnknownFieldsAllow
+)
+
+// inferOptions configures inference of schemas from Go types.
+type inferOptions struct {
+	forOpts       *jsonschema.ForOptions
+	pointers      PointerPolicy
+	unknownFields UnknownFieldPolicy // applies only to input schemas
+}
+
+// inferSchema returns the schema for rt.
+func inferSchema(rt reflect.Type, opts *inferOptions) (*jsonschema.Schema, error) {
+	forOpts := opts.forOpts
+	if forOpts == nil {
+		// TODO: we should be able to pass nil opts here.
+		forOpts = &jsonschema.ForOptions{}
+	}
+	s, err := jsonschema.ForType(rt, forOpts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.pointers > PointerNullable {
+		walkStructFields(rt, s, func(f reflect.StructField, name string, parent, prop *jsonschema.Schema) {
+			if f.Type.Kind() != reflect.Pointer {
+				return
+			}
+			parent.Required = slices.DeleteFunc(parent.Required, func(r string) bool { return r == name })
+			if len(parent.Required) == 0 {
+				parent.Required = nil
+			}
+			if opts.pointers == PointerOptional {
+				if i := slices.Index(prop.Types, "null"); i >= 0 && len(prop.Types) == 2 {
+					prop.Type = prop.Types[1-i]
+					prop.Types = nil
+				}
+			}
+		})
+	}
+	if opts.unknownFields == UnknownFieldsAllow {
+		walkStructSchemas(rt, s, func(s *jsonschema.Schema) {
+			s.AdditionalProperties = nil
+		})
+	}
+	return s, nil
+}
+
+// walkStructSchemas calls f for each schema within s that was inferred from a
+// struct type, including s itself.
+func walkStructSchemas(rt reflect.Type, s *jsonschema.Schema, f func(*jsonschema.Schema)) {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if s == nil {
+		return
+	}
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		walkStructSchemas(rt.Elem(), s.Items, f)
+	case reflect.Map:
+		walkStructSchemas(rt.Elem(), s.AdditionalProperties, f)
+	case reflect.Struct:
+		f(s)
+		for _, field := range reflect.VisibleFields(rt) {
+			if name, ok := jsonFieldName(field); ok {
+				walkStructSchemas(field.Type, s.Properties[name], f)
+			}
+		}
+	}
+}
+
+// walkStructFields calls f for each property of s that was inferred from
+// a struct field of rt, recursively.
+// parent is the schema holding the property, and prop is the property schema.
+func walkStructFields(rt reflect.Type, s *jsonschema.Schema, f func(field reflect.StructField, name string, parent, prop *jsonschema.Schema)) {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if s == nil {
+		return
+	}
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		walkStructFields(rt.Elem(), s.Items, f)
+	case reflect.Map:
+		walkStructFields(rt.Elem(), s.AdditionalProperties, f)
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(rt) {
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			prop := s.Properties[name]
+			if prop == nil {
+				continue // e.g. the type has a custom schema
+			}
+			f(field, name, s, prop)
+			walkStructFields(field.Type, prop, f)
+		}
+	}
+}
+
+// jsonFieldName returns the name of the JSON property for field, and reports
+// whether field is encoded as a property of its own.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if field.Anonymous || !field.IsExported() {
+		return "", false
+	}
+	name := field.Name
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		n, _, found := strings.Cut(tag, ",")
+		if n == "-" && !found {
+			return "", false
+		}
+		if n != "" {
+			name = n
+		}
+	}
+	return name, true
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestInferSchemaPointerPolicy(t *testing.T) {
+	type inner struct {
+		P *int `json:"p"`
+	}
+	type args struct {
+		Name  string  `json:"name"`
+		Limit *int    `json:"limit"`
+		Inner []inner `json:"inner"`
+	}
+	rt := reflect.TypeFor[args]()
+	nullableInt := &jsonschema.Schema{Types: []string{"null", "integer"}}
+	intSchema := &jsonschema.Schema{Type: "integer"}
+	for _, test := range []struct {
+		policy       PointerPolicy
+		wantRequired []string
+		wantLimit    *jsonschema.Schema
+		wantInner    *jsonschema.Schema
+	}{
+		{
+			0,
+			[]string{"name", "limit", "inner"},
+			nullableInt,
+			&jsonschema.Schema{Type: "object", Required: []string{"p"}, Properties: map[string]*jsonschema.Schema{"p": nullableInt}},
+		},
+		{
+			PointerOptional,
+			[]string{"name", "inner"},
+			intSchema,
+			&jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"p": intSchema}},
+		},
+		{
+			PointerOptionalNullable,
+			[]string{"name", "inner"},
+			nullableInt,
+			&jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"p": nullableInt}},
+		},
+	} {
+		s, err := inferSchema(rt, &inferOptions{pointers: test.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.wantRequired, s.Required); diff != "" {
+			t.Errorf("policy %d: required mismatch (-want +got):\n%s", test.policy, diff)
+		}
+		if diff := cmp.Diff(test.wantLimit, s.Properties["limit"]); diff != "" {
+			t.Errorf("policy %d: limit mismatch (-want +got):\n%s", test.policy, diff)
+		}
+		gotInner := s.Properties["inner"].Items
+		gotInner.AdditionalProperties = nil // not under test
+		if diff := cmp.Diff(test.wantInner, gotInner); diff != "" {
+			t.Errorf("policy %d: inner mismatch (-want +got):\n%s", test.policy, diff)
+		}
+	}
+}
+
+func TestAddToolPointerFields(t *testing.T) {
+	type args struct {
+		Limit *int `json:"limit"`
+	}
+	var got *int
+	cs, _, cleanup := basicClientServerConnection(t, nil, NewServer(testImpl, &ServerOptions{PointerFields: PointerOptional}), func(s *Server) {
+		AddTool(s, &Tool{Name: "list"}, func(_ context.Context, _ *CallToolRequest, in args) (*CallToolResult, any, error) {
+			got = in.Limit
+			return nil, nil, nil
+		})
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	// An absent limit is now valid, and distinguishable from zero.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "list", Arguments: map[string]any{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("absent limit: got %d, want nil", *got)
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "list", Arguments: map[string]any{"limit": 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || *got != 0 {
+		t.Errorf("zero limit: got %v, want pointer to 0", got)
+	}
+	// null is rejected with PointerOptional.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "list", Arguments: map[string]any{"limit": nil}}); err == nil {
+		t.Error("null limit: got nil error")
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	type inner struct {
+		N int `json:"n"`
+	}
+	type args struct {
+		Name  string  `json:"name"`
+		Inner *inner  `json:"inner,omitempty"`
+		List  []inner `json:"list,omitempty"`
+	}
+	cs, _, cleanup := basicClientServerConnection(t, nil, NewServer(testImpl, &ServerOptions{UnknownFields: UnknownFieldsAllow}), func(s *Server) {
+		handler := func(context.Context, *CallToolRequest, args) (*CallToolResult, inner, error) {
+			return nil, inner{N: 1}, nil
+		}
+		AddTool(s, &Tool{Name: "lenient"}, handler)
+		AddToolWithOptions(s, &Tool{Name: "strict"}, handler, &AddToolOptions{UnknownFields: UnknownFieldsReject})
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name != "lenient" {
+			continue
+		}
+		if got := tool.OutputSchema.(map[string]any)["additionalProperties"]; got != false {
+			t.Errorf("output schema additionalProperties: got %v, want false", got)
+		}
+	}
+
+	extra := map[string]any{
+		"name":  "x",
+		"inner": map[string]any{"n": 1, "trace": "t1"},
+		"list":  []any{map[string]any{"n": 2, "trace": "t2"}},
+		"meta":  "m",
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "lenient", Arguments: extra}); err != nil {
+		t.Errorf("lenient tool: %v", err)
+	}
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "strict", Arguments: extra})
+	if got := errorCode(err); got != CodeInvalidParams {
+		t.Errorf("strict tool: got code %d, want %d (err: %v)", got, CodeInvalidParams, err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// This file holds the experimental resource search extension: a method that
+// lets clients search the resources of servers with large document corpora,
+// rather than listing them all.
+
+const (
+	// methodSearchResources is the method of the resource search extension.
+	methodSearchResources = "go-sdk/resources/search"
+
+	// ResourceSearchCapability is the key in [ServerCapabilities.Experimental]
+	// that servers supporting resource search advertise. See
+	// [ServerOptions.ResourceSearcher].
+	ResourceSearchCapability = "go-sdk/resourceSearch"
+)
+
+// SearchResourcesParams are the params of a resource search.
+//
+// Resource search is an experimental extension of this SDK; it is not part
+// of the MCP specification.
+type SearchResourcesParams struct {
+	// This property is reserved by the protocol to allow clients and servers to
+	// attach additional metadata to their responses.
+	Meta `json:"_meta,omitempty"`
+	// Query is the search query. Its interpretation is up to the server's
+	// [ResourceSearcher].
+	Query string `json:"query"`
+	// An opaque token representing the current pagination position. If provided,
+	// the server should return results starting after this cursor.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+func (x *SearchResourcesParams) isParams()              {}
+func (x *SearchResourcesParams) GetProgressToken() any  { return getProgressToken(x) }
+func (x *SearchResourcesParams) SetProgressToken(t any) { setProgressToken(x, t) }
+func (x *SearchResourcesParams) cursorPtr() *string     { return &x.Cursor }
+
+// SearchResourcesResult is the result of a resource search.
+type SearchResourcesResult struct {
+	// This property is reserved by the protocol to allow clients and servers to
+	// attach additional metadata to their responses.
+	Meta `json:"_meta,omitempty"`
+	// An opaque token representing the pagination position after the last returned
+	// result. If present, there may be more results available.
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Resources holds the matching resources, in an order chosen by the
+	// server.
+	Resources []*Resource `json:"resources"`
+}
+
+func (x *SearchResourcesResult) isResult()              {}
+func (x *SearchResourcesResult) nextCursorPtr() *string { return &x.NextCursor }
+
+// SearchResourcesRequest is a request to search resources.
+type SearchResourcesRequest = ServerRequest[*SearchResourcesParams]
+
+// A ResourceSearcher is a backend for the resource search extension.
+// Set [ServerOptions.ResourceSearcher] to enable the extension on a server.
+type ResourceSearcher interface {
+	SearchResources(context.Context, *SearchResourcesRequest) (*SearchResourcesResult, error)
+}
+
+// SubstringResourceSearcher is a [ResourceSearcher] that searches the
+// resources added to the server with [Server.AddResource]. A resource matches
+// if each word of the query occurs in its URI, name, title or description,
+// ignoring case. Results are in the order of resources/list, and are paginated
+// like it.
+type SubstringResourceSearcher struct{}
+
+// SearchResources implements [ResourceSearcher].
+func (SubstringResourceSearcher) SearchResources(_ context.Context, req *SearchResourcesRequest) (*SearchResourcesResult, error) {
+	words := strings.Fields(strings.ToLower(req.Params.Query))
+	s := req.Session.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keep := func(r *serverResource) bool {
+		text := strings.ToLower(strings.Join([]string{r.resource.URI, r.resource.Name, r.resource.Title, r.resource.Description}, "\n"))
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return paginateFilteredList(s.resources, s.opts.PageSize, req.Params, &SearchResourcesResult{}, keep, func(res *SearchResourcesResult, resources []*serverResource) {
+		res.Resources = []*Resource{} // avoid JSON null
+		for _, r := range resources {
+			res.Resources = append(res.Resources, r.resource)
+		}
+	})
+}
+
+func (s *Server) searchResources(ctx context.Context, req *SearchResourcesRequest) (*SearchResourcesResult, error) {
+	if s.opts.ResourceSearcher == nil {
+		return nil, jsonrpc2.ErrMethodNotFound
+	}
+	return s.opts.ResourceSearcher.SearchResources(ctx, req)
+}
+
+// SearchResources searches the resources of the server, using the
+// experimental resource search extension. The server must support it; see
+// [ResourceSearchCapability].
+func (cs *ClientSession) SearchResources(ctx context.Context, params *SearchResourcesParams) (*SearchResourcesResult, error) {
+	return handleSend[*SearchResourcesResult](ctx, methodSearchResources, newClientRequest(cs, orZero[Params](params)))
+}
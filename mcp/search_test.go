@@ -0,0 +1,76 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSearchResources(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, &ServerOptions{PageSize: 1, ResourceSearcher: SubstringResourceSearcher{}})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		for _, r := range []*Resource{
+			{URI: "file:///docs/install.md", Name: "install", Description: "How to install the server"},
+			{URI: "file:///docs/usage.md", Name: "usage", Title: "Server Usage"},
+			{URI: "file:///src/main.go", Name: "main"},
+		} {
+			s.AddResource(r, nil)
+		}
+	})
+	defer cleanup()
+
+	if _, ok := cs.InitializeResult().Capabilities.Experimental[ResourceSearchCapability]; !ok {
+		t.Errorf("server does not advertise %s", ResourceSearchCapability)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		var uris []string
+		params := &SearchResourcesParams{Query: query}
+		for {
+			res, err := cs.SearchResources(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range res.Resources {
+				uris = append(uris, r.URI)
+			}
+			if res.NextCursor == "" {
+				return uris
+			}
+			params.Cursor = res.NextCursor
+		}
+	}
+	for _, test := range []struct {
+		query string
+		want  []string
+	}{
+		{"server", []string{"file:///docs/install.md", "file:///docs/usage.md"}},
+		{"SERVER usage", []string{"file:///docs/usage.md"}},
+		{"docs", []string{"file:///docs/install.md", "file:///docs/usage.md"}},
+		{".go", []string{"file:///src/main.go"}},
+		{"missing", nil},
+		{"", []string{"file:///docs/install.md", "file:///docs/usage.md", "file:///src/main.go"}},
+	} {
+		if got := search(test.query); !slices.Equal(got, test.want) {
+			t.Errorf("search %q: got %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchResourcesUnsupported(t *testing.T) {
+	cs, _, cleanup := basicClientServerConnection(t, nil, nil, nil)
+	defer cleanup()
+	if _, ok := cs.InitializeResult().Capabilities.Experimental[ResourceSearchCapability]; ok {
+		t.Errorf("server advertises %s without a searcher", ResourceSearchCapability)
+	}
+	_, err := cs.SearchResources(context.Background(), &SearchResourcesParams{Query: "x"})
+	if code, _ := ErrorCode(err); code != CodeMethodNotFound {
+		t.Errorf("SearchResources without a searcher: got %v, want method not found", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// InMemoryTransportOptions configures transports created by
+// [NewInMemoryTransportsWithOptions].
+type InMemoryTransportOptions struct {
+	// Sequencer, if set, controls delivery of messages between the two
+	// transports. Each message written by either side is held by the
+	// Sequencer until it is released by [InMemorySequencer.Step] or
+	// [InMemorySequencer.Flush], and messages are released in the order they
+	// were written.
+	//
+	// A Sequencer may be used by only one pair of transports.
+	Sequencer *InMemorySequencer
+}
+
+// NewInMemoryTransportsWithOptions is like [NewInMemoryTransports], but
+// accepts options. If opts is nil or has no Sequencer, it is equivalent to
+// NewInMemoryTransports.
+func NewInMemoryTransportsWithOptions(opts *InMemoryTransportOptions) (*InMemoryTransport, *InMemoryTransport) {
+	if opts == nil || opts.Sequencer == nil {
+		return NewInMemoryTransports()
+	}
+	c1 := &sequencedConn{seq: opts.Sequencer, avail: make(chan struct{}, 1), closed: make(chan struct{})}
+	c2 := &sequencedConn{seq: opts.Sequencer, avail: make(chan struct{}, 1), closed: make(chan struct{})}
+	c1.peer, c2.peer = c2, c1
+	return &InMemoryTransport{conn: c1}, &InMemoryTransport{conn: c2}
+}
+
+// An InMemorySequencer serializes delivery of messages between two in-memory
+// transports, so that tests can control exactly when each message is seen by
+// its recipient. See [InMemoryTransportOptions.Sequencer].
+//
+// Since no message is delivered until it is released, the client's
+// initialization handshake requires three steps: the initialize request, its
+// response, and the initialized notification. Tests should therefore connect
+// the client concurrently with stepping the sequencer.
+//
+// The zero value is ready to use.
+type InMemorySequencer struct {
+	mu      sync.Mutex
+	pending []sequencedMessage // in write order
+	ready   chan struct{}      // closed when pending becomes non-empty; lazily allocated
+}
+
+type sequencedMessage struct {
+	to   *sequencedConn
+	data []byte
+}
+
+// Pending reports the number of messages that have been written but not yet
+// released.
+func (s *InMemorySequencer) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Step releases the oldest pending message to its recipient and returns it,
+// waiting for a message to be written if none is pending. It returns an error
+// only if ctx is done first.
+//
+// A message whose recipient has been closed is discarded, but is still
+// returned.
+func (s *InMemorySequencer) Step(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			m := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+			return m.deliver()
+		}
+		if s.ready == nil {
+			s.ready = make(chan struct{})
+		}
+		ready := s.ready
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// Flush releases all pending messages, in order, and returns them. It does
+// not wait for further messages: messages written in response to the
+// released ones remain pending.
+func (s *InMemorySequencer) Flush() ([]jsonrpc.Message, error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	var msgs []jsonrpc.Message
+	for _, m := range pending {
+		msg, err := m.deliver()
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (s *InMemorySequencer) add(m sequencedMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, m)
+	if s.ready != nil {
+		close(s.ready)
+		s.ready = nil
+	}
+}
+
+// deliver decodes the message and hands it to its recipient. The recipient
+// and the caller receive separately decoded copies, as they would over a
+// real connection.
+func (m sequencedMessage) deliver() (jsonrpc.Message, error) {
+	msg, err := jsonrpc.DecodeMessage(m.data)
+	if err != nil {
+		return nil, err
+	}
+	in, err := jsonrpc.DecodeMessage(m.data)
+	if err != nil {
+		return nil, err
+	}
+	m.to.receive(in)
+	return msg, nil
+}
+
+// A sequencedConn is one end of a pair of in-memory connections whose
+// messages are routed through an [InMemorySequencer].
+type sequencedConn struct {
+	seq  *InMemorySequencer
+	peer *sequencedConn
+
+	mu    sync.Mutex
+	inbox []jsonrpc.Message
+	avail chan struct{} // signaled (non-blocking) when inbox grows
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *sequencedConn) SessionID() string { return "" }
+
+func (c *sequencedConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, io.EOF
+		default:
+		}
+		c.mu.Lock()
+		if len(c.inbox) > 0 {
+			msg := c.inbox[0]
+			c.inbox = c.inbox[1:]
+			c.mu.Unlock()
+			return msg, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, io.EOF
+		case <-c.avail:
+		}
+	}
+}
+
+func (c *sequencedConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closed:
+		return ErrConnectionClosed
+	case <-c.peer.closed:
+		return ErrConnectionClosed
+	default:
+	}
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	c.seq.add(sequencedMessage{to: c.peer, data: data})
+	return nil
+}
+
+// Close closes both ends of the connection, as with [net.Pipe].
+func (c *sequencedConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.peer.closeOnce.Do(func() { close(c.peer.closed) })
+	return nil
+}
+
+func (c *sequencedConn) receive(msg jsonrpc.Message) {
+	select {
+	case <-c.closed:
+		return
+	default:
+	}
+	c.mu.Lock()
+	c.inbox = append(c.inbox, msg)
+	c.mu.Unlock()
+	select {
+	case c.avail <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// messageMethod returns the method of a request or notification, or "response".
+func messageMethod(msg jsonrpc.Message) string {
+	if req, ok := msg.(*jsonrpc.Request); ok {
+		return req.Method
+	}
+	return "response"
+}
+
+func TestInMemorySequencer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	seq := new(InMemorySequencer)
+	ct, st := NewInMemoryTransportsWithOptions(&InMemoryTransportOptions{Sequencer: seq})
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "tool"}, sayHi)
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+
+	var toolsChanged []string
+	client := NewClient(testImpl, &ClientOptions{
+		ToolListChangedHandler: func(context.Context, *ToolListChangedRequest) {
+			toolsChanged = append(toolsChanged, "changed")
+		},
+		CreateMessageHandler: func(context.Context, *CreateMessageRequest) (*CreateMessageResult, error) {
+			return &CreateMessageResult{Content: &TextContent{}}, nil
+		},
+	})
+	type result struct {
+		cs  *ClientSession
+		err error
+	}
+	connected := make(chan result, 1)
+	go func() {
+		cs, err := client.Connect(ctx, ct, nil)
+		connected <- result{cs, err}
+	}()
+
+	// Nothing is delivered until the sequencer steps.
+	var got []string
+	for range 3 {
+		msg, err := seq.Step(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, messageMethod(msg))
+	}
+	want := []string{methodInitialize, "response", notificationInitialized}
+	if !slices.Equal(got, want) {
+		t.Fatalf("initialization messages: got %v, want %v", got, want)
+	}
+	r := <-connected
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	defer r.cs.Close()
+
+	// Removing the tool and then sampling writes a notification followed by a
+	// request, and the sequencer must release them in that order.
+	server.RemoveTools("tool")
+	done := make(chan error, 1)
+	go func() {
+		_, err := ss.CreateMessage(ctx, new(CreateMessageParams))
+		done <- err
+	}()
+	msg, err := seq.Step(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := messageMethod(msg); got != notificationToolListChanged {
+		t.Fatalf("first step: got %q, want %q", got, notificationToolListChanged)
+	}
+	msg, err = seq.Step(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := messageMethod(msg); got != methodCreateMessage {
+		t.Fatalf("second step: got %q, want %q", got, methodCreateMessage)
+	}
+	if _, err := seq.Step(ctx); err != nil { // the response
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("CreateMessage failed: %v", err)
+	}
+	if len(toolsChanged) != 1 {
+		t.Errorf("got %d tool list change notifications, want 1", len(toolsChanged))
+	}
+	if n := seq.Pending(); n != 0 {
+		t.Errorf("Pending() = %d, want 0", n)
+	}
+
+	// Flush releases everything pending, and nothing more.
+	server.RemoveTools("missing")
+	msgs, err := seq.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("Flush() released %d messages, want 0", len(msgs))
+	}
+	AddTool(server, &Tool{Name: "tool2"}, sayHi)
+	msgs, err = seq.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || messageMethod(msgs[0]) != notificationToolListChanged {
+		t.Errorf("Flush() released %v, want one %s notification", msgs, notificationToolListChanged)
+	}
+
+	// Step waits for a message, and honors the context.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer shortCancel()
+	if _, err := seq.Step(shortCtx); err == nil {
+		t.Error("Step with no pending messages succeeded, want context error")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// A StreamableServeMux serves multiple [Server]s over the streamable HTTP
+// transport, each mounted at its own URL path.
+//
+// Each server is served by its own [StreamableHTTPHandler], but all handlers
+// share the same [StreamableHTTPOptions], including any [EventStore] and
+// [SessionStore]. Sessions belong to the handler that created them: a
+// request for a session at a path other than the one it was created at is
+// answered with 404 Not Found. Sessions saved in a shared SessionStore record
+// the pattern of their handler (see [StoredSessionInfo.Route]), so that they
+// are only recovered by the handler mounted at the same pattern.
+type StreamableServeMux struct {
+	opts StreamableHTTPOptions
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	handlers []*StreamableHTTPHandler
+}
+
+// NewStreamableServeMux returns a new [StreamableServeMux] whose handlers use
+// the given options.
+func NewStreamableServeMux(opts *StreamableHTTPOptions) *StreamableServeMux {
+	m := &StreamableServeMux{mux: http.NewServeMux()}
+	if opts != nil {
+		m.opts = *opts
+	}
+	return m
+}
+
+// Handle mounts the server at the given pattern, which has the syntax of an
+// [http.ServeMux] pattern, such as "/weather" or "POST /tools/{name}".
+// Like [http.ServeMux.Handle], it panics if the pattern is invalid or
+// conflicts with a previously registered one.
+func (m *StreamableServeMux) Handle(pattern string, server *Server) {
+	m.HandleFunc(pattern, func(*http.Request) *Server { return server })
+}
+
+// HandleFunc is like [StreamableServeMux.Handle], but uses getServer to
+// create or look up servers for new sessions at the pattern, as with
+// [NewStreamableHTTPHandler].
+func (m *StreamableServeMux) HandleFunc(pattern string, getServer func(*http.Request) *Server) {
+	h := NewStreamableHTTPHandler(getServer, &m.opts)
+	h.route = pattern
+	m.mux.Handle(pattern, h)
+	m.mu.Lock()
+	m.handlers = append(m.handlers, h)
+	m.mu.Unlock()
+}
+
+// Handler returns the [StreamableHTTPHandler] that would serve the request,
+// and the pattern it was mounted at. If the request doesn't match the
+// pattern of a mounted server, it returns nil and "".
+func (m *StreamableServeMux) Handler(req *http.Request) (*StreamableHTTPHandler, string) {
+	h, pattern := m.mux.Handler(req)
+	sh, ok := h.(*StreamableHTTPHandler)
+	if !ok {
+		return nil, ""
+	}
+	return sh, pattern
+}
+
+// ServeHTTP dispatches the request to the handler of the server mounted at
+// the most specific matching pattern. Requests that match no pattern are
+// answered as by [http.ServeMux].
+func (m *StreamableServeMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mux.ServeHTTP(w, req)
+}
+
+// Shutdown gracefully shuts down the handlers of all mounted servers
+// concurrently, as with [StreamableHTTPHandler.Shutdown]. It returns the
+// context's error if ctx is done before all handlers have shut down.
+func (m *StreamableServeMux) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	handlers := m.handlers
+	m.mu.Unlock()
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+	)
+	for _, h := range handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if h.Shutdown(ctx) != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+	if failed.Load() {
+		// Handlers only fail when ctx is done.
+		return ctx.Err()
+	}
+	return nil
+}
+
+// closeAll closes the sessions of all mounted servers.
+func (m *StreamableServeMux) closeAll() {
+	m.mu.Lock()
+	handlers := m.handlers
+	m.mu.Unlock()
+	for _, h := range handlers {
+		h.closeAll()
+	}
+}
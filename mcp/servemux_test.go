@@ -0,0 +1,141 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamableServeMux(t *testing.T) {
+	ctx := context.Background()
+	mux := NewStreamableServeMux(nil)
+	for _, name := range []string{"a", "b"} {
+		mux.Handle("/"+name, NewServer(&Implementation{Name: name, Version: "v1"}, nil))
+	}
+	defer mux.closeAll()
+	httpServer := httptest.NewServer(mustNotPanic(t, mux))
+	defer httpServer.Close()
+
+	sessions := make(map[string]*ClientSession)
+	for _, name := range []string{"a", "b"} {
+		cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL + "/" + name}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+		if got := cs.InitializeResult().ServerInfo.Name; got != name {
+			t.Errorf("/%s: connected to server %q", name, got)
+		}
+		sessions[name] = cs
+	}
+
+	// A session is only served at the path it was created at.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL+"/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set(sessionIDHeader, sessions["a"].ID())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("session of /a at /b: got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	resp, err = http.Get(httpServer.URL + "/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("/c: got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/a", nil)
+	if h, pattern := mux.Handler(req); h == nil || pattern != "/a" {
+		t.Errorf("Handler(/a) = %v, %q, want handler for /a", h, pattern)
+	}
+}
+
+func TestStreamableServeMuxSessionStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySessionStore()
+	newMux := func() *StreamableServeMux {
+		mux := NewStreamableServeMux(&StreamableHTTPOptions{SessionStore: store})
+		for _, name := range []string{"a", "b"} {
+			mux.Handle("/"+name, NewServer(&Implementation{Name: name, Version: "v1"}, nil))
+		}
+		return mux
+	}
+	mux1 := newMux()
+	httpServer1 := httptest.NewServer(mustNotPanic(t, mux1))
+	defer httpServer1.Close()
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:             httpServer1.URL + "/a",
+		DisableStandaloneSSE: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	stored, err := store.Get(ctx, cs.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Route != "/a" {
+		t.Errorf("stored route %q, want %q", stored.Route, "/a")
+	}
+
+	// Another instance only recovers the session at the same path.
+	mux2 := newMux()
+	defer mux2.closeAll()
+	httpServer2 := httptest.NewServer(mustNotPanic(t, mux2))
+	defer httpServer2.Close()
+	ping := func(url string) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(sessionIDHeader, cs.ID())
+		req.Header.Set(protocolVersionHeader, cs.ProtocolVersion())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := ping(httpServer2.URL + "/b"); got != http.StatusNotFound {
+		t.Errorf("session of /a at /b: got status %d, want %d", got, http.StatusNotFound)
+	}
+	if got := ping(httpServer2.URL + "/a"); got != http.StatusOK {
+		t.Errorf("session of /a at /a: got status %d, want %d", got, http.StatusOK)
+	}
+
+	// Shutdown shuts down all mounted handlers.
+	if err := mux1.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/a", "/b"} {
+		resp, err := http.Post(httpServer1.URL+path, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("%s after Shutdown: got status %d, want %d", path, resp.StatusCode, http.StatusServiceUnavailable)
+		}
+	}
+}
@@ -10,11 +10,13 @@ import (
 	"encoding/base64"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
 	"maps"
 	"net/url"
+	"path"
 	"path/filepath"
 	"reflect"
 	"slices"
@@ -50,12 +52,22 @@ type Server struct {
 	sendingMethodHandler_   MethodHandler
 	receivingMethodHandler_ MethodHandler
 	resourceSubscriptions   map[string]map[*ServerSession]bool // uri -> session -> bool
+	shuttingDown            bool                               // set by Shutdown
+	activeCalls             inFlight                           // tool calls in progress
+
+	events eventBus    // see Server.Events
+	stats  serverStats // see Server.Stats
 }
 
 // ServerOptions is used to configure behavior of the server.
 type ServerOptions struct {
 	// Optional instructions for connected clients.
 	Instructions string
+	// If non-nil, InstructionsFunc returns the instructions for a session,
+	// given its initialize request, in place of Instructions. The request's
+	// Extra holds information about the HTTP request, such as the
+	// authenticated user, if any.
+	InstructionsFunc func(context.Context, *InitializeServerRequest) string
 	// If non-nil, log server activity.
 	Logger *slog.Logger
 	// If non-nil, called when "notifications/initialized" is received.
@@ -69,12 +81,56 @@ type ServerOptions struct {
 	RootsListChangedHandler func(context.Context, *RootsListChangedRequest)
 	// If non-nil, called when "notifications/progress" is received.
 	ProgressNotificationHandler func(context.Context, *ProgressNotificationServerRequest)
+	// If non-nil, called when "notifications/cancelled" is received, after
+	// the context of the cancelled request has been cancelled. Use
+	// [CancellationReason] in a handler to learn why its request was
+	// cancelled.
+	CancelledHandler func(context.Context, *CancelledRequest)
 	// If non-nil, called when "completion/complete" is received.
 	CompletionHandler func(context.Context, *CompleteRequest) (*CompleteResult, error)
+	// If non-nil, ToolDiscovery chooses the tools listed in response to each
+	// tools/list request. See [ToolDiscoveryFunc].
+	ToolDiscovery ToolDiscoveryFunc
+	// If non-nil, ResourceSearcher enables the experimental resource search
+	// extension, and performs the searches. See [ResourceSearcher].
+	ResourceSearcher ResourceSearcher
+	// If non-nil, NegotiateVersion chooses the protocol version of a session,
+	// given the version requested by the client's initialize request. It
+	// must return one of [SupportedProtocolVersions], or an error to refuse
+	// the session, which is reported to the client as an invalid params
+	// error. If nil, the requested version is used if it is supported, and
+	// otherwise the latest version.
+	NegotiateVersion func(requested string) (string, error)
+	// If non-nil, the deadline hints of incoming requests are applied to the
+	// contexts of their handlers, according to the policy. Handlers can use
+	// the context's deadline to return a partial result in time.
+	// See [DeadlineHintKey].
+	DeadlineHints *DeadlinePolicy
+	// If positive, each session records its ToolCallHistorySize most recent
+	// tool calls. See [ServerSession.ToolCallHistory].
+	ToolCallHistorySize int
 	// If non-zero, defines an interval for regular "ping" requests.
 	// If the peer fails to respond to pings originating from the keepalive check,
 	// the session is automatically closed.
 	KeepAlive time.Duration
+	// If non-nil, KeepAliveOptions tunes the keepalive check, for example to
+	// tolerate some failed pings before closing the session.
+	KeepAliveOptions *KeepAliveOptions
+	// If positive, RequestTimeout bounds each request that the server sends,
+	// such as ListRoots, CreateMessage and Elicit, when the context passed to
+	// it has no deadline, so that an unresponsive client cannot block server
+	// goroutines forever.
+	RequestTimeout time.Duration
+	// TransportMiddleware wraps the transport passed to [Server.Connect], to
+	// add behavior such as compression or metrics to every connection. The
+	// first middleware is the outermost.
+	TransportMiddleware []TransportMiddleware
+	// If non-nil, Prune configures the periodic pruning of each session's
+	// orphaned requests and, for streamable sessions, idle streams, and the
+	// closing of sessions older than [PruneOptions.MaxSessionAge], over any
+	// transport. A [StreamableHTTPHandler] can instead be configured with
+	// [StreamableHTTPOptions.Prune], which reports the sessions it closes.
+	Prune *PruneOptions
 	// Function called when a client session subscribes to a resource.
 	SubscribeHandler func(context.Context, *SubscribeRequest) error
 	// Function called when a client session unsubscribes from a resource.
@@ -98,6 +154,51 @@ type ServerOptions struct {
 	// As a special case, if GetSessionID returns the empty string, the
 	// Mcp-Session-Id header will not be set.
 	GetSessionID func() string
+	// If non-nil, Authorize is called before handling "tools/call",
+	// "resources/read" and "prompts/get" requests. If it returns an error, or
+	// a Decision that does not allow the request, the request fails without
+	// invoking the feature handler.
+	//
+	// Authorize lets allow/deny logic, such as a policy engine, live outside of
+	// handler code.
+	Authorize func(context.Context, *AuthzInput) (Decision, error)
+	// If non-nil, Quotas limits how often each principal may call tools.
+	// Calls that exceed their quota fail with an error whose data is a
+	// [QuotaExceededData].
+	Quotas *QuotaOptions
+	// If non-nil, ResultLimit caps the size of tool results.
+	// See [ResultLimit] for details.
+	ResultLimit *ResultLimit
+	// ToolResultLimits overrides ResultLimit for the tools with the given
+	// names. A nil value means that the tool's results are not limited.
+	ToolResultLimits map[string]*ResultLimit
+	// If non-nil, SanitizeArguments is called for each call to a tool added
+	// with [AddTool], after the arguments have been validated against the
+	// input schema but before the tool handler is invoked. It lets servers
+	// share argument normalization and rejection logic across tools.
+	//
+	// Tools added with [Server.AddTool] validate their own arguments, so
+	// SanitizeArguments is not called for them.
+	SanitizeArguments ArgumentSanitizer
+	// If non-nil, ContentFilter is applied to the content and structured
+	// content of tool results. See [FilterToolResult] and
+	// [FilterToolStructuredResult].
+	ContentFilter ContentFilter
+	// SchemaOptions configures how [AddTool] infers input and output schemas
+	// from Go types, for example to supply schemas for particular types with
+	// [jsonschema.ForOptions.TypeSchemas]. It can be overridden for individual
+	// tools with [AddToolWithOptions].
+	SchemaOptions *jsonschema.ForOptions
+	// PointerFields controls how pointer-typed struct fields are represented
+	// in schemas inferred by [AddTool]. If zero, [PointerNullable] is used.
+	PointerFields PointerPolicy
+	// UnknownFields controls whether input schemas inferred by [AddTool]
+	// accept unknown properties. If zero, [UnknownFieldsReject] is used.
+	UnknownFields UnknownFieldPolicy
+	// StructuredText derives the content of results of tools added with
+	// [AddTool] that return only structured output. If nil,
+	// [StructuredTextJSON] is used.
+	StructuredText StructuredTextFunc
 }
 
 // NewServer creates a new MCP server. The resulting server has no features:
@@ -130,6 +231,30 @@ func NewServer(impl *Implementation, options *ServerOptions) *Server {
 		panic("UnsubscribeHandler requires SubscribeHandler")
 	}
 
+	if opts.ResultLimit != nil {
+		if err := opts.ResultLimit.validate(); err != nil {
+			panic(fmt.Errorf("ResultLimit: %v", err))
+		}
+	}
+	for name, l := range opts.ToolResultLimits {
+		if l != nil {
+			if err := l.validate(); err != nil {
+				panic(fmt.Errorf("ToolResultLimits[%q]: %v", name, err))
+			}
+		}
+	}
+
+	if opts.Quotas != nil {
+		q := *opts.Quotas
+		if err := q.validate(); err != nil {
+			panic(fmt.Errorf("Quotas: %v", err))
+		}
+		if q.Store == nil {
+			q.Store = NewInMemoryQuotaStore()
+		}
+		opts.Quotas = &q
+	}
+
 	if opts.GetSessionID == nil {
 		opts.GetSessionID = randText
 	}
@@ -148,6 +273,7 @@ func NewServer(impl *Implementation, options *ServerOptions) *Server {
 		sendingMethodHandler_:   defaultSendingMethodHandler[*ServerSession],
 		receivingMethodHandler_: defaultReceivingMethodHandler[*ServerSession],
 		resourceSubscriptions:   make(map[string]map[*ServerSession]bool),
+		stats:                   serverStats{since: time.Now()},
 	}
 }
 
@@ -158,14 +284,18 @@ func (s *Server) AddPrompt(p *Prompt, h PromptHandler) {
 	s.changeAndNotify(
 		notificationPromptListChanged,
 		&PromptListChangedParams{},
-		func() bool { s.prompts.add(&serverPrompt{p, h}); return true })
+		func() bool {
+			s.prompts.add(&serverPrompt{p, h})
+			s.events.emit(ServerEvent{Kind: PromptAdded, Name: p.Name})
+			return true
+		})
 }
 
 // RemovePrompts removes the prompts with the given names.
 // It is not an error to remove a nonexistent prompt.
 func (s *Server) RemovePrompts(names ...string) {
 	s.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
-		func() bool { return s.prompts.remove(names...) })
+		func() bool { return removeFeatures(s, s.prompts, PromptRemoved, names) })
 }
 
 // AddTool adds a [Tool] to the server, or replaces one with the same name.
@@ -232,10 +362,14 @@ func (s *Server) AddTool(t *Tool, h ToolHandler) {
 	// TODO: Batch these changes by size and time? The typescript SDK doesn't.
 	// TODO: Surface notify error here? best not, in case we need to batch.
 	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
-		func() bool { s.tools.add(st); return true })
+		func() bool {
+			s.tools.add(st)
+			s.events.emit(ServerEvent{Kind: ToolAdded, Name: t.Name})
+			return true
+		})
 }
 
-func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHandler, error) {
+func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out], inferOpts *inferOptions, text StructuredTextFunc) (*Tool, ToolHandler, error) {
 	tt := *t
 
 	// Special handling for an "any" input: treat as an empty object.
@@ -244,7 +378,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 	}
 
 	var inputResolved *jsonschema.Resolved
-	if _, err := setSchema[In](&tt.InputSchema, &inputResolved); err != nil {
+	if _, _, err := setSchema[In](&tt.InputSchema, &inputResolved, inferOpts, false); err != nil {
 		return nil, nil, fmt.Errorf("input schema: %w", err)
 	}
 
@@ -253,13 +387,26 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 	// If Out is a pointer type and we've derived the output schema from its
 	// element type, use the zero value of its element type in place of a typed
 	// nil.
+	//
+	// If the output schema was inferred from a type that is not an object, such
+	// as a string or slice, the output is wrapped in an object under the
+	// "result" property. See [wrapOutputSchema].
 	var (
 		elemZero       any // only non-nil if Out is a pointer type
 		outputResolved *jsonschema.Resolved
+		wrapOutput     bool
 	)
 	if t.OutputSchema != nil || reflect.TypeFor[Out]() != reflect.TypeFor[any]() {
+		var outOpts *inferOptions
+		if inferOpts != nil {
+			// The unknown field policy is for the benefit of clients, and
+			// doesn't apply to output.
+			o := *inferOpts
+			o.unknownFields = 0
+			outOpts = &o
+		}
 		var err error
-		elemZero, err = setSchema[Out](&tt.OutputSchema, &outputResolved)
+		elemZero, wrapOutput, err = setSchema[Out](&tt.OutputSchema, &outputResolved, outOpts, true)
 		if err != nil {
 			return nil, nil, fmt.Errorf("output schema: %v", err)
 		}
@@ -275,8 +422,19 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 		input, err = applySchema(input, inputResolved)
 		if err != nil {
 			// TODO(#450): should this be considered a tool error? (and similar below)
+			if verr, ok := err.(*ValidationError); ok {
+				return nil, verr.wireError(CodeInvalidParams, `invalid params: validating "arguments"`)
+			}
 			return nil, fmt.Errorf("%w: validating \"arguments\": %v", jsonrpc2.ErrInvalidParams, err)
 		}
+		if req.Session != nil {
+			if sanitize := req.Session.server.opts.SanitizeArguments; sanitize != nil {
+				input, err = sanitizeArguments(ctx, sanitize, req, input)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
 
 		// Unmarshal and validate args.
 		var in In
@@ -318,6 +476,9 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 			}
 		}
 		if outval != nil {
+			if wrapOutput {
+				outval = map[string]any{wrappedResultProperty: outval}
+			}
 			outbytes, err := json.Marshal(outval)
 			if err != nil {
 				return nil, fmt.Errorf("marshaling output: %w", err)
@@ -329,17 +490,29 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 			// some types may have custom JSON marshalling (issue #447).
 			outJSON, err = applySchema(outJSON, outputResolved)
 			if err != nil {
-				return nil, fmt.Errorf("validating tool output: %w", err)
+				if verr, ok := err.(*ValidationError); ok {
+					return nil, verr.wireError(CodeOutputValidation, outputValidationPrefix)
+				}
+				return nil, fmt.Errorf("%s: %w", outputValidationPrefix, err)
 			}
 			res.StructuredContent = outJSON // avoid a second marshal over the wire
 
-			// If the Content field isn't being used, return the serialized JSON in a
+			// If the Content field isn't being used, derive it from the structured
+			// content. By default, this returns the serialized JSON in a
 			// TextContent block, as the spec suggests:
 			// https://modelcontextprotocol.io/specification/2025-06-18/server/tools#structured-content.
 			if res.Content == nil {
-				res.Content = []Content{&TextContent{
-					Text: string(outJSON),
-				}}
+				format := text
+				if format == nil && req.Session != nil {
+					format = req.Session.server.opts.StructuredText
+				}
+				if format == nil {
+					format = StructuredTextJSON
+				}
+				res.Content, err = format(outJSON)
+				if err != nil {
+					return nil, fmt.Errorf("converting structured output to content: %w", err)
+				}
 			}
 		}
 		return res, nil
@@ -350,7 +523,10 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 
 // setSchema sets the schema and resolved schema corresponding to the type T.
 //
-// If sfield is nil, the schema is derived from T.
+// If sfield is nil, the schema is derived from T, using inferOpts if it is
+// non-nil. In that case, if wrap is
+// set and the derived schema does not have type "object", it is wrapped with
+// [wrapOutputSchema] and wrapped is true.
 //
 // Pointers are treated equivalently to non-pointers when deriving the schema.
 // If an indirection occurred to derive the schema, a non-nil zero value is
@@ -362,7 +538,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 //
 // TODO(rfindley): we really shouldn't ever return 'null' results. Maybe we
 // should have a jsonschema.Zero(schema) helper?
-func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err error) {
+func setSchema[T any](sfield *any, rfield **jsonschema.Resolved, inferOpts *inferOptions, wrap bool) (zero any, wrapped bool, err error) {
 	var internalSchema *jsonschema.Schema
 	if *sfield == nil {
 		rt := reflect.TypeFor[T]()
@@ -370,19 +546,25 @@ func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err
 			rt = rt.Elem()
 			zero = reflect.Zero(rt).Interface()
 		}
-		// TODO: we should be able to pass nil opts here.
-		internalSchema, err = jsonschema.ForType(rt, &jsonschema.ForOptions{})
+		if inferOpts == nil {
+			inferOpts = &inferOptions{}
+		}
+		internalSchema, err = inferSchema(rt, inferOpts)
 		if err == nil {
+			if wrap && !isObjectSchema(internalSchema) {
+				internalSchema = wrapOutputSchema(internalSchema)
+				wrapped = true
+			}
 			*sfield = internalSchema
 		}
 	} else if err := remarshal(*sfield, &internalSchema); err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	if err != nil {
-		return zero, err
+		return zero, false, err
 	}
 	*rfield, err = internalSchema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
-	return zero, err
+	return zero, wrapped, err
 }
 
 // AddTool adds a tool and typed tool handler to the server.
@@ -397,18 +579,75 @@ func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err
 // empty object schema value.
 //
 // If the tool's output schema is nil, and the Out type is not 'any', the
-// output schema is set to the schema inferred from the Out type argument,
-// which must also be a map or struct. If the Out type is 'any', the output
-// schema is omitted.
+// output schema is set to the schema inferred from the Out type argument.
+// If the Out type is not a map or struct, such as a string or slice, the
+// output is wrapped in an object under the "result" property, since the spec
+// requires structured content to be an object; use [StructuredOutput] on the
+// client to unwrap it. If the Out type is 'any', the output schema is
+// omitted.
+//
+// Schemas are inferred using [ServerOptions.SchemaOptions]. To customize
+// inference for a single tool, use [AddToolWithOptions].
 //
 // Unlike [Server.AddTool], AddTool does a lot automatically, and forces
 // tools to conform to the MCP spec. See [ToolHandlerFor] for a detailed
 // description of this automatic behavior.
 func AddTool[In, Out any](s *Server, t *Tool, h ToolHandlerFor[In, Out]) {
-	tt, hh, err := toolForErr(t, h)
+	AddToolWithOptions(s, t, h, nil)
+}
+
+// AddToolOptions configures [AddToolWithOptions].
+type AddToolOptions struct {
+	// SchemaOptions configures inference of the input and output schemas
+	// from the In and Out types. If nil, [ServerOptions.SchemaOptions] is
+	// used.
+	SchemaOptions *jsonschema.ForOptions
+	// PointerFields controls how pointer-typed struct fields are represented
+	// in inferred schemas. If zero, [ServerOptions.PointerFields] is used.
+	PointerFields PointerPolicy
+	// UnknownFields controls whether the inferred input schema accepts
+	// unknown properties. If zero, [ServerOptions.UnknownFields] is used.
+	UnknownFields UnknownFieldPolicy
+
+	// StructuredText derives the content of results that have only
+	// structured output. If nil, [ServerOptions.StructuredText] is used.
+	StructuredText StructuredTextFunc
+
+	// Hooks, if non-nil, are called around the tool handler.
+	// See [ToolHooks].
+	Hooks *ToolHooks
+}
+
+// AddToolWithOptions is like [AddTool], but allows configuring how the tool
+// is added. If opts is nil, it is equivalent to AddTool.
+func AddToolWithOptions[In, Out any](s *Server, t *Tool, h ToolHandlerFor[In, Out], opts *AddToolOptions) {
+	inferOpts := &inferOptions{
+		forOpts:       s.opts.SchemaOptions,
+		pointers:      s.opts.PointerFields,
+		unknownFields: s.opts.UnknownFields,
+	}
+	if opts != nil {
+		if opts.SchemaOptions != nil {
+			inferOpts.forOpts = opts.SchemaOptions
+		}
+		if opts.PointerFields != 0 {
+			inferOpts.pointers = opts.PointerFields
+		}
+		if opts.UnknownFields != 0 {
+			inferOpts.unknownFields = opts.UnknownFields
+		}
+	}
+	var text StructuredTextFunc
+	if opts != nil {
+		text = opts.StructuredText
+	}
+	tt, hh, err := toolForErr(t, h, inferOpts, text)
 	if err != nil {
 		panic(fmt.Sprintf("AddTool: tool %q: %v", t.Name, err))
 	}
+	if opts != nil {
+		hh = opts.Hooks.Wrap(hh)
+	}
 	s.AddTool(tt, hh)
 }
 
@@ -416,7 +655,7 @@ func AddTool[In, Out any](s *Server, t *Tool, h ToolHandlerFor[In, Out]) {
 // It is not an error to remove a nonexistent tool.
 func (s *Server) RemoveTools(names ...string) {
 	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
-		func() bool { return s.tools.remove(names...) })
+		func() bool { return removeFeatures(s, s.tools, ToolRemoved, names) })
 }
 
 // AddResource adds a [Resource] to the server, or replaces one with the same URI.
@@ -428,6 +667,7 @@ func (s *Server) AddResource(r *Resource, h ResourceHandler) {
 				panic(err) // url.Parse includes the URI in the error
 			}
 			s.resources.add(&serverResource{r, h})
+			s.events.emit(ServerEvent{Kind: ResourceAdded, Name: r.URI})
 			return true
 		})
 }
@@ -436,7 +676,7 @@ func (s *Server) AddResource(r *Resource, h ResourceHandler) {
 // It is not an error to remove a nonexistent resource.
 func (s *Server) RemoveResources(uris ...string) {
 	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
-		func() bool { return s.resources.remove(uris...) })
+		func() bool { return removeFeatures(s, s.resources, ResourceRemoved, uris) })
 }
 
 // AddResourceTemplate adds a [ResourceTemplate] to the server, or replaces one with the same URI.
@@ -450,6 +690,7 @@ func (s *Server) AddResourceTemplate(t *ResourceTemplate, h ResourceHandler) {
 				panic(fmt.Errorf("URI template %q is invalid: %w", t.URITemplate, err))
 			}
 			s.resourceTemplates.add(&serverResourceTemplate{t, h})
+			s.events.emit(ServerEvent{Kind: ResourceTemplateAdded, Name: t.URITemplate})
 			return true
 		})
 }
@@ -458,7 +699,9 @@ func (s *Server) AddResourceTemplate(t *ResourceTemplate, h ResourceHandler) {
 // It is not an error to remove a nonexistent resource.
 func (s *Server) RemoveResourceTemplates(uriTemplates ...string) {
 	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
-		func() bool { return s.resourceTemplates.remove(uriTemplates...) })
+		func() bool {
+			return removeFeatures(s, s.resourceTemplates, ResourceTemplateRemoved, uriTemplates)
+		})
 }
 
 func (s *Server) capabilities() *ServerCapabilities {
@@ -483,6 +726,9 @@ func (s *Server) capabilities() *ServerCapabilities {
 	if s.opts.CompletionHandler != nil {
 		caps.Completions = &CompletionCapabilities{}
 	}
+	if s.opts.ResourceSearcher != nil {
+		caps.Experimental = map[string]any{ResourceSearchCapability: map[string]any{}}
+	}
 	return caps
 }
 
@@ -539,19 +785,33 @@ func (s *Server) getPrompt(ctx context.Context, req *GetPromptRequest) (*GetProm
 	if !ok {
 		// Return a proper JSON-RPC error with the correct error code
 		return nil, &jsonrpc2.WireError{
-			Code:    codeInvalidParams,
+			Code:    CodeInvalidParams,
 			Message: fmt.Sprintf("unknown prompt %q", req.Params.Name),
 		}
 	}
+	in := &AuthzInput{Session: req.Session, Method: methodGetPrompt, Target: req.Params.Name, Extra: req.Extra}
+	if req.Params.Arguments != nil {
+		data, err := json.Marshal(req.Params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		in.Arguments = data
+	}
+	if err := s.authorize(ctx, in); err != nil {
+		return nil, err
+	}
 	return prompt.handler(ctx, req)
 }
 
-func (s *Server) listTools(_ context.Context, req *ListToolsRequest) (*ListToolsResult, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Server) listTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResult, error) {
 	if req.Params == nil {
 		req.Params = &ListToolsParams{}
 	}
+	if s.opts.ToolDiscovery != nil {
+		return s.discoverTools(ctx, req)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return paginateList(s.tools, s.opts.PageSize, req.Params, &ListToolsResult{}, func(res *ListToolsResult, tools []*serverTool) {
 		res.Tools = []*Tool{} // avoid JSON null
 		for _, t := range tools {
@@ -562,15 +822,59 @@ func (s *Server) listTools(_ context.Context, req *ListToolsRequest) (*ListTools
 
 func (s *Server) callTool(ctx context.Context, req *CallToolRequest) (*CallToolResult, error) {
 	s.mu.Lock()
+	if s.shuttingDown {
+		s.mu.Unlock()
+		return nil, ErrServerShutdown
+	}
 	st, ok := s.tools.get(req.Params.Name)
+	if ok {
+		s.activeCalls.start()
+		defer func() {
+			s.mu.Lock()
+			s.activeCalls.finish()
+			s.mu.Unlock()
+		}()
+	}
 	s.mu.Unlock()
 	if !ok {
 		return nil, &jsonrpc2.WireError{
-			Code:    codeInvalidParams,
+			Code:    CodeInvalidParams,
 			Message: fmt.Sprintf("unknown tool %q", req.Params.Name),
 		}
 	}
+	in := &AuthzInput{
+		Session:   req.Session,
+		Method:    methodCallTool,
+		Target:    req.Params.Name,
+		Arguments: req.Params.Arguments,
+		Extra:     req.Extra,
+	}
+	start := time.Now()
+	if err := s.authorize(ctx, in); err != nil {
+		req.Session.recordToolCall(req.Params, start, nil, err)
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, req); err != nil {
+		req.Session.recordToolCall(req.Params, start, nil, err)
+		return nil, err
+	}
 	res, err := st.handler(ctx, req)
+	defer func() { req.Session.recordToolCall(req.Params, start, res, err) }()
+	if err == nil && res != nil && s.opts.ContentFilter != nil {
+		res2 := *res
+		if res2.Content, err = filterContents(ctx, s.opts.ContentFilter, FilterToolResult, res.Content); err != nil {
+			return nil, err
+		}
+		if res.StructuredContent != nil {
+			if res2.StructuredContent, err = filterStructured(ctx, s.opts.ContentFilter, FilterToolStructuredResult, res.StructuredContent); err != nil {
+				return nil, err
+			}
+		}
+		res = &res2
+	}
+	if err == nil {
+		res, err = applyResultLimit(ctx, s.resultLimit(req.Params.Name), req, res)
+	}
 	if err == nil && res != nil && res.Content == nil {
 		res2 := *res
 		res2.Content = []Content{} // avoid "null"
@@ -585,7 +889,11 @@ func (s *Server) listResources(_ context.Context, req *ListResourcesRequest) (*L
 	if req.Params == nil {
 		req.Params = &ListResourcesParams{}
 	}
-	return paginateList(s.resources, s.opts.PageSize, req.Params, &ListResourcesResult{}, func(res *ListResourcesResult, resources []*serverResource) {
+	var keep func(*serverResource) bool
+	if f, ok := ResourceFilterFrom(req.Params); ok {
+		keep = func(r *serverResource) bool { return f.Match(r.resource) }
+	}
+	return paginateFilteredList(s.resources, s.opts.PageSize, req.Params, &ListResourcesResult{}, keep, func(res *ListResourcesResult, resources []*serverResource) {
 		res.Resources = []*Resource{} // avoid JSON null
 		for _, r := range resources {
 			res.Resources = append(res.Resources, r.resource)
@@ -618,6 +926,9 @@ func (s *Server) readResource(ctx context.Context, req *ReadResourceRequest) (*R
 		// Treat an unregistered resource the same as a registered one that couldn't be found.
 		return nil, ResourceNotFoundError(uri)
 	}
+	if err := s.authorize(ctx, &AuthzInput{Session: req.Session, Method: methodReadResource, Target: uri, Extra: req.Extra}); err != nil {
+		return nil, err
+	}
 	res, err := handler(ctx, req)
 	if err != nil {
 		return nil, err
@@ -676,12 +987,11 @@ func fileResourceHandler(dir string) ResourceHandler {
 	return func(ctx context.Context, req *ReadResourceRequest) (_ *ReadResourceResult, err error) {
 		defer util.Wrapf(&err, "reading resource %s", req.Params.URI)
 
-		// TODO(#25): use a memoizing API here.
-		rootRes, err := req.Session.ListRoots(ctx, nil)
+		rawRoots, err := req.Session.Roots(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("listing roots: %w", err)
 		}
-		roots, err := fileRoots(rootRes.Roots)
+		roots, err := fileRoots(rawRoots)
 		if err != nil {
 			return nil, err
 		}
@@ -792,24 +1102,81 @@ func (s *Server) Run(ctx context.Context, t Transport) error {
 	}
 }
 
+// ErrServerShutdown is the error of attempts to connect to a [Server], or to
+// call its tools, once [Server.Shutdown] has been called.
+var ErrServerShutdown = errors.New("server is shutting down")
+
+// Shutdown gracefully shuts down the server.
+//
+// Shutdown first stops accepting sessions and tool calls: subsequent calls
+// to [Server.Connect] and tools/call requests fail with [ErrServerShutdown].
+// It then waits until tool calls in progress have completed, or ctx is done.
+// Finally, it sends a log message at level "notice" to connected clients
+// that have set a log level, and closes their sessions.
+//
+// Sessions are closed even if ctx is done first, in which case Shutdown
+// returns the context's error. Requests still in progress when sessions are
+// closed are cancelled, with cause ErrServerShutdown. A server cannot be
+// restarted after Shutdown.
+//
+// To hand sessions off to other server instances instead, use
+// [StreamableHTTPHandler.Shutdown].
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	idle := s.activeCalls.wait()
+	s.mu.Unlock()
+
+	var err error
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// Use a fresh context for the notification, so that clients are told
+	// even if ctx is done, but don't let a slow client delay the shutdown.
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.LogToAll(notifyCtx, &LoggingMessageParams{Level: "notice", Data: "server is shutting down"}); err != nil {
+		s.opts.Logger.Warn("failed to notify sessions of shutdown", "error", err)
+	}
+	for ss := range s.Sessions() {
+		// Close waits for requests in progress, so cancel them first.
+		ss.conn.CancelReceivedBefore(time.Now(), ErrServerShutdown)
+		ss.Close()
+	}
+	return err
+}
+
+// sessionSerial numbers server sessions.
+var sessionSerial atomic.Int64
+
 // bind implements the binder[*ServerSession] interface, so that Servers can
 // be connected using [connect].
 func (s *Server) bind(mcpConn Connection, conn *jsonrpc2.Connection, state *ServerSessionState, onClose func()) *ServerSession {
 	assert(mcpConn != nil && conn != nil, "nil connection")
-	ss := &ServerSession{conn: conn, mcpConn: mcpConn, server: s, onClose: onClose}
+	ss := &ServerSession{conn: conn, mcpConn: mcpConn, server: s, onClose: onClose, created: time.Now(), serial: sessionSerial.Add(1)}
 	if state != nil {
 		ss.state = *state
 	}
+	if p := s.opts.Prune; p != nil && p.Interval > 0 {
+		ss.startPruning(p)
+	}
 	s.mu.Lock()
 	s.sessions = append(s.sessions, ss)
 	s.mu.Unlock()
 	s.opts.Logger.Info("server session connected", "session_id", ss.ID())
+	s.events.emit(ServerEvent{Kind: SessionConnected, Session: ss})
 	return ss
 }
 
 // disconnect implements the binder[*ServerSession] interface, so that
 // Servers can be connected using [connect].
 func (s *Server) disconnect(cc *ServerSession) {
+	if cc.pruneCancel != nil {
+		cc.pruneCancel()
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.sessions = slices.DeleteFunc(s.sessions, func(cc2 *ServerSession) bool {
@@ -820,6 +1187,7 @@ func (s *Server) disconnect(cc *ServerSession) {
 		delete(subscribedSessions, cc)
 	}
 	s.opts.Logger.Info("server session disconnected", "session_id", cc.ID())
+	s.events.emit(ServerEvent{Kind: SessionDisconnected, Session: cc})
 }
 
 // ServerSessionOptions configures the server session.
@@ -845,8 +1213,15 @@ func (s *Server) Connect(ctx context.Context, t Transport, opts *ServerSessionOp
 		onClose = opts.onClose
 	}
 
+	s.mu.Lock()
+	shuttingDown := s.shuttingDown
+	s.mu.Unlock()
+	if shuttingDown {
+		return nil, ErrServerShutdown
+	}
+
 	s.opts.Logger.Info("server connecting")
-	ss, err := connect(ctx, t, s, state, onClose)
+	ss, err := connect(ctx, wrapTransport(t, s.opts.TransportMiddleware), s, state, onClose)
 	if err != nil {
 		s.opts.Logger.Error("server connect error", "error", err)
 		return nil, err
@@ -889,6 +1264,7 @@ func (ss *ServerSession) initialized(ctx context.Context, params *InitializedPar
 }
 
 func (s *Server) callRootsListChangedHandler(ctx context.Context, req *RootsListChangedRequest) (Result, error) {
+	req.Session.roots.invalidate()
 	if h := s.opts.RootsListChangedHandler; h != nil {
 		h(ctx, req)
 	}
@@ -931,9 +1307,15 @@ type ServerSession struct {
 	conn            *jsonrpc2.Connection
 	mcpConn         Connection
 	keepaliveCancel context.CancelFunc // TODO: theory around why keepaliveCancel need not be guarded
+	pruneCancel     context.CancelFunc // set in bind, if pruning is configured
+	created         time.Time
+	serial          int64 // unique in the process, for sessions without an ID
 
 	mu    sync.Mutex
 	state ServerSessionState
+
+	roots     rootsCache
+	toolCalls toolCallHistory
 }
 
 func (ss *ServerSession) updateState(mut func(*ServerSessionState)) {
@@ -941,11 +1323,35 @@ func (ss *ServerSession) updateState(mut func(*ServerSessionState)) {
 	mut(&ss.state)
 	copy := ss.state
 	ss.mu.Unlock()
-	if c, ok := ss.mcpConn.(serverConnection); ok {
+	if c, ok := asConnection[serverConnection](ss.mcpConn); ok {
 		c.sessionUpdated(copy)
 	}
 }
 
+// SetIdleTimeout sets the duration after which the session is closed if the
+// client makes no requests, overriding [StreamableHTTPOptions.SessionTimeout].
+// A timeout that is not positive means the session never times out. The new
+// timeout is persisted with the session, if it is stored in a
+// [SessionStore].
+//
+// SetIdleTimeout can be called from an [ServerOptions.InitializedHandler], for
+// example, to vary the timeout with the client.
+//
+// It returns an error if the session's transport doesn't support idle
+// timeouts, or the session is stateless.
+func (ss *ServerSession) SetIdleTimeout(timeout time.Duration) error {
+	c, ok := asConnection[idleTimeoutConnection](ss.mcpConn)
+	if !ok {
+		return errors.New("transport does not support idle timeouts")
+	}
+	if err := c.setIdleTimeout(timeout); err != nil {
+		return err
+	}
+	// Persist the new timeout.
+	ss.updateState(func(*ServerSessionState) {})
+	return nil
+}
+
 // State returns a copy of the current session state.
 func (ss *ServerSession) State() ServerSessionState {
 	ss.mu.Lock()
@@ -981,7 +1387,7 @@ func (ss *ServerSession) checkInitialized(method string) error {
 }
 
 func (ss *ServerSession) ID() string {
-	if c, ok := ss.mcpConn.(hasSessionID); ok {
+	if c, ok := asConnection[hasSessionID](ss.mcpConn); ok {
 		return c.SessionID()
 	}
 	return ""
@@ -1018,11 +1424,34 @@ func (ss *ServerSession) CreateMessage(ctx context.Context, params *CreateMessag
 }
 
 // Elicit sends an elicitation request to the client asking for user input.
+//
+// If the user accepts, properties that are missing from the result content
+// are set to their defaults in params.RequestedSchema. As with tool input,
+// defaults of required properties are not applied.
 func (ss *ServerSession) Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
 	if err := ss.checkInitialized(methodElicit); err != nil {
 		return nil, err
 	}
-	return handleSend[*ElicitResult](ctx, methodElicit, newServerRequest(ss, orZero[Params](params)))
+	res, err := handleSend[*ElicitResult](ctx, methodElicit, newServerRequest(ss, orZero[Params](params)))
+	if err != nil {
+		return nil, err
+	}
+	if params != nil && params.RequestedSchema != nil && res.Action == "accept" {
+		var schema *jsonschema.Schema
+		if err := remarshal(params.RequestedSchema, &schema); err != nil {
+			return nil, fmt.Errorf("requested schema: %w", err)
+		}
+		if schema != nil {
+			resolved, err := schema.Resolve(nil)
+			if err != nil {
+				return nil, fmt.Errorf("requested schema: %w", err)
+			}
+			if err := applyElicitDefaults(resolved, res); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
 }
 
 // Log sends a log message to the client.
@@ -1074,6 +1503,36 @@ func (s *Server) AddReceivingMiddleware(middleware ...Middleware) {
 	addMiddleware(&s.receivingMethodHandler_, middleware)
 }
 
+// AddToolMiddleware adds receiving middleware that applies only to calls of
+// tools whose names match pattern, using the syntax of [path.Match]. Calls to
+// other tools, and all other methods, bypass the middleware. This is useful
+// for expensive interceptors, such as requiring human approval for
+// destructive tools.
+//
+// As with [Server.AddReceivingMiddleware], middleware is applied from right to
+// left, and middleware added later runs before middleware added earlier.
+//
+// AddToolMiddleware panics if pattern is malformed.
+func (s *Server) AddToolMiddleware(pattern string, middleware ...Middleware) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Errorf("AddToolMiddleware: bad pattern %q: %v", pattern, err))
+	}
+	s.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		matched := next
+		addMiddleware(&matched, middleware)
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodCallTool {
+				if r, ok := req.(*CallToolRequest); ok && r.Params != nil {
+					if ok, _ := path.Match(pattern, r.Params.Name); ok {
+						return matched(ctx, method, req)
+					}
+				}
+			}
+			return next(ctx, method, req)
+		}
+	})
+}
+
 // serverMethodInfos maps from the RPC method name to serverMethodInfos.
 //
 // The 'allowMissingParams' values are extracted from the protocol schema.
@@ -1081,7 +1540,7 @@ func (s *Server) AddReceivingMiddleware(middleware ...Middleware) {
 // curating these method flags.
 var serverMethodInfos = map[string]methodInfo{
 	methodComplete:               newServerMethodInfo(serverMethod((*Server).complete), 0),
-	methodInitialize:             newServerMethodInfo(serverSessionMethod((*ServerSession).initialize), 0),
+	methodInitialize:             newServerMethodInfo(serverMethod((*Server).initialize), 0),
 	methodPing:                   newServerMethodInfo(serverSessionMethod((*ServerSession).ping), missingParamsOK),
 	methodListPrompts:            newServerMethodInfo(serverMethod((*Server).listPrompts), missingParamsOK),
 	methodGetPrompt:              newServerMethodInfo(serverMethod((*Server).getPrompt), 0),
@@ -1090,6 +1549,7 @@ var serverMethodInfos = map[string]methodInfo{
 	methodListResources:          newServerMethodInfo(serverMethod((*Server).listResources), missingParamsOK),
 	methodListResourceTemplates:  newServerMethodInfo(serverMethod((*Server).listResourceTemplates), missingParamsOK),
 	methodReadResource:           newServerMethodInfo(serverMethod((*Server).readResource), 0),
+	methodSearchResources:        newServerMethodInfo(serverMethod((*Server).searchResources), 0),
 	methodSetLevel:               newServerMethodInfo(serverSessionMethod((*ServerSession).setLevel), 0),
 	methodSubscribe:              newServerMethodInfo(serverMethod((*Server).subscribe), 0),
 	methodUnsubscribe:            newServerMethodInfo(serverMethod((*Server).unsubscribe), 0),
@@ -1149,24 +1609,61 @@ func (ss *ServerSession) handle(ctx context.Context, req *jsonrpc.Request) (any,
 	// server->client calls and notifications to the incoming request from which
 	// they originated. See [idContextKey] for details.
 	ctx = context.WithValue(ctx, idContextKey{}, req.ID)
-	return handleReceive(ctx, ss, req)
+	start := time.Now()
+	res, err := handleReceive(ctx, ss, req)
+	ss.server.stats.recordMethod(req.Method, start, err)
+	if err != nil {
+		ss.server.events.emit(ServerEvent{Kind: HandlerError, Session: ss, Method: req.Method, Err: err})
+	}
+	return res, err
 }
 
 func (ss *ServerSession) InitializeParams() *InitializeParams { return ss.state.InitializeParams }
 
+// ProtocolVersion returns the protocol version negotiated with the client, or
+// the empty string if the session is not initialized.
+func (ss *ServerSession) ProtocolVersion() string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.state.protocolVersion()
+}
+
+// initialize handles an initialize request, computing the session's
+// instructions with InstructionsFunc if it is set.
+func (s *Server) initialize(ctx context.Context, req *InitializeServerRequest) (*InitializeResult, error) {
+	res, err := req.Session.initialize(ctx, req.Params)
+	if err != nil || s.opts.InstructionsFunc == nil {
+		return res, err
+	}
+	res.Instructions = s.opts.InstructionsFunc(ctx, req)
+	return res, nil
+}
+
 func (ss *ServerSession) initialize(ctx context.Context, params *InitializeParams) (*InitializeResult, error) {
 	if params == nil {
 		return nil, fmt.Errorf("%w: \"params\" must be be provided", jsonrpc2.ErrInvalidParams)
 	}
+	s := ss.server
+	version := negotiatedVersion(params.ProtocolVersion)
+	if f := s.opts.NegotiateVersion; f != nil {
+		var err error
+		version, err = f(params.ProtocolVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", jsonrpc2.ErrInvalidParams, err)
+		}
+		if !slices.Contains(supportedProtocolVersions, version) {
+			return nil, fmt.Errorf("negotiated unsupported protocol version %q", version)
+		}
+	}
 	ss.updateState(func(state *ServerSessionState) {
 		state.InitializeParams = params
+		state.ProtocolVersion = version
 	})
 
-	s := ss.server
 	return &InitializeResult{
 		// TODO(rfindley): alter behavior when falling back to an older version:
 		// reject unsupported features.
-		ProtocolVersion: negotiatedVersion(params.ProtocolVersion),
+		ProtocolVersion: version,
 		Capabilities:    s.capabilities(),
 		Instructions:    s.opts.Instructions,
 		ServerInfo:      s.impl,
@@ -1177,12 +1674,12 @@ func (ss *ServerSession) ping(context.Context, *PingParams) (*emptyResult, error
 	return &emptyResult{}, nil
 }
 
-// cancel is a placeholder: cancellation is handled the jsonrpc2 package.
-//
-// It should never be invoked in practice because cancellation is preempted,
-// but having its signature here facilitates the construction of methodInfo
-// that can be used to validate incoming cancellation notifications.
-func (ss *ServerSession) cancel(context.Context, *CancelledParams) (Result, error) {
+// cancel calls the CancelledHandler, if any. Cancellation itself is handled
+// by the jsonrpc2 package, which preempts cancellation notifications.
+func (ss *ServerSession) cancel(ctx context.Context, params *CancelledParams) (Result, error) {
+	if h := ss.server.opts.CancelledHandler; h != nil {
+		h(ctx, serverRequestFor(ss, params))
+	}
 	return nil, nil
 }
 
@@ -1224,7 +1721,7 @@ func (ss *ServerSession) Wait() error {
 
 // startKeepalive starts the keepalive mechanism for this server session.
 func (ss *ServerSession) startKeepalive(interval time.Duration) {
-	startKeepalive(ss, interval, &ss.keepaliveCancel)
+	startKeepalive(ss, interval, ss.server.opts.KeepAliveOptions, &ss.keepaliveCancel)
 }
 
 // pageToken is the internal structure for the opaque pagination cursor.
@@ -1266,6 +1763,13 @@ func decodeCursor(cursor string) (*pageToken, error) {
 // and sets its next cursor for subsequent pages.
 // If there are no more pages, the next cursor within the result will be an empty string.
 func paginateList[P listParams, R listResult[T], T any](fs *featureSet[T], pageSize int, params P, res R, setFunc func(R, []T)) (R, error) {
+	return paginateFilteredList(fs, pageSize, params, res, nil, setFunc)
+}
+
+// paginateFilteredList is like paginateList, but pages through only the
+// features for which keep returns true. If keep is nil, all features are
+// kept.
+func paginateFilteredList[P listParams, R listResult[T], T any](fs *featureSet[T], pageSize int, params P, res R, keep func(T) bool, setFunc func(R, []T)) (R, error) {
 	var seq iter.Seq[T]
 	if params.cursorPtr() == nil || *params.cursorPtr() == "" {
 		seq = fs.all()
@@ -1281,6 +1785,9 @@ func paginateList[P listParams, R listResult[T], T any](fs *featureSet[T], pageS
 	var count int
 	var features []T
 	for f := range seq {
+		if keep != nil && !keep(f) {
+			continue
+		}
 		count++
 		// If we've seen pageSize + 1 elements, we've gathered enough info to determine
 		// if there's a next page. Stop processing the sequence.
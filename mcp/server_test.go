@@ -7,7 +7,9 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -481,12 +483,20 @@ func TestAddTool(t *testing.T) {
 	}) {
 		t.Error("good In: expected no panic")
 	}
-	if !panics(func() {
+	// Non-object outputs are wrapped, rather than rejected.
+	if panics(func() {
 		AddTool(s, &Tool{Name: "T2"}, func(context.Context, *CallToolRequest, map[string]any) (*CallToolResult, int, error) {
 			return nil, 0, nil
 		})
 	}) {
-		t.Error("bad Out: expected panic")
+		t.Error("wrapped Out: expected no panic")
+	}
+	if !panics(func() {
+		AddTool(s, &Tool{Name: "T3", OutputSchema: &schema{Type: "integer"}}, func(context.Context, *CallToolRequest, map[string]any) (*CallToolResult, int, error) {
+			return nil, 0, nil
+		})
+	}) {
+		t.Error("bad output schema: expected panic")
 	}
 }
 
@@ -497,7 +507,7 @@ func testToolForSchema[In, Out any](t *testing.T, tool *Tool, in string, out Out
 	th := func(context.Context, *CallToolRequest, In) (*CallToolResult, Out, error) {
 		return nil, out, nil
 	}
-	gott, goth, err := toolForErr(tool, th)
+	gott, goth, err := toolForErr(tool, th, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -588,3 +598,258 @@ func TestToolForSchemas(t *testing.T) {
 		},
 		"")
 }
+
+func TestAddToolSchemaOptions(t *testing.T) {
+	type celsius float64
+	type in struct {
+		Temp celsius `json:"temp"`
+	}
+	handler := func(context.Context, *CallToolRequest, in) (*CallToolResult, any, error) { return nil, nil, nil }
+	tempSchema := func(desc string) *jsonschema.ForOptions {
+		return &jsonschema.ForOptions{TypeSchemas: map[reflect.Type]*schema{
+			reflect.TypeFor[celsius](): {Type: "number", Description: desc},
+		}}
+	}
+
+	s := NewServer(testImpl, &ServerOptions{SchemaOptions: tempSchema("server")})
+	AddTool(s, &Tool{Name: "default"}, handler)
+	AddToolWithOptions(s, &Tool{Name: "override"}, handler, &AddToolOptions{SchemaOptions: tempSchema("tool")})
+
+	for name, want := range map[string]string{"default": "server", "override": "tool"} {
+		st, ok := s.tools.get(name)
+		if !ok {
+			t.Fatalf("missing tool %q", name)
+		}
+		got := st.tool.InputSchema.(*schema).Properties["temp"].Description
+		if got != want {
+			t.Errorf("%s: got description %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	ctx := context.Background()
+	var requested []string
+	server := NewServer(testImpl, &ServerOptions{
+		NegotiateVersion: func(v string) (string, error) {
+			requested = append(requested, v)
+			switch v {
+			case "refuse":
+				return "", errors.New("refused")
+			case "bogus":
+				return "1999-01-01", nil
+			}
+			return protocolVersion20250326, nil
+		},
+	})
+	connect := func(version string) (*ClientSession, *ServerSession, error) {
+		client := NewClient(testImpl, nil)
+		client.AddSendingMiddleware(func(next MethodHandler) MethodHandler {
+			return func(ctx context.Context, method string, req Request) (Result, error) {
+				if p, ok := req.GetParams().(*InitializeParams); ok {
+					p.ProtocolVersion = version
+				}
+				return next(ctx, method, req)
+			}
+		})
+		ct, st := NewInMemoryTransports()
+		ss, err := server.Connect(ctx, st, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ss.Close() })
+		cs, err := client.Connect(ctx, ct, nil)
+		if err == nil {
+			t.Cleanup(func() { cs.Close() })
+		}
+		return cs, ss, err
+	}
+
+	cs, ss, err := connect(latestProtocolVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cs.ProtocolVersion(); got != protocolVersion20250326 {
+		t.Errorf("client: ProtocolVersion() = %q, want %q", got, protocolVersion20250326)
+	}
+	if got := ss.ProtocolVersion(); got != protocolVersion20250326 {
+		t.Errorf("server: ProtocolVersion() = %q, want %q", got, protocolVersion20250326)
+	}
+	for _, v := range []string{"refuse", "bogus"} {
+		if _, _, err := connect(v); err == nil {
+			t.Errorf("requesting %q: got nil error", v)
+		}
+	}
+	if diff := cmp.Diff([]string{latestProtocolVersion, "refuse", "bogus"}, requested); diff != "" {
+		t.Errorf("requested versions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInstructionsFunc(t *testing.T) {
+	server := NewServer(testImpl, &ServerOptions{
+		Instructions: "static",
+		InstructionsFunc: func(_ context.Context, req *InitializeServerRequest) string {
+			return "Hello, " + req.Params.ClientInfo.Name
+		},
+	})
+	client := NewClient(&Implementation{Name: "alice", Version: "v1"}, nil)
+	cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+	defer cleanup()
+	if got, want := cs.InitializeResult().Instructions, "Hello, alice"; got != want {
+		t.Errorf("Instructions = %q, want %q", got, want)
+	}
+}
+
+func TestAddToolMiddleware(t *testing.T) {
+	var gated []string
+	gate := func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			name := req.(*CallToolRequest).Params.Name
+			gated = append(gated, name)
+			if name == "delete_all" {
+				return nil, errors.New("not approved")
+			}
+			return next(ctx, method, req)
+		}
+	}
+	server := NewServer(testImpl, nil)
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		for _, name := range []string{"delete_one", "delete_all", "read"} {
+			AddTool(s, &Tool{Name: name}, sayHi)
+		}
+		s.AddToolMiddleware("delete_*", gate)
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+	args := map[string]any{"Name": "x"}
+	for _, name := range []string{"read", "delete_one"} {
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: name, Arguments: args}); err != nil {
+			t.Errorf("CallTool(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "delete_all", Arguments: args}); err == nil || !strings.Contains(err.Error(), "not approved") {
+		t.Errorf("CallTool(delete_all) = %v, want not approved", err)
+	}
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"delete_one", "delete_all"}; !slices.Equal(gated, want) {
+		t.Errorf("gated calls = %v, want %v", gated, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("AddToolMiddleware with a bad pattern did not panic")
+		}
+	}()
+	server.AddToolMiddleware("[", gate)
+}
+
+func TestServerShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server.AddTool(&Tool{Name: "slow", InputSchema: &jsonschema.Schema{Type: "object"}}, func(ctx context.Context, _ *CallToolRequest) (*CallToolResult, error) {
+		close(started)
+		<-release
+		return &CallToolResult{Content: []Content{&TextContent{Text: "done"}}}, nil
+	})
+	st, ct := NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := make(chan any, 1)
+	cs, err := NewClient(testImpl, &ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *LoggingMessageRequest) {
+			logs <- req.Params.Data
+		},
+	}).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if err := cs.SetLoggingLevel(ctx, &SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := cs.CallTool(ctx, &CallToolParams{Name: "slow"})
+		callErr <- err
+	}()
+	<-started
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- server.Shutdown(ctx) }()
+
+	// New sessions and tool calls are refused while the call is in progress.
+	for {
+		st, _ := NewInMemoryTransports()
+		ss2, err := server.Connect(ctx, st, nil)
+		if errors.Is(err, ErrServerShutdown) {
+			break
+		}
+		if err == nil {
+			ss2.Close()
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "slow"}); err == nil || !strings.Contains(err.Error(), ErrServerShutdown.Error()) {
+		t.Errorf("CallTool during shutdown: got %v, want %v", err, ErrServerShutdown)
+	}
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned %v before the tool call completed", err)
+	default:
+	}
+
+	// Once the call completes, clients are notified and sessions are closed.
+	close(release)
+	if err := <-callErr; err != nil {
+		t.Errorf("in-progress CallTool failed: %v", err)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+	if got := <-logs; got != "server is shutting down" {
+		t.Errorf("got log message %v, want shutdown notice", got)
+	}
+	waitSession(t, ctx, ss)
+}
+
+func TestServerShutdownTimeout(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	started := make(chan struct{})
+	server.AddTool(&Tool{Name: "hang", InputSchema: &jsonschema.Schema{Type: "object"}}, func(ctx context.Context, _ *CallToolRequest) (*CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	st, ct := NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewClient(testImpl, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	go cs.CallTool(ctx, &CallToolParams{Name: "hang"})
+	<-started
+
+	// Sessions are closed even if the calls do not complete in time.
+	shutdownCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	waitCtx, waitCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer waitCancel()
+	waitSession(t, waitCtx, ss)
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// A ServerEventKind is the kind of a [ServerEvent].
+type ServerEventKind int
+
+const (
+	ToolAdded ServerEventKind = iota + 1
+	ToolRemoved
+	PromptAdded
+	PromptRemoved
+	ResourceAdded
+	ResourceRemoved
+	ResourceTemplateAdded
+	ResourceTemplateRemoved
+	SessionConnected
+	SessionDisconnected
+	// A method handler returned an error.
+	HandlerError
+)
+
+func (k ServerEventKind) String() string {
+	switch k {
+	case ToolAdded:
+		return "tool added"
+	case ToolRemoved:
+		return "tool removed"
+	case PromptAdded:
+		return "prompt added"
+	case PromptRemoved:
+		return "prompt removed"
+	case ResourceAdded:
+		return "resource added"
+	case ResourceRemoved:
+		return "resource removed"
+	case ResourceTemplateAdded:
+		return "resource template added"
+	case ResourceTemplateRemoved:
+		return "resource template removed"
+	case SessionConnected:
+		return "session connected"
+	case SessionDisconnected:
+		return "session disconnected"
+	case HandlerError:
+		return "handler error"
+	}
+	return fmt.Sprintf("ServerEventKind(%d)", int(k))
+}
+
+// A ServerEvent describes something that happened in a [Server].
+// See [Server.Events].
+type ServerEvent struct {
+	Kind ServerEventKind
+	Time time.Time
+	// Name is the name of the tool or prompt, the URI of the resource, or the
+	// URI template of the resource template, for feature events.
+	Name string
+	// Session is the session, for session events and handler errors.
+	Session *ServerSession
+	// Method and Err are the method and error, for handler errors.
+	Method string
+	Err    error
+}
+
+// eventBufferSize is the capacity of the channels returned by Server.Events.
+const eventBufferSize = 100
+
+// Events returns a channel that receives the events of the server that occur
+// after the call: the addition and removal of features, the connection and
+// disconnection of sessions, and errors returned by method handlers.
+//
+// Each call returns a new channel, so that independent subsystems, such as
+// metrics or audit logging, can each observe all events. Events are never
+// delayed for a slow receiver: if a channel's buffer is full, events are
+// dropped for that channel. When ctx is done, the channel stops receiving
+// events and is closed.
+func (s *Server) Events(ctx context.Context) <-chan ServerEvent {
+	ch := s.events.subscribe()
+	context.AfterFunc(ctx, func() { s.events.unsubscribe(ch) })
+	return ch
+}
+
+// eventBus delivers server events to subscribers.
+type eventBus struct {
+	mu   sync.Mutex
+	subs []chan ServerEvent
+}
+
+func (b *eventBus) subscribe() chan ServerEvent {
+	ch := make(chan ServerEvent, eventBufferSize)
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes and closes ch.
+func (b *eventBus) unsubscribe(ch chan ServerEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i := slices.Index(b.subs, ch); i >= 0 {
+		b.subs = slices.Delete(b.subs, i, i+1)
+		close(ch)
+	}
+}
+
+// emit sends e to all subscribers, without blocking.
+func (b *eventBus) emit(e ServerEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) == 0 {
+		return
+	}
+	e.Time = time.Now()
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// removeFeatures removes the features with the given uids from fs, emitting
+// an event of the given kind for each one that was present. It reports
+// whether any were removed.
+func removeFeatures[T any](s *Server, fs *featureSet[T], kind ServerEventKind, uids []string) bool {
+	for _, uid := range uids {
+		if _, ok := fs.get(uid); ok {
+			s.events.emit(ServerEvent{Kind: kind, Name: uid})
+		}
+	}
+	return fs.remove(uids...)
+}
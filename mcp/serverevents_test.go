@@ -0,0 +1,104 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServerEvents(t *testing.T) {
+	s := NewServer(testImpl, nil)
+	events := s.Events(context.Background())
+	other := s.Events(context.Background())
+
+	type summary struct {
+		Kind    ServerEventKind
+		Name    string
+		Method  string
+		Session bool
+		Err     bool
+	}
+	next := func() summary {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Time.IsZero() {
+				t.Errorf("event %v has zero time", e.Kind)
+			}
+			return summary{e.Kind, e.Name, e.Method, e.Session != nil, e.Err != nil}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+			return summary{}
+		}
+	}
+
+	AddTool(s, &Tool{Name: "t"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+	s.AddPrompt(&Prompt{Name: "p"}, nil)
+	s.AddResource(&Resource{URI: "file:///r"}, nil)
+	s.AddResourceTemplate(&ResourceTemplate{URITemplate: "file:///{x}"}, nil)
+	s.RemoveTools("t", "missing")
+	s.RemovePrompts("p")
+	s.RemoveResources("file:///r")
+	s.RemoveResourceTemplates("file:///{x}")
+
+	cs, _, cleanup := basicClientServerConnection(t, nil, s, nil)
+	if _, err := cs.GetPrompt(context.Background(), &GetPromptParams{Name: "missing"}); err == nil {
+		t.Fatal("GetPrompt of missing prompt succeeded")
+	}
+	cleanup()
+
+	want := []summary{
+		{Kind: ToolAdded, Name: "t"},
+		{Kind: PromptAdded, Name: "p"},
+		{Kind: ResourceAdded, Name: "file:///r"},
+		{Kind: ResourceTemplateAdded, Name: "file:///{x}"},
+		{Kind: ToolRemoved, Name: "t"},
+		{Kind: PromptRemoved, Name: "p"},
+		{Kind: ResourceRemoved, Name: "file:///r"},
+		{Kind: ResourceTemplateRemoved, Name: "file:///{x}"},
+		{Kind: SessionConnected, Session: true},
+		{Kind: HandlerError, Method: methodGetPrompt, Session: true, Err: true},
+		{Kind: SessionDisconnected, Session: true},
+	}
+	var got []summary
+	for range want {
+		got = append(got, next())
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	if len(other) != len(want) {
+		t.Errorf("second subscriber got %d events, want %d", len(other), len(want))
+	}
+}
+
+func TestServerEventsCancel(t *testing.T) {
+	s := NewServer(testImpl, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	events := s.Events(ctx)
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("received event after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancellation")
+	}
+	// Later events are not sent to the removed subscription.
+	s.AddPrompt(&Prompt{Name: "p"}, nil)
+	s.events.mu.Lock()
+	n := len(s.events.subs)
+	s.events.mu.Unlock()
+	if n != 0 {
+		t.Errorf("got %d subscriptions after cancellation, want 0", n)
+	}
+}
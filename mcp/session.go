@@ -25,5 +25,22 @@ type ServerSessionState struct {
 	// LogLevel is the logging level for the session.
 	LogLevel LoggingLevel `json:"logLevel"`
 
+	// ProtocolVersion is the negotiated protocol version.
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+
 	// TODO: resource subscriptions
 }
+
+// protocolVersion returns the negotiated protocol version, or the empty
+// string if the session is not initialized.
+func (s *ServerSessionState) protocolVersion() string {
+	if s.ProtocolVersion != "" {
+		return s.ProtocolVersion
+	}
+	if s.InitializeParams != nil {
+		// The state may predate the ProtocolVersion field, or be the default
+		// state of a stateless session.
+		return negotiatedVersion(s.InitializeParams.ProtocolVersion)
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// A SessionEncrypter encrypts the data of a session before a [SessionStore]
+// writes it to external storage, and decrypts it after reading it back.
+// A [StoredSessionInfo] holds the client's initialize params, including its
+// implementation details and capabilities, which should not be readable by
+// everyone with access to the store.
+//
+// Stores that persist sessions outside the process accept a SessionEncrypter
+// in their options; see for example package
+// github.com/orkhanm/go-sdk/sessionredis.
+//
+// Implementations must be safe for concurrent use. They may call out to a key
+// management service, using the given context.
+type SessionEncrypter interface {
+	// Encrypt returns the encryption of plaintext, the encoded data of the
+	// session with the given ID.
+	Encrypt(ctx context.Context, sessionID string, plaintext []byte) ([]byte, error)
+
+	// Decrypt reverses Encrypt. It must fail if ciphertext was not returned
+	// by Encrypt for the same session ID, so that the data of one session
+	// cannot be substituted for another's.
+	Decrypt(ctx context.Context, sessionID string, ciphertext []byte) ([]byte, error)
+}
+
+// ErrSessionDecrypt is returned by the [SessionEncrypter] of
+// [NewAESSessionEncrypter] when data cannot be decrypted with any of its keys.
+var ErrSessionDecrypt = errors.New("cannot decrypt session data")
+
+// NewAESSessionEncrypter returns a [SessionEncrypter] that uses AES-GCM with a
+// random nonce, and authenticates the session ID along with the data.
+//
+// Each key must be 16, 24 or 32 bytes long, to select AES-128, AES-192 or
+// AES-256. Data is encrypted with the first key, and decrypted with whichever
+// key succeeds, so that keys can be rotated: put the new key first, and remove
+// an old key once all sessions encrypted with it have expired.
+func NewAESSessionEncrypter(keys ...[]byte) (SessionEncrypter, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no session encryption keys")
+	}
+	var e aesSessionEncrypter
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("session encryption key %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("session encryption key %d: %w", i, err)
+		}
+		e.aeads = append(e.aeads, aead)
+	}
+	return &e, nil
+}
+
+type aesSessionEncrypter struct {
+	aeads []cipher.AEAD // the first one encrypts
+}
+
+func (e *aesSessionEncrypter) Encrypt(_ context.Context, sessionID string, plaintext []byte) ([]byte, error) {
+	aead := e.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(sessionID)), nil
+}
+
+func (e *aesSessionEncrypter) Decrypt(_ context.Context, sessionID string, ciphertext []byte) ([]byte, error) {
+	for _, aead := range e.aeads {
+		n := aead.NonceSize()
+		if len(ciphertext) < n {
+			continue
+		}
+		if plaintext, err := aead.Open(nil, ciphertext[:n], ciphertext[n:], []byte(sessionID)); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrSessionDecrypt
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAESSessionEncrypter(t *testing.T) {
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 16)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	old, err := NewAESSessionEncrypter(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(`{"sessionState":{"initializeParams":{"clientInfo":{"name":"secret"}}}}`)
+	ciphertext, err := old.Encrypt(ctx, "s1", plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, []byte("secret")) {
+		t.Error("ciphertext contains the plaintext")
+	}
+	again, err := old.Encrypt(ctx, "s1", plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ciphertext, again) {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+	got, err := old.Decrypt(ctx, "s1", ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt = %q, want %q", got, plaintext)
+	}
+
+	// The data of one session cannot be used for another, or tampered with.
+	if _, err := old.Decrypt(ctx, "s2", ciphertext); !errors.Is(err, ErrSessionDecrypt) {
+		t.Errorf("Decrypt with another session ID: got %v, want %v", err, ErrSessionDecrypt)
+	}
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := old.Decrypt(ctx, "s1", tampered); !errors.Is(err, ErrSessionDecrypt) {
+		t.Errorf("Decrypt of tampered data: got %v, want %v", err, ErrSessionDecrypt)
+	}
+	if _, err := old.Decrypt(ctx, "s1", []byte("short")); !errors.Is(err, ErrSessionDecrypt) {
+		t.Errorf("Decrypt of short data: got %v, want %v", err, ErrSessionDecrypt)
+	}
+
+	// After rotation, old data can still be decrypted, and new data is
+	// encrypted with the new key.
+	rotated, err := NewAESSessionEncrypter(newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := rotated.Decrypt(ctx, "s1", ciphertext); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt after rotation: got %q, %v", got, err)
+	}
+	ciphertext, err = rotated.Encrypt(ctx, "s1", plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Decrypt(ctx, "s1", ciphertext); err == nil {
+		t.Error("data encrypted after rotation was decrypted with the old key")
+	}
+
+	for _, keys := range [][][]byte{nil, {[]byte("short")}, {newKey, []byte("short")}} {
+		if _, err := NewAESSessionEncrypter(keys...); err == nil {
+			t.Errorf("NewAESSessionEncrypter(%d keys) succeeded with missing or invalid keys", len(keys))
+		}
+	}
+}
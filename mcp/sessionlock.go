@@ -0,0 +1,141 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sessionLockTTL bounds how long a lock is held by an instance that fails
+// to release it, for example because it crashed. Held locks are renewed
+// every third of it. It is a variable for testing.
+var sessionLockTTL = 30 * time.Second
+
+// sessionLockRetry is the interval at which a held lock is retried.
+const sessionLockRetry = 10 * time.Millisecond
+
+// A SessionLocker is a [SessionStore] that can also provide mutual exclusion
+// for a session across server instances. See [StreamableHTTPHandler.SessionLock].
+//
+// Implementations must be safe for concurrent use, and TryLock and Unlock
+// must be atomic across all instances sharing the store, for example by
+// using compare-and-set scripts in Redis, as the sessionredis package does.
+type SessionLocker interface {
+	// TryLock acquires the lock for the session on behalf of owner, if it is
+	// not held by another owner. It reports whether the lock was acquired.
+	// The lock is released automatically after ttl.
+	//
+	// If owner already holds the lock, TryLock must succeed and extend the
+	// lock to expire after ttl: held locks are renewed this way.
+	TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock for the session, if it is held by owner.
+	Unlock(ctx context.Context, sessionID, owner string) error
+}
+
+// SessionLock acquires a lock for the given session, blocking until it is
+// available or ctx is done. It returns a function that releases the lock.
+//
+// If the handler's [StreamableHTTPOptions.SessionStore] implements
+// [SessionLocker], the lock is held in the store, so that instances sharing
+// the store exclude each other. This allows preserving the order of processing
+// when several instances receive requests for the same session concurrently,
+// for example in HTTP middleware keyed by the Mcp-Session-Id header.
+// Otherwise, the lock only excludes callers using the same handler.
+//
+// The lock is renewed periodically until it is released, so it may be held
+// for as long as needed. If the instance holding it fails to release it, for
+// example because it crashed, it expires after 30 seconds.
+func (h *StreamableHTTPHandler) SessionLock(ctx context.Context, sessionID string) (unlock func(), err error) {
+	var locker SessionLocker = &h.locks
+	if l, ok := h.options().SessionStore.(SessionLocker); ok {
+		locker = l
+	}
+	owner := randText()
+	for {
+		ok, err := locker.TryLock(ctx, sessionID, owner, sessionLockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sessionLockRetry):
+		}
+	}
+	// Renew the lock until it is released.
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(sessionLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			ok, err := locker.TryLock(context.Background(), sessionID, owner, sessionLockTTL)
+			if err != nil {
+				h.options().Logger.Error("failed to renew session lock", "error", err, "session_id", sessionID)
+			} else if !ok {
+				h.options().Logger.Error("session lock expired while held", "session_id", sessionID)
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-stopped
+			if err := locker.Unlock(context.Background(), sessionID, owner); err != nil {
+				h.options().Logger.Error("failed to release session lock", "error", err, "session_id", sessionID)
+			}
+		})
+	}, nil
+}
+
+// memoryLocks implements [SessionLocker] in memory.
+// The zero value is ready to use.
+type memoryLocks struct {
+	mu   sync.Mutex
+	held map[string]heldLock // keyed by session ID
+}
+
+type heldLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+func (l *memoryLocks) TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if hl, ok := l.held[sessionID]; ok && hl.owner != owner && now.Before(hl.expiresAt) {
+		return false, nil
+	}
+	if l.held == nil {
+		l.held = make(map[string]heldLock)
+	}
+	l.held[sessionID] = heldLock{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (l *memoryLocks) Unlock(ctx context.Context, sessionID, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if hl, ok := l.held[sessionID]; ok && hl.owner == owner {
+		delete(l.held, sessionID)
+	}
+	return nil
+}
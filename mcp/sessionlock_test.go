@@ -0,0 +1,101 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSessionLock(t *testing.T) {
+	store := NewInMemorySessionStore()
+	defer store.Close()
+	getServer := func(*http.Request) *Server { return NewServer(testImpl, nil) }
+	// Two handlers sharing a store exclude each other.
+	h1 := NewStreamableHTTPHandler(getServer, &StreamableHTTPOptions{SessionStore: store})
+	h2 := NewStreamableHTTPHandler(getServer, &StreamableHTTPOptions{SessionStore: store})
+
+	ctx := context.Background()
+	unlock, err := h1.SessionLock(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Other sessions are unaffected.
+	unlockOther, err := h2.SessionLock(ctx, "s2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockOther()
+
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := h2.SessionLock(shortCtx, "s1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SessionLock of held lock: got %v, want deadline exceeded", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		unlock2, err := h2.SessionLock(ctx, "s1")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- unlock2
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	unlock() // idempotent
+	(<-acquired)()
+}
+
+func TestMemoryLocksExpire(t *testing.T) {
+	var l memoryLocks
+	ctx := context.Background()
+	if ok, _ := l.TryLock(ctx, "s", "a", time.Millisecond); !ok {
+		t.Fatal("TryLock of free lock failed")
+	}
+	if ok, _ := l.TryLock(ctx, "s", "b", time.Hour); ok {
+		t.Fatal("TryLock of held lock succeeded")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := l.TryLock(ctx, "s", "b", time.Hour); !ok {
+		t.Fatal("TryLock of expired lock failed")
+	}
+	// A stale owner cannot release the lock.
+	l.Unlock(ctx, "s", "a")
+	if ok, _ := l.TryLock(ctx, "s", "c", time.Hour); ok {
+		t.Fatal("lock released by stale owner")
+	}
+}
+
+func TestSessionLockRenewal(t *testing.T) {
+	defer func(ttl time.Duration) { sessionLockTTL = ttl }(sessionLockTTL)
+	sessionLockTTL = 30 * time.Millisecond
+
+	h := NewStreamableHTTPHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, nil)
+	ctx := context.Background()
+	unlock, err := h.SessionLock(ctx, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lock is held for longer than its TTL.
+	shortCtx, cancel := context.WithTimeout(ctx, 5*sessionLockTTL)
+	defer cancel()
+	if _, err := h.SessionLock(shortCtx, "s"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SessionLock of held lock: got %v, want deadline exceeded", err)
+	}
+	unlock()
+	unlock2, err := h.SessionLock(ctx, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock2()
+}
@@ -41,10 +41,16 @@ var (
 // Implementations must preserve the refs field when storing and retrieving
 // session data to ensure timeout logic works correctly across server instances.
 //
-// # Example Implementation
+// # Encryption
 //
-// See the examples/server/redis-sessions directory for a complete Redis-based
-// implementation.
+// Session data includes the client's initialize params. Implementations that
+// write it to external storage should allow encrypting it with a
+// [SessionEncrypter].
+//
+// # Implementations
+//
+// Package github.com/orkhanm/go-sdk/sessionredis provides an implementation
+// backed by Redis.
 type SessionStore interface {
 	// Get retrieves a session by its ID.
 	//
@@ -108,6 +114,16 @@ type StoredSessionInfo struct {
 
 	// LastAccessedAt is when the session was last accessed.
 	LastAccessedAt time.Time `json:"lastAccessedAt"`
+
+	// HandedOff reports whether the session was saved by a server instance
+	// that shut down, so that another instance may resume it.
+	// See [StreamableHTTPHandler.Shutdown].
+	HandedOff bool `json:"handedOff,omitempty"`
+
+	// Route is the pattern at which the session's server is mounted in a
+	// [StreamableServeMux], if any. A session is only recovered by the
+	// handler mounted at the same pattern.
+	Route string `json:"route,omitempty"`
 }
 
 // InMemorySessionStore is a simple in-memory implementation of SessionStore.
@@ -123,6 +139,7 @@ type InMemorySessionStore struct {
 	sessions map[string]*memorySessionEntry
 	stopCh   chan struct{}
 	once     sync.Once
+	locks    memoryLocks
 }
 
 type memorySessionEntry struct {
@@ -238,6 +255,16 @@ func (s *InMemorySessionStore) RefreshTTL(ctx context.Context, sessionID string,
 	return nil
 }
 
+// TryLock implements SessionLocker.TryLock.
+func (s *InMemorySessionStore) TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	return s.locks.TryLock(ctx, sessionID, owner, ttl)
+}
+
+// Unlock implements SessionLocker.Unlock.
+func (s *InMemorySessionStore) Unlock(ctx context.Context, sessionID, owner string) error {
+	return s.locks.Unlock(ctx, sessionID, owner)
+}
+
 // cleanupLoop runs in the background and removes expired sessions.
 func (s *InMemorySessionStore) cleanupLoop() {
 	ticker := time.NewTicker(30 * time.Second)
@@ -16,6 +16,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
+	"math/rand/v2"
 	"net/http"
 	"reflect"
 	"slices"
@@ -45,6 +47,12 @@ var supportedProtocolVersions = []string{
 	protocolVersion20241105,
 }
 
+// SupportedProtocolVersions returns the protocol versions that the SDK
+// supports, latest first.
+func SupportedProtocolVersions() []string {
+	return slices.Clone(supportedProtocolVersions)
+}
+
 // negotiatedVersion returns the effective protocol version to use, given a
 // client version.
 func negotiatedVersion(clientVersion string) string {
@@ -74,6 +82,9 @@ type Session interface {
 	sendingMethodHandler() MethodHandler
 	receivingMethodHandler() MethodHandler
 	getConn() *jsonrpc2.Connection
+	// ProtocolVersion returns the negotiated protocol version, or the empty
+	// string if the session is not initialized.
+	ProtocolVersion() string
 }
 
 // Middleware is a function from [MethodHandler] to [MethodHandler].
@@ -96,15 +107,44 @@ func defaultSendingMethodHandler[S Session](ctx context.Context, method string,
 	if strings.HasPrefix(method, "notifications/") {
 		return nil, req.GetSession().getConn().Notify(ctx, method, req.GetParams())
 	}
+	if timeout := requestTimeout(req.GetSession()); timeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	params := downgradeParams(req.GetSession().ProtocolVersion(), req.GetParams())
+	if cs, ok := req.GetSession().(*ClientSession); ok {
+		var done func()
+		params, done = cs.attachProgressToken(ctx, method, params, info.newParams)
+		defer done()
+		params = cs.attachDeadlineHint(ctx, method, params)
+		if cs.hedged(method) {
+			return cs.hedgedCall(ctx, method, params, info.newResult)
+		}
+	}
 	// Create the result to unmarshal into.
 	// The concrete type of the result is the return type of the receiving function.
 	res := info.newResult()
-	if err := call(ctx, req.GetSession().getConn(), method, req.GetParams(), res); err != nil {
+	if err := call(ctx, req.GetSession().getConn(), method, params, res); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// requestTimeout returns the RequestTimeout option of the client or server
+// of the session.
+func requestTimeout(s Session) time.Duration {
+	switch s := s.(type) {
+	case *ClientSession:
+		return s.client.opts.RequestTimeout
+	case *ServerSession:
+		return s.server.opts.RequestTimeout
+	}
+	return 0
+}
+
 // Helper method to avoid typed nil.
 func orZero[T any, P *U, U any](p P) T {
 	if p == nil {
@@ -146,20 +186,30 @@ func handleReceive[S Session](ctx context.Context, session S, jreq *jsonrpc.Requ
 	if err != nil {
 		return nil, err
 	}
-	params, err := info.unmarshalParams(jreq.Params)
+	var params Params
+	if v, ok := jsonrpc2.DirectValue(jreq); ok {
+		params, err = info.directParams(v)
+	} else {
+		params, err = info.unmarshalParams(jreq.Params)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("handling '%s': %w", jreq.Method, err)
 	}
 
 	mh := session.receivingMethodHandler()
 	re, _ := jreq.Extra.(*RequestExtra)
+	if ss, ok := any(session).(*ServerSession); ok && jreq.IsCall() {
+		var cancel context.CancelFunc
+		ctx, cancel = ss.applyDeadlineHint(ctx, params)
+		defer cancel()
+	}
 	req := info.newRequest(session, params, re)
 	// mh might be user code, so ensure that it returns the right values for the jsonrpc2 protocol.
 	res, err := mh(ctx, jreq.Method, req)
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	return downgradeResult(session.ProtocolVersion(), res), nil
 }
 
 // checkRequest checks the given request against the provided method info, to
@@ -186,7 +236,11 @@ func checkRequest(req *jsonrpc.Request, infos map[string]methodInfo) (methodInfo
 	//
 	// However, it's checked again after unmarshalling to catch the rare but
 	// possible case where "params" is JSON null (see https://go.dev/issue/33835).
-	if info.flags&missingParamsOK == 0 && len(req.Params) == 0 {
+	hasParams := len(req.Params) > 0
+	if v, ok := jsonrpc2.DirectValue(req); ok {
+		hasParams = v != nil
+	}
+	if info.flags&missingParamsOK == 0 && !hasParams {
 		return methodInfo{}, fmt.Errorf("%w: missing required \"params\"", jsonrpc2.ErrInvalidRequest)
 	}
 	return info, nil
@@ -200,7 +254,13 @@ type methodInfo struct {
 	// Unmarshal params from the wire into a Params struct.
 	// Used on the receive side.
 	unmarshalParams func(json.RawMessage) (Params, error)
-	newRequest      func(Session, Params, *RequestExtra) Request
+	// Convert params received as a Go value on a direct connection into a
+	// Params struct. Used on the receive side.
+	directParams func(any) (Params, error)
+	newRequest   func(Session, Params, *RequestExtra) Request
+	// Create a pointer to an empty Params struct, for sending a request
+	// whose params are nil. Used on the send side.
+	newParams func() Params
 	// Run the code when a call to the method is received.
 	// Used on the receive side.
 	handleMethod MethodHandler
@@ -268,27 +328,51 @@ func newServerMethodInfo[P paramsPtr[T], R Result, T any](d typedServerMethodHan
 // If isRequest is set, the method is treated as a request rather than a
 // notification.
 func newMethodInfo[P paramsPtr[T], R Result, T any](flags methodFlags) methodInfo {
+	unmarshalParams := func(m json.RawMessage) (Params, error) {
+		var p P
+		if m != nil {
+			if err := json.Unmarshal(m, &p); err != nil {
+				return nil, fmt.Errorf("unmarshaling %q into a %T: %w", m, p, err)
+			}
+		}
+		// We must check missingParamsOK here, in addition to checkRequest, to
+		// catch the edge cases where "params" is set to JSON null.
+		// See also https://go.dev/issue/33835.
+		//
+		// We need to ensure that p is non-null to guard against crashes, as our
+		// internal code or externally provided handlers may assume that params
+		// is non-null.
+		if flags&missingParamsOK == 0 && p == nil {
+			return nil, fmt.Errorf("%w: missing required \"params\"", jsonrpc2.ErrInvalidRequest)
+		}
+		return orZero[Params](p), nil
+	}
 	return methodInfo{
-		flags: flags,
-		unmarshalParams: func(m json.RawMessage) (Params, error) {
-			var p P
-			if m != nil {
-				if err := json.Unmarshal(m, &p); err != nil {
-					return nil, fmt.Errorf("unmarshaling %q into a %T: %w", m, p, err)
+		flags:           flags,
+		unmarshalParams: unmarshalParams,
+		directParams: func(v any) (Params, error) {
+			p, ok := v.(P)
+			if c, isConverter := v.(directConverter); !ok && isConverter {
+				cv, err := c.convertDirect()
+				if err != nil {
+					return nil, err
+				}
+				p, ok = cv.(P)
+			}
+			if !ok {
+				// The sender used another type, as for older protocol versions.
+				data, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
 				}
+				return unmarshalParams(data)
 			}
-			// We must check missingParamsOK here, in addition to checkRequest, to
-			// catch the edge cases where "params" is set to JSON null.
-			// See also https://go.dev/issue/33835.
-			//
-			// We need to ensure that p is non-null to guard against crashes, as our
-			// internal code or externally provided handlers may assume that params
-			// is non-null.
 			if flags&missingParamsOK == 0 && p == nil {
 				return nil, fmt.Errorf("%w: missing required \"params\"", jsonrpc2.ErrInvalidRequest)
 			}
 			return orZero[Params](p), nil
 		},
+		newParams: func() Params { return P(new(T)) },
 		// newResult is used on the send side, to construct the value to unmarshal the result into.
 		// R is a pointer to a result struct. There is no way to "unpointer" it without reflection.
 		// TODO(jba): explore generic approaches to this, perhaps by treating R in
@@ -329,15 +413,6 @@ func clientSessionMethod[P Params, R Result](f func(*ClientSession, context.Cont
 	}
 }
 
-// Error codes
-const (
-	codeResourceNotFound = -32002
-	// The error code if the method exists and was called properly, but the peer does not support it.
-	codeUnsupportedMethod = -31001
-	// The error code for invalid parameters
-	codeInvalidParams = -32602
-)
-
 // notifySessions calls Notify on all the sessions.
 // Should be called on a copy of the peer sessions.
 func notifySessions[S Session, P Params](sessions []S, method string, params P) {
@@ -395,10 +470,22 @@ func setProgressToken(p Params, pt any) {
 	m := p.GetMeta()
 	if m == nil {
 		m = map[string]any{}
+		p.SetMeta(m)
 	}
 	m[progressTokenKey] = pt
 }
 
+// copyParams returns a shallow copy of params. Its metadata is copied, so that
+// it can be modified without affecting params.
+func copyParams(params RequestParams) RequestParams {
+	v := reflect.ValueOf(params)
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	p := c.Interface().(RequestParams)
+	p.SetMeta(maps.Clone(params.GetMeta()))
+	return p
+}
+
 // A Request is a method request with parameters and additional information, such as the session.
 // Request is implemented by [*ClientRequest] and [*ServerRequest].
 type Request interface {
@@ -502,40 +589,126 @@ type listResult[T any] interface {
 	nextCursorPtr() *string
 }
 
+// KeepAliveOptions tunes the keepalive check of a session, enabled by the
+// KeepAlive field of [ClientOptions] or [ServerOptions].
+type KeepAliveOptions struct {
+	// MaxFailures is the number of consecutive pings that must fail for the
+	// session to be closed. If zero, the session is closed after the first
+	// failed ping. A successful ping resets the count.
+	MaxFailures int
+	// Jitter, if positive, is the largest fraction of the keepalive interval
+	// by which each wait between pings is randomly shortened or lengthened,
+	// so that many sessions don't ping in lockstep. Values greater than 0.5
+	// are treated as 0.5, so that each wait is at least half the interval.
+	Jitter float64
+	// OnFailure, if non-nil, is called after each failed ping, with the
+	// error and the number of consecutive failures. When failures reaches
+	// MaxFailures, it is called before the session is closed, so that the
+	// application can log the failure or prepare to recover from it.
+	// OnFailure is called from the keepalive goroutine, and pings are
+	// paused until it returns.
+	OnFailure func(session Session, err error, failures int)
+}
+
+// maxKeepaliveJitter is the largest honored [KeepAliveOptions.Jitter].
+const maxKeepaliveJitter = 0.5
+
+// keepaliveWait returns a random wait before the next ping, which differs
+// from interval by at most the given fraction of it. The jitter is bounded,
+// so that pings are never sent back to back.
+func keepaliveWait(interval time.Duration, jitter float64) time.Duration {
+	jitter = min(jitter, maxKeepaliveJitter)
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration((2*rand.Float64()-1)*jitter*float64(interval))
+}
+
 // keepaliveSession represents a session that supports keepalive functionality.
 type keepaliveSession interface {
+	Session
 	Ping(ctx context.Context, params *PingParams) error
 	Close() error
 }
 
 // startKeepalive starts the keepalive mechanism for a session.
 // It assigns the cancel function to the provided cancelPtr and starts a goroutine
-// that sends ping messages at the specified interval.
-func startKeepalive(session keepaliveSession, interval time.Duration, cancelPtr *context.CancelFunc) {
+// that sends ping messages at the specified interval, tuned by opts, which may
+// be nil.
+func startKeepalive(session keepaliveSession, interval time.Duration, opts *KeepAliveOptions, cancelPtr *context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 	// Assign cancel function before starting goroutine to avoid race condition.
 	// We cannot return it because the caller may need to cancel during the
 	// window between goroutine scheduling and function return.
 	*cancelPtr = cancel
 
+	if opts == nil {
+		opts = &KeepAliveOptions{}
+	}
+	maxFailures := max(opts.MaxFailures, 1)
+	next := func() time.Duration { return keepaliveWait(interval, opts.Jitter) }
+
 	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+		timer := time.NewTimer(next())
+		defer timer.Stop()
 
+		failures := 0
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
+			case <-timer.C:
 				pingCtx, pingCancel := context.WithTimeout(context.Background(), interval/2)
 				err := session.Ping(pingCtx, nil)
 				pingCancel()
-				if err != nil {
-					// Ping failed, close the session
-					_ = session.Close()
-					return
+				if err == nil {
+					failures = 0
+				} else {
+					failures++
+					if opts.OnFailure != nil {
+						opts.OnFailure(session, err, failures)
+					}
+					if failures >= maxFailures {
+						// Too many pings failed, close the session
+						_ = session.Close()
+						return
+					}
 				}
+				timer.Reset(next())
 			}
 		}
 	}()
 }
+
+// An inFlight counts operations in progress, so that a shutdown can wait for
+// them to complete. Its methods must be called with the mutex of its owner
+// held.
+type inFlight struct {
+	n    int
+	idle chan struct{} // if non-nil, closed when n drops to zero
+}
+
+// start records the start of an operation.
+func (f *inFlight) start() { f.n++ }
+
+// finish records the completion of an operation.
+func (f *inFlight) finish() {
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+}
+
+// wait returns a channel that is closed when no operations are in progress.
+func (f *inFlight) wait() <-chan struct{} {
+	if f.n == 0 {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	return f.idle
+}
@@ -7,11 +7,13 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
 	"github.com/orkhanm/go-sdk/jsonrpc"
@@ -51,9 +53,32 @@ type SSEHandler struct {
 }
 
 // SSEOptions specifies options for an [SSEHandler].
-// for now, it is empty, but may be extended in future.
-// https://github.com/modelcontextprotocol/go-sdk/issues/507
-type SSEOptions struct{}
+type SSEOptions struct {
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// posted to a session. See [SSEServerTransport.MaxMessageSize].
+	MaxMessageSize int
+
+	// WriteTimeout, if positive, bounds each write to the event stream of a
+	// session. See [SSEServerTransport.WriteTimeout].
+	WriteTimeout time.Duration
+
+	// FlushInterval, if positive, is how long events may be buffered before
+	// they are flushed. See [SSEServerTransport.FlushInterval].
+	FlushInterval time.Duration
+
+	// MaxBufferedEvents, if positive, is the number of buffered events that
+	// causes a flush. See [SSEServerTransport.MaxBufferedEvents].
+	MaxBufferedEvents int
+
+	// AllowedOrigins, if non-empty, lists the origins allowed to make
+	// requests. Requests with another Origin header are rejected with 403
+	// Forbidden. See [StreamableHTTPOptions.AllowedOrigins].
+	AllowedOrigins []string
+
+	// ValidateOrigin, if non-nil, reports whether requests with the given
+	// Origin header are allowed, in place of AllowedOrigins.
+	ValidateOrigin func(origin string) bool
+}
 
 // NewSSEHandler returns a new [SSEHandler] that creates and manages MCP
 // sessions created via incoming HTTP requests.
@@ -110,6 +135,29 @@ type SSEServerTransport struct {
 	// Response is the hanging response body to the incoming GET request.
 	Response http.ResponseWriter
 
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// posted to the session endpoint. Larger messages are rejected with 413
+	// Request Entity Too Large, before they are read in full.
+	MaxMessageSize int
+
+	// WriteTimeout, if positive, is the deadline for writing and flushing each
+	// event to Response, so that a client that stops reading cannot block the
+	// session indefinitely. If a write fails, as when it times out, the
+	// session is closed. The timeout requires a Response that supports write
+	// deadlines (see [http.ResponseController]); otherwise it is ignored.
+	WriteTimeout time.Duration
+
+	// FlushInterval, if positive, is how long written events may be buffered
+	// before they are flushed to the client, so that bursts of events are
+	// sent together. If zero, each event is flushed as it is written.
+	FlushInterval time.Duration
+
+	// MaxBufferedEvents, if positive, is the maximum number of events to
+	// buffer while waiting for FlushInterval to elapse. When it is reached,
+	// the events are flushed immediately. It is ignored if FlushInterval is
+	// zero.
+	MaxBufferedEvents int
+
 	// incoming is the queue of incoming messages.
 	// It is never closed, and by convention, incoming is non-nil if and only if
 	// the transport is connected.
@@ -122,6 +170,9 @@ type SSEServerTransport struct {
 	mu     sync.Mutex    // also guards writes to Response
 	closed bool          // set when the stream is closed
 	done   chan struct{} // closed when the connection is closed
+
+	buffered   int         // events written since the last flush
+	flushTimer *time.Timer // if non-nil, a pending flush of buffered events
 }
 
 // ServeHTTP handles POST requests to the transport endpoint.
@@ -132,7 +183,14 @@ func (t *SSEServerTransport) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	}
 
 	// Read and parse the message.
+	if t.MaxMessageSize > 0 {
+		req.Body = http.MaxBytesReader(w, req.Body, int64(t.MaxMessageSize))
+	}
 	data, err := io.ReadAll(req.Body)
+	if msg, ok := bodyTooLarge(err); ok {
+		http.Error(w, msg, http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
 		http.Error(w, "failed to read body", http.StatusBadRequest)
 		return
@@ -178,6 +236,10 @@ func (t *SSEServerTransport) Connect(context.Context) (Connection, error) {
 }
 
 func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !originAllowed(req, h.opts.AllowedOrigins, h.opts.ValidateOrigin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
 	sessionID := req.URL.Query().Get("sessionid")
 
 	// TODO: consider checking Content-Type here. For now, we are lax.
@@ -222,7 +284,14 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	transport := &SSEServerTransport{Endpoint: endpoint.RequestURI(), Response: w}
+	transport := &SSEServerTransport{
+		Endpoint:          endpoint.RequestURI(),
+		Response:          w,
+		MaxMessageSize:    h.opts.MaxMessageSize,
+		WriteTimeout:      h.opts.WriteTimeout,
+		FlushInterval:     h.opts.FlushInterval,
+		MaxBufferedEvents: h.opts.MaxBufferedEvents,
+	}
 
 	// The session is terminated when the request exits.
 	h.mu.Lock()
@@ -241,6 +310,11 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	ss, err := server.Connect(req.Context(), transport, nil)
+	if errors.Is(err, ErrServerShutdown) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		http.Error(w, "connection failed", http.StatusInternalServerError)
 		return
@@ -298,10 +372,85 @@ func (s *sseServerConn) Write(ctx context.Context, msg jsonrpc.Message) error {
 		return io.EOF
 	}
 
-	_, err = writeEvent(s.t.Response, Event{Name: "message", Data: data})
+	if err := s.t.writeLocked(Event{Name: "message", Data: data}); err != nil {
+		// The stream is broken, or the client has stopped reading it.
+		s.t.closeLocked()
+		return err
+	}
+	return nil
+}
+
+// writeLocked writes evt to the response, and flushes it according to the
+// transport's FlushInterval and MaxBufferedEvents.
+//
+// t.mu must be held.
+func (t *SSEServerTransport) writeLocked(evt Event) error {
+	t.setWriteDeadline()
+	// Hide the response's Flusher, so that writeEvent doesn't flush: we flush
+	// below, observing the error.
+	if _, err := writeEvent(struct{ io.Writer }{t.Response}, evt); err != nil {
+		return err
+	}
+	t.buffered++
+	if t.FlushInterval <= 0 || (t.MaxBufferedEvents > 0 && t.buffered >= t.MaxBufferedEvents) {
+		return t.flushLocked()
+	}
+	if t.flushTimer == nil {
+		t.flushTimer = time.AfterFunc(t.FlushInterval, func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.flushTimer = nil
+			if !t.closed && t.buffered > 0 {
+				if err := t.flushLocked(); err != nil {
+					t.closeLocked()
+				}
+			}
+		})
+	}
+	return nil
+}
+
+// flushLocked flushes buffered events to the client.
+//
+// t.mu must be held.
+func (t *SSEServerTransport) flushLocked() error {
+	t.buffered = 0
+	if t.flushTimer != nil {
+		t.flushTimer.Stop()
+		t.flushTimer = nil
+	}
+	t.setWriteDeadline()
+	err := http.NewResponseController(t.Response).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
 	return err
 }
 
+// setWriteDeadline sets the deadline of the next write to the response,
+// if the transport has a WriteTimeout.
+func (t *SSEServerTransport) setWriteDeadline() {
+	if t.WriteTimeout > 0 {
+		// If the response doesn't support deadlines, there is no timeout.
+		_ = http.NewResponseController(t.Response).SetWriteDeadline(time.Now().Add(t.WriteTimeout))
+	}
+}
+
+// closeLocked closes the transport, causing the hanging GET to exit.
+//
+// t.mu must be held.
+func (t *SSEServerTransport) closeLocked() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	close(t.done)
+	if t.flushTimer != nil {
+		t.flushTimer.Stop()
+		t.flushTimer = nil
+	}
+}
+
 // Close implements io.Closer, and closes the session.
 //
 // It must be safe to call Close more than once, as the close may
@@ -310,10 +459,7 @@ func (s *sseServerConn) Write(ctx context.Context, msg jsonrpc.Message) error {
 func (s *sseServerConn) Close() error {
 	s.t.mu.Lock()
 	defer s.t.mu.Unlock()
-	if !s.t.closed {
-		s.t.closed = true
-		close(s.t.done)
-	}
+	s.t.closeLocked()
 	return nil
 }
 
@@ -329,6 +475,49 @@ type SSEClientTransport struct {
 	// HTTPClient is the client to use for making HTTP requests. If nil,
 	// http.DefaultClient is used.
 	HTTPClient *http.Client
+
+	// MaxEventSize is the maximum size in bytes of a server-sent event. If the
+	// server sends a larger event, the connection fails with an error wrapping
+	// [ErrEventTooLarge]. If zero, [DefaultMaxEventSize] is used.
+	MaxEventSize int
+
+	// ReadBufferSize is the initial size in bytes of the buffer for reading
+	// server-sent events. The buffer grows as needed, up to MaxEventSize.
+	// If zero, a small default is used.
+	ReadBufferSize int
+
+	// ConnectTimeout, if positive, limits the time to establish each network
+	// connection to the endpoint, so that [Client.Connect] fails fast if the
+	// endpoint is unreachable, whatever the deadline of its context.
+	//
+	// If ConnectTimeout or HandshakeTimeout is set, the transport of the
+	// HTTPClient must be nil or an [*http.Transport]. It is cloned, and not
+	// modified.
+	ConnectTimeout time.Duration
+
+	// HandshakeTimeout, if positive, limits the time of the TLS handshake of
+	// each connection, as [http.Transport.TLSHandshakeTimeout] does.
+	HandshakeTimeout time.Duration
+}
+
+// httpClient returns the HTTP client to use for requests, configured with
+// the timeouts of the transport.
+func (c *SSEClientTransport) httpClient() (*http.Client, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if c.ConnectTimeout <= 0 && c.HandshakeTimeout <= 0 {
+		return client, nil
+	}
+	transport, err := cloneTransport(client)
+	if err != nil {
+		return nil, err
+	}
+	setTimeouts(transport, c.ConnectTimeout, c.HandshakeTimeout)
+	hc := *client
+	hc.Transport = transport
+	return &hc, nil
 }
 
 // Connect connects through the client endpoint.
@@ -341,19 +530,24 @@ func (c *SSEClientTransport) Connect(ctx context.Context) (Connection, error) {
 	if err != nil {
 		return nil, err
 	}
-	httpClient := c.HTTPClient
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+	httpClient, err := c.httpClient()
+	if err != nil {
+		return nil, err
 	}
+	ownsClient := httpClient != c.HTTPClient && httpClient != http.DefaultClient
 	req.Header.Set("Accept", "text/event-stream")
 	resp, err := httpClient.Do(req)
 	if err != nil {
+		if ownsClient {
+			httpClient.CloseIdleConnections()
+		}
 		return nil, err
 	}
 
+	events := eventScanner{maxEventSize: c.MaxEventSize, bufferSize: c.ReadBufferSize}
 	msgEndpoint, err := func() (*url.URL, error) {
 		var evt Event
-		for evt, err = range scanEvents(resp.Body) {
+		for evt, err = range events.scan(resp.Body) {
 			break
 		}
 		if err != nil {
@@ -362,17 +556,20 @@ func (c *SSEClientTransport) Connect(ctx context.Context) (Connection, error) {
 		if evt.Name != "endpoint" {
 			return nil, fmt.Errorf("first event is %q, want %q", evt.Name, "endpoint")
 		}
-		raw := string(evt.Data)
-		return parsedURL.Parse(raw)
+		return parsedURL.Parse(parseEndpointEvent(evt.Data))
 	}()
 	if err != nil {
 		resp.Body.Close()
+		if ownsClient {
+			httpClient.CloseIdleConnections()
+		}
 		return nil, fmt.Errorf("missing endpoint: %v", err)
 	}
 
 	// From here on, the stream takes ownership of resp.Body.
 	s := &sseClientConn{
 		client:      httpClient,
+		ownsClient:  ownsClient,
 		msgEndpoint: msgEndpoint,
 		incoming:    make(chan []byte, 100),
 		body:        resp.Body,
@@ -382,8 +579,11 @@ func (c *SSEClientTransport) Connect(ctx context.Context) (Connection, error) {
 	go func() {
 		defer s.Close() // close the transport when the GET exits
 
-		for evt, err := range scanEvents(resp.Body) {
+		for evt, err := range events.scan(resp.Body) {
 			if err != nil {
+				if errors.Is(err, ErrEventTooLarge) {
+					s.setErr(err)
+				}
 				return
 			}
 			select {
@@ -404,6 +604,7 @@ func (c *SSEClientTransport) Connect(ctx context.Context) (Connection, error) {
 //   - Close terminates the GET request.
 type sseClientConn struct {
 	client      *http.Client // HTTP client to use for requests
+	ownsClient  bool         // client was created for the connection, as by httpClient
 	msgEndpoint *url.URL     // session endpoint for POSTs
 	incoming    chan []byte  // queue of incoming messages
 
@@ -411,6 +612,24 @@ type sseClientConn struct {
 	body   io.ReadCloser // body of the hanging GET
 	closed bool          // set when the stream is closed
 	done   chan struct{} // closed when the stream is closed
+	err    error         // if set, the error that broke the stream, such as ErrEventTooLarge
+}
+
+// setErr records the error that broke the stream.
+func (c *sseClientConn) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// readErr returns the error for a Read after the stream is closed.
+func (c *sseClientConn) readErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return io.EOF
 }
 
 // TODO(jba): get the session ID. (Not urgent because SSE transports have been removed from the spec.)
@@ -428,12 +647,12 @@ func (c *sseClientConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 		return nil, ctx.Err()
 
 	case <-c.done:
-		return nil, io.EOF
+		return nil, c.readErr()
 
 	case data := <-c.incoming:
 		// TODO(rfindley): do we really need to check this? We receive from c.done above.
 		if c.isDone() {
-			return nil, io.EOF
+			return nil, c.readErr()
 		}
 		msg, err := jsonrpc2.DecodeMessage(data)
 		if err != nil {
@@ -473,6 +692,9 @@ func (c *sseClientConn) Close() error {
 	if !c.closed {
 		c.closed = true
 		_ = c.body.Close()
+		if c.ownsClient {
+			c.client.CloseIdleConnections()
+		}
 		close(c.done)
 	}
 	return nil
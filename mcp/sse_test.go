@@ -7,14 +7,21 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/orkhanm/go-sdk/jsonrpc"
 )
 
 func TestSSEServer(t *testing.T) {
@@ -131,3 +138,192 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req)
 }
+
+func TestSSEMaxMessageSize(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	httpServer := httptest.NewServer(NewSSEHandler(func(*http.Request) *Server { return server }, &SSEOptions{MaxMessageSize: 1000}))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &SSEClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": strings.Repeat("x", 1000)}})
+	if err == nil || !strings.Contains(err.Error(), "413") {
+		t.Errorf("CallTool with large arguments: got %v, want status 413", err)
+	}
+}
+
+func TestSSEClientClosesIdleConnections(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	httpServer := httptest.NewServer(NewSSEHandler(func(*http.Request) *Server { return server }, nil))
+	defer httpServer.Close()
+
+	// Count the connections of the client that are open.
+	var open atomic.Int32
+	var dialer net.Dialer
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			c, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			open.Add(1)
+			return &closeCountingConn{Conn: c, open: &open}, nil
+		},
+	}}
+	// With a ConnectTimeout, the transport of the client is cloned for the
+	// connection, which must close its idle connections when done.
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &SSEClientTransport{Endpoint: httpServer.URL, HTTPClient: client, ConnectTimeout: time.Second}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+	for deadline := time.Now().Add(5 * time.Second); open.Load() > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections still open after Close", open.Load())
+		}
+	}
+}
+
+// A closeCountingConn decrements a count of open connections when it is
+// first closed.
+type closeCountingConn struct {
+	net.Conn
+	open *atomic.Int32
+	once sync.Once
+}
+
+func (c *closeCountingConn) Close() error {
+	c.once.Do(func() { c.open.Add(-1) })
+	return c.Conn.Close()
+}
+
+// stallingWriter is an http.ResponseWriter whose writes block once it is
+// stalled, until their deadline. It counts its flushes.
+type stallingWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	flushes  int
+	stalled  bool
+	deadline time.Time
+}
+
+func (w *stallingWriter) Header() http.Header { return w.header }
+func (w *stallingWriter) WriteHeader(int)     {}
+
+func (w *stallingWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(b)
+}
+
+func (w *stallingWriter) FlushError() error {
+	w.mu.Lock()
+	stalled, deadline := w.stalled, w.deadline
+	if !stalled {
+		w.flushes++
+	}
+	w.mu.Unlock()
+	if !stalled {
+		return nil
+	}
+	if deadline.IsZero() {
+		select {} // blocked forever
+	}
+	time.Sleep(time.Until(deadline))
+	return os.ErrDeadlineExceeded
+}
+
+func (w *stallingWriter) SetWriteDeadline(t time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deadline = t
+	return nil
+}
+
+func (w *stallingWriter) flushCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushes
+}
+
+func TestSSEServerWriteTimeout(t *testing.T) {
+	ctx := context.Background()
+	w := &stallingWriter{header: http.Header{}}
+	transport := &SSEServerTransport{Endpoint: "/session", Response: w, WriteTimeout: 10 * time.Millisecond}
+	conn, err := transport.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Write(ctx, &jsonrpc.Request{Method: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A client that stops reading fails the write, and closes the session.
+	w.mu.Lock()
+	w.stalled = true
+	w.mu.Unlock()
+	if err := conn.Write(ctx, &jsonrpc.Request{Method: "ping"}); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Write to stalled client: got %v, want deadline exceeded", err)
+	}
+	select {
+	case <-transport.done:
+	default:
+		t.Error("session not closed after write timeout")
+	}
+	if err := conn.Write(ctx, &jsonrpc.Request{Method: "ping"}); err == nil {
+		t.Error("Write after write timeout succeeded")
+	}
+}
+
+func TestSSEServerFlushInterval(t *testing.T) {
+	ctx := context.Background()
+	w := &stallingWriter{header: http.Header{}}
+	transport := &SSEServerTransport{
+		Endpoint:          "/session",
+		Response:          w,
+		FlushInterval:     20 * time.Millisecond,
+		MaxBufferedEvents: 3,
+	}
+	conn, err := transport.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	base := w.flushCount() // the endpoint event
+
+	// Events are buffered until MaxBufferedEvents is reached...
+	for range 4 {
+		if err := conn.Write(ctx, &jsonrpc.Request{Method: "ping"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := w.flushCount() - base; got != 1 {
+		t.Errorf("after 4 events, got %d flushes, want 1", got)
+	}
+	// ...or FlushInterval elapses.
+	deadline := time.Now().Add(5 * time.Second)
+	for w.flushCount()-base < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("buffered event was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if got := strings.Count(w.buf.String(), "event: message"); got != 4 {
+		t.Errorf("got %d events, want 4", got)
+	}
+}
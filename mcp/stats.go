@@ -0,0 +1,109 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// MethodStats summarizes the handling of a method or tool.
+type MethodStats struct {
+	Count int64 // the number of completed calls
+	// Errors is the number of calls that failed. For tools, this includes
+	// calls whose result has IsError set.
+	Errors       int64
+	TotalLatency time.Duration
+	MinLatency   time.Duration
+	MaxLatency   time.Duration
+}
+
+// MeanLatency returns the mean latency of the calls, or zero if there are
+// none.
+func (m MethodStats) MeanLatency() time.Duration {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.TotalLatency / time.Duration(m.Count)
+}
+
+func (m *MethodStats) add(d time.Duration, failed bool) {
+	if m.Count == 0 || d < m.MinLatency {
+		m.MinLatency = d
+	}
+	m.MaxLatency = max(m.MaxLatency, d)
+	m.Count++
+	m.TotalLatency += d
+	if failed {
+		m.Errors++
+	}
+}
+
+// ServerStats holds statistics about the requests and notifications handled
+// by a [Server]. See [Server.Stats].
+type ServerStats struct {
+	// Since is when the server was created.
+	Since time.Time
+	// Methods holds statistics for each JSON-RPC method received from
+	// clients. Only methods supported by the server are included.
+	Methods map[string]MethodStats
+	// Tools holds statistics for calls of each tool, keyed by tool name.
+	// Calls of unknown tools are not included.
+	Tools map[string]MethodStats
+}
+
+// Stats returns a snapshot of the server's statistics, gathered across all
+// sessions since the server was created.
+//
+// Statistics are always gathered. They are cheap to maintain, and suitable
+// for status pages; for detailed monitoring, use middleware.
+func (s *Server) Stats() *ServerStats {
+	st := &s.stats
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return &ServerStats{
+		Since:   st.since,
+		Methods: maps.Clone(st.methods),
+		Tools:   maps.Clone(st.tools),
+	}
+}
+
+// serverStats accumulates the statistics of a server.
+type serverStats struct {
+	since time.Time
+
+	mu      sync.Mutex
+	methods map[string]MethodStats
+	tools   map[string]MethodStats
+}
+
+// recordMethod records the handling of a received method that started at
+// start.
+func (st *serverStats) recordMethod(method string, start time.Time, err error) {
+	if _, ok := serverMethodInfos[method]; !ok {
+		return // don't let clients grow the map without bound
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.methods = addStats(st.methods, method, time.Since(start), err != nil)
+}
+
+// recordTool records a call of the named tool that started at start.
+func (st *serverStats) recordTool(name string, start time.Time, failed bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.tools = addStats(st.tools, name, time.Since(start), failed)
+}
+
+func addStats(m map[string]MethodStats, key string, d time.Duration, failed bool) map[string]MethodStats {
+	if m == nil {
+		m = make(map[string]MethodStats)
+	}
+	ms := m[key]
+	ms.add(d, failed)
+	m[key] = ms
+	return m
+}
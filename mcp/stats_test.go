@@ -0,0 +1,60 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestServerStats(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	s := NewServer(testImpl, nil)
+	AddTool(s, &Tool{Name: "ok"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		time.Sleep(time.Millisecond)
+		return nil, nil, nil
+	})
+	AddTool(s, &Tool{Name: "fail"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return &CallToolResult{IsError: true}, nil, nil
+	})
+	cs, _, cleanup := basicClientServerConnection(t, nil, s, nil)
+	defer cleanup()
+
+	for _, name := range []string{"ok", "ok", "fail", "missing"} {
+		cs.CallTool(ctx, &CallToolParams{Name: name})
+	}
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := s.Stats()
+	if stats.Since.Before(start) || stats.Since.After(time.Now()) {
+		t.Errorf("Since = %v, want time of creation", stats.Since)
+	}
+	if got := stats.Methods[methodCallTool]; got.Count != 4 || got.Errors != 1 {
+		t.Errorf("%s: got %d calls, %d errors; want 4, 1", methodCallTool, got.Count, got.Errors)
+	}
+	if got := stats.Methods[methodListTools]; got.Count != 1 || got.Errors != 0 {
+		t.Errorf("%s: got %d calls, %d errors; want 1, 0", methodListTools, got.Count, got.Errors)
+	}
+	if got := stats.Methods[methodInitialize]; got.Count != 1 {
+		t.Errorf("%s: got %d calls, want 1", methodInitialize, got.Count)
+	}
+	ok := stats.Tools["ok"]
+	if ok.Count != 2 || ok.Errors != 0 {
+		t.Errorf("tool ok: got %d calls, %d errors; want 2, 0", ok.Count, ok.Errors)
+	}
+	if ok.MinLatency < time.Millisecond || ok.MaxLatency < ok.MinLatency || ok.MeanLatency() < ok.MinLatency {
+		t.Errorf("tool ok: bad latencies %+v", ok)
+	}
+	if fail := stats.Tools["fail"]; fail.Count != 1 || fail.Errors != 1 {
+		t.Errorf("tool fail: got %d calls, %d errors; want 1, 1", fail.Count, fail.Errors)
+	}
+	if _, ok := stats.Tools["missing"]; ok {
+		t.Error("unknown tool has statistics")
+	}
+}
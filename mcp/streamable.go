@@ -7,6 +7,8 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +17,9 @@ import (
 	"maps"
 	"math"
 	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
@@ -31,6 +35,9 @@ import (
 const (
 	protocolVersionHeader = "Mcp-Protocol-Version"
 	sessionIDHeader       = "Mcp-Session-Id"
+	// noStandaloneHeader is set by clients that never open the standalone
+	// SSE stream. See [StreamableClientTransport.DisableStandaloneSSE].
+	noStandaloneHeader = "Mcp-Go-No-Standalone-Sse"
 )
 
 // A StreamableHTTPHandler is an http.Handler that serves streamable MCP
@@ -39,12 +46,19 @@ const (
 // [MCP spec]: https://modelcontextprotocol.io/2025/03/26/streamable-http-transport.html
 type StreamableHTTPHandler struct {
 	getServer func(*http.Request) *Server
-	opts      StreamableHTTPOptions
+	opts      atomic.Pointer[StreamableHTTPOptions] // see Reconfigure
+	route     string                                // the pattern of the handler in a StreamableServeMux
 
 	onTransportDeletion func(sessionID string) // for testing
 
 	mu       sync.Mutex
 	sessions map[string]*sessionInfo // keyed by session ID
+	draining bool                    // set by Shutdown
+	posts    inFlight                // POST requests in progress
+	pruning  bool                    // the pruning goroutine is running
+	creating int                     // number of sessions being created, counted against MaxSessions
+
+	locks memoryLocks // for SessionLock, if the store is not a SessionLocker
 }
 
 type sessionInfo struct {
@@ -56,6 +70,30 @@ type sessionInfo struct {
 	timerMu sync.Mutex
 	refs    int // reference count
 	timer   *time.Timer
+	stopped bool // the timer is stopped permanently
+
+	reason atomic.Int32 // a SessionTerminationReason, set when the session is closed
+
+	// Concurrent requests, counted against the per-session limits of
+	// StreamableHTTPOptions.
+	gets, posts atomic.Int32
+}
+
+// acquireSlot increments n, unless that would exceed a positive limit. It
+// reports whether n was incremented.
+func acquireSlot(n *atomic.Int32, limit int) bool {
+	if n.Add(1) > int32(limit) && limit > 0 {
+		n.Add(-1)
+		return false
+	}
+	return true
+}
+
+// terminate closes the session, recording the reason unless one was already
+// recorded.
+func (i *sessionInfo) terminate(reason SessionTerminationReason) {
+	i.reason.CompareAndSwap(0, int32(reason))
+	i.session.Close()
 }
 
 // toStored converts sessionInfo to StoredSessionInfo for persistence.
@@ -67,6 +105,7 @@ func (i *sessionInfo) toStored() *StoredSessionInfo {
 		Timeout:        i.timeout,
 		CreatedAt:      now,
 		LastAccessedAt: now,
+		Route:          i.transport.route,
 	}
 }
 
@@ -78,17 +117,11 @@ func (i *sessionInfo) toStored() *StoredSessionInfo {
 // streams, but that is tricy to implement. Clients should generally make
 // keepalive pings if they want to keep the session live.
 func (i *sessionInfo) startPOST() {
-	if i.timeout <= 0 {
-		return
-	}
-
 	i.timerMu.Lock()
 	defer i.timerMu.Unlock()
 
-	if i.timer == nil {
-		return // timer stopped permanently
-	}
-	if i.refs == 0 {
+	// Count requests even without a timeout, since one may be set later.
+	if i.refs == 0 && i.timer != nil {
 		i.timer.Stop()
 	}
 	i.refs++
@@ -97,28 +130,47 @@ func (i *sessionInfo) startPOST() {
 // endPOST sigals that a request for this session is ending, starting the
 // timeout if there are no other requests running.
 func (i *sessionInfo) endPOST() {
-	if i.timeout <= 0 {
-		return
-	}
-
 	i.timerMu.Lock()
 	defer i.timerMu.Unlock()
 
-	if i.timer == nil {
-		return // timer stopped permanently
-	}
-
 	i.refs--
 	assert(i.refs >= 0, "negative ref count")
-	if i.refs == 0 {
+	if i.refs == 0 && i.timer != nil {
 		i.timer.Reset(i.timeout)
 	}
 }
 
+// setTimeout sets the idle timeout of the session. If timeout is not
+// positive, the session never times out.
+func (i *sessionInfo) setTimeout(timeout time.Duration) {
+	i.timerMu.Lock()
+	defer i.timerMu.Unlock()
+
+	if i.stopped {
+		return
+	}
+	i.timeout = timeout
+	if i.timer != nil {
+		i.timer.Stop()
+		i.timer = nil
+	}
+	if timeout <= 0 {
+		return
+	}
+	i.timer = time.AfterFunc(timeout, func() {
+		i.terminate(SessionIdleTimeout)
+	})
+	if i.refs > 0 {
+		// The timer starts when the last request ends.
+		i.timer.Stop()
+	}
+}
+
 // stopTimer stops the inactivity timer permanently.
 func (i *sessionInfo) stopTimer() {
 	i.timerMu.Lock()
 	defer i.timerMu.Unlock()
+	i.stopped = true
 	if i.timer != nil {
 		i.timer.Stop()
 		i.timer = nil
@@ -135,6 +187,12 @@ type StreamableHTTPOptions struct {
 	// client to respond. Server->Client notifications may reach the client if
 	// they are made in the context of an incoming request, as described in the
 	// documentation for [StreamableServerTransport].
+	//
+	// Since a stateless session has no standalone SSE stream, notifications
+	// made with an unrelated context, such as a background context, are sent
+	// on the response stream of the request being handled, if it is an event
+	// stream. Those made before the request is received are held until then.
+	// Once the request is complete, such notifications fail.
 	Stateless bool
 
 	// SessionStore configures persistent session storage.
@@ -172,7 +230,259 @@ type StreamableHTTPOptions struct {
 	// duration, they are automatically closed.
 	//
 	// If SessionTimeout is the zero value, idle sessions are never closed.
+	//
+	// The timeout of a session can be changed with
+	// [ServerSession.SetIdleTimeout].
 	SessionTimeout time.Duration
+
+	// If non-nil, SessionTimeoutFunc is called with the request that creates a
+	// session, such as an initialize request. If it returns a positive
+	// duration, that is the session's idle timeout instead of SessionTimeout.
+	// It can be used, for example, to vary the timeout with the authenticated
+	// principal; see [auth.TokenInfoFromContext].
+	SessionTimeoutFunc func(*http.Request) time.Duration
+
+	// DisableDelete prevents clients from terminating sessions with DELETE
+	// requests, which are answered with 405 Method Not Allowed.
+	DisableDelete bool
+
+	// RequireAuthForDelete causes DELETE requests to be rejected with 401
+	// Unauthorized unless they are authenticated, as by
+	// [auth.RequireBearerToken].
+	RequireAuthForDelete bool
+
+	// If non-nil, OnSessionTerminated is called when a session ends, with the
+	// reason it ended. It is not called for stateless sessions.
+	OnSessionTerminated func(sessionID string, reason SessionTerminationReason)
+
+	// If non-nil, OnRequest is called after each HTTP request is handled,
+	// with information about the request and its response.
+	OnRequest func(RequestInfo)
+
+	// If non-nil, WriteError writes the error responses of the handler and
+	// its sessions, such as 400 Bad Request or 404 Not Found, in place of
+	// [http.Error]. The message is the plain-text description that
+	// http.Error would write. See [WriteProblemDetails] for an
+	// implementation that writes RFC 9457 problem details.
+	WriteError func(w http.ResponseWriter, req *http.Request, status int, message string)
+
+	// If non-nil, Prune configures the periodic pruning of abandoned
+	// sessions, streams and requests of the handler. Sessions closed for
+	// exceeding [PruneOptions.MaxSessionAge] are reported to
+	// OnSessionTerminated with reason [SessionExpired]. Prune is ignored if
+	// Stateless is set.
+	Prune *PruneOptions
+
+	// MaxMessageSize, if positive, is the maximum size in bytes of the body
+	// of a POST request, which holds a message or a batch of messages. Larger
+	// requests are rejected with 413 Request Entity Too Large, before they are read
+	// in full.
+	MaxMessageSize int
+
+	// HeartbeatInterval, if positive, is the interval at which an SSE comment
+	// (": ping") is written to the hanging GET streams of sessions, so that
+	// proxies and load balancers do not close them for being idle. Clients
+	// ignore the comments, so unlike MCP pings they need no response.
+	// HeartbeatInterval is ignored if Stateless is set.
+	HeartbeatInterval time.Duration
+
+	// SSERetry, if positive, is sent to clients as the "retry" field at the
+	// start of each event stream, telling them how long to wait before
+	// reconnecting an interrupted stream. It is sent in whole milliseconds.
+	// Operators can raise it during rolling deploys, for example, to spread
+	// out reconnections. A [StreamableClientTransport] uses it in place of
+	// the delays of its retry policy.
+	SSERetry time.Duration
+
+	// AllowedOrigins, if non-empty, lists the origins, such as
+	// "http://localhost:3000", allowed to make requests. Requests with
+	// another Origin header are rejected with 403 Forbidden, so that
+	// malicious web pages cannot reach a locally hosted server through DNS
+	// rebinding, as the specification requires. Requests without an Origin
+	// header, as sent by most non-browser clients, are allowed. The origin
+	// "*" allows any origin.
+	AllowedOrigins []string
+
+	// ValidateOrigin, if non-nil, reports whether requests with the given
+	// Origin header are allowed, in place of AllowedOrigins.
+	// See [LocalOrigin].
+	ValidateOrigin func(origin string) bool
+
+	// MaxSessions, if positive, limits the number of concurrent sessions of
+	// the handler. Requests that would create another session are rejected
+	// with 503 Service Unavailable and a Retry-After header, so that the
+	// client can retry later, perhaps reaching another server instance.
+	// MaxSessions is ignored if Stateless is set.
+	MaxSessions int
+
+	// MaxGETStreamsPerSession, if positive, limits the number of concurrent
+	// GET requests of each session, which hold the standalone SSE stream or
+	// resume other streams. Requests beyond the limit are rejected with 429
+	// Too Many Requests and a Retry-After header.
+	MaxGETStreamsPerSession int
+
+	// MaxPOSTsPerSession, if positive, limits the number of concurrent POST
+	// requests of each session. Requests beyond the limit are rejected with
+	// 429 Too Many Requests and a Retry-After header.
+	MaxPOSTsPerSession int
+}
+
+// WriteProblemDetails writes an error response as an RFC 9457 problem
+// details object, with content type "application/problem+json". It can be
+// used as [StreamableHTTPOptions.WriteError].
+func WriteProblemDetails(w http.ResponseWriter, _ *http.Request, status int, message string) {
+	data, err := json.Marshal(struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail,omitempty"`
+	}{"about:blank", http.StatusText(status), status, message})
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/problem+json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// bodyTooLarge reports whether err is the error of reading a request body
+// limited by [http.MaxBytesReader] beyond its limit, and if so returns the
+// message of the error response.
+func bodyTooLarge(err error) (string, bool) {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return fmt.Sprintf("%v: exceeds %d bytes", ErrMessageTooLarge, mbe.Limit), true
+	}
+	return "", false
+}
+
+// writeHTTPError writes an error response with f, or with [http.Error] if f
+// is nil.
+func writeHTTPError(f func(http.ResponseWriter, *http.Request, int, string), w http.ResponseWriter, req *http.Request, msg string, code int) {
+	if f == nil {
+		http.Error(w, msg, code)
+		return
+	}
+	f(w, req, code, msg)
+}
+
+// RequestInfo describes an HTTP request handled by a [StreamableHTTPHandler].
+// See [StreamableHTTPOptions.OnRequest].
+type RequestInfo struct {
+	Request   *http.Request
+	Kind      RequestKind
+	SessionID string        // possibly assigned by the request; empty if none
+	Status    int           // the HTTP status code of the response
+	Duration  time.Duration // how long the request took to handle
+	Bytes     int64         // the number of bytes in the response body
+}
+
+// A RequestKind classifies the HTTP requests of the streamable transport.
+type RequestKind string
+
+const (
+	// A POST request, carrying messages from the client.
+	RequestMessage RequestKind = "message"
+	// A GET request for the standalone SSE stream.
+	RequestStream RequestKind = "stream"
+	// A GET request that resumes a stream, with a Last-Event-ID header.
+	RequestResume RequestKind = "resume"
+	// A DELETE request, terminating a session.
+	RequestDelete RequestKind = "delete"
+	// A request with an unsupported method.
+	RequestOther RequestKind = "other"
+)
+
+// requestKind returns the kind of the request.
+func requestKind(req *http.Request) RequestKind {
+	switch req.Method {
+	case http.MethodPost:
+		return RequestMessage
+	case http.MethodGet:
+		if len(req.Header.Values("Last-Event-ID")) > 0 {
+			return RequestResume
+		}
+		return RequestStream
+	case http.MethodDelete:
+		return RequestDelete
+	}
+	return RequestOther
+}
+
+// A recordingWriter is an http.ResponseWriter that records the status and
+// size of the response.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements [http.Flusher], which is needed for event streams.
+func (w *recordingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap supports [http.ResponseController].
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// A SessionTerminationReason describes why a streamable session ended.
+type SessionTerminationReason int
+
+const (
+	// The client terminated the session with a DELETE request.
+	SessionDeleted SessionTerminationReason = iota + 1
+	// The session was idle for longer than
+	// [StreamableHTTPOptions.SessionTimeout].
+	SessionIdleTimeout
+	// The session was closed by the server, for example on shutdown, or its
+	// connection failed.
+	SessionClosed
+	// The session was saved to the session store and closed by
+	// [StreamableHTTPHandler.Shutdown], so that another server instance can
+	// resume it.
+	SessionHandedOff
+	// The session was older than [PruneOptions.MaxSessionAge].
+	SessionExpired
+)
+
+func (r SessionTerminationReason) String() string {
+	switch r {
+	case SessionDeleted:
+		return "deleted"
+	case SessionIdleTimeout:
+		return "idle timeout"
+	case SessionClosed:
+		return "closed"
+	case SessionHandedOff:
+		return "handed off"
+	case SessionExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("SessionTerminationReason(%d)", int(r))
 }
 
 // NewStreamableHTTPHandler returns a new [StreamableHTTPHandler].
@@ -185,22 +495,67 @@ func NewStreamableHTTPHandler(getServer func(*http.Request) *Server, opts *Strea
 		getServer: getServer,
 		sessions:  make(map[string]*sessionInfo),
 	}
+	var o StreamableHTTPOptions
 	if opts != nil {
-		h.opts = *opts
+		o = *opts
 	}
 
-	if h.opts.Logger == nil { // ensure we have a logger
-		h.opts.Logger = ensureLogger(nil)
+	if o.Logger == nil { // ensure we have a logger
+		o.Logger = ensureLogger(nil)
 	}
 
 	// Initialize session store if not provided
-	if h.opts.SessionStore == nil && !h.opts.Stateless {
-		h.opts.SessionStore = NewInMemorySessionStore()
+	if o.SessionStore == nil && !o.Stateless {
+		o.SessionStore = NewInMemorySessionStore()
 	}
+	h.opts.Store(&o)
 
 	return h
 }
 
+// options returns the current options of the handler, which must not be
+// modified.
+func (h *StreamableHTTPHandler) options() *StreamableHTTPOptions {
+	return h.opts.Load()
+}
+
+// Reconfigure changes the options of a running handler, without affecting
+// its sessions. It calls update with a copy of the current options, and
+// makes the result current for requests that begin after it returns.
+//
+// Options that a session captures when it is created, such as
+// SessionTimeout, JSONResponse and Logger, apply only to new sessions. (The
+// idle timeout of an existing session can be changed with
+// [ServerSession.SetIdleTimeout].) Other options, such as DisableDelete,
+// OnRequest and WriteError, apply to all subsequent requests. Prune applies
+// to existing sessions too, from the next time they are pruned.
+//
+// Stateless, SessionStore and EventStore cannot be changed: if update
+// changes them, Reconfigure returns an error and leaves the options unchanged.
+// If update sets Logger to nil, logging is disabled.
+func (h *StreamableHTTPHandler) Reconfigure(update func(*StreamableHTTPOptions)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := h.options()
+	o := *old
+	update(&o)
+	switch {
+	case o.Stateless != old.Stateless:
+		return errors.New("Reconfigure: cannot change Stateless")
+	case o.SessionStore != old.SessionStore:
+		return errors.New("Reconfigure: cannot change SessionStore")
+	case o.EventStore != old.EventStore:
+		return errors.New("Reconfigure: cannot change EventStore")
+	}
+	if o.Logger == nil {
+		o.Logger = ensureLogger(nil)
+	}
+	h.opts.Store(&o)
+	h.startPruningLocked()
+	return nil
+}
+
 // closeAll closes all ongoing sessions, for tests.
 //
 // TODO(rfindley): investigate the best API for callers to configure their
@@ -220,11 +575,121 @@ func (h *StreamableHTTPHandler) closeAll() {
 	h.sessions = nil
 	h.mu.Unlock()
 	for _, s := range sessionInfos {
-		s.session.Close()
+		s.terminate(SessionClosed)
+	}
+}
+
+// Shutdown gracefully shuts down the handler, handing its sessions off to
+// other server instances that share its [SessionStore].
+//
+// Shutdown first stops accepting requests: subsequent requests are answered
+// with 503 Service Unavailable and a Retry-After header. It then waits until
+// POST requests in progress have completed, or ctx is done. Finally, it saves
+// the state of each session to the store, marked as handed off, and closes
+// the session without deleting it from the store, which ends its streams.
+// Clients that reconnect to another instance can continue their sessions
+// there; with a shared [EventStore], they can also resume interrupted
+// streams.
+//
+// Sessions are handed off even if ctx is done first, in which case Shutdown
+// returns the context's error.
+func (h *StreamableHTTPHandler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.draining = true
+	idle := h.posts.wait()
+	h.mu.Unlock()
+
+	var err error
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// No sessions are added once the handler is draining, and sessions
+	// remove themselves from h.sessions when closed, so we can't close them
+	// while holding the lock.
+	h.mu.Lock()
+	sessionInfos := slices.Collect(maps.Values(h.sessions))
+	h.mu.Unlock()
+	opts := h.options()
+	for _, s := range sessionInfos {
+		if opts.SessionStore != nil {
+			stored := s.toStored()
+			stored.Refs = 0
+			stored.HandedOff = true
+			ttl := stored.Timeout
+			if ttl <= 0 {
+				ttl = 24 * time.Hour // as when the session was saved
+			}
+			sessionID := s.session.ID()
+			// Use a fresh context, since the session is lost if it isn't saved.
+			putCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			if err := opts.SessionStore.Put(putCtx, sessionID, stored, ttl); err != nil {
+				opts.Logger.Error("failed to hand off session", "error", err, "session_id", sessionID)
+				cancel()
+				s.terminate(SessionClosed)
+				continue
+			}
+			cancel()
+		}
+		s.terminate(SessionHandedOff)
 	}
+	return err
 }
 
 func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	opts := h.options()
+	if opts.OnRequest != nil {
+		start := time.Now()
+		rw := &recordingWriter{ResponseWriter: w}
+		w = rw
+		defer func() {
+			sessionID := w.Header().Get(sessionIDHeader) // set by initialize
+			if sessionID == "" {
+				sessionID = req.Header.Get(sessionIDHeader)
+			}
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			opts.OnRequest(RequestInfo{
+				Request:   req,
+				Kind:      requestKind(req),
+				SessionID: sessionID,
+				Status:    status,
+				Duration:  time.Since(start),
+				Bytes:     rw.bytes,
+			})
+		}()
+	}
+
+	if !originAllowed(req, opts.AllowedOrigins, opts.ValidateOrigin) {
+		writeHTTPError(opts.WriteError, w, req, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	h.mu.Lock()
+	draining := h.draining
+	if !draining && req.Method == http.MethodPost {
+		h.posts.start()
+		defer func() {
+			h.mu.Lock()
+			h.posts.finish()
+			h.mu.Unlock()
+		}()
+	}
+	h.mu.Unlock()
+	if draining {
+		// Ask the client to retry, perhaps reaching another server instance.
+		w.Header().Set("Retry-After", "1")
+		writeHTTPError(opts.WriteError, w, req, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if req.Method == http.MethodPost && opts.MaxMessageSize > 0 {
+		req.Body = http.MaxBytesReader(w, req.Body, int64(opts.MaxMessageSize))
+	}
+
 	// Allow multiple 'Accept' headers.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Reference/Headers/Accept#syntax
 	accept := strings.Split(strings.Join(req.Header.Values("Accept"), ","), ",")
@@ -243,14 +708,26 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 
 	if req.Method == http.MethodGet {
 		if !streamOK {
-			http.Error(w, "Accept must contain 'text/event-stream' for GET requests", http.StatusBadRequest)
+			writeHTTPError(opts.WriteError, w, req, "Accept must contain 'text/event-stream' for GET requests", http.StatusBadRequest)
 			return
 		}
 	} else if (!jsonOK || !streamOK) && req.Method != http.MethodDelete { // TODO: consolidate with handling of http method below.
-		http.Error(w, "Accept must contain both 'application/json' and 'text/event-stream'", http.StatusBadRequest)
+		writeHTTPError(opts.WriteError, w, req, "Accept must contain both 'application/json' and 'text/event-stream'", http.StatusBadRequest)
 		return
 	}
 
+	if req.Method == http.MethodDelete {
+		if opts.DisableDelete {
+			w.Header().Set("Allow", "GET, POST")
+			writeHTTPError(opts.WriteError, w, req, "Method Not Allowed: session termination is disabled", http.StatusMethodNotAllowed)
+			return
+		}
+		if opts.RequireAuthForDelete && auth.TokenInfoFromContext(req.Context()) == nil {
+			writeHTTPError(opts.WriteError, w, req, "Unauthorized: DELETE requires authentication", http.StatusUnauthorized)
+			return
+		}
+	}
+
 	sessionID := req.Header.Get(sessionIDHeader)
 	var sessInfo *sessionInfo
 	if sessionID != "" {
@@ -261,35 +738,38 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 		// If not found in memory and we're using a session store, check if it exists in store.
 		// This enables session recovery when a client is routed to a different server instance.
 		// The actual session recreation happens below in the sessInfo == nil block.
-		if sessInfo == nil && !h.opts.Stateless && h.opts.SessionStore != nil {
-			_, err := h.opts.SessionStore.Get(req.Context(), sessionID)
+		if sessInfo == nil && !opts.Stateless && opts.SessionStore != nil {
+			_, err := opts.SessionStore.Get(req.Context(), sessionID)
 			if err != nil && !errors.Is(err, ErrSessionNotFound) {
-				h.opts.Logger.Error("failed to load session from store", "error", err, "session_id", sessionID)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				opts.Logger.Error("failed to load session from store", "error", err, "session_id", sessionID)
+				writeHTTPError(opts.WriteError, w, req, "internal server error", http.StatusInternalServerError)
 				return
 			}
 			// If found in store, we'll recreate the session below (sessInfo remains nil for now)
 			// The stored session state will be loaded and used during session creation
 		}
 
-		if sessInfo == nil && !h.opts.Stateless {
+		if sessInfo == nil && !opts.Stateless {
 			// Unless we're in 'stateless' mode, which doesn't perform any Session-ID
 			// validation, we require that the session ID matches a known session or
 			// exists in the session store (which will be recreated below).
 			//
 			// In stateless mode, a temporary transport is created below.
-			if h.opts.SessionStore == nil {
-				http.Error(w, "session not found", http.StatusNotFound)
+			if opts.SessionStore == nil {
+				writeHTTPError(opts.WriteError, w, req, "session not found", http.StatusNotFound)
 				return
 			}
-			// Check if session exists in store
-			_, err := h.opts.SessionStore.Get(req.Context(), sessionID)
+			// Check if session exists in store, for this handler.
+			stored, err := opts.SessionStore.Get(req.Context(), sessionID)
+			if err == nil && stored.Route != h.route {
+				err = ErrSessionNotFound
+			}
 			if errors.Is(err, ErrSessionNotFound) {
-				http.Error(w, "session not found", http.StatusNotFound)
+				writeHTTPError(opts.WriteError, w, req, "session not found", http.StatusNotFound)
 				return
 			} else if err != nil {
-				h.opts.Logger.Error("failed to check session in store", "error", err, "session_id", sessionID)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				opts.Logger.Error("failed to check session in store", "error", err, "session_id", sessionID)
+				writeHTTPError(opts.WriteError, w, req, "internal server error", http.StatusInternalServerError)
 				return
 			}
 			// Session exists in store, it will be recreated below
@@ -298,13 +778,13 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 
 	if req.Method == http.MethodDelete {
 		if sessionID == "" {
-			http.Error(w, "Bad Request: DELETE requires an Mcp-Session-Id header", http.StatusBadRequest)
+			writeHTTPError(opts.WriteError, w, req, "Bad Request: DELETE requires an Mcp-Session-Id header", http.StatusBadRequest)
 			return
 		}
 		if sessInfo != nil { // sessInfo may be nil in stateless mode
 			// Closing the session also removes it from h.sessions, due to the
 			// onClose callback.
-			sessInfo.session.Close()
+			sessInfo.terminate(SessionDeleted)
 		}
 		w.WriteHeader(http.StatusNoContent)
 		return
@@ -312,13 +792,13 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 
 	switch req.Method {
 	case http.MethodPost, http.MethodGet:
-		if req.Method == http.MethodGet && (h.opts.Stateless || sessionID == "") {
-			http.Error(w, "GET requires an active session", http.StatusMethodNotAllowed)
+		if req.Method == http.MethodGet && (opts.Stateless || sessionID == "") {
+			writeHTTPError(opts.WriteError, w, req, "GET requires an active session", http.StatusMethodNotAllowed)
 			return
 		}
 	default:
 		w.Header().Set("Allow", "GET, POST, DELETE")
-		http.Error(w, "Method Not Allowed: streamable MCP servers support GET, POST, and DELETE requests", http.StatusMethodNotAllowed)
+		writeHTTPError(opts.WriteError, w, req, "Method Not Allowed: streamable MCP servers support GET, POST, and DELETE requests", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -357,15 +837,39 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 		protocolVersion = protocolVersion20250326
 	}
 	if !slices.Contains(supportedProtocolVersions, protocolVersion) {
-		http.Error(w, fmt.Sprintf("Bad Request: Unsupported protocol version (supported versions: %s)", strings.Join(supportedProtocolVersions, ",")), http.StatusBadRequest)
+		writeHTTPError(opts.WriteError, w, req, fmt.Sprintf("Bad Request: Unsupported protocol version (supported versions: %s)", strings.Join(supportedProtocolVersions, ",")), http.StatusBadRequest)
 		return
 	}
 
 	if sessInfo == nil {
+		// Reserve a session, so that concurrent requests cannot exceed
+		// MaxSessions. The reservation is released once the session is
+		// recorded in h.sessions.
+		reserved := false
+		if !opts.Stateless && opts.MaxSessions > 0 {
+			h.mu.Lock()
+			reserved = len(h.sessions)+h.creating < opts.MaxSessions
+			if reserved {
+				h.creating++
+			}
+			h.mu.Unlock()
+			if !reserved {
+				w.Header().Set("Retry-After", "1")
+				writeHTTPError(opts.WriteError, w, req, "too many sessions", http.StatusServiceUnavailable)
+				return
+			}
+			defer func() {
+				if reserved {
+					h.mu.Lock()
+					h.creating--
+					h.mu.Unlock()
+				}
+			}()
+		}
 		server := h.getServer(req)
 		if server == nil {
 			// The getServer argument to NewStreamableHTTPHandler returned nil.
-			http.Error(w, "no server available", http.StatusBadRequest)
+			writeHTTPError(opts.WriteError, w, req, "no server available", http.StatusBadRequest)
 			return
 		}
 		if sessionID == "" {
@@ -373,21 +877,32 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			// existing transport.
 			sessionID = server.opts.GetSessionID()
 		}
+		timeout := opts.SessionTimeout
+		if f := opts.SessionTimeoutFunc; f != nil && !opts.Stateless {
+			if d := f(req); d > 0 {
+				timeout = d
+			}
+		}
 		transport := &StreamableServerTransport{
 			SessionID:    sessionID,
-			Stateless:    h.opts.Stateless,
-			EventStore:   h.opts.EventStore,
-			SessionStore: h.opts.SessionStore,
-			Timeout:      h.opts.SessionTimeout,
-			jsonResponse: h.opts.JSONResponse,
-			logger:       h.opts.Logger,
+			Stateless:    opts.Stateless,
+			EventStore:   opts.EventStore,
+			SessionStore: opts.SessionStore,
+			Timeout:      timeout,
+			jsonResponse: opts.JSONResponse,
+			logger:       opts.Logger,
+			writeError:   opts.WriteError,
+
+			HeartbeatInterval: opts.HeartbeatInterval,
+			SSERetry:          opts.SSERetry,
+
+			route: h.route,
 		}
 
 		// To support stateless mode, we initialize the session with a default
 		// state, so that it doesn't reject subsequent requests.
 		var connectOpts *ServerSessionOptions
-		var recoveredTimeout time.Duration
-		if h.opts.Stateless {
+		if opts.Stateless {
 			// Peek at the body to see if it is initialize or initialized.
 			// We want those to be handled as usual.
 			var hasInitialize, hasInitialized bool
@@ -395,8 +910,12 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 				// TODO: verify that this allows protocol version negotiation for
 				// stateless servers.
 				body, err := io.ReadAll(req.Body)
+				if msg, ok := bodyTooLarge(err); ok {
+					writeHTTPError(opts.WriteError, w, req, msg, http.StatusRequestEntityTooLarge)
+					return
+				}
 				if err != nil {
-					http.Error(w, "failed to read body", http.StatusInternalServerError)
+					writeHTTPError(opts.WriteError, w, req, "failed to read body", http.StatusInternalServerError)
 					return
 				}
 				req.Body.Close()
@@ -439,35 +958,55 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			// not stored in the map otherwise.
 			connectOpts = &ServerSessionOptions{
 				onClose: func() {
+					reason := SessionClosed
 					h.mu.Lock()
-					defer h.mu.Unlock()
 					if info, ok := h.sessions[transport.SessionID]; ok {
 						info.stopTimer()
+						if r := info.reason.Load(); r != 0 {
+							reason = SessionTerminationReason(r)
+						}
 						delete(h.sessions, transport.SessionID)
 						if h.onTransportDeletion != nil {
 							h.onTransportDeletion(transport.SessionID)
 						}
 					}
-					// Also delete from persistent store
-					if h.opts.SessionStore != nil {
-						if err := h.opts.SessionStore.Delete(context.Background(), transport.SessionID); err != nil {
-							h.opts.Logger.Error("failed to delete session from store", "error", err, "session_id", transport.SessionID)
+					// Also delete from persistent store, unless another
+					// server instance may resume the session.
+					if opts.SessionStore != nil && reason != SessionHandedOff {
+						if err := opts.SessionStore.Delete(context.Background(), transport.SessionID); err != nil {
+							opts.Logger.Error("failed to delete session from store", "error", err, "session_id", transport.SessionID)
 						}
 					}
+					h.mu.Unlock()
+					if h.options().OnSessionTerminated != nil {
+						h.options().OnSessionTerminated(transport.SessionID, reason)
+					}
 				},
 			}
 
 			// Try to recover session state from the store if available
-			if h.opts.SessionStore != nil && sessionID != "" {
-				stored, err := h.opts.SessionStore.Get(req.Context(), sessionID)
+			if opts.SessionStore != nil && sessionID != "" {
+				stored, err := opts.SessionStore.Get(req.Context(), sessionID)
+				if err == nil && stored.Route != h.route {
+					// The session belongs to a server mounted elsewhere.
+					err = ErrSessionNotFound
+				}
 				if err == nil {
 					// Session found in store, use its state to initialize the new session
 					connectOpts.State = &stored.SessionState
-					recoveredTimeout = stored.Timeout
-					h.opts.Logger.Info("recovered session from store", "session_id", sessionID)
+					if stored.HandedOff {
+						opts.Logger.Info("resuming session handed off by another instance", "session_id", sessionID)
+					}
+					if stored.Timeout > 0 {
+						// Use the recovered timeout, which may have been set
+						// for the session.
+						timeout = stored.Timeout
+						transport.Timeout = timeout
+					}
+					opts.Logger.Info("recovered session from store", "session_id", sessionID)
 				} else if !errors.Is(err, ErrSessionNotFound) {
 					// Unexpected error
-					h.opts.Logger.Warn("failed to recover session from store", "error", err, "session_id", sessionID)
+					opts.Logger.Warn("failed to recover session from store", "error", err, "session_id", sessionID)
 				}
 				// If ErrSessionNotFound, this is a new session, proceed with default initialization
 			}
@@ -477,8 +1016,14 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 		// The context is detached in the jsonrpc2 library when handling the
 		// long-running stream.
 		session, err := server.Connect(req.Context(), transport, connectOpts)
+		if errors.Is(err, ErrServerShutdown) {
+			// Ask the client to retry, perhaps reaching another server instance.
+			w.Header().Set("Retry-After", "1")
+			writeHTTPError(opts.WriteError, w, req, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
-			http.Error(w, "failed connection", http.StatusInternalServerError)
+			writeHTTPError(opts.WriteError, w, req, "failed connection", http.StatusInternalServerError)
 			return
 		}
 		sessInfo = &sessionInfo{
@@ -486,7 +1031,7 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			transport: transport,
 		}
 
-		if h.opts.Stateless {
+		if opts.Stateless {
 			// Stateless mode: close the session when the request exits.
 			defer session.Close() // close the fake session after handling the request
 		} else {
@@ -496,38 +1041,45 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			//
 			// Note that the timer here may fire multiple times, but
 			// sessInfo.session.Close is idempotent.
-			timeout := h.opts.SessionTimeout
-			if recoveredTimeout > 0 {
-				// Use recovered timeout from store if available
-				timeout = recoveredTimeout
-			}
-			if timeout > 0 {
-				sessInfo.timeout = timeout
-				sessInfo.timer = time.AfterFunc(sessInfo.timeout, func() {
-					sessInfo.session.Close()
-				})
-			}
+			sessInfo.setTimeout(timeout)
+			transport.connection.setTimeout = sessInfo.setTimeout
 			h.mu.Lock()
 			h.sessions[transport.SessionID] = sessInfo
+			if reserved {
+				h.creating--
+				reserved = false
+			}
+			h.startPruningLocked()
 			h.mu.Unlock()
 
 			// Save session to persistent store
-			if h.opts.SessionStore != nil {
+			if opts.SessionStore != nil {
 				stored := sessInfo.toStored()
-				ttl := h.opts.SessionTimeout
+				ttl := timeout
 				if ttl <= 0 {
 					// If no timeout is set, use a default TTL of 24 hours for the store
 					// This prevents unlimited growth of the session store
 					ttl = 24 * time.Hour
 				}
-				if err := h.opts.SessionStore.Put(req.Context(), transport.SessionID, stored, ttl); err != nil {
-					h.opts.Logger.Error("failed to save session to store", "error", err, "session_id", transport.SessionID)
+				if err := opts.SessionStore.Put(req.Context(), transport.SessionID, stored, ttl); err != nil {
+					opts.Logger.Error("failed to save session to store", "error", err, "session_id", transport.SessionID)
 					// Don't fail the request if store persistence fails
 				}
 			}
 		}
 	}
 
+	count, limit := &sessInfo.gets, opts.MaxGETStreamsPerSession
+	if req.Method == http.MethodPost {
+		count, limit = &sessInfo.posts, opts.MaxPOSTsPerSession
+	}
+	if !acquireSlot(count, limit) {
+		w.Header().Set("Retry-After", "1")
+		writeHTTPError(opts.WriteError, w, req, fmt.Sprintf("too many concurrent %s requests for session", req.Method), http.StatusTooManyRequests)
+		return
+	}
+	defer count.Add(-1)
+
 	if req.Method == http.MethodPost {
 		sessInfo.startPOST()
 		defer sessInfo.endPOST()
@@ -590,6 +1142,18 @@ type StreamableServerTransport struct {
 	// Used when updating session state in the SessionStore.
 	Timeout time.Duration
 
+	// HeartbeatInterval, if positive, is the interval at which an SSE comment
+	// is written to hanging GET streams to keep them alive.
+	//
+	// See also [StreamableHTTPOptions.HeartbeatInterval].
+	HeartbeatInterval time.Duration
+
+	// SSERetry, if positive, is sent as the "retry" field at the start of
+	// each event stream.
+	//
+	// See also [StreamableHTTPOptions.SSERetry].
+	SSERetry time.Duration
+
 	// jsonResponse, if set, tells the server to prefer to respond to requests
 	// using application/json responses rather than text/event-stream.
 	//
@@ -609,6 +1173,14 @@ type StreamableServerTransport struct {
 	// to write their own streamable HTTP handler.
 	logger *slog.Logger
 
+	// optional error writer provided through the
+	// [StreamableHTTPOptions.WriteError].
+	writeError func(http.ResponseWriter, *http.Request, int, string)
+
+	// route is the pattern of the handler in a [StreamableServeMux], recorded
+	// with the session in the SessionStore.
+	route string
+
 	// connection is non-nil if and only if the transport has been connected.
 	connection *streamableServerConn
 }
@@ -624,8 +1196,12 @@ func (t *StreamableServerTransport) Connect(ctx context.Context) (Connection, er
 		eventStore:     t.EventStore,
 		sessionStore:   t.SessionStore,
 		timeout:        t.Timeout,
+		heartbeat:      t.HeartbeatInterval,
+		sseRetry:       t.SSERetry,
+		route:          t.route,
 		jsonResponse:   t.jsonResponse,
 		logger:         ensureLogger(t.logger), // see #556: must be non-nil
+		writeError:     t.writeError,
 		incoming:       make(chan jsonrpc.Message, 10),
 		done:           make(chan struct{}),
 		streams:        make(map[string]*stream),
@@ -649,14 +1225,27 @@ type streamableServerConn struct {
 	jsonResponse bool
 	eventStore   EventStore
 	sessionStore SessionStore // for persisting session state updates
-	timeout      time.Duration // session timeout for store updates
+	// route is [StoredSessionInfo.Route].
+	route string
 
-	logger *slog.Logger
+	// heartbeat, if positive, is the interval of SSE comments on GET streams.
+	heartbeat time.Duration
+	// sseRetry, if positive, is the "retry" field of event streams.
+	sseRetry time.Duration
+
+	logger     *slog.Logger
+	writeError func(http.ResponseWriter, *http.Request, int, string) // if nil, use http.Error
+
+	// setTimeout, if set, changes the idle timeout of the session.
+	// It is set by the StreamableHTTPHandler.
+	setTimeout func(time.Duration)
 
 	incoming chan jsonrpc.Message // messages from the client to the server
 
 	mu sync.Mutex // guards all fields below
 
+	timeout time.Duration // session timeout for store updates
+
 	// Sessions are closed exactly once.
 	isDone bool
 	done   chan struct{}
@@ -680,6 +1269,35 @@ type streamableServerConn struct {
 	//
 	// Lifecycle: requestStreams persist until their response is received.
 	requestStreams map[jsonrpc.ID]string
+
+	// For stateless sessions, pending holds unrelated messages written before
+	// the session's request stream was registered, and served records that
+	// it has been.
+	pending [][]byte
+	served  bool
+
+	// noStandalone records that the client never opens the standalone SSE
+	// stream, so that messages for it fail rather than being stored in the
+	// event store, where the client would never read them.
+	noStandalone bool
+}
+
+// maxPendingMessages bounds the number of messages held for a stateless
+// session before its request stream is registered.
+const maxPendingMessages = 100
+
+// requestStreamLocked returns the stream of a request in progress, other than
+// the standalone SSE stream, or nil if there is none. Stateless sessions have
+// at most one.
+//
+// c.mu must be held.
+func (c *streamableServerConn) requestStreamLocked() *stream {
+	for id, s := range c.streams {
+		if id != "" {
+			return s
+		}
+	}
+	return nil
 }
 
 func (c *streamableServerConn) SessionID() string {
@@ -693,6 +1311,9 @@ func (c *streamableServerConn) sessionUpdated(state ServerSessionState) {
 	if c.stateless || c.sessionStore == nil {
 		return
 	}
+	c.mu.Lock()
+	timeout := c.timeout
+	c.mu.Unlock()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -701,13 +1322,14 @@ func (c *streamableServerConn) sessionUpdated(state ServerSessionState) {
 	stored := &StoredSessionInfo{
 		SessionState:   state,
 		Refs:           0, // Refs are managed by the handler, not here
-		Timeout:        c.timeout,
+		Timeout:        timeout,
 		CreatedAt:      time.Now(), // Note: ideally we'd preserve the original CreatedAt
 		LastAccessedAt: time.Now(),
+		Route:          c.route,
 	}
 
 	// Calculate TTL
-	ttl := c.timeout
+	ttl := timeout
 	if ttl <= 0 {
 		ttl = 24 * time.Hour // Default TTL
 	}
@@ -720,6 +1342,20 @@ func (c *streamableServerConn) sessionUpdated(state ServerSessionState) {
 	}
 }
 
+// setIdleTimeout implements [idleTimeoutConnection].
+func (c *streamableServerConn) setIdleTimeout(timeout time.Duration) error {
+	if c.stateless {
+		return errors.New("stateless sessions have no idle timeout")
+	}
+	c.mu.Lock()
+	c.timeout = timeout
+	c.mu.Unlock()
+	if c.setTimeout != nil {
+		c.setTimeout(timeout)
+	}
+	return nil
+}
+
 // A stream is a single logical stream of SSE events within a server session.
 // A stream begins with a client request, or with a client GET that has
 // no Last-Event-ID header.
@@ -747,6 +1383,10 @@ type stream struct {
 	//
 	// Requests are removed when their response has been received.
 	requests map[jsonrpc.ID]struct{}
+
+	// idleSince records when the last HTTP response delivering the stream
+	// ended, if deliver is nil. See [PruneOptions.MaxIdleStreams].
+	idleSince time.Time
 }
 
 // doneLocked reports whether the stream is logically complete.
@@ -794,7 +1434,7 @@ type idContextKey struct{}
 // ServeHTTP handles a single HTTP request for the session.
 func (t *StreamableServerTransport) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if t.connection == nil {
-		http.Error(w, "transport not connected", http.StatusInternalServerError)
+		writeHTTPError(t.writeError, w, req, "transport not connected", http.StatusInternalServerError)
 		return
 	}
 	switch req.Method {
@@ -805,11 +1445,16 @@ func (t *StreamableServerTransport) ServeHTTP(w http.ResponseWriter, req *http.R
 	default:
 		// Should not be reached, as this is checked in StreamableHTTPHandler.ServeHTTP.
 		w.Header().Set("Allow", "GET, POST")
-		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+		t.connection.httpError(w, req, "unsupported method", http.StatusMethodNotAllowed)
 		return
 	}
 }
 
+// httpError writes an HTTP error response for the request.
+func (c *streamableServerConn) httpError(w http.ResponseWriter, req *http.Request, msg string, code int) {
+	writeHTTPError(c.writeError, w, req, msg, code)
+}
+
 // serveGET streams messages to a hanging http GET, with stream ID and last
 // message parsed from the Last-Event-ID header.
 //
@@ -825,11 +1470,11 @@ func (c *streamableServerConn) serveGET(w http.ResponseWriter, req *http.Request
 		var ok bool
 		streamID, lastIdx, ok = parseEventID(eid)
 		if !ok {
-			http.Error(w, fmt.Sprintf("malformed Last-Event-ID %q", eid), http.StatusBadRequest)
+			c.httpError(w, req, fmt.Sprintf("malformed Last-Event-ID %q", eid), http.StatusBadRequest)
 			return
 		}
 		if c.eventStore == nil {
-			http.Error(w, "stream replay unsupported", http.StatusBadRequest)
+			c.httpError(w, req, "stream replay unsupported", http.StatusBadRequest)
 			return
 		}
 	}
@@ -837,7 +1482,7 @@ func (c *streamableServerConn) serveGET(w http.ResponseWriter, req *http.Request
 	ctx, cancel := context.WithCancel(req.Context())
 	defer cancel()
 
-	stream, done := c.acquireStream(ctx, w, streamID, &lastIdx)
+	stream, done := c.acquireStream(ctx, w, req, streamID, &lastIdx)
 	if stream == nil {
 		return
 	}
@@ -845,16 +1490,38 @@ func (c *streamableServerConn) serveGET(w http.ResponseWriter, req *http.Request
 	defer func() {
 		stream.mu.Lock()
 		stream.deliver = nil
+		stream.idleSince = time.Now()
 		stream.mu.Unlock()
 	}()
 
-	select {
-	case <-ctx.Done():
-		// request cancelled
-	case <-done:
-		// request complete
-	case <-c.done:
-		// session closed
+	// Write a comment to the stream periodically, so that intermediaries do
+	// not consider it idle.
+	var heartbeat <-chan time.Time
+	if c.heartbeat > 0 {
+		ticker := time.NewTicker(c.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			// request cancelled
+			return
+		case <-done:
+			// request complete
+			return
+		case <-c.done:
+			// session closed
+			return
+		case <-heartbeat:
+			// Hold the stream lock, so as not to interleave with deliveries.
+			stream.mu.Lock()
+			err := writeComment(w, "ping")
+			stream.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
 	}
 }
 
@@ -887,7 +1554,7 @@ func (c *streamableServerConn) writeEvent(w http.ResponseWriter, stream *stream,
 // Importantly, this function must hold the stream mutex until done replaying
 // all messages, so that no delivery or storage of new messages occurs while
 // the stream is still replaying.
-func (c *streamableServerConn) acquireStream(ctx context.Context, w http.ResponseWriter, streamID string, lastIdx *int) (*stream, chan struct{}) {
+func (c *streamableServerConn) acquireStream(ctx context.Context, w http.ResponseWriter, req *http.Request, streamID string, lastIdx *int) (*stream, chan struct{}) {
 	// if tempStream is set, the stream is done and we're just replaying messages.
 	//
 	// We record a temporary stream to claim exclusive replay rights.
@@ -921,7 +1588,7 @@ func (c *streamableServerConn) acquireStream(ctx context.Context, w http.Respons
 
 	// Check that this stream wasn't claimed by another request.
 	if !tempStream && s.deliver != nil {
-		http.Error(w, "stream ID conflicts with ongoing stream", http.StatusConflict)
+		c.httpError(w, req, "stream ID conflicts with ongoing stream", http.StatusConflict)
 		return nil, nil
 	}
 
@@ -943,7 +1610,7 @@ func (c *streamableServerConn) acquireStream(ctx context.Context, w http.Respons
 				//
 				// 400 is not really accurate, but should at least have no side effects.
 				// Other SDKs (typescript) do not have a mechanism for events to be purged.
-				http.Error(w, "failed to replay events", http.StatusBadRequest)
+				c.httpError(w, req, "failed to replay events", http.StatusBadRequest)
 				return nil, nil
 			}
 			toReplay = append(toReplay, data)
@@ -962,6 +1629,11 @@ func (c *streamableServerConn) acquireStream(ctx context.Context, w http.Respons
 			f.Flush()
 		}
 	}
+	if c.sseRetry > 0 {
+		if err := writeRetry(w, c.sseRetry); err != nil {
+			return nil, nil
+		}
+	}
 
 	for _, data := range toReplay {
 		if err := c.writeEvent(w, s, data, lastIdx); err != nil {
@@ -997,23 +1669,27 @@ func (c *streamableServerConn) acquireStream(ctx context.Context, w http.Respons
 // It returns an HTTP status code and error message.
 func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Request) {
 	if len(req.Header.Values("Last-Event-ID")) > 0 {
-		http.Error(w, "can't send Last-Event-ID for POST request", http.StatusBadRequest)
+		c.httpError(w, req, "can't send Last-Event-ID for POST request", http.StatusBadRequest)
 		return
 	}
 
 	// Read incoming messages.
 	body, err := io.ReadAll(req.Body)
+	if msg, ok := bodyTooLarge(err); ok {
+		c.httpError(w, req, msg, http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
-		http.Error(w, "failed to read body", http.StatusBadRequest)
+		c.httpError(w, req, "failed to read body", http.StatusBadRequest)
 		return
 	}
 	if len(body) == 0 {
-		http.Error(w, "POST requires a non-empty body", http.StatusBadRequest)
+		c.httpError(w, req, "POST requires a non-empty body", http.StatusBadRequest)
 		return
 	}
 	incoming, isBatch, err := readBatch(body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("malformed payload: %v", err), http.StatusBadRequest)
+		c.httpError(w, req, fmt.Sprintf("malformed payload: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1021,9 +1697,14 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 	if protocolVersion == "" {
 		protocolVersion = protocolVersion20250326
 	}
+	if req.Header.Get(noStandaloneHeader) != "" {
+		c.mu.Lock()
+		c.noStandalone = true
+		c.mu.Unlock()
+	}
 
 	if isBatch && protocolVersion >= protocolVersion20250618 {
-		http.Error(w, fmt.Sprintf("JSON-RPC batching is not supported in %s and later (request version: %s)", protocolVersion20250618, protocolVersion), http.StatusBadRequest)
+		c.httpError(w, req, fmt.Sprintf("JSON-RPC batching is not supported in %s and later (request version: %s)", protocolVersion20250618, protocolVersion), http.StatusBadRequest)
 		return
 	}
 
@@ -1043,7 +1724,7 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 			// the HTTP request. If we didn't do this, a request with a bad method or
 			// missing ID could be silently swallowed.
 			if _, err := checkRequest(jreq, serverMethodInfos); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				c.httpError(w, req, err.Error(), http.StatusBadRequest)
 				return
 			}
 			if jreq.Method == methodInitialize {
@@ -1068,7 +1749,7 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 			case <-c.done:
 				// The session is closing. Since we haven't yet written any data to the
 				// response, we can signal to the client that the session is gone.
-				http.Error(w, "session is closing", http.StatusNotFound)
+				c.httpError(w, req, "session is closing", http.StatusNotFound)
 				return
 			}
 		}
@@ -1084,7 +1765,7 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 	// soon as they're published.
 	stream, err := c.newStream(req.Context(), calls, randText())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("storing stream: %v", err), http.StatusInternalServerError)
+		c.httpError(w, req, fmt.Sprintf("storing stream: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -1099,6 +1780,11 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 	if c.sessionID != "" && isInitialize {
 		w.Header().Set(sessionIDHeader, c.sessionID)
 	}
+	if !c.jsonResponse && c.sseRetry > 0 {
+		if err := writeRetry(w, c.sseRetry); err != nil {
+			return
+		}
+	}
 
 	// Message delivery has two paths, depending on whether we're responding with JSON or
 	// event stream.
@@ -1148,18 +1834,37 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 		// TODO(rfindley): if we have no event store, we should really cancel all
 		// remaining requests here, since the client will never get the results.
 		stream.deliver = nil
+		stream.idleSince = time.Now()
 		stream.mu.Unlock()
 	}()
 
 	// The stream is now set up to deliver messages.
 	//
 	// Register it before publishing incoming messages.
+	//
+	// Hold the stream lock while doing so, so that messages held for a
+	// stateless session are delivered before any others.
+	stream.mu.Lock()
 	c.mu.Lock()
 	c.streams[stream.id] = stream
 	for reqID := range calls {
 		c.requestStreams[reqID] = stream.id
 	}
+	pending := c.pending
+	c.pending = nil
+	c.served = true
 	c.mu.Unlock()
+	for _, data := range pending {
+		if c.eventStore != nil {
+			if err := c.eventStore.Append(req.Context(), c.sessionID, stream.id, data); err != nil {
+				// TODO: report a side-channel error.
+			}
+		}
+		if err := stream.deliver(data, false); err != nil {
+			// TODO: report a side-channel error.
+		}
+	}
+	stream.mu.Unlock()
 
 	// Publish incoming messages.
 	for _, msg := range incoming {
@@ -1277,6 +1982,27 @@ func (c *streamableServerConn) Write(ctx context.Context, msg jsonrpc.Message) e
 		if streamID, ok := c.requestStreams[relatedRequest]; ok {
 			s = c.streams[streamID]
 		}
+	} else if c.stateless && !c.jsonResponse {
+		// A stateless session has no standalone SSE stream, so use the stream
+		// of the request in progress.
+		s = c.requestStreamLocked()
+		if s == nil && !c.served && !c.isDone {
+			// The request hasn't been registered yet: hold the message.
+			if len(c.pending) >= maxPendingMessages {
+				c.mu.Unlock()
+				return fmt.Errorf("%w: too many messages before request", jsonrpc2.ErrRejected)
+			}
+			c.pending = append(c.pending, data)
+			c.mu.Unlock()
+			return nil
+		}
+		if s == nil {
+			c.mu.Unlock()
+			return fmt.Errorf("%w: stateless session has no request in progress", jsonrpc2.ErrRejected)
+		}
+	} else if c.noStandalone {
+		c.mu.Unlock()
+		return fmt.Errorf("%w: client has no standalone SSE stream", jsonrpc2.ErrRejected)
 	} else {
 		s = c.streams[""] // standalone SSE stream
 	}
@@ -1370,9 +2096,109 @@ func (c *streamableServerConn) Close() error {
 type StreamableClientTransport struct {
 	Endpoint   string
 	HTTPClient *http.Client
+	// TLSClientConfig, if non-nil, configures TLS for connections to the
+	// endpoint, in place of the TLS configuration of the HTTPClient's
+	// transport. RootCAs and Certificates are applied to it.
+	//
+	// If any of TLSClientConfig, RootCAs, Certificates, Network,
+	// ConnectTimeout and HandshakeTimeout is set, the transport of the
+	// HTTPClient (or of [http.DefaultClient]) must be nil or an
+	// [*http.Transport]. It is cloned for each connection, and not modified.
+	TLSClientConfig *tls.Config
+	// RootCAs, if non-nil, is the set of root certificate authorities used
+	// to verify the endpoint's certificate, in place of the system roots. It
+	// can be used to pin the CA of the endpoint.
+	RootCAs *x509.CertPool
+	// Certificates are client certificates to present to the endpoint, for
+	// mutual TLS.
+	Certificates []tls.Certificate
+	// Network, if non-nil, configures how connections to the endpoint are
+	// made and pooled, such as through a proxy.
+	Network *NetworkOptions
+	// ConnectTimeout, if positive, limits the time to establish each network
+	// connection to the endpoint, or to its proxy, so that [Client.Connect]
+	// fails fast if the endpoint is unreachable, whatever the deadline of
+	// its context.
+	ConnectTimeout time.Duration
+	// HandshakeTimeout, if positive, limits the time of the TLS handshake of
+	// each connection, as [http.Transport.TLSHandshakeTimeout] does.
+	HandshakeTimeout time.Duration
+	// HeaderFunc, if non-nil, is called for every HTTP request that the
+	// client makes to the endpoint (POST, GET and DELETE), after the MCP
+	// headers are set and before the request is sent. It can add or replace
+	// headers, such as rotating auth tokens, tenant IDs or tracing headers.
+	// The context is that of the request.
+	HeaderFunc func(ctx context.Context, req *http.Request)
 	// MaxRetries is the maximum number of times to attempt a reconnect before giving up.
 	// It defaults to 5. To disable retries, use a negative number.
+	// It is ignored if RetryPolicy is set.
 	MaxRetries int
+	// RetryPolicy, if non-nil, decides whether and when to retry failed
+	// requests: the reconnection of interrupted streams, and POST requests
+	// that fail with a status meaning that the server did not process them,
+	// such as 503 Service Unavailable when the server is shutting down. See
+	// [RetryPolicy] and [BackoffPolicy].
+	//
+	// If nil, streams are reconnected after network errors with exponential
+	// backoff, up to MaxRetries times, and POST requests are not retried.
+	RetryPolicy RetryPolicy
+	// StandaloneSSE configures reconnection of the standalone SSE stream.
+	// If nil, the stream is reconnected like the streams of POST requests.
+	StandaloneSSE *StreamReconnectOptions
+	// IgnoreServerRetry prevents the client from honoring the "retry" field
+	// of server-sent events (see [StreamableHTTPOptions.SSERetry]). By
+	// default, once the server has sent one, it replaces the delays of the
+	// retry policy before each attempt to reconnect a stream, although the
+	// policy still decides whether to make the attempt.
+	IgnoreServerRetry bool
+	// DisableStandaloneSSE prevents the client from opening the standalone
+	// SSE stream, a long-lived GET request on which the server sends messages
+	// that are unrelated to client requests. The client then receives only
+	// responses to its requests, and messages that the server sends while
+	// handling them. The client tells the server that it won't open the
+	// stream, so that the server fails to send other requests and
+	// notifications, rather than storing them for a stream that is never
+	// read.
+	DisableStandaloneSSE bool
+	// MaxEventSize is the maximum size in bytes of a server-sent event. If the
+	// server sends a larger event, the connection fails with an error wrapping
+	// [ErrEventTooLarge]. If zero, [DefaultMaxEventSize] is used.
+	MaxEventSize int
+	// ReadBufferSize is the initial size in bytes of the buffer for reading
+	// server-sent events. The buffer grows as needed, up to MaxEventSize.
+	// If zero, a small default is used.
+	ReadBufferSize int
+	// MaxMessageSize, if positive, is the maximum size in bytes of a JSON
+	// response from the server, which holds a message or a batch of messages.
+	// A larger response fails the connection with an error wrapping
+	// [ErrMessageTooLarge]. Messages in server-sent events are limited by
+	// MaxEventSize instead.
+	MaxMessageSize int
+	// Batch, if set, causes the client to combine concurrent outgoing
+	// messages into JSON-RPC batches, each sent in a single HTTP request,
+	// when the negotiated protocol version permits batching (versions
+	// before 2025-06-18). Some older servers handle batches more efficiently.
+	Batch *BatchOptions
+	// Resume, if non-nil, is the state of an initialized session to resume,
+	// as reported to OnResumptionState by an earlier connection, perhaps of
+	// another process. [Client.Connect] then reattaches to the session
+	// instead of initializing a new one, and reopens the standalone SSE
+	// stream from its last event, so that the server can replay the events
+	// that were missed, if it has an [EventStore]. Until the server notices
+	// that the earlier connection is gone, it refuses to reopen the stream,
+	// and the attempt is retried. If the server no longer has the session,
+	// [Client.Connect] fails with an error, as does the connection if the
+	// server later refuses to reopen the stream because the session is gone.
+	Resume *ResumptionState
+	// If non-nil, OnResumptionState is called with the state needed to
+	// resume the session: once it is initialized, and whenever an event with
+	// an ID is received on the standalone SSE stream. Calls are not
+	// concurrent, and must not block. The state can be saved, to be passed
+	// as Resume when the client restarts.
+	//
+	// Note that closing a session terminates it on the server, so that it
+	// can no longer be resumed.
+	OnResumptionState func(ResumptionState)
 
 	// TODO(rfindley): propose exporting these.
 	// If strict is set, the transport is in 'strict mode', where any violation
@@ -1383,8 +2209,103 @@ type StreamableClientTransport struct {
 	logger *slog.Logger
 }
 
-// These settings are not (yet) exposed to the user in
-// StreamableClientTransport.
+// ResumptionState is the state of a [StreamableClientTransport] session
+// needed to resume it. See [StreamableClientTransport.Resume].
+type ResumptionState struct {
+	// SessionID is the ID that the server assigned to the session.
+	SessionID string `json:"sessionId"`
+	// InitializeResult is the server's response to the initialize request,
+	// which holds the negotiated protocol version.
+	InitializeResult *InitializeResult `json:"initializeResult"`
+	// LastEventID is the ID of the last event received on the standalone
+	// SSE stream, if any.
+	LastEventID string `json:"lastEventId,omitempty"`
+}
+
+// StreamReconnectOptions configures how a [StreamableClientTransport]
+// reconnects its standalone SSE stream: the hanging GET request on which the
+// server sends messages that are unrelated to client requests.
+//
+// Attempts to reconnect are delayed with exponential backoff and jitter. If
+// all attempts fail, the connection fails.
+type StreamReconnectOptions struct {
+	// MaxRetries is the maximum number of consecutive attempts to reconnect
+	// before giving up. If zero, [StreamableClientTransport.MaxRetries] is
+	// used. To disable reconnection, use a negative number.
+	MaxRetries int
+	// InitialDelay is the delay before the first attempt to reconnect.
+	// If zero, one second is used.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts. If zero, 30 seconds is used.
+	MaxDelay time.Duration
+	// GrowFactor is the factor by which the delay increases after each
+	// attempt. If zero, 1.5 is used. Values less than 1 are treated as 1.
+	GrowFactor float64
+	// If non-nil, OnDown is called when the stream is interrupted, before
+	// attempting to reconnect, with the error that interrupted it, or
+	// [io.EOF] if the server ended it.
+	OnDown func(error)
+}
+
+// BatchOptions configures how a [StreamableClientTransport] batches
+// outgoing messages.
+//
+// A message waits up to FlushInterval for others to join its batch, and the
+// call that writes it doesn't return until the batch is sent.
+type BatchOptions struct {
+	// FlushInterval is how long a batch collects messages before it is sent.
+	// If zero, 10 milliseconds is used.
+	FlushInterval time.Duration
+	// MaxSize is the maximum number of messages in a batch. A batch is sent as
+	// soon as it is full. If zero, batches have no maximum size.
+	MaxSize int
+}
+
+// NetworkOptions configures the connections of a [StreamableClientTransport]
+// to its endpoint. Zero fields leave the settings of the HTTP client's
+// transport unchanged.
+type NetworkOptions struct {
+	// Proxy returns the proxy to use for a request, as for
+	// [http.Transport.Proxy]. HTTP, HTTPS and SOCKS5 proxies are supported.
+	// Use [http.ProxyURL] for a fixed proxy, or [http.ProxyFromEnvironment]
+	// to honor the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	Proxy func(*http.Request) (*url.URL, error)
+	// DialContext dials TCP connections, as for [http.Transport.DialContext].
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// KeepAlive is the interval between TCP keep-alive probes of connections
+	// dialed by the default dialer. If negative, keep-alive probes are
+	// disabled. It is ignored if DialContext is set.
+	KeepAlive time.Duration
+	// MaxIdleConns limits the number of idle connections in the pool, across
+	// all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the number of idle connections to each host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the number of connections to each host,
+	// including those in use.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection stays in the pool.
+	IdleConnTimeout time.Duration
+}
+
+// defaultDialTimeout is the timeout of the dialer used when only
+// [NetworkOptions.KeepAlive] is set, matching [http.DefaultTransport].
+const defaultDialTimeout = 30 * time.Second
+
+// defaultBatchFlushInterval is the default for [BatchOptions.FlushInterval].
+const defaultBatchFlushInterval = 10 * time.Millisecond
+
+// A reconnectPolicy controls the backoff of attempts to reconnect a stream.
+type reconnectPolicy struct {
+	maxRetries   int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	growFactor   float64
+}
+
+// These defaults are not exposed to the user in StreamableClientTransport,
+// except for the standalone SSE stream.
 const (
 	// reconnectGrowFactor is the multiplicative factor by which the delay increases after each attempt.
 	// A value of 1.0 results in a constant delay, while a value of 2.0 would double it each time.
@@ -1405,44 +2326,115 @@ const (
 // When closed, the connection issues a DELETE request to terminate the logical
 // session.
 func (t *StreamableClientTransport) Connect(ctx context.Context) (Connection, error) {
-	client := t.HTTPClient
-	if client == nil {
-		client = http.DefaultClient
+	if r := t.Resume; r != nil {
+		if r.SessionID == "" || r.InitializeResult == nil {
+			return nil, errors.New("resumption state has no session ID or initialize result")
+		}
+		if v := r.InitializeResult.ProtocolVersion; !slices.Contains(supportedProtocolVersions, v) {
+			return nil, unsupportedProtocolVersionError{v}
+		}
 	}
-	maxRetries := t.MaxRetries
-	if maxRetries == 0 {
-		maxRetries = 5
-	} else if maxRetries < 0 {
-		maxRetries = 0
+	client, err := t.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	defaultRetry := reconnectPolicy{
+		maxRetries:   retries(t.MaxRetries, 5),
+		initialDelay: reconnectInitialDelay,
+		maxDelay:     reconnectMaxDelay,
+		growFactor:   reconnectGrowFactor,
+	}
+	var retry RetryPolicy = defaultRetry
+	if t.RetryPolicy != nil {
+		retry = t.RetryPolicy
+	}
+	standaloneRetry := retry
+	var onStandaloneDown func(error)
+	if o := t.StandaloneSSE; o != nil {
+		p := defaultRetry
+		p.maxRetries = retries(o.MaxRetries, defaultRetry.maxRetries)
+		if o.InitialDelay > 0 {
+			p.initialDelay = o.InitialDelay
+		}
+		if o.MaxDelay > 0 {
+			p.maxDelay = o.MaxDelay
+		}
+		if o.GrowFactor != 0 {
+			p.growFactor = max(1, o.GrowFactor)
+		}
+		standaloneRetry = p
+		onStandaloneDown = o.OnDown
+	}
+	var batch *clientBatcher
+	if o := t.Batch; o != nil {
+		batch = &clientBatcher{interval: o.FlushInterval, maxSize: o.MaxSize}
+		if batch.interval <= 0 {
+			batch.interval = defaultBatchFlushInterval
+		}
 	}
 	// Create a new cancellable context that will manage the connection's lifecycle.
 	// This is crucial for cleanly shutting down the background SSE listener by
 	// cancelling its blocking network operations, which prevents hangs on exit.
 	connCtx, cancel := context.WithCancel(ctx)
 	conn := &streamableClientConn{
-		url:        t.Endpoint,
-		client:     client,
-		incoming:   make(chan jsonrpc.Message, 10),
-		done:       make(chan struct{}),
-		maxRetries: maxRetries,
-		strict:     t.strict,
-		logger:     t.logger,
-		ctx:        connCtx,
-		cancel:     cancel,
-		failed:     make(chan struct{}),
+		url:              t.Endpoint,
+		client:           client,
+		incoming:         make(chan jsonrpc.Message, 10),
+		done:             make(chan struct{}),
+		retry:            retry,
+		standaloneRetry:  standaloneRetry,
+		onStandaloneDown: onStandaloneDown,
+		noStandalone:     t.DisableStandaloneSSE,
+		ignoreRetry:      t.IgnoreServerRetry,
+		events:           eventScanner{maxEventSize: t.MaxEventSize, bufferSize: t.ReadBufferSize},
+		maxMessageSize:   t.MaxMessageSize,
+		batch:            batch,
+		ownsClient:       client != t.HTTPClient && client != http.DefaultClient,
+		headerFunc:       t.HeaderFunc,
+		strict:           t.strict,
+		logger:           t.logger,
+		ctx:              connCtx,
+		cancel:           cancel,
+		failed:           make(chan struct{}),
+
+		onResumptionState: t.OnResumptionState,
+	}
+	if r := t.Resume; r != nil {
+		conn.resumed = true
+		conn.sessionID = r.SessionID
+		conn.initializedResult = r.InitializeResult
+		conn.lastEventID = r.LastEventID
 	}
 	return conn, nil
 }
 
 type streamableClientConn struct {
-	url        string
-	client     *http.Client
-	ctx        context.Context
-	cancel     context.CancelFunc
-	incoming   chan jsonrpc.Message
-	maxRetries int
-	strict     bool         // from [StreamableClientTransport.strict]
-	logger     *slog.Logger // from [StreamableClientTransport.logger]
+	url      string
+	client   *http.Client
+	ctx      context.Context
+	cancel   context.CancelFunc
+	incoming chan jsonrpc.Message
+	strict   bool         // from [StreamableClientTransport.strict]
+	logger   *slog.Logger // from [StreamableClientTransport.logger]
+
+	// headerFunc is [StreamableClientTransport.HeaderFunc].
+	headerFunc func(context.Context, *http.Request)
+
+	// onResumptionState is [StreamableClientTransport.OnResumptionState].
+	onResumptionState func(ResumptionState)
+	// resumed reports whether the connection resumes a session, from
+	// [StreamableClientTransport.Resume].
+	resumed bool
+
+	retry            RetryPolicy    // for POST requests and their streams
+	standaloneRetry  RetryPolicy    // for the standalone SSE stream
+	onStandaloneDown func(error)    // from [StreamReconnectOptions.OnDown]
+	noStandalone     bool           // from [StreamableClientTransport.DisableStandaloneSSE]
+	ignoreRetry      bool           // from [StreamableClientTransport.IgnoreServerRetry]
+	events           eventScanner   // for reading SSE streams
+	maxMessageSize   int            // from [StreamableClientTransport.MaxMessageSize]
+	batch            *clientBatcher // if non-nil, batches outgoing messages
+	ownsClient       bool           // client was created for the connection, as by httpClient
 
 	// Guard calls to Close, as it may be called multiple times.
 	closeOnce sync.Once
@@ -1464,6 +2456,111 @@ type streamableClientConn struct {
 	mu                sync.Mutex
 	initializedResult *InitializeResult
 	sessionID         string
+	lastEventID       string        // of the standalone SSE stream
+	serverRetry       time.Duration // the last "retry" field sent by the server
+}
+
+// retries returns the number of retries for a MaxRetries option n: def if n
+// is zero, and none if n is negative.
+func retries(n, def int) int {
+	switch {
+	case n == 0:
+		return def
+	case n < 0:
+		return 0
+	}
+	return n
+}
+
+// httpClient returns the client to use for a connection: t.HTTPClient, or
+// http.DefaultClient if it is nil, with a clone of its transport configured
+// with the TLS and network settings of t. If there are no such settings, the
+// client is returned as is.
+func (t *StreamableClientTransport) httpClient() (*http.Client, error) {
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if t.TLSClientConfig == nil && t.RootCAs == nil && len(t.Certificates) == 0 && t.Network == nil &&
+		t.ConnectTimeout <= 0 && t.HandshakeTimeout <= 0 {
+		return client, nil
+	}
+	transport, err := cloneTransport(client)
+	if err != nil {
+		return nil, err
+	}
+	if t.TLSClientConfig != nil || t.RootCAs != nil || len(t.Certificates) > 0 {
+		if t.TLSClientConfig != nil {
+			transport.TLSClientConfig = t.TLSClientConfig.Clone()
+		} else if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = new(tls.Config)
+		}
+		if t.RootCAs != nil {
+			transport.TLSClientConfig.RootCAs = t.RootCAs
+		}
+		if len(t.Certificates) > 0 {
+			transport.TLSClientConfig.Certificates = slices.Concat(transport.TLSClientConfig.Certificates, t.Certificates)
+		}
+	}
+	if n := t.Network; n != nil {
+		if n.Proxy != nil {
+			transport.Proxy = n.Proxy
+		}
+		if n.DialContext != nil {
+			transport.DialContext = n.DialContext
+		} else if n.KeepAlive != 0 {
+			d := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: n.KeepAlive}
+			transport.DialContext = d.DialContext
+		}
+		if n.MaxIdleConns != 0 {
+			transport.MaxIdleConns = n.MaxIdleConns
+		}
+		if n.MaxIdleConnsPerHost != 0 {
+			transport.MaxIdleConnsPerHost = n.MaxIdleConnsPerHost
+		}
+		if n.MaxConnsPerHost != 0 {
+			transport.MaxConnsPerHost = n.MaxConnsPerHost
+		}
+		if n.IdleConnTimeout != 0 {
+			transport.IdleConnTimeout = n.IdleConnTimeout
+		}
+	}
+	setTimeouts(transport, t.ConnectTimeout, t.HandshakeTimeout)
+	c := *client
+	c.Transport = transport
+	return &c, nil
+}
+
+// cloneTransport returns a clone of the transport of client, which must be
+// nil or an [*http.Transport].
+func cloneTransport(client *http.Client) (*http.Transport, error) {
+	switch rt := client.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return rt.Clone(), nil
+	default:
+		return nil, fmt.Errorf("cannot configure HTTP transport of type %T", rt)
+	}
+}
+
+// setTimeouts sets the connect and TLS handshake timeouts of transport, if
+// they are positive.
+func setTimeouts(transport *http.Transport, connect, handshake time.Duration) {
+	if connect > 0 {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, connect)
+			defer cancel()
+			return dial(ctx, network, addr)
+		}
+	}
+	if handshake > 0 {
+		transport.TLSHandshakeTimeout = handshake
+	}
 }
 
 // errSessionMissing distinguishes if the session is known to not be present on
@@ -1477,12 +2574,52 @@ type streamableClientConn struct {
 // differentiate a 'NotFound' error from other errors?
 var errSessionMissing = errors.New("session not found")
 
+// An httpStatusError reports an unexpected HTTP response status.
+type httpStatusError struct {
+	code   int
+	status string // e.g. "503 Service Unavailable"
+}
+
+func (e *httpStatusError) Error() string { return e.status }
+
 var _ clientConnection = (*streamableClientConn)(nil)
 
+// resumedState implements [resumedConnection].
+func (c *streamableClientConn) resumedState() *InitializeResult {
+	if !c.resumed {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.initializedResult
+}
+
+// reportResumptionState calls onResumptionState, if set, with the current
+// state. If eventID is non-empty, it is first recorded as the last event ID
+// of the standalone SSE stream.
+func (c *streamableClientConn) reportResumptionState(eventID string) {
+	c.mu.Lock()
+	if eventID != "" {
+		c.lastEventID = eventID
+	}
+	state := ResumptionState{
+		SessionID:        c.sessionID,
+		InitializeResult: c.initializedResult,
+		LastEventID:      c.lastEventID,
+	}
+	c.mu.Unlock()
+	if c.onResumptionState != nil {
+		c.onResumptionState(state)
+	}
+}
+
 func (c *streamableClientConn) sessionUpdated(state clientSessionState) {
 	c.mu.Lock()
 	c.initializedResult = state.InitializeResult
 	c.mu.Unlock()
+	if !c.resumed {
+		c.reportResumptionState("")
+	}
 
 	// Start the standalone SSE stream as soon as we have the initialized
 	// result.
@@ -1497,7 +2634,9 @@ func (c *streamableClientConn) sessionUpdated(state clientSessionState) {
 	// § 2.5: A server using the Streamable HTTP transport MAY assign a session
 	// ID at initialization time, by including it in an Mcp-Session-Id header
 	// on the HTTP response containing the InitializeResult.
-	go c.handleSSE("standalone SSE stream", nil, true, nil)
+	if !c.noStandalone {
+		go c.handleSSE("standalone SSE stream", nil, true, nil)
+	}
 }
 
 // fail handles an asynchronous error while reading.
@@ -1554,35 +2693,187 @@ func (c *streamableClientConn) Write(ctx context.Context, msg jsonrpc.Message) e
 	if err := c.failure(); err != nil {
 		return err
 	}
+	if c.batching() {
+		return c.batch.add(ctx, msg, func(msgs []jsonrpc.Message) error {
+			// The batch outlives the calls that contributed to it.
+			return c.post(c.ctx, msgs)
+		})
+	}
+	return c.post(ctx, []jsonrpc.Message{msg})
+}
 
-	var requestSummary string
-	var isCall bool
-	switch msg := msg.(type) {
-	case *jsonrpc.Request:
-		requestSummary = fmt.Sprintf("sending %q", msg.Method)
-		isCall = msg.IsCall()
-	case *jsonrpc.Response:
-		requestSummary = fmt.Sprintf("sending jsonrpc response #%d", msg.ID)
-	default:
-		panic("unreachable")
+// batching reports whether outgoing messages should be batched.
+func (c *streamableClientConn) batching() bool {
+	if c.batch == nil {
+		return false
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Batching is only possible once the protocol version is known.
+	return c.initializedResult != nil && c.initializedResult.ProtocolVersion < protocolVersion20250618
+}
 
-	data, err := jsonrpc.EncodeMessage(msg)
-	if err != nil {
-		return fmt.Errorf("%s: %v", requestSummary, err)
+// A clientBatcher collects outgoing messages into batches.
+type clientBatcher struct {
+	interval time.Duration
+	maxSize  int
+
+	mu      sync.Mutex
+	pending *pendingBatch // the batch collecting messages, if any
+}
+
+// A pendingBatch is a batch of messages waiting to be sent.
+type pendingBatch struct {
+	msgs  []jsonrpc.Message
+	timer *time.Timer
+	done  chan struct{} // closed when the batch is sent
+	err   error         // the result of sending the batch
+}
+
+// add adds msg to the pending batch, starting one if necessary, and waits
+// until the batch is sent with send. It returns the error from send.
+//
+// If ctx is done before the batch is sent, msg is removed from the batch and
+// the context's error is returned. If the batch is already being sent, msg
+// can no longer be withdrawn, and add returns nil without waiting.
+func (b *clientBatcher) add(ctx context.Context, msg jsonrpc.Message, send func([]jsonrpc.Message) error) error {
+	b.mu.Lock()
+	p := b.pending
+	if p == nil {
+		p = &pendingBatch{done: make(chan struct{})}
+		b.pending = p
+		p.timer = time.AfterFunc(b.interval, func() { b.flush(p, send) })
+	}
+	p.msgs = append(p.msgs, msg)
+	full := b.maxSize > 0 && len(p.msgs) >= b.maxSize
+	b.mu.Unlock()
+	if full {
+		p.timer.Stop()
+		b.flush(p, send)
 	}
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		if b.remove(p, msg) {
+			return ctx.Err()
+		}
+		return nil
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
-	if err != nil {
-		return err
+// remove removes msg from the batch p, and reports whether it did so before
+// the batch was sent.
+func (b *clientBatcher) remove(p *pendingBatch, msg jsonrpc.Message) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending != p {
+		return false
+	}
+	i := slices.Index(p.msgs, msg)
+	if i < 0 {
+		return false
+	}
+	p.msgs = slices.Delete(p.msgs, i, i+1)
+	if len(p.msgs) == 0 {
+		// Nothing left to send.
+		p.timer.Stop()
+		b.pending = nil
+		close(p.done)
+	}
+	return true
+}
+
+// flush sends the batch p, unless it has already been sent.
+func (b *clientBatcher) flush(p *pendingBatch, send func([]jsonrpc.Message) error) {
+	b.mu.Lock()
+	if b.pending != p {
+		b.mu.Unlock()
+		return
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json, text/event-stream")
-	c.setMCPHeaders(req)
+	b.pending = nil
+	b.mu.Unlock()
+	p.err = send(p.msgs)
+	close(p.done)
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("%s: %v", requestSummary, err)
+// post sends messages to the server in a POST request, as a JSON-RPC batch if
+// there is more than one.
+func (c *streamableClientConn) post(ctx context.Context, msgs []jsonrpc.Message) error {
+	var requestSummary string
+	var data []byte
+	calls := make(map[jsonrpc.ID]bool) // calls awaiting a response
+	for _, msg := range msgs {
+		if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() {
+			calls[req.ID] = true
+		}
+	}
+	if len(msgs) == 1 {
+		switch msg := msgs[0].(type) {
+		case *jsonrpc.Request:
+			requestSummary = fmt.Sprintf("sending %q", msg.Method)
+		case *jsonrpc.Response:
+			requestSummary = fmt.Sprintf("sending jsonrpc response #%d", msg.ID)
+		default:
+			panic("unreachable")
+		}
+		var err error
+		data, err = jsonrpc.EncodeMessage(msgs[0])
+		if err != nil {
+			return fmt.Errorf("%s: %w", requestSummary, err)
+		}
+	} else {
+		requestSummary = fmt.Sprintf("sending batch of %d messages", len(msgs))
+		batch := make([]json.RawMessage, len(msgs))
+		for i, msg := range msgs {
+			var err error
+			batch[i], err = jsonrpc.EncodeMessage(msg)
+			if err != nil {
+				return fmt.Errorf("%s: %w", requestSummary, err)
+			}
+		}
+		var err error
+		data, err = json.Marshal(batch)
+		if err != nil {
+			return fmt.Errorf("%s: %w", requestSummary, err)
+		}
+	}
+	isCall := len(calls) > 0
+
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		c.setMCPHeaders(req)
+
+		resp, err = c.client.Do(req)
+		if err != nil {
+			// Don't retry: the server may have received the request.
+			return fmt.Errorf("%s: %w", requestSummary, err)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+			break
+		}
+		if !postRetryable(resp) {
+			// The server may have processed the request.
+			break
+		}
+		delay, ok := c.retry.Retry(attempt, resp, nil)
+		if !ok {
+			break
+		}
+		resp.Body.Close()
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", requestSummary, ctx.Err())
+		case <-c.done:
+			return fmt.Errorf("%s: %w", requestSummary, ErrConnectionClosed)
+		case <-time.After(delay):
+		}
 	}
 
 	// §2.5.3: "The server MAY terminate the session at any time, after
@@ -1599,7 +2890,7 @@ func (c *streamableClientConn) Write(ctx context.Context, msg jsonrpc.Message) e
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		resp.Body.Close()
-		return fmt.Errorf("broken session: %v", resp.Status)
+		return fmt.Errorf("broken session: %w", &httpStatusError{code: resp.StatusCode, status: resp.Status})
 	}
 
 	if sessionID := resp.Header.Get(sessionIDHeader); sessionID != "" {
@@ -1637,12 +2928,8 @@ func (c *streamableClientConn) Write(ctx context.Context, msg jsonrpc.Message) e
 		go c.handleJSON(requestSummary, resp)
 
 	case "text/event-stream":
-		var forCall *jsonrpc.Request
-		if jsonReq, ok := msg.(*jsonrpc.Request); ok && jsonReq.IsCall() {
-			forCall = jsonReq
-		}
-		// TODO: should we cancel this logical SSE request if/when jsonReq is canceled?
-		go c.handleSSE(requestSummary, resp, false, forCall)
+		// TODO: should we cancel this logical SSE request if/when the calls are canceled?
+		go c.handleSSE(requestSummary, resp, false, calls)
 
 	default:
 		resp.Body.Close()
@@ -1655,9 +2942,10 @@ func (c *streamableClientConn) Write(ctx context.Context, msg jsonrpc.Message) e
 // TODO: replace with a better mechanism when client-side auth is in place.
 var testAuth atomic.Bool
 
+// setMCPHeaders sets the MCP headers of req, and then calls the
+// transport's HeaderFunc, if any.
 func (c *streamableClientConn) setMCPHeaders(req *http.Request) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
 	if c.initializedResult != nil {
 		req.Header.Set(protocolVersionHeader, c.initializedResult.ProtocolVersion)
@@ -1665,38 +2953,65 @@ func (c *streamableClientConn) setMCPHeaders(req *http.Request) {
 	if c.sessionID != "" {
 		req.Header.Set(sessionIDHeader, c.sessionID)
 	}
+	if c.noStandalone {
+		req.Header.Set(noStandaloneHeader, "1")
+	}
 	if testAuth.Load() {
 		req.Header.Set("Authorization", "Bearer foo")
 	}
+	c.mu.Unlock()
+	if c.headerFunc != nil {
+		c.headerFunc(req.Context(), req)
+	}
 }
 
 func (c *streamableClientConn) handleJSON(requestSummary string, resp *http.Response) {
-	body, err := io.ReadAll(resp.Body)
+	var r io.Reader = resp.Body
+	if c.maxMessageSize > 0 {
+		r = io.LimitReader(r, int64(c.maxMessageSize)+1)
+	}
+	body, err := io.ReadAll(r)
 	resp.Body.Close()
 	if err != nil {
-		c.fail(fmt.Errorf("%s: failed to read body: %v", requestSummary, err))
+		c.fail(fmt.Errorf("%s: failed to read body: %w", requestSummary, err))
 		return
 	}
-	msg, err := jsonrpc.DecodeMessage(body)
+	if c.maxMessageSize > 0 && len(body) > c.maxMessageSize {
+		c.fail(fmt.Errorf("%s: %w: exceeds %d bytes", requestSummary, ErrMessageTooLarge, c.maxMessageSize))
+		return
+	}
+	// The response to a batch is a batch.
+	msgs, _, err := readBatch(body)
 	if err != nil {
-		c.fail(fmt.Errorf("%s: failed to decode response: %v", requestSummary, err))
+		c.fail(fmt.Errorf("%s: failed to decode response: %w", requestSummary, err))
 		return
 	}
-	select {
-	case c.incoming <- msg:
-	case <-c.done:
-		// The connection was closed by the client; exit gracefully.
+	for _, msg := range msgs {
+		select {
+		case c.incoming <- msg:
+		case <-c.done:
+			// The connection was closed by the client; exit gracefully.
+			return
+		}
 	}
 }
 
 // handleSSE manages the lifecycle of an SSE connection. It can be either
 // persistent (for the main GET listener) or temporary (for a POST response).
 //
-// If forCall is set, it is the call that initiated the stream, and the
-// stream is complete when we receive its response.
-func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *http.Response, persistent bool, forCall *jsonrpc2.Request) {
+// If calls is non-empty, it holds the IDs of the calls that initiated the
+// stream, and the stream is complete when we receive their responses.
+func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *http.Response, persistent bool, calls map[jsonrpc.ID]bool) {
 	resp := initialResp
 	var lastEventID string
+	if persistent {
+		// Resume the standalone stream of a resumed session where it left
+		// off.
+		c.mu.Lock()
+		lastEventID = c.lastEventID
+		c.mu.Unlock()
+	}
+	var cause error // what interrupted the stream
 	for {
 		// TODO: we should set a reasonable limit on the number of times we'll try
 		// getting a response for a given request.
@@ -1704,8 +3019,10 @@ func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *htt
 		// Eventually, if we don't get the response, we should stop trying and
 		// fail the request.
 		if resp != nil {
-			eventID, clientClosed := c.processStream(requestSummary, resp, forCall)
-			lastEventID = eventID
+			eventID, clientClosed, err := c.processStream(requestSummary, resp, persistent, calls)
+			if eventID != "" || !persistent {
+				lastEventID = eventID
+			}
 
 			// If the connection was closed by the client, we're done.
 			if clientClosed {
@@ -1716,13 +3033,24 @@ func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *htt
 			if lastEventID == "" && !persistent {
 				return
 			}
+			if err == nil {
+				err = io.EOF
+			}
+			cause = err
+			if persistent && c.onStandaloneDown != nil {
+				c.onStandaloneDown(err)
+			}
 		}
 
 		// The stream was interrupted or ended by the server. Attempt to reconnect.
-		newResp, err := c.reconnect(lastEventID)
+		policy := c.retry
+		if persistent {
+			policy = c.standaloneRetry
+		}
+		newResp, err := c.reconnect(lastEventID, policy, cause)
 		if err != nil {
 			// All reconnection attempts failed: fail the connection.
-			c.fail(fmt.Errorf("%s: failed to reconnect (session ID: %v): %v", requestSummary, c.sessionID, err))
+			c.fail(fmt.Errorf("%s: failed to reconnect (session ID: %v): %w", requestSummary, c.sessionID, err))
 			return
 		}
 		resp = newResp
@@ -1738,11 +3066,13 @@ func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *htt
 			resp.Body.Close()
 			return
 		}
-		if resp.StatusCode == http.StatusNotFound && persistent && !c.strict {
+		if resp.StatusCode == http.StatusNotFound && persistent && !c.strict && !c.resumed {
 			// modelcontextprotocol/gosdk#393: some servers return NotFound instead
 			// of MethodNotAllowed for the standalone SSE stream.
 			//
-			// Treat this like MethodNotAllowed in non-strict mode.
+			// Treat this like MethodNotAllowed in non-strict mode, except in
+			// a resumed session, where a 404 more likely means that the
+			// session expired, and the events it missed are lost.
 			if c.logger != nil {
 				c.logger.Warn("got 404 instead of 405 for standalonw SSE stream")
 			}
@@ -1764,90 +3094,159 @@ func (c *streamableClientConn) handleSSE(requestSummary string, initialResp *htt
 }
 
 // processStream reads from a single response body, sending events to the
-// incoming channel. It returns the ID of the last processed event and a flag
-// indicating if the connection was closed by the client. If resp is nil, it
-// returns "", false.
-func (c *streamableClientConn) processStream(requestSummary string, resp *http.Response, forCall *jsonrpc.Request) (lastEventID string, clientClosed bool) {
+// incoming channel. Calls are removed from calls as their responses are
+// received. If persistent is set, the stream is the standalone SSE stream,
+// whose event IDs are reported as resumption state. It returns the ID of the
+// last processed event, a flag indicating if the connection was closed by the
+// client, and the error that interrupted the stream, if any.
+func (c *streamableClientConn) processStream(requestSummary string, resp *http.Response, persistent bool, calls map[jsonrpc.ID]bool) (lastEventID string, clientClosed bool, streamErr error) {
 	defer resp.Body.Close()
-	for evt, err := range scanEvents(resp.Body) {
+	for evt, err := range c.events.scan(resp.Body) {
+		if errors.Is(err, ErrEventTooLarge) {
+			// Don't reconnect: the event would be replayed.
+			c.fail(fmt.Errorf("%s: %w", requestSummary, err))
+			return "", true, nil
+		}
 		if err != nil {
+			streamErr = err
 			break
 		}
 
 		if evt.ID != "" {
 			lastEventID = evt.ID
 		}
+		if evt.Retry > 0 && !c.ignoreRetry {
+			c.mu.Lock()
+			c.serverRetry = evt.Retry
+			c.mu.Unlock()
+		}
+		if len(evt.Data) == 0 {
+			// An event without data, such as one that only sets the retry
+			// delay, is not dispatched.
+			continue
+		}
 
 		msg, err := jsonrpc.DecodeMessage(evt.Data)
 		if err != nil {
-			c.fail(fmt.Errorf("%s: failed to decode event: %v", requestSummary, err))
-			return "", true
+			c.fail(fmt.Errorf("%s: failed to decode event: %w", requestSummary, err))
+			return "", true, nil
 		}
 
 		select {
 		case c.incoming <- msg:
-			if jsonResp, ok := msg.(*jsonrpc.Response); ok && forCall != nil {
-				// TODO: we should never get a response when forReq is nil (the standalone SSE request).
+			if persistent && evt.ID != "" {
+				c.reportResumptionState(evt.ID)
+			}
+			if jsonResp, ok := msg.(*jsonrpc.Response); ok && len(calls) > 0 {
+				// TODO: we should never get a response when calls is empty (the standalone SSE request).
 				// We should detect this case.
-				if jsonResp.ID == forCall.ID {
-					return "", true
+				if calls[jsonResp.ID] {
+					delete(calls, jsonResp.ID)
+					if len(calls) == 0 {
+						return "", true, nil
+					}
 				}
 			}
 		case <-c.done:
 			// The connection was closed by the client; exit gracefully.
-			return "", true
+			return "", true, nil
 		}
 	}
 	// The loop finished without an error, indicating the server closed the stream.
 	//
 	// If the lastEventID is "", the stream is not retryable and we should
-	// report a synthetic error for the call.
-	if lastEventID == "" && forCall != nil {
-		errmsg := &jsonrpc2.Response{
-			ID:    forCall.ID,
-			Error: fmt.Errorf("request terminated without response"),
-		}
-		select {
-		case c.incoming <- errmsg:
-		case <-c.done:
+	// report a synthetic error for each call.
+	if lastEventID == "" {
+		for id := range calls {
+			errmsg := &jsonrpc2.Response{
+				ID:    id,
+				Error: fmt.Errorf("request terminated without response"),
+			}
+			select {
+			case c.incoming <- errmsg:
+			case <-c.done:
+				return "", true, nil
+			}
 		}
 	}
-	return lastEventID, false
+	return lastEventID, false, streamErr
 }
 
-// reconnect handles the logic of retrying a connection with an exponential
-// backoff strategy. It returns a new, valid HTTP response if successful, or
-// an error if all retries are exhausted.
-func (c *streamableClientConn) reconnect(lastEventID string) (*http.Response, error) {
-	var finalErr error
-
+// reconnect handles the logic of retrying a connection according to policy,
+// after the stream was interrupted by cause. It returns a new HTTP response
+// if successful, which may have an error status that the policy doesn't
+// retry, or an error if all retries are exhausted.
+func (c *streamableClientConn) reconnect(lastEventID string, policy RetryPolicy, cause error) (*http.Response, error) {
 	// We can reach the 'reconnect' path through the standlone SSE request, in which case
 	// lastEventID will be "".
 	//
 	// In this case, we need an initial attempt.
+	//
+	// A resumed session's standalone stream also needs an initial attempt,
+	// although it has a last event ID.
 	attempt := 0
-	if lastEventID != "" {
+	if lastEventID != "" && cause != nil {
 		attempt = 1
 	}
 
-	for ; attempt <= c.maxRetries; attempt++ {
+	var (
+		resp     *http.Response
+		finalErr = cause
+		attempts int
+	)
+	for ; ; attempt++ {
+		var delay time.Duration
+		if attempt > 0 {
+			d, ok := policy.Retry(attempt, resp, finalErr)
+			if !ok {
+				if resp != nil {
+					// Let the caller handle the status.
+					return resp, nil
+				}
+				break
+			}
+			if resp != nil {
+				resp.Body.Close()
+				resp = nil
+			}
+			delay = d
+		}
+		if attempt > 0 || cause != nil {
+			// Honor the server's retry delay, if any, when reconnecting.
+			c.mu.Lock()
+			if c.serverRetry > 0 {
+				delay = c.serverRetry
+			}
+			c.mu.Unlock()
+		}
 		select {
 		case <-c.done:
 			return nil, fmt.Errorf("connection closed by client during reconnect")
-		case <-time.After(calculateReconnectDelay(attempt)):
-			resp, err := c.establishSSE(lastEventID)
+		case <-time.After(delay):
+			attempts++
+			r, err := c.establishSSE(lastEventID)
 			if err != nil {
 				finalErr = err // Store the error and try again.
 				continue
 			}
-			return resp, nil
+			if r.StatusCode >= 200 && r.StatusCode < 300 {
+				return r, nil
+			}
+			if r.StatusCode == http.StatusConflict && c.resumed {
+				// The stream may still be held by the connection that this one
+				// resumes, until the server notices that it is gone.
+				r.Body.Close()
+				finalErr = &httpStatusError{code: r.StatusCode, status: r.Status}
+				continue
+			}
+			resp, finalErr = r, nil
 		}
 	}
 	// If the loop completes, all retries have failed.
-	if finalErr != nil {
-		return nil, fmt.Errorf("connection failed after %d attempts: %w", c.maxRetries, finalErr)
+	if finalErr != nil && attempts > 0 {
+		return nil, fmt.Errorf("connection failed after %d attempts: %w", attempts, finalErr)
 	}
-	return nil, fmt.Errorf("connection failed after %d attempts", c.maxRetries)
+	return nil, fmt.Errorf("connection failed after %d attempts", attempts)
 }
 
 // Close implements the [Connection] interface.
@@ -1869,6 +3268,9 @@ func (c *streamableClientConn) Close() error {
 
 		// Cancel any hanging network requests after cleanup.
 		c.cancel()
+		if c.ownsClient {
+			c.client.CloseIdleConnections()
+		}
 		close(c.done)
 	})
 	return c.closeErr
@@ -1891,18 +3293,36 @@ func (c *streamableClientConn) establishSSE(lastEventID string) (*http.Response,
 	return c.client.Do(req)
 }
 
-// calculateReconnectDelay calculates a delay using exponential backoff with full jitter.
-func calculateReconnectDelay(attempt int) time.Duration {
-	if attempt == 0 {
+// delay calculates the delay before a reconnect attempt using exponential
+// backoff with full jitter.
+func (p reconnectPolicy) delay(attempt int) time.Duration {
+	backoffDuration := p.backoff(attempt)
+	if backoffDuration <= 0 {
 		return 0
 	}
-	// Calculate the exponential backoff using the grow factor.
-	backoffDuration := time.Duration(float64(reconnectInitialDelay) * math.Pow(reconnectGrowFactor, float64(attempt-1)))
-	// Cap the backoffDuration at maxDelay.
-	backoffDuration = min(backoffDuration, reconnectMaxDelay)
-
 	// Use a full jitter using backoffDuration
 	jitter := rand.N(backoffDuration)
 
 	return backoffDuration + jitter
 }
+
+// backoff calculates the exponential backoff before a reconnect attempt,
+// without jitter.
+func (p reconnectPolicy) backoff(attempt int) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	// Calculate the exponential backoff using the grow factor.
+	backoffDuration := time.Duration(float64(p.initialDelay) * math.Pow(p.growFactor, float64(attempt-1)))
+	// Cap the backoffDuration at maxDelay.
+	return min(backoffDuration, p.maxDelay)
+}
+
+// Retry implements [RetryPolicy], retrying network errors but no error
+// statuses.
+func (p reconnectPolicy) Retry(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if resp != nil || attempt > p.maxRetries {
+		return 0, false
+	}
+	return p.delay(attempt), true
+}
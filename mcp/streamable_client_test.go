@@ -6,12 +6,18 @@ package mcp
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -442,3 +448,351 @@ func TestStreamableClientUnresumableRequest(t *testing.T) {
 		t.Errorf("Connect: got error %v, want containing %q", err, msg)
 	}
 }
+
+func TestStreamableClientTLS(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	httpServer := httptest.NewUnstartedServer(NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil))
+	httpServer.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(httpServer.Certificate())
+	// The test server's certificate serves as a client certificate, since
+	// the server doesn't verify it.
+	cert := httpServer.TLS.Certificates[0]
+
+	for _, test := range []struct {
+		name      string
+		transport *StreamableClientTransport
+		wantErr   bool
+	}{
+		{"roots and certificate", &StreamableClientTransport{RootCAs: roots, Certificates: []tls.Certificate{cert}}, false},
+		{"config", &StreamableClientTransport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}}}, false},
+		{"config and roots", &StreamableClientTransport{TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{cert}}, RootCAs: roots}, false},
+		{"no roots", &StreamableClientTransport{Certificates: []tls.Certificate{cert}}, true},
+		{"no certificate", &StreamableClientTransport{RootCAs: roots}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.transport.Endpoint = httpServer.URL
+			test.transport.MaxRetries = -1
+			cs, err := NewClient(testImpl, nil).Connect(ctx, test.transport, nil)
+			if err == nil {
+				cs.Close()
+			}
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Connect: got error %v, want error: %t", err, test.wantErr)
+			}
+		})
+	}
+
+	// TLS settings can't be applied to other HTTP transports.
+	transport := &StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)},
+		RootCAs:    roots,
+	}
+	if _, err := transport.Connect(ctx); err == nil {
+		t.Error("Connect with custom HTTP transport and TLS settings succeeded")
+	}
+}
+
+func TestStreamableClientHeaderFunc(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu      sync.Mutex
+		methods = map[string]bool{}
+	)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, nil)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.Header.Get("X-Tenant"), "tenant-1"; got != want {
+			t.Errorf("%s request: X-Tenant = %q, want %q", req.Method, got, want)
+		}
+		mu.Lock()
+		methods[req.Method] = true
+		mu.Unlock()
+		handler.ServeHTTP(w, req)
+	}))
+	defer httpServer.Close()
+
+	transport := &StreamableClientTransport{
+		Endpoint: httpServer.URL,
+		HeaderFunc: func(ctx context.Context, req *http.Request) {
+			if ctx == nil {
+				t.Error("HeaderFunc called with nil context")
+			}
+			req.Header.Set("X-Tenant", "tenant-1")
+		},
+	}
+	cs, err := NewClient(testImpl, nil).Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the standalone SSE stream.
+	for {
+		mu.Lock()
+		ok := methods[http.MethodGet]
+		mu.Unlock()
+		if ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, m := range []string{http.MethodPost, http.MethodGet, http.MethodDelete} {
+		if !methods[m] {
+			t.Errorf("no %s request", m)
+		}
+	}
+}
+
+func TestStreamableClientNetwork(t *testing.T) {
+	ctx := context.Background()
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, nil)
+	// The proxy serves requests for mcp.example itself.
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Host != "mcp.example" {
+			http.Error(w, "unknown host", http.StatusBadGateway)
+			return
+		}
+		proxied.Add(1)
+		handler.ServeHTTP(w, req)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dials atomic.Int32
+	var dialer net.Dialer
+	transport := &StreamableClientTransport{
+		Endpoint: "http://mcp.example/",
+		Network: &NetworkOptions{
+			Proxy: http.ProxyURL(proxyURL),
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials.Add(1)
+				return dialer.DialContext(ctx, network, addr)
+			},
+			MaxIdleConnsPerHost: 1,
+		},
+	}
+	cs, err := NewClient(testImpl, nil).Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if proxied.Load() == 0 {
+		t.Error("no requests went through the proxy")
+	}
+	if dials.Load() == 0 {
+		t.Error("the dialer was not used")
+	}
+	// The options don't leak into the default transport.
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == 1 {
+		t.Error("default transport was modified")
+	}
+}
+
+func TestClientTransportTimeouts(t *testing.T) {
+	// A listener that accepts connections but never completes a TLS
+	// handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, c)
+			mu.Unlock()
+		}
+	}()
+	// A client whose dials hang until their context is done.
+	hangingClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}}
+	const timeout = 50 * time.Millisecond
+	tests := []struct {
+		name      string
+		transport Transport
+		want      string
+	}{
+		{"streamable connect", &StreamableClientTransport{Endpoint: "http://mcp.example/", HTTPClient: hangingClient, ConnectTimeout: timeout}, "deadline exceeded"},
+		{"streamable handshake", &StreamableClientTransport{Endpoint: "https://" + ln.Addr().String(), HandshakeTimeout: timeout}, "handshake timeout"},
+		{"sse connect", &SSEClientTransport{Endpoint: "http://mcp.example/", HTTPClient: hangingClient, ConnectTimeout: timeout}, "deadline exceeded"},
+		{"sse handshake", &SSEClientTransport{Endpoint: "https://" + ln.Addr().String(), HandshakeTimeout: timeout}, "handshake timeout"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := NewClient(testImpl, nil).Connect(context.Background(), test.transport, nil)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Connect: got %v, want error containing %q", err, test.want)
+			}
+			if d := time.Since(start); d > 5*time.Second {
+				t.Errorf("Connect took %v", d)
+			}
+		})
+	}
+}
+
+func TestStreamableClientResume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	var initializes atomic.Int32
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodInitialize {
+				initializes.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		EventStore: NewMemoryEventStore(nil),
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	newClient := func(progress chan<- string) *Client {
+		return NewClient(testImpl, &ClientOptions{
+			ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+				progress <- req.Params.Message
+			},
+		})
+	}
+	notify := func(msg string) {
+		t.Helper()
+		for ss := range server.Sessions() {
+			if err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: "t", Message: msg}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	receive := func(progress <-chan string, want string) {
+		t.Helper()
+		select {
+		case got := <-progress:
+			if got != want {
+				t.Errorf("got progress %q, want %q", got, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("progress %q was not delivered", want)
+		}
+	}
+
+	// The first process connects, and records its resumption state.
+	var (
+		mu    sync.Mutex
+		state ResumptionState
+	)
+	progress1 := make(chan string, 10)
+	connectCtx, connectCancel := context.WithCancel(ctx)
+	cs1, err := newClient(progress1).Connect(connectCtx, &StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		MaxRetries: -1,
+		OnResumptionState: func(s ResumptionState) {
+			mu.Lock()
+			state = s
+			mu.Unlock()
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notify("first")
+	receive(progress1, "first")
+	mu.Lock()
+	saved := state
+	mu.Unlock()
+	if saved.SessionID != cs1.ID() || saved.InitializeResult == nil || saved.LastEventID == "" {
+		t.Fatalf("incomplete resumption state %+v", saved)
+	}
+
+	// The process exits without closing its session, and misses an event.
+	connectCancel()
+	notify("missed")
+
+	// The next process resumes the session, without initializing it again,
+	// and receives the missed event.
+	progress2 := make(chan string, 10)
+	cs2, err := newClient(progress2).Connect(ctx, &StreamableClientTransport{
+		Endpoint:    httpServer.URL,
+		Resume:      &saved,
+		RetryPolicy: &BackoffPolicy{BaseDelay: time.Millisecond, Jitter: -1},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs2.Close()
+	if cs2.ID() != saved.SessionID {
+		t.Errorf("resumed session ID %q, want %q", cs2.ID(), saved.SessionID)
+	}
+	if got := cs2.ProtocolVersion(); got != saved.InitializeResult.ProtocolVersion {
+		t.Errorf("resumed protocol version %q, want %q", got, saved.InitializeResult.ProtocolVersion)
+	}
+	receive(progress2, "missed")
+	if _, err := cs2.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := initializes.Load(); got != 1 {
+		t.Errorf("server received %d initialize requests, want 1", got)
+	}
+
+	// A session that the server no longer has can't be resumed.
+	gone := ResumptionState{SessionID: cs2.ID(), InitializeResult: saved.InitializeResult}
+	if err := cs2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newClient(progress2).Connect(ctx, &StreamableClientTransport{
+		Endpoint: httpServer.URL,
+		Resume:   &gone,
+	}, nil); !errors.Is(err, errSessionMissing) {
+		t.Errorf("Connect resuming deleted session: got %v, want %v", err, errSessionMissing)
+	}
+
+	// Invalid resumption state is rejected.
+	if _, err := newClient(progress2).Connect(ctx, &StreamableClientTransport{
+		Endpoint: httpServer.URL,
+		Resume:   &ResumptionState{SessionID: saved.SessionID},
+	}, nil); err == nil {
+		t.Error("Connect with incomplete resumption state succeeded")
+	}
+}
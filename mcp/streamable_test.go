@@ -5,6 +5,7 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -372,7 +373,8 @@ func TestStreamableServerShutdown(t *testing.T) {
 // uses a proxy that is killed and restarted to simulate a recoverable network
 // outage.
 //
-// TODO: Until we have a way to clean up abandoned sessions, this test will leak goroutines (see #499)
+// If the client does not recover, its abandoned session is cleaned up by
+// pruning (see #499).
 func TestClientReplay(t *testing.T) {
 	for _, test := range []clientReplayTest{
 		{"default", 0, true},
@@ -419,9 +421,21 @@ func testClientReplay(t *testing.T, test clientReplayTest) {
 			return new(CallToolResult), nil, nil
 		})
 
-	realServer := httptest.NewServer(mustNotPanic(t, NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+	terminated := make(chan SessionTerminationReason, 1)
+	opts := &StreamableHTTPOptions{
 		EventStore: NewMemoryEventStore(nil), // necessary for replay
-	})))
+		OnSessionTerminated: func(_ string, reason SessionTerminationReason) {
+			select {
+			case terminated <- reason:
+			default:
+			}
+		},
+	}
+	if !test.wantRecovered {
+		// The client may abandon its session.
+		opts.Prune = &PruneOptions{Interval: 10 * time.Millisecond, MaxSessionAge: 100 * time.Millisecond}
+	}
+	realServer := httptest.NewServer(mustNotPanic(t, NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, opts)))
 	t.Cleanup(func() {
 		t.Log("Closing real HTTP server")
 		realServer.Close()
@@ -510,10 +524,430 @@ func testClientReplay(t *testing.T, test clientReplayTest) {
 			t.Errorf("CallTool failed unexpectedly: %v", err)
 		}
 	} else {
-		// Otherwise, the call should fail.
+		// Otherwise, the call should fail, and the session should end: the
+		// client may delete it, but if it can't, pruning closes it.
 		if callErr == nil {
 			t.Errorf("CallTool succeeded unexpectedly")
 		}
+		select {
+		case reason := <-terminated:
+			if reason != SessionDeleted && reason != SessionExpired {
+				t.Errorf("session terminated with reason %v, want %v or %v", reason, SessionDeleted, SessionExpired)
+			}
+		case <-ctx.Done():
+			t.Error("abandoned session was not pruned")
+		}
+	}
+}
+
+func TestStandaloneSSEReconnect(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)
+	var gets atomic.Int32
+	httpServer := httptest.NewServer(mustNotPanic(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && gets.Add(1) <= 2 {
+			// Interrupt the first two standalone streams.
+			ctx, cancel := context.WithTimeout(req.Context(), 20*time.Millisecond)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		handler.ServeHTTP(w, req)
+	})))
+	defer httpServer.Close()
+
+	downs := make(chan error, 10)
+	notes := make(chan string, 1)
+	client := NewClient(testImpl, &ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+			notes <- req.Params.Message
+		},
+	})
+	cs, err := client.Connect(ctx, &StreamableClientTransport{
+		Endpoint: httpServer.URL,
+		StandaloneSSE: &StreamReconnectOptions{
+			InitialDelay: time.Millisecond,
+			OnDown:       func(err error) { downs <- err },
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	for range 2 {
+		select {
+		case <-downs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the stream to go down")
+		}
+	}
+	// Wait for the third stream, and check that it delivers messages.
+	for gets.Load() < 3 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	for ss := range server.Sessions() {
+		for {
+			// Retry until the stream is registered.
+			err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: "t", Message: "up"})
+			if err == nil {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	select {
+	case got := <-notes:
+		if got != "up" {
+			t.Errorf("got notification %q, want %q", got, "up")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+}
+
+func TestDisableStandaloneSSE(t *testing.T) {
+	for _, withStore := range []bool{false, true} {
+		t.Run(fmt.Sprintf("eventstore=%t", withStore), func(t *testing.T) {
+			ctx := context.Background()
+			server := NewServer(testImpl, nil)
+			opts := &StreamableHTTPOptions{}
+			if withStore {
+				opts.EventStore = NewMemoryEventStore(nil)
+			}
+			handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, opts)
+			var gets atomic.Int32
+			httpServer := httptest.NewServer(mustNotPanic(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodGet {
+					gets.Add(1)
+				}
+				handler.ServeHTTP(w, req)
+			})))
+			defer httpServer.Close()
+
+			cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+				Endpoint:             httpServer.URL,
+				DisableStandaloneSSE: true,
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+			if err := cs.Ping(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+			if n := gets.Load(); n != 0 {
+				t.Errorf("got %d GET requests, want 0", n)
+			}
+			// Unrelated server messages fail, rather than waiting for a stream
+			// that is never opened.
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			for ss := range server.Sessions() {
+				if err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: "t"}); err == nil {
+					t.Error("NotifyProgress without a standalone stream: got nil error")
+				}
+				if _, err := ss.ListRoots(ctx, nil); err == nil || errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("ListRoots without a standalone stream: got %v, want immediate failure", err)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamableClientBatching(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name         string
+		version      string
+		jsonResponse bool
+		wantBatches  bool
+	}{
+		{"old version", protocolVersion20250326, false, true},
+		{"old version, JSON", protocolVersion20250326, true, true},
+		{"latest version", latestProtocolVersion, false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(testImpl, nil)
+			server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+				return func(ctx context.Context, method string, req Request) (Result, error) {
+					res, err := next(ctx, method, req)
+					if res, ok := res.(*InitializeResult); ok {
+						res.ProtocolVersion = test.version
+					}
+					return res, err
+				}
+			})
+			handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+				JSONResponse: test.jsonResponse,
+			})
+			var batches atomic.Int32
+			httpServer := httptest.NewServer(mustNotPanic(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodPost {
+					body, err := io.ReadAll(req.Body)
+					if err != nil {
+						t.Error(err)
+					}
+					if bytes.HasPrefix(body, []byte("[")) {
+						batches.Add(1)
+					}
+					req.Body = io.NopCloser(bytes.NewReader(body))
+				}
+				handler.ServeHTTP(w, req)
+			})))
+			defer httpServer.Close()
+
+			const n = 5
+			transport := &StreamableClientTransport{
+				Endpoint: httpServer.URL,
+				Batch:    &BatchOptions{FlushInterval: 100 * time.Millisecond, MaxSize: n},
+			}
+			cs, err := NewClient(testImpl, nil).Connect(ctx, transport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+
+			var wg sync.WaitGroup
+			for range n {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := cs.ListTools(ctx, nil); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+			if got := batches.Load() > 0; got != test.wantBatches {
+				t.Errorf("sent %d batches, want batches: %t", batches.Load(), test.wantBatches)
+			}
+		})
+	}
+}
+
+func TestClientBatcherCancel(t *testing.T) {
+	b := &clientBatcher{interval: 50 * time.Millisecond}
+	sent := make(chan []jsonrpc.Message, 1)
+	send := func(msgs []jsonrpc.Message) error {
+		sent <- msgs
+		return nil
+	}
+	kept := &jsonrpc.Request{Method: "kept"}
+	cancelled := &jsonrpc.Request{Method: "cancelled"}
+
+	errc := make(chan error, 1)
+	go func() { errc <- b.add(context.Background(), kept, send) }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// A message withdrawn before the batch is sent is not sent.
+	if err := b.add(ctx, cancelled, send); !errors.Is(err, context.Canceled) {
+		t.Errorf("add with cancelled context: got %v, want %v", err, context.Canceled)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if got := <-sent; len(got) != 1 || got[0] != kept {
+		t.Errorf("sent %v, want only the kept message", got)
+	}
+
+	// A batch emptied by cancellation is not sent.
+	if err := b.add(ctx, cancelled, send); !errors.Is(err, context.Canceled) {
+		t.Errorf("add with cancelled context: got %v, want %v", err, context.Canceled)
+	}
+	time.Sleep(2 * b.interval)
+	select {
+	case got := <-sent:
+		t.Errorf("sent %v after cancellation", got)
+	default:
+	}
+}
+
+func TestStreamableClientMaxEventSize(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "big"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: strings.Repeat("x", 1000)}}}, nil, nil
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:       httpServer.URL,
+		MaxEventSize:   500,
+		ReadBufferSize: 64,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "big"}); !errors.Is(err, ErrEventTooLarge) {
+		t.Errorf("CallTool with a large result: got %v, want ErrEventTooLarge", err)
+	}
+}
+
+func TestStreamableOnRequest(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	infos := make(chan RequestInfo, 100)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		OnRequest: func(info RequestInfo) { infos <- info },
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionID := cs.ID()
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+
+	seen := make(map[RequestKind]bool)
+	for !seen[RequestMessage] || !seen[RequestStream] || !seen[RequestDelete] {
+		var info RequestInfo
+		select {
+		case info = <-infos:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out; saw %v", seen)
+		}
+		seen[info.Kind] = true
+		if info.SessionID != sessionID {
+			t.Errorf("%s request: got session ID %q, want %q", info.Kind, info.SessionID, sessionID)
+		}
+		wantStatus := http.StatusOK
+		switch info.Kind {
+		case RequestMessage:
+			if info.Request.Method != http.MethodPost {
+				t.Errorf("message request has method %s", info.Request.Method)
+			}
+			if info.Status == http.StatusAccepted {
+				wantStatus = http.StatusAccepted // notifications
+			} else if info.Bytes == 0 {
+				t.Error("message request: got empty response")
+			}
+		case RequestDelete:
+			wantStatus = http.StatusNoContent
+		}
+		if info.Status != wantStatus {
+			t.Errorf("%s request: got status %d, want %d", info.Kind, info.Status, wantStatus)
+		}
+	}
+}
+
+func TestStreamableWriteError(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		WriteError: WriteProblemDetails,
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	for _, test := range []struct {
+		name       string
+		method     string
+		sessionID  string
+		body       string
+		wantStatus int
+	}{
+		{"no session", http.MethodGet, "", "", http.StatusMethodNotAllowed},
+		{"unknown session", http.MethodPost, "unknown", "{}", http.StatusNotFound},
+		{"malformed payload", http.MethodPost, cs.ID(), "{", http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(ctx, test.method, httpServer.URL, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			if test.sessionID != "" {
+				req.Header.Set(sessionIDHeader, test.sessionID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("got Content-Type %q, want application/problem+json", got)
+			}
+			var problem struct {
+				Title  string
+				Status int
+				Detail string
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Status != test.wantStatus || problem.Title != http.StatusText(test.wantStatus) || problem.Detail == "" {
+				t.Errorf("got problem %+v", problem)
+			}
+		})
+	}
+}
+
+func TestStreamableReconfigure(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	var requests atomic.Int32
+	if err := handler.Reconfigure(func(o *StreamableHTTPOptions) {
+		o.DisableDelete = true
+		o.OnRequest = func(RequestInfo) { requests.Add(1) }
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The existing session survives reconfiguration.
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() == 0 {
+		t.Error("OnRequest not called after Reconfigure")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(sessionIDHeader, cs.ID())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE after disabling: got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	if err := handler.Reconfigure(func(o *StreamableHTTPOptions) {
+		o.Stateless = true
+		o.DisableDelete = false
+	}); err == nil {
+		t.Error("Reconfigure of Stateless succeeded")
+	}
+	if !handler.options().DisableDelete {
+		t.Error("failed Reconfigure changed options")
 	}
 }
 
@@ -1426,6 +1860,66 @@ func TestStreamableStateless(t *testing.T) {
 	})
 }
 
+func TestStreamableStatelessNotifications(t *testing.T) {
+	ctx := context.Background()
+	after := make(chan error, 1)
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "work"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		// Notify with a context unrelated to the request.
+		err := req.Session.NotifyProgress(context.Background(), &ProgressNotificationParams{
+			ProgressToken: "tok",
+			Progress:      1,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		ss := req.Session
+		go func() {
+			time.Sleep(50 * time.Millisecond) // after the request is complete
+			after <- ss.NotifyProgress(context.Background(), &ProgressNotificationParams{
+				ProgressToken: "tok",
+				Progress:      2,
+			})
+		}()
+		return &CallToolResult{}, nil, nil
+	})
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{Stateless: true})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	notes := make(chan float64, 10)
+	client := NewClient(testImpl, &ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+			notes <- req.Params.Progress
+		},
+	})
+	cs, err := client.Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	params := &CallToolParams{Name: "work"}
+	params.SetProgressToken("tok")
+	res, err := cs.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool failed: %v", textContent(t, res))
+	}
+	select {
+	case got := <-notes:
+		if got != 1 {
+			t.Errorf("got progress %v, want 1", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+	if err := <-after; err == nil {
+		t.Error("notification after the request completed: got nil error")
+	}
+}
+
 func textContent(t *testing.T, res *CallToolResult) string {
 	t.Helper()
 	if len(res.Content) != 1 {
@@ -1626,6 +2120,238 @@ func TestStreamableClientContextPropagation(t *testing.T) {
 	}
 }
 
+func TestStreamableShutdownHandoff(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySessionStore()
+	defer store.Close()
+	reasons := make(chan SessionTerminationReason, 1)
+	newHandler := func() *StreamableHTTPHandler {
+		server := NewServer(testImpl, nil)
+		return NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+			SessionStore: store,
+			OnSessionTerminated: func(_ string, reason SessionTerminationReason) {
+				select {
+				case reasons <- reason:
+				default:
+				}
+			},
+		})
+	}
+	// Route requests to the current handler, as a load balancer would.
+	var current atomic.Pointer[StreamableHTTPHandler]
+	old := newHandler()
+	current.Store(old)
+	httpServer := httptest.NewServer(mustNotPanic(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		current.Load().ServeHTTP(w, req)
+	})))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	current.Store(newHandler())
+	if err := old.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case reason := <-reasons:
+		if reason != SessionHandedOff {
+			t.Errorf("got termination reason %v, want %v", reason, SessionHandedOff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for termination")
+	}
+	stored, err := store.Get(ctx, cs.ID())
+	if err != nil {
+		t.Fatalf("session not in store after shutdown: %v", err)
+	}
+	if !stored.HandedOff || stored.SessionState.InitializeParams == nil {
+		t.Errorf("stored session: got %+v, want initialized and handed off", stored)
+	}
+
+	// The old handler rejects requests with a retryable status.
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set(sessionIDHeader, cs.ID())
+	rec := httptest.NewRecorder()
+	old.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("after shutdown: got status %d, Retry-After %q; want 503 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// The session continues on the new handler.
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatalf("after handoff: %v", err)
+	}
+}
+
+func TestStreamableSessionTermination(t *testing.T) {
+	ctx := context.Background()
+	type terminated struct {
+		id     string
+		reason SessionTerminationReason
+	}
+	connect := func(t *testing.T, opts *StreamableHTTPOptions) (*ClientSession, *Server, string, chan terminated) {
+		t.Helper()
+		ch := make(chan terminated, 1)
+		opts.OnSessionTerminated = func(id string, reason SessionTerminationReason) {
+			ch <- terminated{id, reason}
+		}
+		server := NewServer(testImpl, nil)
+		handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, opts)
+		httpServer := httptest.NewServer(mustNotPanic(t, handler))
+		t.Cleanup(httpServer.Close)
+		cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { cs.Close() })
+		return cs, server, httpServer.URL, ch
+	}
+	wait := func(t *testing.T, ch chan terminated, id string, want SessionTerminationReason) {
+		t.Helper()
+		select {
+		case got := <-ch:
+			if got.id != id || got.reason != want {
+				t.Errorf("OnSessionTerminated(%q, %v), want (%q, %v)", got.id, got.reason, id, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for termination")
+		}
+	}
+	deleteSession := func(t *testing.T, url, id string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(sessionIDHeader, id)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("deleted", func(t *testing.T) {
+		cs, _, _, ch := connect(t, &StreamableHTTPOptions{})
+		cs.Close()
+		wait(t, ch, cs.ID(), SessionDeleted)
+	})
+	t.Run("idle", func(t *testing.T) {
+		cs, _, _, ch := connect(t, &StreamableHTTPOptions{SessionTimeout: 50 * time.Millisecond})
+		wait(t, ch, cs.ID(), SessionIdleTimeout)
+	})
+	t.Run("expired", func(t *testing.T) {
+		cs, _, _, ch := connect(t, &StreamableHTTPOptions{
+			Prune: &PruneOptions{Interval: 10 * time.Millisecond, MaxSessionAge: 50 * time.Millisecond},
+		})
+		wait(t, ch, cs.ID(), SessionExpired)
+	})
+	t.Run("closed", func(t *testing.T) {
+		cs, server, _, ch := connect(t, &StreamableHTTPOptions{})
+		for ss := range server.Sessions() {
+			ss.Close()
+		}
+		wait(t, ch, cs.ID(), SessionClosed)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		cs, server, url, _ := connect(t, &StreamableHTTPOptions{DisableDelete: true})
+		if got := deleteSession(t, url, cs.ID()); got != http.StatusMethodNotAllowed {
+			t.Errorf("DELETE: got status %d, want %d", got, http.StatusMethodNotAllowed)
+		}
+		if n := len(slices.Collect(server.Sessions())); n != 1 {
+			t.Errorf("got %d sessions after DELETE, want 1", n)
+		}
+	})
+	t.Run("unauthenticated", func(t *testing.T) {
+		cs, _, url, _ := connect(t, &StreamableHTTPOptions{RequireAuthForDelete: true})
+		if got := deleteSession(t, url, cs.ID()); got != http.StatusUnauthorized {
+			t.Errorf("DELETE: got status %d, want %d", got, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestStreamableSessionTimeoutOverride(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name        string
+		funcTimeout time.Duration // returned by SessionTimeoutFunc
+		setTimeout  time.Duration // passed to SetIdleTimeout, if non-zero
+		want        time.Duration
+	}{
+		{"func", 100 * time.Millisecond, 0, 100 * time.Millisecond},
+		{"set", time.Hour, 150 * time.Millisecond, 150 * time.Millisecond},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(testImpl, &ServerOptions{
+				InitializedHandler: func(_ context.Context, req *InitializedRequest) {
+					if test.setTimeout != 0 {
+						if err := req.Session.SetIdleTimeout(test.setTimeout); err != nil {
+							t.Error(err)
+						}
+					}
+				},
+			})
+			store := NewInMemorySessionStore()
+			terminated := make(chan SessionTerminationReason, 1)
+			handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+				SessionTimeout:     time.Hour,
+				SessionTimeoutFunc: func(*http.Request) time.Duration { return test.funcTimeout },
+				SessionStore:       store,
+				OnSessionTerminated: func(_ string, reason SessionTerminationReason) {
+					terminated <- reason
+				},
+			})
+			httpServer := httptest.NewServer(mustNotPanic(t, handler))
+			defer httpServer.Close()
+
+			start := time.Now()
+			cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+
+			// The timeout is persisted with the session.
+			for {
+				stored, err := store.Get(ctx, cs.ID())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stored.Timeout == test.want {
+					break
+				}
+				if time.Since(start) > 5*time.Second {
+					t.Fatalf("stored timeout is %v, want %v", stored.Timeout, test.want)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			select {
+			case reason := <-terminated:
+				if reason != SessionIdleTimeout {
+					t.Errorf("session terminated with reason %v, want %v", reason, SessionIdleTimeout)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("session did not time out")
+			}
+		})
+	}
+
+	// Sessions over other transports have no idle timeout.
+	_, ss, cleanup := basicConnection(t, nil)
+	defer cleanup()
+	if err := ss.SetIdleTimeout(time.Second); err == nil {
+		t.Error("SetIdleTimeout over in-memory transport: got nil error")
+	}
+}
+
 func TestStreamableSessionTimeout(t *testing.T) {
 	// TODO: this test relies on timing and may be flaky.
 	// Fixing with testing/synctest is challenging because it uses real I/O (via
@@ -1728,3 +2454,267 @@ func mustNotPanic(t *testing.T, h http.Handler) http.Handler {
 		h.ServeHTTP(w, req)
 	})
 }
+
+func TestStreamableMaxMessageSize(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		MaxMessageSize: 1000,
+		JSONResponse:   true,
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Large requests are rejected.
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": strings.Repeat("x", 1000)}})
+	if err == nil || !strings.Contains(err.Error(), http.StatusText(http.StatusRequestEntityTooLarge)) {
+		t.Errorf("CallTool with large arguments: got %v, want 413 error", err)
+	}
+
+	// Clients limit the size of JSON responses.
+	_, err = NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:       httpServer.URL,
+		MaxMessageSize: 10,
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), ErrMessageTooLarge.Error()) {
+		t.Errorf("Connect with small MaxMessageSize: got %v, want %v", err, ErrMessageTooLarge)
+	}
+}
+
+func TestStreamableHeartbeat(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		HeartbeatInterval: 10 * time.Millisecond,
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:             httpServer.URL,
+		DisableStandaloneSSE: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	// The hanging GET receives heartbeat comments while idle.
+	get, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get.Header.Set("Accept", "text/event-stream")
+	get.Header.Set(sessionIDHeader, cs.ID())
+	resp, err := http.DefaultClient.Do(get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET: got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	r := bufio.NewReader(resp.Body)
+	for range 2 {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != ": ping\n" {
+			t.Fatalf("got line %q, want a heartbeat", line)
+		}
+		if line, err := r.ReadString('\n'); err != nil || line != "\n" {
+			t.Fatalf("got line %q, %v after heartbeat, want blank line", line, err)
+		}
+	}
+	resp.Body.Close()
+
+	// Clients ignore heartbeats on their standalone stream.
+	progress := make(chan string, 1)
+	cs2, err := NewClient(testImpl, &ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+			progress <- req.Params.Message
+		},
+	}).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs2.Close()
+	time.Sleep(50 * time.Millisecond)
+	for ss := range server.Sessions() {
+		if ss.ID() == cs2.ID() {
+			if err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: "t", Message: "hello"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	select {
+	case got := <-progress:
+		if got != "hello" {
+			t.Errorf("got progress %q, want %q", got, "hello")
+		}
+	case <-ctx.Done():
+		t.Fatal("progress notification was not delivered")
+	}
+}
+
+func TestStreamableSSERetry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		SSERetry: 20 * time.Millisecond,
+	})
+	var gets atomic.Int32
+	httpServer := httptest.NewServer(mustNotPanic(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && gets.Add(1) == 1 {
+			// Interrupt the first standalone stream.
+			ctx, cancel := context.WithTimeout(req.Context(), 20*time.Millisecond)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		handler.ServeHTTP(w, req)
+	})))
+	defer httpServer.Close()
+
+	// The server's retry delay replaces the client's much longer one.
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+		Endpoint:      httpServer.URL,
+		StandaloneSSE: &StreamReconnectOptions{InitialDelay: time.Hour},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	for gets.Load() < 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("standalone stream was not reconnected")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	// Event streams begin with the retry field.
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	post, err := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Header.Set("Accept", "application/json, text/event-stream")
+	post.Header.Set("Content-Type", "application/json")
+	post.Header.Set(sessionIDHeader, cs.ID())
+	post.Header.Set(protocolVersionHeader, cs.ProtocolVersion())
+	resp, err := http.DefaultClient.Do(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "retry: 20\n\n") {
+		t.Errorf("POST response does not begin with the retry field:\n%s", data)
+	}
+}
+
+func TestStreamableLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "block"}, func(context.Context, *CallToolRequest, map[string]any) (*CallToolResult, any, error) {
+		close(started)
+		<-release
+		return &CallToolResult{}, nil, nil
+	})
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+		MaxSessions:             1,
+		MaxGETStreamsPerSession: 1,
+		MaxPOSTsPerSession:      1,
+	})
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	connect := func() (*ClientSession, error) {
+		return NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+			Endpoint:             httpServer.URL,
+			DisableStandaloneSSE: true,
+		}, nil)
+	}
+	cs, err := connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := connect(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("second session: got %v, want 503 error", err)
+	}
+
+	send := func(method, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, httpServer.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set(sessionIDHeader, cs.ID())
+		req.Header.Set(protocolVersionHeader, cs.ProtocolVersion())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	checkLimited := func(name string, resp *http.Response) {
+		t.Helper()
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+			t.Errorf("%s: got status %d, Retry-After %q; want %d with Retry-After", name, resp.StatusCode, resp.Header.Get("Retry-After"), http.StatusTooManyRequests)
+		}
+	}
+
+	// The first GET holds the standalone stream.
+	get := send(http.MethodGet, "")
+	if get.StatusCode != http.StatusOK {
+		t.Fatalf("GET: got status %d, want %d", get.StatusCode, http.StatusOK)
+	}
+	checkLimited("second GET", send(http.MethodGet, ""))
+	get.Body.Close()
+
+	// The first POST holds a blocking tool call.
+	called := make(chan error, 1)
+	go func() {
+		_, err := cs.CallTool(ctx, &CallToolParams{Name: "block"})
+		called <- err
+	}()
+	<-started
+	checkLimited("second POST", send(http.MethodPost, `{"jsonrpc":"2.0","id":100,"method":"ping"}`))
+	close(release)
+	if err := <-called; err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Errorf("ping after the call: %v", err)
+	}
+
+	// Closing the session makes room for another.
+	cs.Close()
+	cs2, err := connect()
+	if err != nil {
+		t.Fatalf("connecting after close: %v", err)
+	}
+	cs2.Close()
+}
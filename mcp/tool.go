@@ -5,6 +5,7 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -37,13 +38,15 @@ type ToolHandler func(context.Context, *CallToolRequest) (*CallToolResult, error
 //     be overridden in [AddTool].
 //   - The input value is automatically unmarshaled from req.Params.Arguments.
 //   - The input value is automatically validated against its input schema.
-//     Invalid input is rejected before getting to the handler.
+//     Invalid input is rejected before getting to the handler, with an
+//     "invalid params" error whose data is a [ValidationError] locating
+//     each problem.
 //   - If the Out type is not the empty interface [any], it provides the
 //     default output schema for the tool (which again may be overridden in
 //     [AddTool]).
 //   - The Out value is used to populate result.StructuredOutput.
-//   - If [CallToolResult.Content] is unset, it is populated with the JSON
-//     content of the output.
+//   - If [CallToolResult.Content] is unset, it is populated from the output,
+//     by default with its JSON text. See [StructuredTextFunc].
 //   - An error result is treated as a tool error, rather than a protocol
 //     error, and is therefore packed into CallToolResult.Content, with
 //     [IsError] set.
@@ -54,6 +57,38 @@ type ToolHandler func(context.Context, *CallToolRequest) (*CallToolResult, error
 // or error. The effective result will be populated as described above.
 type ToolHandlerFor[In, Out any] func(_ context.Context, request *CallToolRequest, input In) (result *CallToolResult, output Out, _ error)
 
+// A StructuredTextFunc derives the content of a tool result from its
+// structured content, for a [ToolHandlerFor] that returns an output value but
+// leaves [CallToolResult.Content] unset. The structured content is passed as
+// JSON.
+//
+// The spec suggests that tools returning structured content also return it
+// serialized in a text content block, which [StructuredTextJSON] does by
+// default. Since some hosts display both, it can be changed with
+// [ServerOptions.StructuredText] or [AddToolOptions.StructuredText].
+type StructuredTextFunc func(structured json.RawMessage) ([]Content, error)
+
+// StructuredTextJSON returns the structured content as JSON text.
+// This is the default [StructuredTextFunc].
+func StructuredTextJSON(structured json.RawMessage) ([]Content, error) {
+	return []Content{&TextContent{Text: string(structured)}}, nil
+}
+
+// StructuredTextIndent returns the structured content as indented JSON text.
+func StructuredTextIndent(structured json.RawMessage) ([]Content, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, structured, "", "  "); err != nil {
+		return nil, err
+	}
+	return []Content{&TextContent{Text: buf.String()}}, nil
+}
+
+// StructuredTextNone returns no content, so that the result holds only the
+// structured content.
+func StructuredTextNone(json.RawMessage) ([]Content, error) {
+	return []Content{}, nil
+}
+
 // A serverTool is a tool definition that is bound to a tool handler.
 type serverTool struct {
 	tool    *Tool
@@ -89,7 +124,7 @@ func applySchema(data json.RawMessage, resolved *jsonschema.Resolved) (json.RawM
 		if err := resolved.ApplyDefaults(&v); err != nil {
 			return nil, fmt.Errorf("applying schema defaults:\n%w", err)
 		}
-		if err := resolved.Validate(&v); err != nil {
+		if err := validate(resolved, &v); err != nil {
 			return nil, err
 		}
 		// We must re-marshal with the default values applied.
@@ -101,3 +136,78 @@ func applySchema(data json.RawMessage, resolved *jsonschema.Resolved) (json.RawM
 	}
 	return data, nil
 }
+
+// wrappedResultProperty is the property that holds tool output values that
+// are not JSON objects. See [wrapOutputSchema].
+const wrappedResultProperty = "result"
+
+// wrappedSchemaKeyword is the schema keyword that marks the output schemas
+// of wrapped tool output. See [wrapOutputSchema].
+const wrappedSchemaKeyword = "x-go-sdk-wrapped"
+
+// isObjectSchema reports whether s describes only JSON objects.
+func isObjectSchema(s *jsonschema.Schema) bool {
+	return s.Type == "object"
+}
+
+// wrapOutputSchema returns an object schema with a single required "result"
+// property described by s, marked as a wrapper so that clients can unwrap
+// the output (see [StructuredOutput]).
+//
+// The spec requires structured tool output to be an object, so [AddTool]
+// uses this schema for output types, such as strings and slices, that would
+// otherwise not be objects.
+func wrapOutputSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{wrappedResultProperty: s},
+		Required:   []string{wrappedResultProperty},
+		Extra:      map[string]any{wrappedSchemaKeyword: true},
+	}
+}
+
+// isWrappedSchema reports whether the output schema of a tool is that of
+// wrapped output, as produced by [wrapOutputSchema].
+func isWrappedSchema(outputSchema any) bool {
+	if outputSchema == nil {
+		return false
+	}
+	var s jsonschema.Schema
+	if err := remarshal(outputSchema, &s); err != nil {
+		return false
+	}
+	return s.Extra[wrappedSchemaKeyword] == true
+}
+
+// StructuredOutput unmarshals the structured content of a tool result into a
+// value of type Out.
+//
+// It is the client-side counterpart of [AddTool]: if the output schema of the
+// tool that produced res, as listed by the server, marks the output as
+// wrapped under a "result" property because it is not an object, the
+// structured content is unwrapped. If tool is nil, the content is not
+// unwrapped.
+func StructuredOutput[Out any](res *CallToolResult, tool *Tool) (Out, error) {
+	var out Out
+	if res.StructuredContent == nil {
+		return out, fmt.Errorf("tool result has no structured content")
+	}
+	var err error
+	if tool == nil || !isWrappedSchema(tool.OutputSchema) {
+		err = remarshal(res.StructuredContent, &out)
+	} else {
+		var wrapper map[string]json.RawMessage
+		if err := remarshal(res.StructuredContent, &wrapper); err != nil {
+			return out, err
+		}
+		v, ok := wrapper[wrappedResultProperty]
+		if !ok {
+			return out, fmt.Errorf("structured content has no %q property", wrappedResultProperty)
+		}
+		err = json.Unmarshal(v, &out)
+	}
+	if err != nil {
+		return out, fmt.Errorf("unmarshaling structured content: %w", err)
+	}
+	return out, nil
+}
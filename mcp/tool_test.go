@@ -66,7 +66,7 @@ func TestToolErrorHandling(t *testing.T) {
 	// Create a tool that returns a structured error
 	structuredErrorHandler := func(ctx context.Context, req *CallToolRequest, args map[string]any) (*CallToolResult, any, error) {
 		return nil, nil, &jsonrpc2.WireError{
-			Code:    codeInvalidParams,
+			Code:    CodeInvalidParams,
 			Message: "internal server error",
 		}
 	}
@@ -111,8 +111,8 @@ func TestToolErrorHandling(t *testing.T) {
 			t.Fatalf("expected WireError, got %[1]T: %[1]v", err)
 		}
 
-		if wireErr.Code != codeInvalidParams {
-			t.Errorf("expected error code %d, got %d", codeInvalidParams, wireErr.Code)
+		if wireErr.Code != CodeInvalidParams {
+			t.Errorf("expected error code %d, got %d", CodeInvalidParams, wireErr.Code)
 		}
 	})
 
@@ -145,3 +145,158 @@ func TestToolErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestWrappedOutput(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	cs, _, cleanup := basicConnection(t, func(s *Server) {
+		AddTool(s, &Tool{Name: "string"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, string, error) {
+			return nil, "hello", nil
+		})
+		AddTool(s, &Tool{Name: "list"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, []string, error) {
+			return nil, []string{"a", "b"}, nil
+		})
+		AddTool(s, &Tool{Name: "point"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, *point, error) {
+			return nil, &point{1, 2}, nil
+		})
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]*Tool{}
+	for _, tool := range tools.Tools {
+		byName[tool.Name] = tool
+		var s jsonschema.Schema
+		if err := remarshal(tool.OutputSchema, &s); err != nil {
+			t.Fatal(err)
+		}
+		if s.Type != "object" {
+			t.Errorf("%s: output schema has type %q, want object", tool.Name, s.Type)
+		}
+		if _, ok := s.Properties[wrappedResultProperty]; ok != (tool.Name != "point") {
+			t.Errorf("%s: wrapped = %t", tool.Name, ok)
+		}
+	}
+
+	call := func(name string) *CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	res := call("string")
+	if got := res.Content[0].(*TextContent).Text; got != `{"result":"hello"}` {
+		t.Errorf("string: got text %s", got)
+	}
+	if got, err := StructuredOutput[string](res, byName["string"]); err != nil || got != "hello" {
+		t.Errorf("StructuredOutput[string] = %q, %v, want %q", got, err, "hello")
+	}
+	if got, err := StructuredOutput[[]string](call("list"), byName["list"]); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("StructuredOutput[[]string] = %v, %v", got, err)
+	}
+	if got, err := StructuredOutput[*point](call("point"), byName["point"]); err != nil || *got != (point{1, 2}) {
+		t.Errorf("StructuredOutput[*point] = %v, %v", got, err)
+	}
+	if _, err := StructuredOutput[string](&CallToolResult{}, byName["string"]); err == nil {
+		t.Error("StructuredOutput with no structured content: got nil error")
+	}
+}
+
+// label is a struct that is encoded as a JSON string.
+type label struct{ s string }
+
+func (l label) MarshalJSON() ([]byte, error) { return json.Marshal(l.s) }
+func (l *label) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &l.s)
+}
+
+func TestStructuredOutputSchemaOptions(t *testing.T) {
+	// The server's schema options make label a string, so it is wrapped.
+	schemaOpts := &jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{reflect.TypeFor[label](): {Type: "string"}},
+	}
+	server := NewServer(testImpl, &ServerOptions{SchemaOptions: schemaOpts})
+	AddTool(server, &Tool{Name: "label"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, label, error) {
+		return nil, label{"x"}, nil
+	})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+
+	res, err := cs.CallTool(context.Background(), &CallToolParams{Name: "label"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The client needn't know the server's schema options to unwrap the
+	// output.
+	if got, err := StructuredOutput[label](res, tools.Tools[0]); err != nil || got.s != "x" {
+		t.Errorf("StructuredOutput[label] = %v, %v, want x", got, err)
+	}
+}
+
+func TestStructuredText(t *testing.T) {
+	type out struct {
+		A int `json:"a"`
+	}
+	handler := func(context.Context, *CallToolRequest, any) (*CallToolResult, out, error) {
+		return nil, out{A: 1}, nil
+	}
+	upper := func(structured json.RawMessage) ([]Content, error) {
+		return []Content{&TextContent{Text: strings.ToUpper(string(structured))}}, nil
+	}
+	failing := func(json.RawMessage) ([]Content, error) {
+		return nil, errors.New("boom")
+	}
+
+	for _, test := range []struct {
+		name       string
+		serverText StructuredTextFunc
+		toolText   StructuredTextFunc
+		want       string // JSON of the content; empty means an error
+	}{
+		{"default", nil, nil, `[{"type":"text","text":"{\"a\":1}"}]`},
+		{"indent", StructuredTextIndent, nil, `[{"type":"text","text":"{\n  \"a\": 1\n}"}]`},
+		{"none", StructuredTextNone, nil, `null`}, // the client decodes [] as nil
+		{"tool overrides server", StructuredTextNone, upper, `[{"type":"text","text":"{\"A\":1}"}]`},
+		{"error", failing, nil, ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(testImpl, &ServerOptions{StructuredText: test.serverText})
+			cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+				AddToolWithOptions(s, &Tool{Name: "t"}, handler, &AddToolOptions{StructuredText: test.toolText})
+			})
+			defer cleanup()
+
+			res, err := cs.CallTool(context.Background(), &CallToolParams{Name: "t"})
+			if test.want == "" {
+				if err == nil {
+					t.Fatal("CallTool succeeded unexpectedly")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(res.Content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("content = %s, want %s", got, test.want)
+			}
+			if res.StructuredContent == nil {
+				t.Error("StructuredContent is unset")
+			}
+		})
+	}
+}
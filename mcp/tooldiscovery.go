@@ -0,0 +1,96 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// A ToolDiscoveryFunc chooses the tools returned by tools/list for a
+// request. It is passed all of the server's tools, in the order tools/list
+// would otherwise return them, and returns the tools to list, in the order
+// to list them. It may drop tools and reorder them, but must not add tools
+// that the server does not have.
+//
+// Tools that are not listed can still be called. To hide tools from a
+// session entirely, use [ServerOptions.Authorize].
+//
+// A ToolDiscoveryFunc is typically used to list only the tools relevant to a
+// query, such as one sent with [SetToolQuery], or to the state of the
+// session. For results to paginate correctly, it must return the same tools
+// in the same order for each page of a listing.
+type ToolDiscoveryFunc func(ctx context.Context, req *ListToolsRequest, tools []*Tool) ([]*Tool, error)
+
+// ToolQueryKey is the key in the _meta field of tools/list params that holds
+// a query describing the tools the client is interested in. The SDK does not
+// interpret the query; a server's [ServerOptions.ToolDiscovery] function may
+// use it to select tools.
+const ToolQueryKey = "go-sdk/toolQuery"
+
+// SetToolQuery sets a tool query in the params' Meta field.
+// See [ToolQueryKey].
+func SetToolQuery(p *ListToolsParams, query string) {
+	m := p.GetMeta()
+	if m == nil {
+		m = map[string]any{}
+		p.SetMeta(m)
+	}
+	m[ToolQueryKey] = query
+}
+
+// ToolQuery returns the tool query from the params' Meta field, if there is
+// one. See [ToolQueryKey].
+func ToolQuery(p *ListToolsParams) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	q, ok := p.GetMeta()[ToolQueryKey].(string)
+	return q, ok
+}
+
+// discoverTools returns a page of the tools chosen by the server's
+// ToolDiscovery function.
+func (s *Server) discoverTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResult, error) {
+	s.mu.Lock()
+	var all []*Tool
+	for st := range s.tools.all() {
+		all = append(all, st.tool)
+	}
+	pageSize := s.opts.PageSize
+	s.mu.Unlock()
+
+	tools, err := s.opts.ToolDiscovery(ctx, req, all)
+	if err != nil {
+		return nil, err
+	}
+	// Tools are paginated by position in the chosen list: the cursor holds
+	// the name of the last tool of the previous page.
+	start := 0
+	if req.Params.Cursor != "" {
+		token, err := decodeCursor(req.Params.Cursor)
+		if err != nil {
+			return nil, jsonrpc2.ErrInvalidParams
+		}
+		i := slices.IndexFunc(tools, func(t *Tool) bool { return t.Name == token.LastUID })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: tool %q of cursor is no longer listed", jsonrpc2.ErrInvalidParams, token.LastUID)
+		}
+		start = i + 1
+	}
+	res := &ListToolsResult{Tools: []*Tool{}} // avoid JSON null
+	end := min(start+pageSize, len(tools))
+	res.Tools = append(res.Tools, tools[start:end]...)
+	if end < len(tools) {
+		res.NextCursor, err = encodeCursor(tools[end-1].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestToolDiscovery(t *testing.T) {
+	ctx := context.Background()
+	// Rank tools by whether their name contains the query, and list at most
+	// three.
+	discover := func(_ context.Context, req *ListToolsRequest, tools []*Tool) ([]*Tool, error) {
+		q, ok := ToolQuery(req.Params)
+		if !ok {
+			return tools, nil
+		}
+		if q == "fail" {
+			return nil, errors.New("discovery failed")
+		}
+		var matches []*Tool
+		for _, t := range tools {
+			if strings.Contains(t.Name, q) {
+				matches = append(matches, t)
+			}
+		}
+		slices.Reverse(matches)
+		return matches[:min(3, len(matches))], nil
+	}
+	server := NewServer(testImpl, &ServerOptions{PageSize: 2, ToolDiscovery: discover})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, func(s *Server) {
+		for _, name := range []string{"git_commit", "git_log", "git_push", "git_status", "search"} {
+			AddTool(s, &Tool{Name: name}, sayHi)
+		}
+	})
+	defer cleanup()
+
+	list := func(query string) []string {
+		t.Helper()
+		params := &ListToolsParams{}
+		if query != "" {
+			SetToolQuery(params, query)
+		}
+		var names []string
+		for tool, err := range cs.Tools(ctx, params) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	if got, want := list(""), []string{"git_commit", "git_log", "git_push", "git_status", "search"}; !slices.Equal(got, want) {
+		t.Errorf("list without query: got %v, want %v", got, want)
+	}
+	if got, want := list("git"), []string{"git_status", "git_push", "git_log"}; !slices.Equal(got, want) {
+		t.Errorf("list git: got %v, want %v", got, want)
+	}
+	if got := list("nothing"); len(got) != 0 {
+		t.Errorf("list nothing: got %v, want none", got)
+	}
+	params := &ListToolsParams{}
+	SetToolQuery(params, "fail")
+	if _, err := cs.ListTools(ctx, params); err == nil || !strings.Contains(err.Error(), "discovery failed") {
+		t.Errorf("ListTools with failing discovery: got %v", err)
+	}
+
+	// Unlisted tools can still be called.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "git_commit", Arguments: map[string]any{"Name": "x"}}); err != nil {
+		t.Errorf("calling an unlisted tool: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import "context"
+
+// ToolHooks are called around the handler of a tool. They are the place to
+// integrate a tool with the environment it runs in, such as a sandbox or a
+// remote executor, so that the handler itself need not be aware of it.
+//
+// Install hooks with [AddToolOptions.Hooks], or wrap a [ToolHandler] with
+// [ToolHooks.Wrap]. The hooks see the request before its arguments are
+// unmarshaled and validated, and the result after a [ToolHandlerFor]'s
+// output and error have been packed into it.
+type ToolHooks struct {
+	// Before, if non-nil, is called before the handler. It returns the
+	// context for the handler and for After, which may carry values such as
+	// a handle to a sandbox; if nil, the original context is used.
+	//
+	// To veto the call, Before returns a non-nil result, typically with
+	// IsError set, which is used instead of calling the handler. If Before
+	// returns an error, the call fails with that error, which is treated as
+	// a protocol error.
+	Before func(context.Context, *CallToolRequest) (context.Context, *CallToolResult, error)
+
+	// After, if non-nil, is called with the result and error of the handler,
+	// or of a vetoing Before, and returns the result and error to use. It can,
+	// for example, release resources acquired by Before, or post-process the
+	// result. After is not called if Before fails.
+	After func(context.Context, *CallToolRequest, *CallToolResult, error) (*CallToolResult, error)
+}
+
+// Wrap returns a handler that calls h with the hooks.
+func (hooks *ToolHooks) Wrap(h ToolHandler) ToolHandler {
+	if hooks == nil || (hooks.Before == nil && hooks.After == nil) {
+		return h
+	}
+	return func(ctx context.Context, req *CallToolRequest) (*CallToolResult, error) {
+		var (
+			res *CallToolResult
+			err error
+		)
+		if hooks.Before != nil {
+			var hctx context.Context
+			hctx, res, err = hooks.Before(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			if hctx != nil {
+				ctx = hctx
+			}
+		}
+		if res == nil {
+			res, err = h(ctx, req)
+		}
+		if hooks.After != nil {
+			res, err = hooks.After(ctx, req, res, err)
+		}
+		return res, err
+	}
+}
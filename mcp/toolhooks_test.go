@@ -0,0 +1,77 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type sandboxKey struct{}
+
+func TestToolHooks(t *testing.T) {
+	var released []string
+	hooks := &ToolHooks{
+		Before: func(ctx context.Context, req *CallToolRequest) (context.Context, *CallToolResult, error) {
+			var args struct{ Path string }
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, nil, err
+			}
+			if args.Path == "/etc/passwd" {
+				return nil, &CallToolResult{
+					IsError: true,
+					Content: []Content{&TextContent{Text: "denied"}},
+				}, nil
+			}
+			return context.WithValue(ctx, sandboxKey{}, "jail-"+args.Path), nil, nil
+		},
+		After: func(ctx context.Context, req *CallToolRequest, res *CallToolResult, err error) (*CallToolResult, error) {
+			if sb, ok := ctx.Value(sandboxKey{}).(string); ok {
+				released = append(released, sb)
+			}
+			if err == nil && !res.IsError {
+				res.Content = append(res.Content, &TextContent{Text: "post-processed"})
+			}
+			return res, err
+		},
+	}
+	type in struct {
+		Path string `json:"path"`
+	}
+	s := NewServer(testImpl, nil)
+	handled := 0
+	AddToolWithOptions(s, &Tool{Name: "read"}, func(ctx context.Context, req *CallToolRequest, args in) (*CallToolResult, any, error) {
+		handled++
+		sb, _ := ctx.Value(sandboxKey{}).(string)
+		return &CallToolResult{Content: []Content{&TextContent{Text: sb}}}, nil, nil
+	}, &AddToolOptions{Hooks: hooks})
+
+	cs, _, cleanup := basicClientServerConnection(t, nil, s, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "read", Arguments: map[string]any{"path": "/tmp/x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError || len(res.Content) != 2 || res.Content[0].(*TextContent).Text != "jail-/tmp/x" || res.Content[1].(*TextContent).Text != "post-processed" {
+		t.Errorf("allowed call: got %+v", res.Content)
+	}
+
+	res, err = cs.CallTool(ctx, &CallToolParams{Name: "read", Arguments: map[string]any{"path": "/etc/passwd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError || len(res.Content) != 1 || res.Content[0].(*TextContent).Text != "denied" {
+		t.Errorf("vetoed call: got %+v", res.Content)
+	}
+	if handled != 1 {
+		t.Errorf("handler called %d times, want 1", handled)
+	}
+	if len(released) != 1 || released[0] != "jail-/tmp/x" {
+		t.Errorf("released = %v", released)
+	}
+}
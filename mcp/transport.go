@@ -5,6 +5,8 @@
 package mcp
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -13,7 +15,11 @@ import (
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
 	"github.com/orkhanm/go-sdk/internal/xcontext"
@@ -24,6 +30,10 @@ import (
 // is closed or in the process of closing.
 var ErrConnectionClosed = errors.New("connection closed")
 
+// ErrMessageTooLarge is the error that fails a connection when the peer sends
+// a message larger than the transport's MaxMessageSize.
+var ErrMessageTooLarge = errors.New("message too large")
+
 // A Transport is used to create a bidirectional connection between MCP client
 // and server.
 //
@@ -84,13 +94,45 @@ type serverConnection interface {
 	sessionUpdated(ServerSessionState)
 }
 
+// An idleTimeoutConnection is a server Connection whose session can time out
+// when idle, such as a streamable HTTP connection.
+type idleTimeoutConnection interface {
+	Connection
+	setIdleTimeout(time.Duration) error
+}
+
+// A Framing selects how messages are delimited on a byte stream.
+type Framing int
+
+const (
+	// FramingNewline delimits messages with newlines, as specified for the
+	// MCP stdio transport. This is the default.
+	FramingNewline Framing = iota + 1
+	// FramingContentLength precedes each message with a header section
+	// holding its Content-Length, as in the Language Server Protocol.
+	// Without a MaxMessageSize, incoming messages are limited to 64 MiB.
+	FramingContentLength
+	// FramingAuto detects the framing of the first incoming message, and
+	// uses it for both reading and writing. Messages written before the
+	// first message is received are newline-delimited.
+	FramingAuto
+)
+
 // A StdioTransport is a [Transport] that communicates over stdin/stdout using
-// newline-delimited JSON.
-type StdioTransport struct{}
+// newline-delimited JSON, or the framing selected by its Framing field.
+type StdioTransport struct {
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// read from stdin. A larger message fails the connection with an error
+	// wrapping [ErrMessageTooLarge], before it is read in full.
+	MaxMessageSize int
+	// Framing selects how messages are delimited. If zero, messages are
+	// newline-delimited.
+	Framing Framing
+}
 
 // Connect implements the [Transport] interface.
-func (*StdioTransport) Connect(context.Context) (Connection, error) {
-	return newIOConn(rwc{os.Stdin, nopCloserWriter{os.Stdout}}), nil
+func (t *StdioTransport) Connect(context.Context) (Connection, error) {
+	return newIOConn(rwc{os.Stdin, nopCloserWriter{os.Stdout}}, t.MaxMessageSize, t.Framing), nil
 }
 
 // nopCloserWriter is an io.WriteCloser with a trivial Close method.
@@ -102,28 +144,72 @@ func (nopCloserWriter) Close() error { return nil }
 
 // An IOTransport is a [Transport] that communicates over separate
 // io.ReadCloser and io.WriteCloser using newline-delimited JSON.
+//
+// To communicate over a single io.ReadWriteCloser, such as an SSH channel,
+// a serial port or a network connection, use [NewIOTransport].
 type IOTransport struct {
 	Reader io.ReadCloser
 	Writer io.WriteCloser
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// read from Reader. A larger message fails the connection with an error
+	// wrapping [ErrMessageTooLarge], before it is read in full.
+	MaxMessageSize int
+	// Framing selects how messages are delimited. If zero, messages are
+	// newline-delimited.
+	Framing Framing
+	// If non-nil, Codec encodes messages in place of JSON, for peers that
+	// agree on another format. Since the encoded messages need not be text,
+	// they are framed with a Content-Length header: Framing must be zero or
+	// FramingContentLength. JSON-RPC batches are not supported.
+	Codec jsonrpc.Codec
+
+	rwc io.ReadWriteCloser // if set, used instead of Reader and Writer
+}
+
+// NewIOTransport returns an [IOTransport] that communicates over rwc using
+// newline-delimited JSON. Closing the connection closes rwc once.
+//
+// The Reader and Writer fields of the result are unset, and ignored if set.
+func NewIOTransport(rwc io.ReadWriteCloser) *IOTransport {
+	return &IOTransport{rwc: rwc}
 }
 
 // Connect implements the [Transport] interface.
 func (t *IOTransport) Connect(context.Context) (Connection, error) {
-	return newIOConn(rwc{t.Reader, t.Writer}), nil
+	framing := t.Framing
+	if t.Codec != nil {
+		if framing != 0 && framing != FramingContentLength {
+			return nil, errors.New("IOTransport with a Codec requires FramingContentLength")
+		}
+		framing = FramingContentLength
+	}
+	var c *ioConn
+	if t.rwc != nil {
+		c = newIOConn(t.rwc, t.MaxMessageSize, framing)
+	} else {
+		c = newIOConn(rwc{t.Reader, t.Writer}, t.MaxMessageSize, framing)
+	}
+	c.codec = t.Codec
+	return c, nil
 }
 
 // An InMemoryTransport is a [Transport] that communicates over an in-memory
 // network connection, using newline-delimited JSON.
 //
-// InMemoryTransports should be constructed using [NewInMemoryTransports],
-// which returns two transports connected to each other.
+// InMemoryTransports should be constructed using [NewInMemoryTransports]
+// or [NewInMemoryTransportsWithOptions], which return two transports
+// connected to each other.
 type InMemoryTransport struct {
-	rwc io.ReadWriteCloser
+	rwc  io.ReadWriteCloser
+	conn *sequencedConn // if set, messages are delivered by an InMemorySequencer
 }
 
 // Connect implements the [Transport] interface.
 func (t *InMemoryTransport) Connect(context.Context) (Connection, error) {
-	return newIOConn(t.rwc), nil
+	if t.conn != nil {
+		return t.conn, nil
+	}
+	return newIOConn(t.rwc, 0, 0), nil
 }
 
 // NewInMemoryTransports returns two [InMemoryTransport] objects that connect
@@ -134,7 +220,7 @@ func (t *InMemoryTransport) Connect(context.Context) (Connection, error) {
 // clients, as the client initializes the MCP session during connection.
 func NewInMemoryTransports() (*InMemoryTransport, *InMemoryTransport) {
 	c1, c2 := net.Pipe()
-	return &InMemoryTransport{c1}, &InMemoryTransport{c2}
+	return &InMemoryTransport{rwc: c1}, &InMemoryTransport{rwc: c2}
 }
 
 type binder[T handler, State any] interface {
@@ -164,10 +250,12 @@ func connect[H handler, State any](ctx context.Context, t Transport, b binder[H,
 		preempter.conn = conn
 		return jsonrpc2.HandlerFunc(h.handle)
 	}
+	_, direct := mcpConn.(directConnection)
 	_ = jsonrpc2.NewConnection(ctx, jsonrpc2.ConnectionConfig{
 		Reader:    reader,
 		Writer:    writer,
 		Closer:    mcpConn,
+		Direct:    direct,
 		Bind:      bind,
 		Preempter: &preempter,
 		OnDone: func() {
@@ -188,19 +276,52 @@ type canceller struct {
 // Preempt implements [jsonrpc2.Preempter].
 func (c *canceller) Preempt(ctx context.Context, req *jsonrpc.Request) (result any, err error) {
 	if req.Method == notificationCancelled {
+		data := json.RawMessage(req.Params)
+		if v, ok := jsonrpc2.DirectValue(req); ok {
+			// Decode the params as if from the wire, so that the request ID
+			// has a wire type.
+			if data, err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+		}
 		var params CancelledParams
-		if err := json.Unmarshal(req.Params, &params); err != nil {
+		if err := json.Unmarshal(data, &params); err != nil {
 			return nil, err
 		}
 		id, err := jsonrpc2.MakeID(params.RequestID)
 		if err != nil {
 			return nil, err
 		}
-		go c.conn.Cancel(id)
+		go c.conn.CancelCause(id, &cancelledError{reason: params.Reason})
 	}
 	return nil, jsonrpc2.ErrNotHandled
 }
 
+// A cancelledError is the cause of the cancellation of a request's context
+// by a cancelled notification from the peer.
+type cancelledError struct {
+	reason string
+}
+
+func (e *cancelledError) Error() string {
+	if e.reason == "" {
+		return "request cancelled by peer"
+	}
+	return "request cancelled by peer: " + e.reason
+}
+
+// CancellationReason reports whether ctx, or a context it was derived from,
+// is the context of a request handler that was cancelled by the peer, and if
+// so returns the reason given in the cancelled notification. The reason may
+// be empty.
+func CancellationReason(ctx context.Context) (reason string, ok bool) {
+	var cerr *cancelledError
+	if errors.As(context.Cause(ctx), &cerr) {
+		return cerr.reason, true
+	}
+	return "", false
+}
+
 // call executes and awaits a jsonrpc2 call on the given connection,
 // translating errors into the mcp domain.
 func call(ctx context.Context, conn *jsonrpc2.Connection, method string, params Params, result Result) error {
@@ -212,9 +333,10 @@ func call(ctx context.Context, conn *jsonrpc2.Connection, method string, params
 	case errors.Is(err, jsonrpc2.ErrClientClosing), errors.Is(err, jsonrpc2.ErrServerClosing):
 		return fmt.Errorf("%w: calling %q: %v", ErrConnectionClosed, method, err)
 	case ctx.Err() != nil:
-		// Notify the peer of cancellation.
+		// Notify the peer of cancellation. If ctx was cancelled with a cause,
+		// as by [context.WithCancelCause], the cause is the reason.
 		err := conn.Notify(xcontext.Detach(ctx), notificationCancelled, &CancelledParams{
-			Reason:    ctx.Err().Error(),
+			Reason:    context.Cause(ctx).Error(),
 			RequestID: call.ID().Raw(),
 		})
 		return errors.Join(ctx.Err(), err)
@@ -229,6 +351,11 @@ func call(ctx context.Context, conn *jsonrpc2.Connection, method string, params
 type LoggingTransport struct {
 	Transport Transport
 	Writer    io.Writer
+
+	// If set, Capture receives a structured capture of the connection's
+	// traffic, one JSON [CaptureEntry] per line. Captures can be loaded with
+	// [ReadCapture] and replayed with a [ReplayTransport].
+	Capture io.Writer
 }
 
 // Connect connects the underlying transport, returning a [Connection] that writes
@@ -238,56 +365,129 @@ func (t *LoggingTransport) Connect(ctx context.Context) (Connection, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &loggingConn{delegate: delegate, w: t.Writer}, nil
+	return &loggingConn{delegate: delegate, w: t.Writer, capture: t.Capture}, nil
+}
+
+// A TransportMiddleware wraps a [Transport], much as a [net/http.RoundTripper]
+// may wrap another, to layer behavior such as compression, metrics or
+// encryption over any transport. See [ClientOptions.TransportMiddleware] and
+// [ServerOptions.TransportMiddleware].
+//
+// A [Connection] that wraps another should implement an Unwrap method
+// returning the wrapped connection:
+//
+//	Unwrap() Connection
+//
+// so that clients and servers can still use features of the underlying
+// connection, such as the session state kept by the streamable transports.
+type TransportMiddleware func(Transport) Transport
+
+// wrapTransport wraps t in the given middleware, the first outermost.
+func wrapTransport(t Transport, middleware []TransportMiddleware) Transport {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		t = middleware[i](t)
+	}
+	return t
+}
+
+// asConnection reports the first connection implementing T in the chain of c
+// and the connections it wraps, as reported by their Unwrap methods.
+func asConnection[T any](c Connection) (T, bool) {
+	for c != nil {
+		if t, ok := c.(T); ok {
+			return t, true
+		}
+		u, ok := c.(interface{ Unwrap() Connection })
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	var zero T
+	return zero, false
 }
 
 type loggingConn struct {
 	delegate Connection
 
-	mu sync.Mutex
-	w  io.Writer
+	mu      sync.Mutex
+	w       io.Writer // may be nil
+	capture io.Writer // may be nil
 }
 
 func (c *loggingConn) SessionID() string { return c.delegate.SessionID() }
 
+func (c *loggingConn) Unwrap() Connection { return c.delegate }
+
 // Read is a stream middleware that logs incoming messages.
 func (s *loggingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 	msg, err := s.delegate.Read(ctx)
-
-	if err != nil {
-		s.mu.Lock()
-		fmt.Fprintf(s.w, "read error: %v\n", err)
-		s.mu.Unlock()
-	} else {
-		data, err := jsonrpc2.EncodeMessage(msg)
-		s.mu.Lock()
-		if err != nil {
-			fmt.Fprintf(s.w, "LoggingTransport: failed to marshal: %v", err)
-		}
-		fmt.Fprintf(s.w, "read: %s\n", string(data))
-		s.mu.Unlock()
-	}
-
+	data := s.encode(msg, err)
+	s.record(CaptureRead, data, err)
+	s.logf(CaptureRead, data, err)
 	return msg, err
 }
 
 // Write is a stream middleware that logs outgoing messages.
 func (s *loggingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data := s.encode(msg, nil)
+	// Capture the write before it happens, so that it precedes any response
+	// in the capture.
+	s.record(CaptureWrite, data, nil)
 	err := s.delegate.Write(ctx, msg)
 	if err != nil {
+		s.record(CaptureWrite, nil, err)
+	}
+	s.logf(CaptureWrite, data, err)
+	return err
+}
+
+// encode encodes msg, if err is nil.
+func (s *loggingConn) encode(msg jsonrpc.Message, err error) []byte {
+	if err != nil {
+		return nil
+	}
+	data, err := jsonrpc2.EncodeMessage(msg)
+	if err != nil && s.w != nil {
 		s.mu.Lock()
-		fmt.Fprintf(s.w, "write error: %v\n", err)
+		fmt.Fprintf(s.w, "LoggingTransport: failed to marshal: %v", err)
 		s.mu.Unlock()
+	}
+	return data
+}
+
+// logf writes the outcome of a read or write to the log.
+func (s *loggingConn) logf(dir CaptureDirection, data []byte, err error) {
+	if s.w == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(s.w, "%s error: %v\n", dir, err)
 	} else {
-		data, err := jsonrpc2.EncodeMessage(msg)
-		s.mu.Lock()
-		if err != nil {
-			fmt.Fprintf(s.w, "LoggingTransport: failed to marshal: %v", err)
-		}
-		fmt.Fprintf(s.w, "write: %s\n", string(data))
-		s.mu.Unlock()
+		fmt.Fprintf(s.w, "%s: %s\n", dir, string(data))
 	}
-	return err
+}
+
+// record writes a capture entry for a message, or for a failed read or write.
+func (s *loggingConn) record(dir CaptureDirection, data []byte, err error) {
+	if s.capture == nil {
+		return
+	}
+	e := &CaptureEntry{
+		Time:      time.Now(),
+		Direction: dir,
+		SessionID: s.delegate.SessionID(),
+		Message:   data,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	line, _ := json.Marshal(e) // can't fail: Message is valid JSON or empty
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capture.Write(append(line, '\n'))
 }
 
 func (s *loggingConn) Close() error {
@@ -332,6 +532,8 @@ type ioConn struct {
 
 	writeMu sync.Mutex         // guards Write, which must be concurrency safe.
 	rwc     io.ReadWriteCloser // the underlying stream
+	framing atomic.Int32       // Framing of written messages; set by the read loop for FramingAuto
+	codec   jsonrpc.Codec      // if set, used instead of JSON
 
 	// incoming receives messages from the read loop started in [newIOConn].
 	incoming <-chan msgOrErr
@@ -354,16 +556,54 @@ type ioConn struct {
 	closeErr  error
 }
 
+// A messageLimiter limits the size of the messages that a json.Decoder reads
+// from r. After each message, the reader of the messages sets count to the
+// number of bytes that the decoder has read beyond it.
+type messageLimiter struct {
+	r     io.Reader
+	max   int64
+	count int64 // bytes of the current message read so far
+}
+
+func (l *messageLimiter) Read(p []byte) (int, error) {
+	if l.count > l.max {
+		return 0, fmt.Errorf("%w: exceeds %d bytes", ErrMessageTooLarge, l.max)
+	}
+	// Read at most one byte beyond the limit, to detect exceeding it.
+	if rest := l.max - l.count + 1; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := l.r.Read(p)
+	l.count += int64(n)
+	return n, err
+}
+
 type msgOrErr struct {
 	msg json.RawMessage
 	err error
 }
 
-func newIOConn(rwc io.ReadWriteCloser) *ioConn {
+// newIOConn returns an ioConn that reads and writes rwc, delimiting messages
+// as selected by framing. If maxMessageSize is positive, it limits the size
+// of incoming messages.
+func newIOConn(rwc io.ReadWriteCloser, maxMessageSize int, framing Framing) *ioConn {
 	var (
 		incoming = make(chan msgOrErr)
 		closed   = make(chan struct{})
 	)
+	if framing == 0 {
+		framing = FramingNewline
+	}
+	c := &ioConn{
+		rwc:      rwc,
+		incoming: incoming,
+		closed:   closed,
+	}
+	if framing == FramingContentLength {
+		c.framing.Store(int32(FramingContentLength))
+	} else {
+		c.framing.Store(int32(FramingNewline))
+	}
 	// Start a goroutine for reads, so that we can select on the incoming channel
 	// in [ioConn.Read] and unblock the read as soon as Close is called (see #224).
 	//
@@ -371,22 +611,22 @@ func newIOConn(rwc io.ReadWriteCloser) *ioConn {
 	// but that is unavoidable since AFAIK there is no (easy and portable) way to
 	// guarantee that reads of stdin are unblocked when closed.
 	go func() {
-		dec := json.NewDecoder(rwc)
+		br := bufio.NewReader(rwc)
+		var err error
+		if framing == FramingAuto {
+			framing, err = detectFraming(br)
+			c.framing.Store(int32(framing))
+		}
+		var next func() (json.RawMessage, error)
+		if framing == FramingContentLength {
+			next = func() (json.RawMessage, error) { return readContentLength(br, maxMessageSize) }
+		} else {
+			next = newlineReader(br, maxMessageSize)
+		}
 		for {
 			var raw json.RawMessage
-			err := dec.Decode(&raw)
-			// If decoding was successful, check for trailing data at the end of the stream.
 			if err == nil {
-				// Read the next byte to check if there is trailing data.
-				var tr [1]byte
-				if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
-					// If read byte is not a newline, it is an error.
-					if tr[0] != '\n' {
-						err = fmt.Errorf("invalid trailing data at the end of stream")
-					}
-				} else if readErr != nil && readErr != io.EOF {
-					err = readErr
-				}
+				raw, err = next()
 			}
 			select {
 			case incoming <- msgOrErr{msg: raw, err: err}:
@@ -398,16 +638,153 @@ func newIOConn(rwc io.ReadWriteCloser) *ioConn {
 			}
 		}
 	}()
-	return &ioConn{
-		rwc:      rwc,
-		incoming: incoming,
-		closed:   closed,
+	return c
+}
+
+// newlineReader returns a function that reads newline-delimited JSON
+// messages from r.
+func newlineReader(r io.Reader, maxMessageSize int) func() (json.RawMessage, error) {
+	var limiter *messageLimiter
+	if maxMessageSize > 0 {
+		limiter = &messageLimiter{r: r, max: int64(maxMessageSize)}
+		r = limiter
+	}
+	dec := json.NewDecoder(r)
+	return func() (json.RawMessage, error) {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == nil && limiter != nil {
+			// The decoder may have read ahead into the next message.
+			n, _ := io.Copy(io.Discard, dec.Buffered())
+			limiter.count = n
+		}
+		// If decoding was successful, check for trailing data at the end of the stream.
+		if err == nil {
+			// Read the next byte to check if there is trailing data.
+			var tr [1]byte
+			if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
+				// If read byte is not a newline, it is an error.
+				if tr[0] != '\n' {
+					err = fmt.Errorf("invalid trailing data at the end of stream")
+				}
+			} else if readErr != nil && readErr != io.EOF {
+				err = readErr
+			}
+		}
+		return raw, err
+	}
+}
+
+// Limits on messages framed with a Content-Length header.
+const (
+	// defaultContentLengthLimit is the maximum size of a message body when
+	// the transport has no MaxMessageSize.
+	defaultContentLengthLimit = 64 << 20
+	maxHeaderLineSize         = 4096 // maximum size of a header line
+	maxHeaderLines            = 32   // maximum number of lines in a header section
+)
+
+// readContentLength reads a message preceded by a header section holding its
+// Content-Length, as in the Language Server Protocol. Other headers are
+// ignored.
+//
+// Since the Content-Length is chosen by the peer, the body is read in chunks
+// rather than allocated up front, and its size is limited by maxMessageSize,
+// or [defaultContentLengthLimit] if maxMessageSize is not positive.
+func readContentLength(r *bufio.Reader, maxMessageSize int) (json.RawMessage, error) {
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultContentLengthLimit
+	}
+	length := int64(-1)
+	for n := 0; ; n++ {
+		if n == maxHeaderLines {
+			return nil, fmt.Errorf("header has more than %d lines", maxHeaderLines)
+		}
+		line, err := readHeaderLine(r)
+		if err != nil {
+			if err == io.EOF {
+				if n == 0 && line == "" {
+					return nil, io.EOF // clean EOF
+				}
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("reading header: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+	if length > int64(maxMessageSize) {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrMessageTooLarge, maxMessageSize)
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, length); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readHeaderLine reads a line of a header section, including its newline.
+// It returns an error if the line is longer than [maxHeaderLineSize].
+func readHeaderLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		frag, err := r.ReadSlice('\n')
+		if len(line)+len(frag) > maxHeaderLineSize {
+			return "", fmt.Errorf("header line longer than %d bytes", maxHeaderLineSize)
+		}
+		line = append(line, frag...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+// detectFraming reports the framing of the next message in r: a
+// newline-delimited message starts with a JSON object or array, and any other
+// message with a header.
+func detectFraming(r *bufio.Reader) (Framing, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		case '{', '[':
+			return FramingNewline, nil
+		default:
+			return FramingContentLength, nil
+		}
 	}
 }
 
 func (c *ioConn) SessionID() string { return "" }
 
 func (c *ioConn) sessionUpdated(state ServerSessionState) {
+	if state.ProtocolVersion != "" {
+		c.protocolVersion = state.ProtocolVersion
+		return
+	}
 	protocolVersion := ""
 	if state.InitializeParams != nil {
 		protocolVersion = state.InitializeParams.ProtocolVersion
@@ -512,6 +889,9 @@ func (t *ioConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 		return nil, io.EOF
 	}
 
+	if t.codec != nil {
+		return t.codec.Decode(raw)
+	}
 	msgs, batch, err := readBatch(raw)
 	if err != nil {
 		return nil, err
@@ -593,9 +973,7 @@ func (t *ioConn) Write(ctx context.Context, msg jsonrpc.Message) error {
 				if err != nil {
 					return err
 				}
-				data = append(data, '\n')
-				_, err = t.rwc.Write(data)
-				return err
+				return t.writeFrame(data)
 			}
 			return nil
 		}
@@ -607,18 +985,31 @@ func (t *ioConn) Write(ctx context.Context, msg jsonrpc.Message) error {
 			if err != nil {
 				return err
 			}
-			data = append(data, '\n')
-			_, err = t.rwc.Write(data)
-			return err
+			return t.writeFrame(data)
 		}
 		return nil
 	}
-	data, err := jsonrpc2.EncodeMessage(msg)
+	encode := jsonrpc2.EncodeMessage
+	if t.codec != nil {
+		encode = t.codec.Encode
+	}
+	data, err := encode(msg)
 	if err != nil {
 		return fmt.Errorf("marshaling message: %v", err)
 	}
-	data = append(data, '\n') // newline delimited
-	_, err = t.rwc.Write(data)
+	return t.writeFrame(data)
+}
+
+// writeFrame writes a message with the framing of the connection.
+// It must be called with writeMu held.
+func (t *ioConn) writeFrame(data []byte) error {
+	if Framing(t.framing.Load()) == FramingContentLength {
+		header := "Content-Length: " + strconv.Itoa(len(data)) + "\r\n\r\n"
+		data = append([]byte(header), data...)
+	} else {
+		data = append(data, '\n') // newline delimited
+	}
+	_, err := t.rwc.Write(data)
 	return err
 }
 
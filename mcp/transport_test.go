@@ -5,9 +5,17 @@
 package mcp
 
 import (
+	"bufio"
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
@@ -23,7 +31,7 @@ func TestBatchFraming(t *testing.T) {
 	ctx := context.Background()
 
 	r, w := io.Pipe()
-	tport := newIOConn(rwc{r, w})
+	tport := newIOConn(rwc{r, w}, 0, 0)
 	tport.outgoingBatch = make([]jsonrpc.Message, 0, 2)
 	t.Cleanup(func() { tport.Close() })
 
@@ -100,7 +108,7 @@ func TestIOConnRead(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tr := newIOConn(rwc{
 				rc: io.NopCloser(strings.NewReader(tt.input)),
-			})
+			}, 0, 0)
 			t.Cleanup(func() { tr.Close() })
 			if tt.protocolVersion != "" {
 				tr.sessionUpdated(ServerSessionState{
@@ -119,3 +127,312 @@ func TestIOConnRead(t *testing.T) {
 		})
 	}
 }
+
+func TestIOConnMaxMessageSize(t *testing.T) {
+	ctx := context.Background()
+	msg := func(id int, method string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q}`, id, method) + "\n"
+	}
+	small := msg(1, "ping")
+	limit := len(small) + 10
+
+	// Many messages within the limit are read, although together they
+	// exceed it, and the decoder reads ahead.
+	input := strings.Repeat(small, 100) + msg(2, strings.Repeat("x", limit))
+	conn := newIOConn(rwc{rc: io.NopCloser(strings.NewReader(input))}, limit, 0)
+	defer conn.Close()
+	for i := range 100 {
+		if _, err := conn.Read(ctx); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+	}
+	if _, err := conn.Read(ctx); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("reading large message: got %v, want %v", err, ErrMessageTooLarge)
+	}
+}
+
+func TestIOConnContentLength(t *testing.T) {
+	ctx := context.Background()
+	frame := func(body string, headers ...string) string {
+		return strings.Join(headers, "\r\n") + fmt.Sprintf("Content-Length: %d\r\n\r\n", len(body)) + body
+	}
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	input := frame(ping) + frame(ping, "Content-Type: application/vscode-jsonrpc; charset=utf-8\r\n") + frame(strings.Repeat(" ", 100)+ping)
+	var out strings.Builder
+	conn := newIOConn(rwc{rc: io.NopCloser(strings.NewReader(input)), wc: nopCloserWriter{&out}}, 100, FramingContentLength)
+	defer conn.Close()
+	for i := range 2 {
+		msg, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if req, ok := msg.(*jsonrpc.Request); !ok || req.Method != "ping" {
+			t.Errorf("message %d: got %v, want ping request", i, msg)
+		}
+	}
+	if _, err := conn.Read(ctx); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("reading large message: got %v, want %v", err, ErrMessageTooLarge)
+	}
+
+	if err := conn.Write(ctx, &jsonrpc.Request{Method: "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	want := frame(`{"jsonrpc":"2.0","method":"ping"}`)
+	if got := out.String(); got != want {
+		t.Errorf("Write: got %q, want %q", got, want)
+	}
+}
+
+func TestReadContentLengthLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"huge length", "Content-Length: 9000000000000000000\r\n\r\n{}"},
+		{"over default limit", fmt.Sprintf("Content-Length: %d\r\n\r\n{}", defaultContentLengthLimit+1)},
+		{"long header line", "X-Pad: " + strings.Repeat("x", 2*maxHeaderLineSize) + "\r\nContent-Length: 2\r\n\r\n{}"},
+		{"many header lines", strings.Repeat("X-Pad: x\r\n", maxHeaderLines) + "Content-Length: 2\r\n\r\n{}"},
+		{"short body", "Content-Length: 1000\r\n\r\n{}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg, err := readContentLength(bufio.NewReader(strings.NewReader(test.input)), 0)
+			if err == nil || err == io.EOF {
+				t.Errorf("got (%q, %v), want error", msg, err)
+			}
+		})
+	}
+}
+
+func TestFramingAuto(t *testing.T) {
+	ctx := context.Background()
+	c1, c2 := net.Pipe()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	st := NewIOTransport(c1)
+	st.Framing = FramingAuto
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewIOTransport(c2)
+	ct.Framing = FramingContentLength
+	cs, err := NewClient(testImpl, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool: got %q, want %q", got, "hi user")
+	}
+	cs.Close()
+	ss.Wait()
+}
+
+// base64Codec is a jsonrpc.Codec that encodes JSON messages in base64.
+type base64Codec struct{ encoded atomic.Int32 }
+
+func (c *base64Codec) Encode(msg jsonrpc.Message) ([]byte, error) {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	c.encoded.Add(1)
+	return base64.StdEncoding.AppendEncode(nil, data), nil
+}
+
+func (c *base64Codec) Decode(data []byte) (jsonrpc.Message, error) {
+	data, err := base64.StdEncoding.AppendDecode(nil, data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonrpc.DecodeMessage(data)
+}
+
+func TestIOTransportCodec(t *testing.T) {
+	ctx := context.Background()
+	c1, c2 := net.Pipe()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	var scodec, ccodec base64Codec
+	st := NewIOTransport(c1)
+	st.Codec = &scodec
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewIOTransport(c2)
+	ct.Codec = &ccodec
+	cs, err := NewClient(testImpl, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool: got %q, want %q", got, "hi user")
+	}
+	cs.Close()
+	ss.Wait()
+	if scodec.encoded.Load() == 0 || ccodec.encoded.Load() == 0 {
+		t.Errorf("codec not used: server encoded %d messages, client %d", scodec.encoded.Load(), ccodec.encoded.Load())
+	}
+
+	bad := NewIOTransport(c1)
+	bad.Codec = &scodec
+	bad.Framing = FramingNewline
+	if _, err := bad.Connect(ctx); err == nil {
+		t.Error("Connect with Codec and FramingNewline succeeded, want error")
+	}
+}
+
+// closeCounter counts the calls to Close of a net.Conn.
+type closeCounter struct {
+	net.Conn
+	closes atomic.Int32
+}
+
+func (c *closeCounter) Close() error {
+	c.closes.Add(1)
+	return c.Conn.Close()
+}
+
+func TestNewIOTransport(t *testing.T) {
+	ctx := context.Background()
+	c1, c2 := net.Pipe()
+	sconn, cconn := &closeCounter{Conn: c1}, &closeCounter{Conn: c2}
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	ss, err := server.Connect(ctx, NewIOTransport(sconn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewClient(testImpl, nil).Connect(ctx, NewIOTransport(cconn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool: got %q, want %q", got, "hi user")
+	}
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ss.Wait()
+	if got := cconn.closes.Load(); got != 1 {
+		t.Errorf("client connection closed %d times, want 1", got)
+	}
+}
+
+// countingTransport is a transport wrapper that counts the messages read and
+// written by its connections, and records the order of reads if order is
+// non-nil.
+type countingTransport struct {
+	Transport
+	name  string
+	order *[]string
+	reads atomic.Int64
+	write atomic.Int64
+}
+
+func (t *countingTransport) Connect(ctx context.Context) (Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Connection: conn, t: t}, nil
+}
+
+type countingConn struct {
+	Connection
+	t *countingTransport
+}
+
+func (c *countingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err == nil {
+		if c.t.order != nil {
+			*c.t.order = append(*c.t.order, c.t.name)
+		}
+		c.t.reads.Add(1)
+	}
+	return msg, err
+}
+
+func (c *countingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	c.t.write.Add(1)
+	return c.Connection.Write(ctx, msg)
+}
+
+func (c *countingConn) Unwrap() Connection { return c.Connection }
+
+func TestTransportMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	var order []string // client reads, by middleware
+	var clientTransports, serverTransports []*countingTransport
+	counting := func(ts *[]*countingTransport, name string, order *[]string) TransportMiddleware {
+		return func(t Transport) Transport {
+			ct := &countingTransport{Transport: t, name: name, order: order}
+			*ts = append(*ts, ct)
+			return ct
+		}
+	}
+	server := NewServer(testImpl, &ServerOptions{
+		TransportMiddleware: []TransportMiddleware{counting(&serverTransports, "server", nil)},
+	})
+	AddTool(server, &Tool{Name: "id"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: req.Session.ID()}}}, nil, nil
+	})
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	client := NewClient(testImpl, &ClientOptions{
+		TransportMiddleware: []TransportMiddleware{
+			counting(&clientTransports, "outer", &order),
+			counting(&clientTransports, "inner", &order),
+		},
+	})
+	cs, err := client.Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	// The session features of the wrapped streamable connections still work.
+	if cs.ID() == "" {
+		t.Error("client session has no ID")
+	}
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != cs.ID() {
+		t.Errorf("server session ID = %q, want %q", got, cs.ID())
+	}
+
+	if len(clientTransports) != 2 || len(serverTransports) != 1 {
+		t.Fatalf("got %d client and %d server transports, want 2 and 1", len(clientTransports), len(serverTransports))
+	}
+	// The first middleware is outermost, so it reads each message last.
+	if len(order) < 2 || order[0] != "inner" || order[1] != "outer" {
+		t.Errorf("client read order = %v, want inner before outer", order)
+	}
+	for _, ct := range append(clientTransports, serverTransports...) {
+		// initialize, initialized and tools/call.
+		if ct.write.Load() < 2 || ct.reads.Load() < 2 {
+			t.Errorf("%s transport: got %d writes and %d reads, want at least 2 each", ct.name, ct.write.Load(), ct.reads.Load())
+		}
+	}
+}
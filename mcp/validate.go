@@ -0,0 +1,271 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+// A ValidationError reports that a value, such as the arguments or output of
+// a tool, does not conform to its JSON schema.
+//
+// When tool arguments fail validation, the server sends the client an
+// "invalid params" error whose data is the JSON encoding of the
+// ValidationError. Use [AsValidationError] to recover it on the client.
+type ValidationError struct {
+	// Issues lists the problems, in the order they were found.
+	Issues []ValidationIssue `json:"errors"`
+}
+
+// A ValidationIssue is a single schema violation.
+type ValidationIssue struct {
+	// JSONPointer locates the offending value within the validated value,
+	// as an RFC 6901 JSON Pointer. The empty string refers to the value
+	// itself.
+	JSONPointer string `json:"jsonPointer"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// If the value has the wrong JSON type, ExpectedTypes lists the types
+	// allowed by the schema, and ActualType is the type of the value.
+	ExpectedTypes []string `json:"expectedTypes,omitempty"`
+	ActualType    string   `json:"actualType,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	var msgs []string
+	for _, is := range e.Issues {
+		if is.JSONPointer == "" {
+			msgs = append(msgs, is.Message)
+		} else {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", is.JSONPointer, is.Message))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AsValidationError reports whether err is, or was caused by, a
+// [ValidationError], and returns it if so.
+//
+// On the client, it recovers the ValidationError from the data of an error
+// returned by the server, such as from [ClientSession.CallTool].
+func AsValidationError(err error) (*ValidationError, bool) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr, true
+	}
+	var werr *jsonrpc2.WireError
+	if errors.As(err, &werr) && len(werr.Data) > 0 {
+		var v ValidationError
+		if json.Unmarshal(werr.Data, &v) == nil && len(v.Issues) > 0 {
+			return &v, true
+		}
+	}
+	return nil, false
+}
+
+// An OutputValidationError reports that the output of a tool did not conform
+// to the tool's output schema. [ClientSession.CallTool] returns it when the
+// server reports that validation of the tool's output failed, with the error
+// code [CodeOutputValidation].
+type OutputValidationError struct {
+	// Tool is the name of the tool.
+	Tool string
+	// Validation holds the problems found.
+	Validation *ValidationError
+
+	err error // the error reported by the server
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("tool %q: output does not match its schema: %v", e.Tool, e.Validation)
+}
+
+func (e *OutputValidationError) Unwrap() error { return e.err }
+
+// outputValidationError returns err as an [*OutputValidationError], if it
+// reports that validating the output of the named tool failed. Otherwise it
+// returns err.
+func outputValidationError(tool string, err error) error {
+	var werr *jsonrpc2.WireError
+	if !errors.As(err, &werr) || werr.Code != CodeOutputValidation {
+		return err
+	}
+	verr, ok := AsValidationError(werr)
+	if !ok {
+		return err
+	}
+	return &OutputValidationError{Tool: tool, Validation: verr, err: err}
+}
+
+// outputValidationPrefix begins the message of errors reporting invalid tool
+// output.
+const outputValidationPrefix = "validating tool output"
+
+// wireError returns verr as a JSON-RPC error with the given code.
+func (verr *ValidationError) wireError(code int64, prefix string) *jsonrpc2.WireError {
+	data, _ := json.Marshal(verr)
+	return &jsonrpc2.WireError{
+		Code:    code,
+		Message: fmt.Sprintf("%s: %v", prefix, verr),
+		Data:    data,
+	}
+}
+
+// validate validates v against rs, returning a [*ValidationError] on failure.
+//
+// The jsonschema package reports failures as a single message without the
+// location of the offending value, so on failure validate walks the schema
+// alongside v, validating each value against its subschema, to locate the
+// problems.
+func validate(rs *jsonschema.Resolved, v any) error {
+	err := rs.Validate(v)
+	if err == nil {
+		return nil
+	}
+	if p, ok := v.(*map[string]any); ok {
+		v = *p
+	}
+	issues, ok := locateIssues(rs.Schema(), v, "")
+	if !ok || len(issues) == 0 {
+		issues = []ValidationIssue{{Message: trimValidationMessage(err)}}
+	}
+	return &ValidationError{Issues: issues}
+}
+
+// locateIssues returns the schema violations of v against s, with pointers
+// relative to ptr. It reports false if s cannot be checked in parts, for
+// example because it uses references.
+func locateIssues(s *jsonschema.Schema, v any, ptr string) ([]ValidationIssue, bool) {
+	if s.Ref != "" || s.DynamicRef != "" || len(s.Defs) > 0 || len(s.Definitions) > 0 {
+		return nil, false
+	}
+	// Check v against a copy of s in which the schemas of its elements are
+	// trivial; the elements are checked separately below.
+	shallow := *s
+	var issues []ValidationIssue
+	switch v := v.(type) {
+	case map[string]any:
+		if len(s.Properties) > 0 {
+			shallow.Properties = make(map[string]*jsonschema.Schema, len(s.Properties))
+			for name := range s.Properties {
+				shallow.Properties[name] = &jsonschema.Schema{}
+			}
+		}
+		// Additional properties are checked separately unless they are
+		// forbidden, or patternProperties determine which ones are additional.
+		additional := s.AdditionalProperties
+		if additional != nil && (len(s.PatternProperties) > 0 || isFalseSchema(additional)) {
+			additional = nil
+		}
+		if additional != nil {
+			shallow.AdditionalProperties = &jsonschema.Schema{}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			ps, ok := s.Properties[name]
+			if !ok {
+				ps = additional
+			}
+			if ps == nil {
+				continue
+			}
+			is, ok := locateIssues(ps, v[name], ptr+"/"+escapePointerToken(name))
+			if !ok {
+				return nil, false
+			}
+			issues = append(issues, is...)
+		}
+	case []any:
+		if s.Items != nil {
+			shallow.Items = &jsonschema.Schema{}
+			for i, e := range v {
+				if i < len(s.PrefixItems) {
+					continue // leave prefixItems to the shallow check
+				}
+				is, ok := locateIssues(s.Items, e, ptr+"/"+strconv.Itoa(i))
+				if !ok {
+					return nil, false
+				}
+				issues = append(issues, is...)
+			}
+		}
+	}
+	rs, err := shallow.Resolve(nil)
+	if err != nil {
+		return nil, false
+	}
+	if err := rs.Validate(v); err != nil {
+		is := ValidationIssue{JSONPointer: ptr, Message: trimValidationMessage(err)}
+		if types := schemaTypes(s); len(types) > 0 {
+			if actual := jsonType(v); !slices.ContainsFunc(types, func(t string) bool { return typeAllows(t, actual) }) {
+				is.ExpectedTypes, is.ActualType = types, actual
+			}
+		}
+		issues = append([]ValidationIssue{is}, issues...)
+	}
+	return issues, true
+}
+
+// schemaTypes returns the JSON types allowed by s, or nil if s does not
+// restrict the type.
+func schemaTypes(s *jsonschema.Schema) []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	return s.Types
+}
+
+// jsonType returns the JSON schema type of a value unmarshaled from JSON.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeAllows reports whether the schema type t allows values of type actual.
+func typeAllows(t, actual string) bool {
+	return t == actual || (t == "number" && actual == "integer")
+}
+
+// isFalseSchema reports whether s is the schema that matches nothing.
+func isFalseSchema(s *jsonschema.Schema) bool {
+	return s.Not != nil && reflect.ValueOf(*s.Not).IsZero()
+}
+
+// escapePointerToken escapes a JSON Pointer reference token (RFC 6901,
+// section 3).
+func escapePointerToken(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// trimValidationMessage removes the schema location prefix from a
+// validation error message.
+func trimValidationMessage(err error) string {
+	return strings.TrimPrefix(err.Error(), "validating root: ")
+}
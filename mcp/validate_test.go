@@ -0,0 +1,143 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+)
+
+func TestValidate(t *testing.T) {
+	type item struct {
+		N string `json:"n"`
+	}
+	type args struct {
+		X     int               `json:"x"`
+		Items []item            `json:"items"`
+		Tags  map[string]string `json:"a/b,omitempty"`
+	}
+	s, err := jsonschema.For[args](nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := s.Resolve(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		in       string
+		pointers []string
+	}{
+		{`{"x": 1, "items": []}`, nil},
+		{`{"x": "a", "items": []}`, []string{"/x"}},
+		{`{"x": 1, "items": [{"n": "ok"}, {"n": 3}]}`, []string{"/items/1/n"}},
+		{`{"items": [{}]}`, []string{"", "/items/0"}},
+		{`{"x": 1, "items": [], "a/b": {"k": 1}}`, []string{"/a~1b/k"}},
+		{`{"x": 1, "items": [], "extra": true}`, []string{""}},
+	} {
+		var v map[string]any
+		if err := json.Unmarshal([]byte(test.in), &v); err != nil {
+			t.Fatal(err)
+		}
+		err := validate(rs, &v)
+		if test.pointers == nil {
+			if err != nil {
+				t.Errorf("%s: got error %v, want nil", test.in, err)
+			}
+			continue
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("%s: got error %v (%[2]T), want *ValidationError", test.in, err)
+		}
+		var got []string
+		for _, is := range verr.Issues {
+			got = append(got, is.JSONPointer)
+			if is.Message == "" || strings.HasPrefix(is.Message, "validating") {
+				t.Errorf("%s: bad message %q", test.in, is.Message)
+			}
+		}
+		if diff := cmp.Diff(test.pointers, got); diff != "" {
+			t.Errorf("%s: pointers mismatch (-want +got):\n%s", test.in, diff)
+		}
+	}
+}
+
+func TestValidationErrorFromServer(t *testing.T) {
+	type in struct {
+		Count int `json:"count"`
+	}
+	type out struct {
+		Name string `json:"name" jsonschema:"the name"`
+	}
+	cs, _, cleanup := basicConnection(t, func(s *Server) {
+		AddTool(s, &Tool{Name: "count"}, func(context.Context, *CallToolRequest, in) (*CallToolResult, any, error) {
+			return nil, nil, nil
+		})
+		outSchema := &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{"name": {Type: "string", MinLength: jsonschema.Ptr(2)}},
+		}
+		AddTool(s, &Tool{Name: "short", OutputSchema: outSchema}, func(context.Context, *CallToolRequest, any) (*CallToolResult, out, error) {
+			return nil, out{Name: "x"}, nil
+		})
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := cs.CallTool(ctx, &CallToolParams{Name: "count", Arguments: map[string]any{"count": "many"}})
+	if got := errorCode(err); got != CodeInvalidParams {
+		t.Errorf("got code %d, want %d (err: %v)", got, CodeInvalidParams, err)
+	}
+	verr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("AsValidationError(%v) failed", err)
+	}
+	if len(verr.Issues) != 1 || verr.Issues[0].JSONPointer != "/count" {
+		t.Errorf("got issues %+v, want one at /count", verr.Issues)
+	}
+	if is := verr.Issues[0]; !slices.Equal(is.ExpectedTypes, []string{"integer"}) || is.ActualType != "string" {
+		t.Errorf("got types %v, %q, want [integer], string", is.ExpectedTypes, is.ActualType)
+	}
+	var ovErr *OutputValidationError
+	if errors.As(err, &ovErr) {
+		t.Errorf("invalid arguments reported as invalid output: %v", err)
+	}
+
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "short"})
+	if got := errorCode(err); got != CodeOutputValidation {
+		t.Errorf("got code %d, want %d (err: %v)", got, CodeOutputValidation, err)
+	}
+	if verr, ok := AsValidationError(err); !ok || verr.Issues[0].JSONPointer != "/name" {
+		t.Errorf("output validation: got %v, %t, want issue at /name", verr, ok)
+	}
+	if !errors.As(err, &ovErr) {
+		t.Fatalf("got %T, want *OutputValidationError", err)
+	}
+	if ovErr.Tool != "short" || ovErr.Validation.Issues[0].JSONPointer != "/name" {
+		t.Errorf("got %+v, want tool short with an issue at /name", ovErr)
+	}
+	if is := ovErr.Validation.Issues[0]; is.ExpectedTypes != nil || is.ActualType != "" {
+		t.Errorf("got types %v, %q for a length violation, want none", is.ExpectedTypes, is.ActualType)
+	}
+
+	// Only the error code identifies output validation errors, not the message.
+	internal := &jsonrpc2.WireError{
+		Code:    CodeInternalError,
+		Message: outputValidationPrefix + ": something",
+		Data:    json.RawMessage(`{"errors":[{"jsonPointer":"","message":"m"}]}`),
+	}
+	if err := outputValidationError("short", internal); err != internal {
+		t.Errorf("outputValidationError of an internal error = %v, want it unchanged", err)
+	}
+}
@@ -0,0 +1,652 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/orkhanm/go-sdk/auth"
+	"github.com/orkhanm/go-sdk/internal/jsonrpc2"
+	"github.com/orkhanm/go-sdk/jsonrpc"
+)
+
+// This file implements a WebSocket transport. It is not defined by the MCP
+// specification, but lets clients behind proxies that interfere with
+// streamable HTTP's hanging requests use a single full-duplex connection.
+//
+// Each WebSocket text message holds one JSON-RPC message. The server assigns
+// the session an ID in the Mcp-Session-Id header of its handshake response.
+// If the connection fails, the client may resume the session by connecting
+// again with that header, within the server's resume timeout. Messages that
+// the server sends while the client is disconnected are held, and sent when
+// it reconnects. Messages in transit when the connection fails may be lost.
+// A client ends its session by closing the connection normally.
+
+// DefaultWebSocketResumeTimeout is the default for
+// [WebSocketOptions.ResumeTimeout].
+const DefaultWebSocketResumeTimeout = 30 * time.Second
+
+// defaultWebSocketMaxPending is the default for
+// [WebSocketOptions.MaxPendingMessages].
+const defaultWebSocketMaxPending = 100
+
+// WebSocketHandler is an http.Handler that serves MCP sessions over WebSocket
+// connections, which clients make with a [WebSocketClientTransport].
+type WebSocketHandler struct {
+	getServer func(*http.Request) *Server
+	opts      WebSocketOptions
+
+	mu       sync.Mutex
+	sessions map[string]*webSocketServerConn // keyed by session ID
+}
+
+// WebSocketOptions configures a [WebSocketHandler].
+type WebSocketOptions struct {
+	// ResumeTimeout is how long the session of a failed connection is kept
+	// for the client to resume it. If zero, [DefaultWebSocketResumeTimeout]
+	// is used. If negative, sessions end with their connection.
+	ResumeTimeout time.Duration
+
+	// MaxPendingMessages bounds the number of messages held for a client
+	// while it is disconnected. If the server sends more, the session is
+	// closed. If zero, 100 is used.
+	MaxPendingMessages int
+
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// received from a client. A larger message closes the connection with
+	// an error wrapping [ErrMessageTooLarge]. If zero, 16 MiB is used.
+	MaxMessageSize int
+
+	// AllowedOrigins, if non-empty, lists the origins allowed to open
+	// connections. Requests with another Origin header are rejected with 403
+	// Forbidden. Browsers don't restrict cross-origin WebSocket connections,
+	// so a locally hosted server should set this or ValidateOrigin. See
+	// [StreamableHTTPOptions.AllowedOrigins].
+	AllowedOrigins []string
+
+	// ValidateOrigin, if non-nil, reports whether requests with the given
+	// Origin header are allowed, in place of AllowedOrigins.
+	ValidateOrigin func(origin string) bool
+
+	// Logger specifies the logger to use.
+	// If nil, do not log.
+	Logger *slog.Logger
+}
+
+// NewWebSocketHandler returns a new [WebSocketHandler].
+//
+// The getServer function is used to create or look up servers for new
+// sessions. It is OK for getServer to return the same server multiple times.
+// If getServer returns nil, a 400 Bad Request will be served.
+func NewWebSocketHandler(getServer func(*http.Request) *Server, opts *WebSocketOptions) *WebSocketHandler {
+	h := &WebSocketHandler{
+		getServer: getServer,
+		sessions:  make(map[string]*webSocketServerConn),
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.ResumeTimeout == 0 {
+		h.opts.ResumeTimeout = DefaultWebSocketResumeTimeout
+	}
+	if h.opts.MaxPendingMessages <= 0 {
+		h.opts.MaxPendingMessages = defaultWebSocketMaxPending
+	}
+	h.opts.Logger = ensureLogger(h.opts.Logger)
+	return h
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !originAllowed(req, h.opts.AllowedOrigins, h.opts.ValidateOrigin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if ue := checkWebSocketUpgrade(req); ue != nil {
+		switch ue.status {
+		case http.StatusMethodNotAllowed:
+			w.Header().Set("Allow", "GET")
+		case http.StatusUpgradeRequired:
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		http.Error(w, ue.msg, ue.status)
+		return
+	}
+
+	// Resume an existing session.
+	if sessionID := req.Header.Get(sessionIDHeader); sessionID != "" {
+		h.mu.Lock()
+		conn := h.sessions[sessionID]
+		h.mu.Unlock()
+		if conn == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		ws, err := upgradeWebSocket(w, req, http.Header{sessionIDHeader: {sessionID}})
+		if err != nil {
+			h.opts.Logger.Error("websocket upgrade failed", "error", err, "session_id", sessionID)
+			return
+		}
+		ws.maxSize = h.opts.MaxMessageSize
+		h.opts.Logger.Info("resuming websocket session", "session_id", sessionID)
+		conn.attach(ws, req)
+		return
+	}
+
+	server := h.getServer(req)
+	if server == nil {
+		// The getServer argument to NewWebSocketHandler returned nil.
+		http.Error(w, "no server available", http.StatusBadRequest)
+		return
+	}
+	conn := &webSocketServerConn{
+		sessionID:     server.opts.GetSessionID(),
+		resumeTimeout: h.opts.ResumeTimeout,
+		maxPending:    h.opts.MaxPendingMessages,
+		logger:        h.opts.Logger,
+		incoming:      make(chan jsonrpc.Message, 10),
+		done:          make(chan struct{}),
+	}
+	header := http.Header{}
+	if conn.sessionID == "" {
+		// Without an ID, the session can't be resumed.
+		conn.resumeTimeout = -1
+	} else {
+		header.Set(sessionIDHeader, conn.sessionID)
+	}
+	ws, err := upgradeWebSocket(w, req, header)
+	if err != nil {
+		h.opts.Logger.Error("websocket upgrade failed", "error", err)
+		return
+	}
+	ws.maxSize = h.opts.MaxMessageSize
+	if conn.sessionID != "" {
+		h.mu.Lock()
+		h.sessions[conn.sessionID] = conn
+		h.mu.Unlock()
+		conn.onClose = func() { h.remove(conn) }
+	}
+	// The hijacked connection outlives the request, so don't use its context.
+	if _, err := server.Connect(context.WithoutCancel(req.Context()), &webSocketServerTransport{conn}, nil); err != nil {
+		h.opts.Logger.Error("websocket connection failed", "error", err, "session_id", conn.sessionID)
+		ws.close(wsCloseGoingAway, "connection failed")
+		h.remove(conn)
+		return
+	}
+	conn.attach(ws, req)
+}
+
+// remove removes the session of conn from the handler.
+func (h *WebSocketHandler) remove(conn *webSocketServerConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sessions[conn.sessionID] == conn {
+		delete(h.sessions, conn.sessionID)
+	}
+}
+
+// A webSocketServerTransport connects a server session to the WebSocket
+// connections of a [WebSocketHandler].
+type webSocketServerTransport struct {
+	conn *webSocketServerConn
+}
+
+// Connect implements the [Transport] interface.
+func (t *webSocketServerTransport) Connect(context.Context) (Connection, error) {
+	return t.conn, nil
+}
+
+// A webSocketServerConn is the server side of a WebSocket session, which
+// may span several WebSocket connections if the client resumes it.
+type webSocketServerConn struct {
+	sessionID     string
+	resumeTimeout time.Duration
+	maxPending    int
+	logger        *slog.Logger
+	onClose       func() // if set, called when the session is closed
+	incoming      chan jsonrpc.Message
+	done          chan struct{}
+
+	wmu sync.Mutex // serializes writes, so that held messages are sent in order
+
+	mu      sync.Mutex // guards the fields below
+	ws      *wsConn    // the current connection, or nil if the client is disconnected
+	extra   *RequestExtra
+	pending [][]byte    // messages held while the client is disconnected
+	timer   *time.Timer // closes the session if the client doesn't resume it
+	closed  bool
+}
+
+// SessionID implements the [Connection] interface.
+func (c *webSocketServerConn) SessionID() string { return c.sessionID }
+
+// attach makes ws the connection of the session, replacing any previous
+// connection, sends any messages held for the client, and starts reading
+// messages from ws. The request that opened ws provides the
+// [RequestExtra] of incoming requests.
+func (c *webSocketServerConn) attach(ws *wsConn, req *http.Request) {
+	// Close the previous connection first, as a write to it may be blocked.
+	c.mu.Lock()
+	old := c.ws
+	c.mu.Unlock()
+	if old != nil {
+		old.close(wsCloseGoingAway, "session resumed elsewhere")
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		ws.close(wsCloseNormal, "session closed")
+		return
+	}
+	c.ws = ws
+	c.extra = &RequestExtra{TokenInfo: auth.TokenInfoFromContext(req.Context()), Header: req.Header}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for i, data := range pending {
+		if err := ws.writeMessage(data); err != nil {
+			c.mu.Lock()
+			c.pending = append(pending[i:], c.pending...)
+			c.mu.Unlock()
+			c.detach(ws, err)
+			return
+		}
+	}
+	go c.readLoop(ws)
+}
+
+// detach records that ws failed with err. If ws is the current connection,
+// the session waits for the client to resume it, or ends if it can't be
+// resumed.
+func (c *webSocketServerConn) detach(ws *wsConn, err error) {
+	ws.close(wsCloseGoingAway, "")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ws != ws || c.closed {
+		return
+	}
+	c.ws = nil
+	if c.resumeTimeout < 0 {
+		c.logger.Info("websocket connection failed", "error", err, "session_id", c.sessionID)
+		go c.Close()
+		return
+	}
+	c.logger.Info("websocket connection failed; awaiting resumption", "error", err, "session_id", c.sessionID)
+	c.timer = time.AfterFunc(c.resumeTimeout, func() {
+		c.logger.Info("websocket session was not resumed", "session_id", c.sessionID)
+		c.Close()
+	})
+}
+
+// readLoop reads messages from ws until it fails.
+func (c *webSocketServerConn) readLoop(ws *wsConn) {
+	for {
+		data, err := ws.readMessage()
+		if err != nil {
+			if ce, ok := err.(*wsCloseError); ok && ce.code == wsCloseNormal {
+				// The client closed the connection normally, ending the
+				// session.
+				c.mu.Lock()
+				current := c.ws == ws
+				c.mu.Unlock()
+				if current {
+					c.Close()
+				}
+				return
+			}
+			c.detach(ws, err)
+			return
+		}
+		msg, err := jsonrpc2.DecodeMessage(data)
+		if err != nil {
+			c.detach(ws, ws.fail(wsCloseProtocolError, fmt.Sprintf("invalid message: %v", err)))
+			return
+		}
+		if req, ok := msg.(*jsonrpc.Request); ok {
+			c.mu.Lock()
+			req.Extra = c.extra
+			c.mu.Unlock()
+		}
+		select {
+		case c.incoming <- msg:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Read implements the [Connection] interface.
+func (c *webSocketServerConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-c.incoming:
+		return msg, nil
+	case <-c.done:
+		return nil, io.EOF
+	}
+}
+
+// Write implements the [Connection] interface. If the client is
+// disconnected, the message is held until it resumes the session.
+func (c *webSocketServerConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc2.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.mu.Lock()
+	ws, closed := c.ws, c.closed
+	c.mu.Unlock()
+	if closed {
+		return errors.New("session is closed")
+	}
+	if ws != nil {
+		if err = ws.writeMessage(data); err == nil {
+			return nil
+		}
+		c.detach(ws, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumeTimeout < 0 {
+		return ErrConnectionClosed
+	}
+	if len(c.pending) >= c.maxPending {
+		return fmt.Errorf("websocket session %s: too many messages while disconnected", c.sessionID)
+	}
+	c.pending = append(c.pending, data)
+	return nil
+}
+
+// Close implements the [Connection] interface.
+func (c *webSocketServerConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	ws := c.ws
+	c.ws = nil
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mu.Unlock()
+	if ws != nil {
+		ws.close(wsCloseNormal, "session closed")
+	}
+	if c.onClose != nil {
+		c.onClose()
+	}
+	return nil
+}
+
+// A WebSocketClientTransport is a [Transport] that connects to a
+// [WebSocketHandler].
+type WebSocketClientTransport struct {
+	// Endpoint is the URL of the handler. Its scheme may be ws, wss, http or
+	// https.
+	Endpoint string
+	// HTTPClient is the client to use for the opening handshake. If nil,
+	// http.DefaultClient is used. Its transport must support HTTP/1.1
+	// upgrades, as [http.Transport] does.
+	HTTPClient *http.Client
+	// MaxRetries is the maximum number of times to attempt to reconnect and
+	// resume the session after the connection fails, before giving up.
+	// It defaults to 5. To disable retries, use a negative number.
+	MaxRetries int
+	// MaxMessageSize, if positive, is the maximum size in bytes of a message
+	// received from the server. A larger message fails the connection with
+	// an error wrapping [ErrMessageTooLarge]. If zero, 16 MiB is used.
+	MaxMessageSize int
+}
+
+// Connect implements the [Transport] interface.
+//
+// The resulting [Connection] reconnects if the WebSocket connection fails,
+// resuming the session. When closed, it closes the WebSocket connection
+// normally, ending the session.
+func (t *WebSocketClientTransport) Connect(ctx context.Context) (Connection, error) {
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ws, resp, err := dialWebSocket(ctx, client, t.Endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	ws.maxSize = t.MaxMessageSize
+	// If the server doesn't assign a session ID, the session can't be
+	// resumed.
+	sessionID := resp.Header.Get(sessionIDHeader)
+	// The connection outlives the context of Connect.
+	connCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &webSocketClientConn{
+		endpoint:  t.Endpoint,
+		client:    client,
+		sessionID: sessionID,
+		maxSize:   t.MaxMessageSize,
+		retry: reconnectPolicy{
+			maxRetries:   retries(t.MaxRetries, 5),
+			initialDelay: reconnectInitialDelay,
+			maxDelay:     reconnectMaxDelay,
+			growFactor:   reconnectGrowFactor,
+		},
+		ctx:      connCtx,
+		cancel:   cancel,
+		incoming: make(chan jsonrpc.Message, 10),
+		done:     make(chan struct{}),
+		ws:       ws,
+	}
+	go c.readLoop(ws)
+	return c, nil
+}
+
+// A webSocketClientConn is the client side of a WebSocket session.
+type webSocketClientConn struct {
+	endpoint  string
+	client    *http.Client
+	sessionID string
+	maxSize   int // from [WebSocketClientTransport.MaxMessageSize]
+	retry     reconnectPolicy
+	ctx       context.Context // canceled on Close
+	cancel    context.CancelFunc
+	incoming  chan jsonrpc.Message
+	done      chan struct{}
+
+	wmu sync.Mutex // serializes writes
+
+	mu     sync.Mutex    // guards the fields below
+	ws     *wsConn       // the current connection, or nil while reconnecting
+	up     chan struct{} // closed when reconnected, if ws is nil
+	closed bool
+	err    error // the error that ended the session, if any
+}
+
+// SessionID implements the [Connection] interface.
+func (c *webSocketClientConn) SessionID() string { return c.sessionID }
+
+// readLoop reads messages from ws until it fails.
+func (c *webSocketClientConn) readLoop(ws *wsConn) {
+	for {
+		data, err := ws.readMessage()
+		if err != nil {
+			c.lost(ws, err)
+			return
+		}
+		msg, err := jsonrpc2.DecodeMessage(data)
+		if err != nil {
+			c.fail(ws.fail(wsCloseProtocolError, fmt.Sprintf("invalid message: %v", err)))
+			return
+		}
+		select {
+		case c.incoming <- msg:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// lost records that ws failed with err. If ws is the current connection, the
+// client tries to reconnect.
+func (c *webSocketClientConn) lost(ws *wsConn, err error) {
+	ws.close(wsCloseGoingAway, "")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ws != ws || c.closed {
+		return
+	}
+	c.ws = nil
+	c.up = make(chan struct{})
+	if ce, ok := err.(*wsCloseError); ok && ce.code == wsCloseNormal {
+		// The server ended the session.
+		go c.fail(io.EOF)
+		return
+	}
+	if c.sessionID == "" || c.retry.maxRetries == 0 {
+		go c.fail(err)
+		return
+	}
+	go c.reconnect(err)
+}
+
+// reconnect attempts to resume the session, with backoff. The first attempt
+// is made immediately.
+func (c *webSocketClientConn) reconnect(cause error) {
+	err := cause
+	for attempt := 1; attempt <= c.retry.maxRetries; attempt++ {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.retry.delay(attempt - 1)):
+		}
+		var ws *wsConn
+		var resp *http.Response
+		ws, resp, err = dialWebSocket(c.ctx, c.client, c.endpoint, http.Header{sessionIDHeader: {c.sessionID}})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				c.fail(fmt.Errorf("%w: %v", errSessionMissing, err))
+				return
+			}
+			continue
+		}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			ws.close(wsCloseNormal, "")
+			return
+		}
+		ws.maxSize = c.maxSize
+		c.ws = ws
+		close(c.up)
+		c.mu.Unlock()
+		go c.readLoop(ws)
+		return
+	}
+	c.fail(fmt.Errorf("websocket reconnection failed after %d attempts: %w", c.retry.maxRetries, err))
+}
+
+// fail ends the session with err.
+func (c *webSocketClientConn) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	c.Close()
+}
+
+// Read implements the [Connection] interface.
+func (c *webSocketClientConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-c.incoming:
+		return msg, nil
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, io.EOF
+	}
+}
+
+// Write implements the [Connection] interface. While the client is
+// reconnecting, Write waits until it has resumed the session.
+func (c *webSocketClientConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc2.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	for {
+		c.mu.Lock()
+		ws, up, closed, cerr := c.ws, c.up, c.closed, c.err
+		c.mu.Unlock()
+		if closed {
+			if cerr != nil {
+				return cerr
+			}
+			return ErrConnectionClosed
+		}
+		if ws == nil {
+			select {
+			case <-up:
+				continue
+			case <-c.done:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		err := ws.writeMessage(data)
+		if err == nil {
+			return nil
+		}
+		c.lost(ws, err)
+	}
+}
+
+// Close implements the [Connection] interface.
+func (c *webSocketClientConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	ws := c.ws
+	c.ws = nil
+	c.mu.Unlock()
+	c.cancel()
+	if ws != nil {
+		ws.close(wsCloseNormal, "")
+	}
+	return nil
+}
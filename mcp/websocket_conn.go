@@ -0,0 +1,405 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements the subset of the WebSocket protocol (RFC 6455) needed
+// by the WebSocket transport: the opening handshake over HTTP/1.1, and framing
+// of messages and control frames. Extensions are not supported.
+
+const (
+	// webSocketGUID is the value that the server concatenates with the
+	// client's key to prove that it received the opening handshake.
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// webSocketSubprotocol is the subprotocol negotiated by the transport.
+	webSocketSubprotocol = "mcp"
+	// defaultWebSocketMessageSize is the default maximum size of an incoming
+	// message.
+	defaultWebSocketMessageSize = 16 << 20 // 16 MiB
+)
+
+// WebSocket opcodes.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// WebSocket close status codes.
+const (
+	wsCloseNormal        = 1000
+	wsCloseGoingAway     = 1001
+	wsCloseProtocolError = 1002
+	wsCloseTooLarge      = 1009
+)
+
+var errWebSocketProtocol = errors.New("websocket protocol error")
+
+// A wsCloseError reports that the peer closed the WebSocket connection with
+// a close frame.
+type wsCloseError struct {
+	code   int // 0 if the frame had no status code
+	reason string
+}
+
+func (e *wsCloseError) Error() string {
+	return fmt.Sprintf("websocket closed by peer: code %d %s", e.code, e.reason)
+}
+
+// A wsConn is a single WebSocket connection.
+//
+// Messages may be read by one goroutine at a time, and written concurrently.
+type wsConn struct {
+	rwc     io.ReadWriteCloser
+	br      *bufio.Reader
+	client  bool // frames sent by clients are masked
+	maxSize int  // maximum size of an incoming message; if zero, 16 MiB
+
+	wmu sync.Mutex // guards writes to rwc
+
+	closeOnce sync.Once
+}
+
+// webSocketAccept returns the Sec-WebSocket-Accept value for key.
+func webSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerHasToken reports whether the comma-separated header values of h for
+// name contain token, ignoring case.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// An upgradeError is an invalid WebSocket opening handshake, to be answered
+// with the given HTTP status.
+type upgradeError struct {
+	status int
+	msg    string
+}
+
+func (e *upgradeError) Error() string { return e.msg }
+
+// checkWebSocketUpgrade checks that req is a valid WebSocket opening
+// handshake. It returns nil if it is.
+func checkWebSocketUpgrade(req *http.Request) *upgradeError {
+	switch {
+	case req.Method != http.MethodGet:
+		return &upgradeError{http.StatusMethodNotAllowed, "method not allowed"}
+	case !headerHasToken(req.Header, "Connection", "upgrade") || !headerHasToken(req.Header, "Upgrade", "websocket"):
+		return &upgradeError{http.StatusBadRequest, "Bad Request: not a websocket upgrade"}
+	case req.Header.Get("Sec-WebSocket-Version") != "13":
+		return &upgradeError{http.StatusUpgradeRequired, "Upgrade Required: unsupported websocket version"}
+	}
+	key, err := base64.StdEncoding.DecodeString(req.Header.Get("Sec-WebSocket-Key"))
+	if err != nil || len(key) != 16 {
+		return &upgradeError{http.StatusBadRequest, "Bad Request: invalid Sec-WebSocket-Key"}
+	}
+	return nil
+}
+
+// upgradeWebSocket completes the opening handshake of the WebSocket
+// connection requested by req, which must have been checked with
+// [checkWebSocketUpgrade], and takes over its network connection. The
+// response includes the given header.
+func upgradeWebSocket(w http.ResponseWriter, req *http.Request, header http.Header) (*wsConn, error) {
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijacking connection: %w", err)
+	}
+	// Clear any deadlines set by the http.Server.
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	fmt.Fprintf(&b, "Sec-WebSocket-Accept: %s\r\n", webSocketAccept(req.Header.Get("Sec-WebSocket-Key")))
+	if headerHasToken(req.Header, "Sec-WebSocket-Protocol", webSocketSubprotocol) {
+		fmt.Fprintf(&b, "Sec-WebSocket-Protocol: %s\r\n", webSocketSubprotocol)
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+		}
+	}
+	b.WriteString("\r\n")
+	if _, err := brw.WriteString(b.String()); err == nil {
+		err = brw.Flush()
+	}
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &wsConn{rwc: netConn, br: brw.Reader}, nil
+}
+
+// dialWebSocket performs the opening handshake of a WebSocket connection to
+// endpoint, which may have a ws, wss, http or https scheme, with the given
+// additional request header. It returns the connection and the handshake
+// response, whose body must not be used. If the server does not switch
+// protocols, the error wraps an [httpStatusError].
+func dialWebSocket(ctx context.Context, client *http.Client, endpoint string, header http.Header) (*wsConn, *http.Response, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	var key [16]byte
+	rand.Read(key[:])
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key[:]))
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Protocol", webSocketSubprotocol)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("websocket handshake: %w", &httpStatusError{resp.StatusCode, resp.Status})
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("websocket handshake: response body is not writable")
+	}
+	if !headerHasToken(resp.Header, "Upgrade", "websocket") || resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(req.Header.Get("Sec-WebSocket-Key")) {
+		rwc.Close()
+		return nil, resp, fmt.Errorf("websocket handshake: %w: invalid response", errWebSocketProtocol)
+	}
+	return &wsConn{rwc: rwc, br: bufio.NewReader(rwc), client: true}, resp, nil
+}
+
+// readMessage reads the next data message, answering pings and skipping
+// pongs. If the peer closes the connection, it returns a [*wsCloseError].
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	inMessage := false
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			ce := &wsCloseError{}
+			if len(payload) >= 2 {
+				ce.code = int(binary.BigEndian.Uint16(payload))
+				ce.reason = string(payload[2:])
+			}
+			// Echo the status code, as required by §5.5.1.
+			c.writeFrame(wsClose, payload[:min(len(payload), 2)])
+			c.rwc.Close()
+			return nil, ce
+		case wsText, wsBinary:
+			if inMessage {
+				return nil, c.fail(wsCloseProtocolError, "unexpected data frame")
+			}
+			inMessage = true
+		case wsContinuation:
+			if !inMessage {
+				return nil, c.fail(wsCloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return nil, c.fail(wsCloseProtocolError, fmt.Sprintf("unknown opcode %d", op))
+		}
+		if len(msg)+len(payload) > c.maxMessageSize() {
+			return nil, c.tooLarge()
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a single frame.
+func (c *wsConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = h[0]&0x80 != 0
+	op = h[0] & 0x0f
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "reserved bits set")
+	}
+	// Frames from clients are masked, and frames from servers are not
+	// (§5.1).
+	if masked := h[1]&0x80 != 0; masked == c.client {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "invalid frame masking")
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if op >= wsClose && (n > 125 || !fin) {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "invalid control frame")
+	}
+	if n > uint64(c.maxMessageSize()) {
+		return false, 0, nil, c.tooLarge()
+	}
+	var mask [4]byte
+	if !c.client {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if !c.client {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeMessage writes data as a text message.
+func (c *wsConn) writeMessage(data []byte) error {
+	return c.writeFrame(wsText, data)
+}
+
+// writeFrame writes a single, final frame.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.writeFrameLocked(op, payload)
+}
+
+// writeFrameLocked is like writeFrame, but c.wmu must be held.
+func (c *wsConn) writeFrameLocked(op byte, payload []byte) error {
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|op)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		rand.Read(mask[:])
+		buf = append(buf, mask[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		for i := range payload {
+			buf[start+i] ^= mask[i%4]
+		}
+	} else {
+		buf = append(buf, payload...)
+	}
+	_, err := c.rwc.Write(buf)
+	return err
+}
+
+// fail closes the connection after a protocol violation by the peer, and
+// returns an error describing it.
+func (c *wsConn) fail(code int, reason string) error {
+	c.close(code, reason)
+	return fmt.Errorf("%w: %s", errWebSocketProtocol, reason)
+}
+
+// maxMessageSize returns the maximum size of an incoming message.
+func (c *wsConn) maxMessageSize() int {
+	if c.maxSize > 0 {
+		return c.maxSize
+	}
+	return defaultWebSocketMessageSize
+}
+
+// tooLarge closes the connection because of an incoming message larger than
+// its maximum size, and returns an error wrapping [ErrMessageTooLarge].
+func (c *wsConn) tooLarge() error {
+	c.close(wsCloseTooLarge, "message too large")
+	return fmt.Errorf("%w: exceeds %d bytes", ErrMessageTooLarge, c.maxMessageSize())
+}
+
+// close closes the connection, first sending a close frame with the given
+// status code, unless another write is in progress.
+func (c *wsConn) close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		// Don't wait for a write that may be blocked on an unresponsive peer.
+		if c.wmu.TryLock() {
+			payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+			payload = append(payload, reason...)
+			c.writeFrameLocked(wsClose, payload)
+			c.wmu.Unlock()
+		}
+		err = c.rwc.Close()
+	})
+	return err
+}
@@ -0,0 +1,360 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// webSocketURL returns the ws:// URL of an httptest server.
+func webSocketURL(s *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	connected := make(chan *ServerSession, 1)
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == methodInitialize {
+				connected <- req.GetSession().(*ServerSession)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	httpServer := httptest.NewServer(NewWebSocketHandler(func(*http.Request) *Server { return server }, nil))
+	defer httpServer.Close()
+
+	client := NewClient(testImpl, nil)
+	client.AddRoots(&Root{URI: "file:///root"})
+	// The connection outlives the context used to make it.
+	connectCtx, connectCancel := context.WithCancel(ctx)
+	cs, err := client.Connect(connectCtx, &WebSocketClientTransport{Endpoint: webSocketURL(httpServer)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connectCancel()
+	ss := <-connected
+	if cs.ID() == "" || cs.ID() != ss.ID() {
+		t.Errorf("client session ID %q, server session ID %q: want equal and non-empty", cs.ID(), ss.ID())
+	}
+
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "hi user" {
+		t.Errorf("CallTool: got %q, want %q", got, "hi user")
+	}
+	// The connection is full-duplex: the server can make requests of the
+	// client.
+	roots, err := ss.ListRoots(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots.Roots) != 1 || roots.Roots[0].URI != "file:///root" {
+		t.Errorf("ListRoots: got %v, want the client's root", roots.Roots)
+	}
+
+	// Closing the client ends the server session.
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitSession(t, ctx, ss)
+}
+
+// waitSession waits for ss to end.
+func waitSession(t *testing.T, ctx context.Context, ss *ServerSession) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		ss.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("server session did not end")
+	}
+}
+
+// breakWebSocket abruptly closes the current connection of a session of h,
+// without a close frame, and waits for the server to notice.
+func breakWebSocket(t *testing.T, h *WebSocketHandler, sessionID string) {
+	t.Helper()
+	h.mu.Lock()
+	conn := h.sessions[sessionID]
+	h.mu.Unlock()
+	if conn == nil {
+		t.Fatalf("no session %q", sessionID)
+	}
+	conn.mu.Lock()
+	ws := conn.ws
+	conn.mu.Unlock()
+	ws.rwc.Close()
+	for {
+		conn.mu.Lock()
+		detached := conn.ws != ws
+		conn.mu.Unlock()
+		if detached {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// gatedRoundTripper lets the first request through, and holds later ones
+// until its gate is closed.
+type gatedRoundTripper struct {
+	requests atomic.Int32
+	gate     chan struct{}
+}
+
+func (rt *gatedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.requests.Add(1) > 1 {
+		<-rt.gate
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWebSocketResume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, sayHi)
+	handler := NewWebSocketHandler(func(*http.Request) *Server { return server }, nil)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	progress := make(chan string, 1)
+	client := NewClient(testImpl, &ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+			progress <- req.Params.Message
+		},
+	})
+	rt := &gatedRoundTripper{gate: make(chan struct{})}
+	cs, err := client.Connect(ctx, &WebSocketClientTransport{
+		Endpoint:   webSocketURL(httpServer),
+		HTTPClient: &http.Client{Transport: rt},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	var ss *ServerSession
+	for s := range server.Sessions() {
+		ss = s
+	}
+
+	// Messages sent while the client is disconnected are held until it
+	// resumes the session.
+	breakWebSocket(t, handler, cs.ID())
+	if err := ss.NotifyProgress(ctx, &ProgressNotificationParams{ProgressToken: "t", Message: "held"}); err != nil {
+		t.Fatal(err)
+	}
+	close(rt.gate)
+	select {
+	case got := <-progress:
+		if got != "held" {
+			t.Errorf("got progress %q, want %q", got, "held")
+		}
+	case <-ctx.Done():
+		t.Fatal("held notification was not delivered")
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "user"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := rt.requests.Load(); got != 2 {
+		t.Errorf("client made %d handshakes, want 2", got)
+	}
+	handler.mu.Lock()
+	if n := len(handler.sessions); n != 1 {
+		t.Errorf("handler has %d sessions, want 1", n)
+	}
+	handler.mu.Unlock()
+}
+
+func TestWebSocketResumeTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	server := NewServer(testImpl, nil)
+	handler := NewWebSocketHandler(func(*http.Request) *Server { return server }, &WebSocketOptions{
+		ResumeTimeout: 50 * time.Millisecond,
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &WebSocketClientTransport{
+		Endpoint:   webSocketURL(httpServer),
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	var ss *ServerSession
+	for s := range server.Sessions() {
+		ss = s
+	}
+
+	// The client doesn't reconnect, so the session ends.
+	breakWebSocket(t, handler, cs.ID())
+	waitSession(t, ctx, ss)
+	if err := cs.Wait(); err == nil {
+		t.Error("client session ended without error")
+	}
+
+	// The session can no longer be resumed.
+	_, resp, err := dialWebSocket(ctx, http.DefaultClient, httpServer.URL, http.Header{sessionIDHeader: {cs.ID()}})
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("resuming expired session: got %v, want 404", err)
+	}
+}
+
+func TestWebSocketUpgradeErrors(t *testing.T) {
+	httpServer := httptest.NewServer(NewWebSocketHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, nil))
+	defer httpServer.Close()
+
+	for _, test := range []struct {
+		name   string
+		method string
+		header map[string]string
+		want   int
+	}{
+		{"post", http.MethodPost, nil, http.StatusMethodNotAllowed},
+		{"no upgrade", http.MethodGet, nil, http.StatusBadRequest},
+		{"bad version", http.MethodGet, map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8"}, http.StatusUpgradeRequired},
+		{"bad key", http.MethodGet, map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "x"}, http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, httpServer.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketFrames(t *testing.T) {
+	c1, c2 := net.Pipe()
+	client := &wsConn{rwc: c1, br: bufio.NewReader(c1), client: true}
+	server := &wsConn{rwc: c2, br: bufio.NewReader(c2)}
+	defer client.close(wsCloseNormal, "")
+
+	// Messages of each length encoding round trip, in both directions.
+	for _, n := range []int{0, 125, 126, 0xffff, 0x10000} {
+		msg := bytes.Repeat([]byte{'x'}, n)
+		for _, c := range []struct{ from, to *wsConn }{{client, server}, {server, client}} {
+			go c.from.writeMessage(msg)
+			got, err := c.to.readMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Errorf("message of length %d: got length %d", n, len(got))
+			}
+		}
+	}
+
+	// Pings are answered while reading, and fragmented messages are
+	// reassembled.
+	go func() {
+		client.writeFrame(wsPing, []byte("ping"))
+		client.wmu.Lock()
+		client.rwc.Write(maskedFrame(0x00|wsText, "frag"))
+		client.rwc.Write(maskedFrame(0x80|wsContinuation, "ment"))
+		client.wmu.Unlock()
+	}()
+	pong := make(chan []byte, 1)
+	go func() {
+		_, op, payload, err := client.readFrame()
+		if err == nil && op == wsPong {
+			pong <- payload
+		}
+		close(pong)
+	}()
+	got, err := server.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "fragment" {
+		t.Errorf("fragmented message: got %q, want %q", got, "fragment")
+	}
+	if p := <-pong; string(p) != "ping" {
+		t.Errorf("got pong %q, want %q", p, "ping")
+	}
+
+	// Unmasked frames from clients are rejected. (Discard the close frame
+	// that the server then sends.)
+	go io.Copy(io.Discard, client.br)
+	go client.rwc.Write([]byte{0x80 | wsText, 1, 'x'})
+	if _, err := server.readMessage(); !errors.Is(err, errWebSocketProtocol) {
+		t.Errorf("unmasked client frame: got %v, want protocol error", err)
+	}
+}
+
+func TestWebSocketMaxMessageSize(t *testing.T) {
+	c1, c2 := net.Pipe()
+	client := &wsConn{rwc: c1, br: bufio.NewReader(c1), client: true}
+	server := &wsConn{rwc: c2, br: bufio.NewReader(c2), maxSize: 10}
+	defer client.close(wsCloseNormal, "")
+
+	go client.writeMessage([]byte("0123456789"))
+	if got, err := server.readMessage(); err != nil || len(got) != 10 {
+		t.Fatalf("message at the limit: got (%q, %v)", got, err)
+	}
+
+	// A message over the limit, even if fragmented, fails the connection.
+	// (Discard the close frame that the server then sends.)
+	go func() {
+		client.wmu.Lock()
+		client.rwc.Write(maskedFrame(0x00|wsText, "012345"))
+		client.rwc.Write(maskedFrame(0x80|wsContinuation, "6789x"))
+		client.wmu.Unlock()
+		io.Copy(io.Discard, client.br)
+	}()
+	if _, err := server.readMessage(); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("message over the limit: got %v, want %v", err, ErrMessageTooLarge)
+	}
+}
+
+// maskedFrame returns a masked frame with the given first byte and payload,
+// which must be short.
+func maskedFrame(b0 byte, payload string) []byte {
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{b0, 0x80 | byte(len(payload))}, mask...)
+	for i := range len(payload) {
+		frame = append(frame, payload[i]^mask[i%4])
+	}
+	return frame
+}
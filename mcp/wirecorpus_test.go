@@ -0,0 +1,305 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/orkhanm/go-sdk/jsonrpc"
+	"github.com/orkhanm/go-sdk/wiretest"
+)
+
+var updateWire = flag.Bool("update-wire", false, "if set, update the wire corpus in ../wiretest/corpus")
+
+// A wireCorpusEntry holds the canonical params and result of a method, from
+// which the messages of the wire corpus are generated.
+type wireCorpusEntry struct {
+	method string
+	since  string // the first protocol version with the method, if not the earliest
+	params func(version string) Params
+	result Result // nil for notifications
+}
+
+func wireCorpusEntries() []wireCorpusEntry {
+	size := int64(42)
+	content := func() []Content {
+		return []Content{
+			&TextContent{Text: "hello"},
+			&AudioContent{Data: []byte("audio"), MIMEType: "audio/wav"},
+			&ResourceLink{URI: "file:///a.txt", Name: "a.txt", MIMEType: "text/plain", Size: &size},
+		}
+	}
+	return []wireCorpusEntry{
+		{
+			method: methodInitialize,
+			params: func(v string) Params {
+				return &InitializeParams{
+					Capabilities:    &ClientCapabilities{Sampling: &SamplingCapabilities{}},
+					ClientInfo:      &Implementation{Name: "client", Version: "v1.0.0"},
+					ProtocolVersion: v,
+				}
+			},
+			result: &InitializeResult{
+				Capabilities: &ServerCapabilities{
+					Completions: &CompletionCapabilities{},
+					Logging:     &LoggingCapabilities{},
+					Tools:       &ToolCapabilities{ListChanged: true},
+				},
+				Instructions: "Be nice.",
+				ServerInfo:   &Implementation{Name: "server", Version: "v1.0.0"},
+			},
+		},
+		{method: notificationInitialized, params: func(string) Params { return &InitializedParams{} }},
+		{method: methodPing, params: func(string) Params { return &PingParams{} }, result: &emptyResult{}},
+		{
+			method: methodListTools,
+			params: func(string) Params { return &ListToolsParams{Cursor: "c1"} },
+			result: &ListToolsResult{
+				Tools: []*Tool{{
+					Name:        "greet",
+					Description: "say hi",
+					InputSchema: map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}},
+				}},
+				NextCursor: "c2",
+			},
+		},
+		{
+			method: methodCallTool,
+			params: func(string) Params {
+				return &CallToolParams{Name: "greet", Arguments: map[string]any{"name": "you"}}
+			},
+			result: &CallToolResult{Content: content(), StructuredContent: map[string]any{"greeting": "hello"}},
+		},
+		{
+			method: methodListPrompts,
+			params: func(string) Params { return &ListPromptsParams{} },
+			result: &ListPromptsResult{Prompts: []*Prompt{{
+				Name:      "code_review",
+				Arguments: []*PromptArgument{{Name: "code", Required: true}},
+			}}},
+		},
+		{
+			method: methodGetPrompt,
+			params: func(string) Params {
+				return &GetPromptParams{Name: "code_review", Arguments: map[string]string{"code": "x := 1"}}
+			},
+			result: &GetPromptResult{
+				Description: "review",
+				Messages: []*PromptMessage{
+					{Role: "user", Content: &TextContent{Text: "review x := 1"}},
+					{Role: "user", Content: &AudioContent{Data: []byte("audio"), MIMEType: "audio/wav"}},
+				},
+			},
+		},
+		{
+			method: methodListResources,
+			params: func(string) Params { return &ListResourcesParams{} },
+			result: &ListResourcesResult{Resources: []*Resource{{URI: "file:///a.txt", Name: "a.txt", MIMEType: "text/plain"}}},
+		},
+		{
+			method: methodSearchResources,
+			params: func(string) Params { return &SearchResourcesParams{Query: "readme"} },
+			result: &SearchResourcesResult{Resources: []*Resource{{URI: "file:///README.md", Name: "README.md", MIMEType: "text/markdown"}}},
+		},
+		{
+			method: methodListResourceTemplates,
+			params: func(string) Params { return &ListResourceTemplatesParams{} },
+			result: &ListResourceTemplatesResult{ResourceTemplates: []*ResourceTemplate{{URITemplate: "file:///{name}", Name: "files"}}},
+		},
+		{
+			method: methodReadResource,
+			params: func(string) Params { return &ReadResourceParams{URI: "file:///a.txt"} },
+			result: &ReadResourceResult{Contents: []*ResourceContents{{URI: "file:///a.txt", MIMEType: "text/plain", Text: "contents"}}},
+		},
+		{method: methodSubscribe, params: func(string) Params { return &SubscribeParams{URI: "file:///a.txt"} }, result: &emptyResult{}},
+		{method: methodUnsubscribe, params: func(string) Params { return &UnsubscribeParams{URI: "file:///a.txt"} }, result: &emptyResult{}},
+		{
+			method: methodComplete,
+			params: func(string) Params {
+				return &CompleteParams{
+					Ref:      &CompleteReference{Type: "ref/prompt", Name: "code_review"},
+					Argument: CompleteParamsArgument{Name: "code", Value: "x"},
+				}
+			},
+			result: &CompleteResult{Completion: CompletionResultDetails{Values: []string{"x := 1"}, Total: 1}},
+		},
+		{method: methodSetLevel, params: func(string) Params { return &SetLoggingLevelParams{Level: "info"} }, result: &emptyResult{}},
+		{method: notificationCancelled, params: func(string) Params { return &CancelledParams{RequestID: 1, Reason: "timeout"} }},
+		{
+			method: notificationProgress,
+			params: func(string) Params {
+				return &ProgressNotificationParams{ProgressToken: "t1", Progress: 1, Total: 2, Message: "halfway"}
+			},
+		},
+		{method: notificationRootsListChanged, params: func(string) Params { return &RootsListChangedParams{} }},
+		{method: notificationToolListChanged, params: func(string) Params { return &ToolListChangedParams{} }},
+		{method: notificationPromptListChanged, params: func(string) Params { return &PromptListChangedParams{} }},
+		{method: notificationResourceListChanged, params: func(string) Params { return &ResourceListChangedParams{} }},
+		{
+			method: notificationResourceUpdated,
+			params: func(string) Params { return &ResourceUpdatedNotificationParams{URI: "file:///a.txt"} },
+		},
+		{
+			method: notificationLoggingMessage,
+			params: func(string) Params {
+				return &LoggingMessageParams{Level: "info", Logger: "app", Data: map[string]any{"msg": "started"}}
+			},
+		},
+		{
+			method: methodListRoots,
+			params: func(string) Params { return &ListRootsParams{} },
+			result: &ListRootsResult{Roots: []*Root{{URI: "file:///home", Name: "home"}}},
+		},
+		{
+			method: methodCreateMessage,
+			params: func(string) Params {
+				return &CreateMessageParams{
+					MaxTokens: 100,
+					Messages: []*SamplingMessage{
+						{Role: "user", Content: &TextContent{Text: "hi"}},
+						{Role: "user", Content: &AudioContent{Data: []byte("audio"), MIMEType: "audio/wav"}},
+					},
+					SystemPrompt: "be brief",
+				}
+			},
+			result: &CreateMessageResult{Role: "assistant", Model: "m1", Content: &TextContent{Text: "hello"}, StopReason: "endTurn"},
+		},
+		{
+			method: methodElicit,
+			since:  protocolVersion20250618,
+			params: func(string) Params {
+				return &ElicitParams{
+					Message:         "name?",
+					RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}},
+				}
+			},
+			result: &ElicitResult{Action: "accept", Content: map[string]any{"name": "you"}},
+		},
+	}
+}
+
+// wireMessages returns the encoded request and result messages of e in the
+// given protocol version. The result is nil for notifications.
+func (e wireCorpusEntry) wireMessages(t *testing.T, version string) (req, res []byte) {
+	t.Helper()
+	encode := func(msg jsonrpc.Message) []byte {
+		data, err := jsonrpc.EncodeMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "\t"); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes()
+	}
+	marshal := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	id, err := jsonrpc.MakeID(float64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &jsonrpc.Request{Method: e.method, Params: marshal(downgradeParams(version, e.params(version)))}
+	if e.result == nil {
+		return encode(r), nil
+	}
+	r.ID = id
+	result := e.result
+	if ir, ok := result.(*InitializeResult); ok {
+		ir2 := *ir
+		ir2.ProtocolVersion = version
+		result = &ir2
+	}
+	return encode(r), encode(&jsonrpc.Response{ID: id, Result: marshal(downgradeResult(version, result))})
+}
+
+// TestWireCorpus checks that the messages of the SDK match the wire corpus of
+// the wiretest package. Run with -update-wire to regenerate the corpus.
+func TestWireCorpus(t *testing.T) {
+	entries := wireCorpusEntries()
+
+	// Every method must be covered.
+	var covered []string
+	for _, e := range entries {
+		covered = append(covered, e.method)
+	}
+	all := slices.Sorted(maps.Keys(serverMethodInfos))
+	for m := range clientMethodInfos {
+		if !slices.Contains(all, m) {
+			all = append(all, m)
+		}
+	}
+	for _, m := range all {
+		if !slices.Contains(covered, m) {
+			t.Errorf("method %q is not in the wire corpus", m)
+		}
+	}
+
+	dir := filepath.Join("..", "wiretest", "corpus")
+	for _, version := range supportedProtocolVersions {
+		for _, e := range entries {
+			if e.since != "" && version < e.since {
+				continue
+			}
+			req, res := e.wireMessages(t, version)
+			check := func(kind wiretest.Kind, got []byte) {
+				if *updateWire {
+					file := filepath.Join(dir, version, filepath.FromSlash(e.method), string(kind)+".json")
+					if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(file, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				diff, err := wiretest.Diff(version, e.method, kind, got)
+				if err != nil {
+					t.Errorf("%s %s %s: %v (run with -update-wire?)", version, e.method, kind, err)
+				} else if diff != "" {
+					t.Errorf("%s %s %s mismatch (run with -update-wire?):\n%s", version, e.method, kind, diff)
+				}
+				if bytes.Contains(got, []byte("null")) {
+					t.Errorf("%s %s %s contains JSON null:\n%s", version, e.method, kind, got)
+				}
+			}
+			check(wiretest.Request, req)
+			if res != nil {
+				check(wiretest.Result, res)
+			}
+		}
+	}
+	if *updateWire {
+		return
+	}
+	for _, version := range supportedProtocolVersions {
+		want := 0
+		for _, e := range entries {
+			if e.since == "" || version >= e.since {
+				want++
+			}
+		}
+		if got := len(wiretest.Methods(version)); got != want {
+			t.Errorf("corpus for %s has %d methods, want %d", version, got, want)
+		}
+	}
+	if got, want := strings.Join(wiretest.Versions(), ","), strings.Join(supportedProtocolVersions, ","); got != want {
+		t.Errorf("corpus versions = %s, want %s", got, want)
+	}
+}
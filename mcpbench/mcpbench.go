@@ -0,0 +1,238 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package mcpbench load tests MCP servers.
+//
+// [Run] drives a [Workload] of tool calls from concurrent client sessions
+// against a server, over any transport, and returns a [Report] of latency
+// percentiles and error rates. It can be used to catch performance
+// regressions, for example from a benchmark:
+//
+//	report, err := mcpbench.Run(ctx, func() (mcp.Transport, error) {
+//		return &mcp.StreamableClientTransport{Endpoint: url}, nil
+//	}, &mcpbench.Workload{Sessions: 10, Rate: 50, Duration: 10 * time.Second, Tool: "greet"})
+//
+// See the examples/client/mcpbench command for a command-line interface.
+package mcpbench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/orkhanm/go-sdk/mcp"
+)
+
+// A Workload describes the load to put on a server.
+type Workload struct {
+	// Sessions is the number of concurrent client sessions.
+	// If zero, one session is used.
+	Sessions int
+	// Rate is the number of tool calls per second made by each session.
+	// If zero, each session makes calls one after another, as fast as the
+	// server allows.
+	Rate float64
+	// Duration is how long to run. If zero, it is 10 seconds.
+	Duration time.Duration
+	// Timeout bounds each call. If zero, calls are bounded only by Duration.
+	Timeout time.Duration
+
+	// Tool is the name of the tool to call.
+	Tool string
+	// Arguments are the arguments of each call.
+	Arguments map[string]any
+	// If PayloadSize is positive, each call has an additional string argument
+	// of that many bytes, named by PayloadArgument, or "payload" if that is
+	// empty.
+	PayloadSize     int
+	PayloadArgument string
+}
+
+// A Report describes the result of running a [Workload].
+type Report struct {
+	Duration   time.Duration // how long the workload ran
+	Calls      int           // the number of completed calls
+	Errors     int           // the number of calls that failed with an error
+	ToolErrors int           // the number of calls whose result has IsError set
+
+	latencies []time.Duration // sorted
+}
+
+// Throughput returns the number of completed calls per second.
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Calls) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the fraction of calls that failed, either with an error
+// or with a tool error.
+func (r *Report) ErrorRate() float64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return float64(r.Errors+r.ToolErrors) / float64(r.Calls)
+}
+
+// Percentile returns the latency at the given percentile, between 0 and 100,
+// of the completed calls, or zero if there are none.
+func (r *Report) Percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	// Use the nearest-rank method.
+	i := int(math.Ceil(p/100*float64(len(r.latencies)))) - 1
+	return r.latencies[min(max(i, 0), len(r.latencies)-1)]
+}
+
+// Max returns the highest latency of the completed calls.
+func (r *Report) Max() time.Duration {
+	return r.Percentile(100)
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "duration:   %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "calls:      %d (%.1f/s)\n", r.Calls, r.Throughput())
+	fmt.Fprintf(&b, "errors:     %d errors, %d tool errors (%.2f%%)\n", r.Errors, r.ToolErrors, 100*r.ErrorRate())
+	fmt.Fprintf(&b, "latency:    p50=%s p90=%s p99=%s max=%s\n",
+		r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Max())
+	return b.String()
+}
+
+// Run connects Workload.Sessions client sessions to a server, each using a
+// transport returned by newTransport, and runs the workload. It returns an
+// error if a session cannot be connected. If ctx is done while the workload
+// is running, the workload ends early, and the report covers the calls made
+// until then.
+//
+// Calls that are interrupted because the workload ends are not counted.
+func Run(ctx context.Context, newTransport func() (mcp.Transport, error), w *Workload) (*Report, error) {
+	if w.Tool == "" {
+		return nil, errors.New("mcpbench: no tool")
+	}
+	nSessions := max(w.Sessions, 1)
+	dur := w.Duration
+	if dur <= 0 {
+		dur = 10 * time.Second
+	}
+	args, err := w.arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcpbench", Version: "v1.0.0"}, nil)
+	var sessions []*mcp.ClientSession
+	defer func() {
+		for _, cs := range sessions {
+			cs.Close()
+		}
+	}()
+	for range nSessions {
+		t, err := newTransport()
+		if err != nil {
+			return nil, err
+		}
+		cs, err := client.Connect(ctx, t, nil)
+		if err != nil {
+			return nil, fmt.Errorf("mcpbench: connecting: %w", err)
+		}
+		sessions = append(sessions, cs)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, dur)
+	defer cancel()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		report  Report
+		allLats []time.Duration
+	)
+	start := time.Now()
+	for _, cs := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r Report
+			runSession(runCtx, cs, w, args, &r)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Calls += r.Calls
+			report.Errors += r.Errors
+			report.ToolErrors += r.ToolErrors
+			allLats = append(allLats, r.latencies...)
+		}()
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+	slices.Sort(allLats)
+	report.latencies = allLats
+	return &report, nil
+}
+
+// runSession makes calls on cs until ctx is done, recording them in r.
+func runSession(ctx context.Context, cs *mcp.ClientSession, w *Workload, args json.RawMessage, r *Report) {
+	var tick <-chan time.Time
+	if w.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / w.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if w.Timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, w.Timeout)
+		}
+		start := time.Now()
+		res, err := cs.CallTool(callCtx, &mcp.CallToolParams{Name: w.Tool, Arguments: args})
+		lat := time.Since(start)
+		cancel()
+		if ctx.Err() != nil {
+			return // the workload ended during the call
+		}
+		r.Calls++
+		r.latencies = append(r.latencies, lat)
+		switch {
+		case err != nil:
+			r.Errors++
+		case res.IsError:
+			r.ToolErrors++
+		}
+	}
+}
+
+// arguments returns the marshaled arguments of each call.
+func (w *Workload) arguments() (json.RawMessage, error) {
+	args := make(map[string]any, len(w.Arguments)+1)
+	maps.Copy(args, w.Arguments)
+	if w.PayloadSize > 0 {
+		name := w.PayloadArgument
+		if name == "" {
+			name = "payload"
+		}
+		args[name] = strings.Repeat("x", w.PayloadSize)
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("mcpbench: marshaling arguments: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcpbench_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/orkhanm/go-sdk/mcp"
+	"github.com/orkhanm/go-sdk/mcpbench"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "v1.0.0"}, nil)
+	var calls atomic.Int32
+	type args struct {
+		Fail    bool   `json:"fail"`
+		Payload string `json:"payload"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in args) (*mcp.CallToolResult, any, error) {
+		if len(in.Payload) != 100 {
+			t.Errorf("got payload of %d bytes, want 100", len(in.Payload))
+		}
+		// Fail every other call.
+		return &mcp.CallToolResult{IsError: in.Fail && calls.Add(1)%2 == 0}, nil, nil
+	})
+	newTransport := func() (mcp.Transport, error) {
+		ct, st := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, st, nil); err != nil {
+			return nil, err
+		}
+		return ct, nil
+	}
+
+	for _, test := range []struct {
+		name     string
+		fail     bool
+		wantRate float64
+	}{
+		{"ok", false, 0},
+		{"tool errors", true, 0.5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := mcpbench.Run(ctx, newTransport, &mcpbench.Workload{
+				Sessions:    3,
+				Rate:        100,
+				Duration:    200 * time.Millisecond,
+				Tool:        "echo",
+				Arguments:   map[string]any{"fail": test.fail},
+				PayloadSize: 100,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 3 sessions at 100 calls/s for 0.2s make about 60 calls.
+			if report.Calls < 10 || report.Calls > 70 {
+				t.Errorf("got %d calls, want about 60", report.Calls)
+			}
+			if report.Errors != 0 {
+				t.Errorf("got %d errors, want 0", report.Errors)
+			}
+			if got := report.ErrorRate(); got < test.wantRate-0.1 || got > test.wantRate+0.1 {
+				t.Errorf("got error rate %.2f, want about %.2f", got, test.wantRate)
+			}
+			p50, p99 := report.Percentile(50), report.Percentile(99)
+			if p50 <= 0 || p99 < p50 || report.Max() < p99 {
+				t.Errorf("bad percentiles: p50=%s p99=%s max=%s", p50, p99, report.Max())
+			}
+		})
+	}
+
+	// Calls of a missing tool fail with an error.
+	report, err := mcpbench.Run(ctx, newTransport, &mcpbench.Workload{
+		Duration: 100 * time.Millisecond,
+		Tool:     "missing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Calls == 0 || report.Errors != report.Calls || report.ErrorRate() != 1 {
+		t.Errorf("missing tool: got %d calls, %d errors", report.Calls, report.Errors)
+	}
+}
@@ -126,6 +126,10 @@ var wellKnownPaths = []string{
 //
 // [RFC 8414]: https://tools.ietf.org/html/rfc8414
 func GetAuthServerMeta(ctx context.Context, issuerURL string, c *http.Client) (*AuthServerMeta, error) {
+	return getAuthServerMeta(ctx, issuerURL, c, nil)
+}
+
+func getAuthServerMeta(ctx context.Context, issuerURL string, c *http.Client, mc *MetadataCache) (*AuthServerMeta, error) {
 	var errs []error
 	for _, p := range wellKnownPaths {
 		u, err := prependToPath(issuerURL, p)
@@ -133,7 +137,7 @@ func GetAuthServerMeta(ctx context.Context, issuerURL string, c *http.Client) (*
 			// issuerURL is bad; no point in continuing.
 			return nil, err
 		}
-		asm, err := getJSON[AuthServerMeta](ctx, c, u, 1<<20)
+		asm, err := getJSON[AuthServerMeta](ctx, c, u, 1<<20, mc)
 		if err == nil {
 			if asm.Issuer != issuerURL { // section 3.3
 				// Security violation; don't keep trying.
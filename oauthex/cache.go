@@ -0,0 +1,159 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/orkhanm/go-sdk/internal/util"
+)
+
+// A MetadataCache caches authorization server and protected resource
+// metadata, so that clients need not fetch it again for every authorization.
+//
+// Responses are cached for the lifetime permitted by their Cache-Control
+// (or Expires) headers, but never longer than the cache's TTL. Responses
+// marked no-store or no-cache are not cached. Failed fetches are never cached.
+//
+// Cached documents are validated on every lookup, exactly as fresh ones are.
+//
+// A MetadataCache is safe for concurrent use by multiple goroutines.
+type MetadataCache struct {
+	ttl time.Duration
+	now func() time.Time // for testing
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry // keyed by URL
+}
+
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMetadataCache returns a new [*MetadataCache] whose entries live for at most
+// ttl. If ttl is zero, it defaults to one hour.
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	return &MetadataCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// GetAuthServerMeta is like the package-level [GetAuthServerMeta], but uses
+// the cache.
+func (mc *MetadataCache) GetAuthServerMeta(ctx context.Context, issuerURL string, c *http.Client) (*AuthServerMeta, error) {
+	return getAuthServerMeta(ctx, issuerURL, c, mc)
+}
+
+// GetProtectedResourceMetadata is like the package-level
+// [GetProtectedResourceMetadata], but uses the cache.
+func (mc *MetadataCache) GetProtectedResourceMetadata(ctx context.Context, metadataURL, resourceID string, c *http.Client) (_ *ProtectedResourceMetadata, err error) {
+	defer util.Wrapf(&err, "GetProtectedResourceMetadata(%q)", metadataURL)
+	return getPRM(ctx, metadataURL, c, resourceID, mc)
+}
+
+// GetProtectedResourceMetadataFromID is like the package-level
+// [GetProtectedResourceMetadataFromID], but uses the cache.
+func (mc *MetadataCache) GetProtectedResourceMetadataFromID(ctx context.Context, resourceID string, c *http.Client) (*ProtectedResourceMetadata, error) {
+	return getPRMFromID(ctx, resourceID, c, mc)
+}
+
+// GetProtectedResourceMetadataFromHeader is like the package-level
+// [GetProtectedResourceMetadataFromHeader], but uses the cache.
+func (mc *MetadataCache) GetProtectedResourceMetadataFromHeader(ctx context.Context, serverURL string, header http.Header, c *http.Client) (*ProtectedResourceMetadata, error) {
+	return getPRMFromHeader(ctx, serverURL, header, c, mc)
+}
+
+// Clear removes all entries from the cache.
+func (mc *MetadataCache) Clear() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	clear(mc.entries)
+}
+
+// get returns the cached body for url, if there is an unexpired one.
+// It may be called on a nil cache.
+func (mc *MetadataCache) get(url string) ([]byte, bool) {
+	if mc == nil {
+		return nil, false
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	e, ok := mc.entries[url]
+	if !ok {
+		return nil, false
+	}
+	if !mc.now().Before(e.expires) {
+		delete(mc.entries, url)
+		return nil, false
+	}
+	return e.data, true
+}
+
+// put caches data, the body of a response for url with the given headers.
+// It may be called on a nil cache.
+func (mc *MetadataCache) put(url string, data []byte, h http.Header) {
+	if mc == nil {
+		return
+	}
+	now := mc.now()
+	lifetime, ok := freshnessLifetime(h, now)
+	if !ok {
+		lifetime = mc.ttl
+	}
+	lifetime = min(lifetime, mc.ttl)
+	if lifetime <= 0 {
+		return
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.entries[url] = cacheEntry{data: data, expires: now.Add(lifetime)}
+}
+
+// freshnessLifetime returns how long a response with the given headers may be
+// reused, following RFC 9111 section 4.2.1. It reports false if the headers do
+// not say.
+func freshnessLifetime(h http.Header, now time.Time) (time.Duration, bool) {
+	var (
+		maxAge    time.Duration
+		hasMaxAge bool
+	)
+	for _, cc := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(cc, ",") {
+			name, val, _ := strings.Cut(strings.TrimSpace(d), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache":
+				return 0, true
+			case "max-age":
+				// An invalid max-age is treated as stale (section 4.2.1), which
+				// the zero value of maxAge accomplishes.
+				secs, _ := strconv.Atoi(strings.Trim(val, `"`))
+				maxAge = time.Duration(secs) * time.Second
+				hasMaxAge = true
+			}
+		}
+	}
+	if hasMaxAge {
+		return maxAge, true
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		// An invalid date is also treated as stale.
+		t, _ := http.ParseTime(exp)
+		return t.Sub(now), true
+	}
+	return 0, false
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetadataCache(t *testing.T) {
+	ctx := context.Background()
+	var (
+		fetches      int
+		cacheControl string
+	)
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "application/json")
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		json.NewEncoder(w).Encode(&ProtectedResourceMetadata{Resource: server.URL})
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		cacheControl string
+		advance      time.Duration // time between the two fetches
+		wantFetches  int
+	}{
+		{"", 0, 1},
+		{"", 2 * time.Hour, 2}, // beyond the TTL
+		{"max-age=60", 30 * time.Second, 1},
+		{"max-age=60", 90 * time.Second, 2},
+		{"public, max-age=86400", 2 * time.Hour, 2}, // TTL caps max-age
+		{"no-store", 0, 2},
+		{"no-cache", 0, 2},
+	} {
+		fetches = 0
+		cacheControl = tt.cacheControl
+		now := time.Now()
+		mc := NewMetadataCache(time.Hour)
+		mc.now = func() time.Time { return now }
+		for range 2 {
+			prm, err := mc.GetProtectedResourceMetadataFromID(ctx, server.URL, server.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prm.Resource != server.URL {
+				t.Errorf("got resource %q, want %q", prm.Resource, server.URL)
+			}
+			now = now.Add(tt.advance)
+		}
+		if fetches != tt.wantFetches {
+			t.Errorf("Cache-Control %q, advance %s: got %d fetches, want %d", tt.cacheControl, tt.advance, fetches, tt.wantFetches)
+		}
+	}
+}
@@ -38,7 +38,15 @@ func prependToPath(urlStr, pre string) (string, error) {
 // getJSON retrieves JSON and unmarshals JSON from the URL, as specified in both
 // RFC 9728 and RFC 8414.
 // It will not read more than limit bytes from the body.
-func getJSON[T any](ctx context.Context, c *http.Client, url string, limit int64) (*T, error) {
+// If mc is non-nil, the response body is cached there.
+func getJSON[T any](ctx context.Context, c *http.Client, url string, limit int64, mc *MetadataCache) (*T, error) {
+	if data, ok := mc.get(url); ok {
+		var t T
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -61,11 +69,16 @@ func getJSON[T any](ctx context.Context, c *http.Client, url string, limit int64
 		return nil, fmt.Errorf("bad content type %q", ct)
 	}
 
-	var t T
+	var raw json.RawMessage
 	dec := json.NewDecoder(io.LimitReader(res.Body, limit))
-	if err := dec.Decode(&t); err != nil {
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var t T
+	if err := json.Unmarshal(raw, &t); err != nil {
 		return nil, err
 	}
+	mc.put(url, raw, res.Header)
 	return &t, nil
 }
 
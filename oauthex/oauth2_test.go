@@ -318,3 +318,30 @@ func (h *fakeResourceHandler) installHandlers(serverURL string) {
 		}
 	}))
 }
+
+func TestBearerChallengeRoundTrip(t *testing.T) {
+	c := &BearerChallenge{
+		Realm:            "example",
+		ResourceMetadata: "https://example.com/.well-known/oauth-protected-resource",
+		Scope:            []string{"read", "write"},
+		Error:            "invalid_token",
+		ErrorDescription: `token "expired", sorry`,
+	}
+	cs, err := ParseWWWAuthenticate([]string{c.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []challenge{{
+		Scheme: "bearer",
+		Params: map[string]string{
+			"realm":             c.Realm,
+			"resource_metadata": c.ResourceMetadata,
+			"scope":             "read write",
+			"error":             c.Error,
+			"error_description": c.ErrorDescription,
+		},
+	}}
+	if !reflect.DeepEqual(cs, want) {
+		t.Errorf("got %v, want %v", cs, want)
+	}
+}
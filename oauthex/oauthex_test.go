@@ -8,8 +8,6 @@ package oauthex
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -67,7 +65,11 @@ func (s *state) handleMCP(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil || !token.Valid {
 		metadataURL := getBaseURL(r) + "/.well-known/oauth-protected-resource"
-		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s", scope="openid profile email"`, metadataURL))
+		challenge := &BearerChallenge{
+			ResourceMetadata: metadataURL,
+			Scope:            []string{"openid", "profile", "email"},
+		}
+		w.Header().Set("WWW-Authenticate", challenge.String())
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -174,10 +176,7 @@ func (s *state) handleToken(w http.ResponseWriter, r *http.Request) {
 	s.mu.Unlock()
 
 	// PKCE verification.
-	hasher := sha256.New()
-	hasher.Write([]byte(codeVerifier))
-	calculatedChallenge := base64.RawURLEncoding.EncodeToString(hasher.Sum(nil))
-	if calculatedChallenge != authCodeInfo.codeChallenge {
+	if !VerifyS256Challenge(codeVerifier, authCodeInfo.codeChallenge) {
 		http.Error(w, "invalid_grant", http.StatusBadRequest)
 		return
 	}
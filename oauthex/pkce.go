@@ -0,0 +1,84 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// This file implements Proof Key for Code Exchange (PKCE).
+// See https://www.rfc-editor.org/rfc/rfc7636.html.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+)
+
+// GenerateVerifier returns a new PKCE code verifier, as described in section
+// 4.1 of RFC 7636. It is the base64url encoding of 32 random bytes.
+func GenerateVerifier() string {
+	return randomString(32)
+}
+
+// S256Challenge returns the S256 code challenge for verifier, as described
+// in section 4.2 of RFC 7636.
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// CheckVerifier reports an error if verifier is not a syntactically valid
+// code verifier: between 43 and 128 characters, each an unreserved URI
+// character.
+func CheckVerifier(verifier string) error {
+	if n := len(verifier); n < 43 || n > 128 {
+		return fmt.Errorf("code verifier has length %d, want between 43 and 128", n)
+	}
+	for _, c := range []byte(verifier) {
+		if !isUnreserved(c) {
+			return fmt.Errorf("code verifier contains invalid character %q", c)
+		}
+	}
+	return nil
+}
+
+// VerifyS256Challenge reports whether verifier matches the S256 code
+// challenge. An authorization server uses it to check the code verifier
+// presented in a token request (RFC 7636, section 4.6).
+func VerifyS256Challenge(verifier, challenge string) bool {
+	if CheckVerifier(verifier) != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(S256Challenge(verifier)), []byte(challenge)) == 1
+}
+
+// GenerateState returns a new value for the OAuth state parameter, which a
+// client uses to bind an authorization response to its request (RFC 6749,
+// section 10.12).
+func GenerateState() string {
+	return randomString(32)
+}
+
+// CheckState reports whether the state parameter of an authorization
+// response matches the state that was sent in the request.
+func CheckState(got, want string) bool {
+	return want != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// randomString returns the base64url encoding of n random bytes.
+func randomString(n int) string {
+	b := make([]byte, n)
+	// Failing to read random bytes is not recoverable.
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPKCE(t *testing.T) {
+	// Example from RFC 7636, Appendix B.
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+	if got := S256Challenge(verifier); got != challenge {
+		t.Errorf("S256Challenge = %q, want %q", got, challenge)
+	}
+	if !VerifyS256Challenge(verifier, challenge) {
+		t.Error("VerifyS256Challenge with matching verifier = false, want true")
+	}
+	if VerifyS256Challenge(verifier, S256Challenge(GenerateVerifier())) {
+		t.Error("VerifyS256Challenge with wrong verifier = true, want false")
+	}
+
+	v := GenerateVerifier()
+	if err := CheckVerifier(v); err != nil {
+		t.Errorf("CheckVerifier(GenerateVerifier()): %v", err)
+	}
+	if v == GenerateVerifier() {
+		t.Error("GenerateVerifier returned the same value twice")
+	}
+	for _, bad := range []string{"short", strings.Repeat("a", 129), strings.Repeat("a", 42) + "+"} {
+		if err := CheckVerifier(bad); err == nil {
+			t.Errorf("CheckVerifier(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	s := GenerateState()
+	if !CheckState(s, s) {
+		t.Error("CheckState(s, s) = false, want true")
+	}
+	if CheckState(GenerateState(), s) {
+		t.Error("CheckState with different state = true, want false")
+	}
+	if CheckState("", "") {
+		t.Error(`CheckState("", "") = true, want false`)
+	}
+}
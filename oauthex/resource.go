@@ -0,0 +1,70 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// This file implements Resource Indicators.
+// See https://www.rfc-editor.org/rfc/rfc8707.html.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// CanonicalResourceURI returns the canonical form of a resource URI, suitable
+// for use as a resource indicator.
+//
+// As required by RFC 8707 section 2, the URI must be absolute. The fragment is
+// removed, and the scheme and host are lower-cased.
+func CanonicalResourceURI(resource string) (string, error) {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("resource %q is not an absolute URI", resource)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// ResourceIndicator returns the resource indicator that a client should send
+// in authorization and token requests for the protected resource at
+// resourceURL.
+//
+// The canonical form of resourceURL is used. If prm is non-nil, its resource
+// identifier must be the same, after canonicalization, as RFC 9728 section 3.3
+// requires of metadata retrieved for resourceURL.
+func ResourceIndicator(resourceURL string, prm *ProtectedResourceMetadata) (string, error) {
+	canon, err := CanonicalResourceURI(resourceURL)
+	if err != nil {
+		return "", err
+	}
+	if prm == nil || prm.Resource == "" {
+		return canon, nil
+	}
+	res, err := CanonicalResourceURI(prm.Resource)
+	if err != nil {
+		return "", err
+	}
+	if canon != res {
+		return "", fmt.Errorf("metadata resource %q does not match %q", prm.Resource, resourceURL)
+	}
+	return canon, nil
+}
+
+// ResourceOption returns an [oauth2.AuthCodeOption] that adds the resource
+// parameter to a request. It can be passed to both [oauth2.Config.AuthCodeURL]
+// and [oauth2.Config.Exchange], as RFC 8707 requires.
+func ResourceOption(resource string) oauth2.AuthCodeOption {
+	return oauth2.SetAuthURLParam("resource", resource)
+}
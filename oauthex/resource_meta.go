@@ -38,7 +38,11 @@ const defaultProtectedResourceMetadataURI = "/.well-known/oauth-protected-resour
 //
 // It then retrieves the metadata at that location using the given client (or the
 // default client if nil) and validates its resource field against resourceID.
-func GetProtectedResourceMetadataFromID(ctx context.Context, resourceID string, c *http.Client) (_ *ProtectedResourceMetadata, err error) {
+func GetProtectedResourceMetadataFromID(ctx context.Context, resourceID string, c *http.Client) (*ProtectedResourceMetadata, error) {
+	return getPRMFromID(ctx, resourceID, c, nil)
+}
+
+func getPRMFromID(ctx context.Context, resourceID string, c *http.Client, mc *MetadataCache) (_ *ProtectedResourceMetadata, err error) {
 	defer util.Wrapf(&err, "GetProtectedResourceMetadataFromID(%q)", resourceID)
 
 	u, err := url.Parse(resourceID)
@@ -47,7 +51,7 @@ func GetProtectedResourceMetadataFromID(ctx context.Context, resourceID string,
 	}
 	// Insert well-known URI into URL.
 	u.Path = path.Join(defaultProtectedResourceMetadataURI, u.Path)
-	return getPRM(ctx, u.String(), c, resourceID)
+	return getPRM(ctx, u.String(), c, resourceID, mc)
 }
 
 // GetProtectedResourceMetadataFromHeader retrieves protected resource metadata
@@ -57,7 +61,11 @@ func GetProtectedResourceMetadataFromID(ctx context.Context, resourceID string,
 // Per RFC 9728 section 3.3, it validates that the resource field of the resulting metadata
 // matches the serverURL (the URL that the client used to make the original request to the resource server).
 // If there is no metadata URL in the header, it returns nil, nil.
-func GetProtectedResourceMetadataFromHeader(ctx context.Context, serverURL string, header http.Header, c *http.Client) (_ *ProtectedResourceMetadata, err error) {
+func GetProtectedResourceMetadataFromHeader(ctx context.Context, serverURL string, header http.Header, c *http.Client) (*ProtectedResourceMetadata, error) {
+	return getPRMFromHeader(ctx, serverURL, header, c, nil)
+}
+
+func getPRMFromHeader(ctx context.Context, serverURL string, header http.Header, c *http.Client, mc *MetadataCache) (_ *ProtectedResourceMetadata, err error) {
 	defer util.Wrapf(&err, "GetProtectedResourceMetadataFromHeader")
 	headers := header[http.CanonicalHeaderKey("WWW-Authenticate")]
 	if len(headers) == 0 {
@@ -71,16 +79,26 @@ func GetProtectedResourceMetadataFromHeader(ctx context.Context, serverURL strin
 	if metadataURL == "" {
 		return nil, nil
 	}
-	return getPRM(ctx, metadataURL, c, serverURL)
+	return getPRM(ctx, metadataURL, c, serverURL, mc)
+}
+
+// GetProtectedResourceMetadata retrieves protected resource metadata from
+// metadataURL, using the given client (or the default client if nil).
+// Per RFC 9728 section 3.3, it validates that the resource field of the
+// resulting metadata matches resourceID.
+func GetProtectedResourceMetadata(ctx context.Context, metadataURL, resourceID string, c *http.Client) (_ *ProtectedResourceMetadata, err error) {
+	defer util.Wrapf(&err, "GetProtectedResourceMetadata(%q)", metadataURL)
+	return getPRM(ctx, metadataURL, c, resourceID, nil)
 }
 
 // getPRM makes a GET request to the given URL, and validates the response.
 // As part of the validation, it compares the returned resource field to wantResource.
-func getPRM(ctx context.Context, purl string, c *http.Client, wantResource string) (*ProtectedResourceMetadata, error) {
+// If mc is non-nil, it is used to cache the response.
+func getPRM(ctx context.Context, purl string, c *http.Client, wantResource string, mc *MetadataCache) (*ProtectedResourceMetadata, error) {
 	if !strings.HasPrefix(strings.ToUpper(purl), "HTTPS://") {
 		return nil, fmt.Errorf("resource URL %q does not use HTTPS", purl)
 	}
-	prm, err := getJSON[ProtectedResourceMetadata](ctx, c, purl, 1<<20)
+	prm, err := getJSON[ProtectedResourceMetadata](ctx, c, purl, 1<<20, mc)
 	if err != nil {
 		return nil, err
 	}
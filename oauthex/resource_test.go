@@ -0,0 +1,58 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestResourceIndicator(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		resourceURL string
+		prmResource string // if empty, no metadata
+		want        string
+		wantErr     bool
+	}{
+		{"no metadata", "HTTPS://MCP.Example.com/mcp#frag", "", "https://mcp.example.com/mcp", false},
+		{"relative", "/mcp", "", "", true},
+		{"same as metadata", "https://mcp.example.com/mcp", "https://mcp.example.com/mcp", "https://mcp.example.com/mcp", false},
+		{"same as metadata after canonicalization", "HTTPS://MCP.Example.com/mcp", "https://mcp.example.com/mcp#f", "https://mcp.example.com/mcp", false},
+		{"below metadata", "https://mcp.example.com/mcp/v1", "https://mcp.example.com/mcp", "", true},
+		{"host metadata", "https://mcp.example.com/mcp", "https://mcp.example.com/", "", true},
+		{"sibling path", "https://mcp.example.com/mcpx", "https://mcp.example.com/mcp", "", true},
+		{"other host", "https://mcp.example.com/mcp", "https://other.example.com/mcp", "", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var prm *ProtectedResourceMetadata
+			if tt.prmResource != "" {
+				prm = &ProtectedResourceMetadata{Resource: tt.prmResource}
+			}
+			got, err := ResourceIndicator(tt.resourceURL, prm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceOption(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "c", Endpoint: oauth2.Endpoint{AuthURL: "https://as.example.com/authorize"}}
+	u, err := url.Parse(cfg.AuthCodeURL("s", ResourceOption("https://mcp.example.com/mcp")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.Query().Get("resource"), "https://mcp.example.com/mcp"; got != want {
+		t.Errorf("resource = %q, want %q", got, want)
+	}
+}
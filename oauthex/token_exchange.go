@@ -0,0 +1,185 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// This file implements OAuth 2.0 Token Exchange.
+// See https://www.rfc-editor.org/rfc/rfc8693.html.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Token type identifiers, from section 3 of RFC 8693.
+const (
+	TokenTypeAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+	TokenTypeIDToken      = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJWT          = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+const grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// TokenExchangeRequest describes a token exchange request (RFC 8693, section 2.1).
+//
+// A typical use is an MCP gateway that receives a request bearing a user's
+// access token, and needs a token for an upstream server on that user's
+// behalf: the inbound token is the SubjectToken, and the upstream server is
+// the Resource.
+type TokenExchangeRequest struct {
+	// ClientID and ClientSecret authenticate the client making the request.
+	// If ClientSecret is set, they are sent using HTTP Basic authentication;
+	// otherwise ClientID, if set, is sent in the request body.
+	ClientID     string
+	ClientSecret string
+
+	// SubjectToken is the REQUIRED token representing the party on whose
+	// behalf the request is made.
+	SubjectToken string
+	// SubjectTokenType is the type of SubjectToken.
+	// If empty, it defaults to [TokenTypeAccessToken].
+	SubjectTokenType string
+
+	// ActorToken is an OPTIONAL token representing the acting party.
+	ActorToken string
+	// ActorTokenType is the type of ActorToken. It is required if ActorToken
+	// is set.
+	ActorTokenType string
+
+	// RequestedTokenType is an OPTIONAL identifier for the type of the
+	// requested token.
+	RequestedTokenType string
+
+	// Resource holds OPTIONAL URIs of the services where the issued token is
+	// intended to be used (see also RFC 8707).
+	Resource []string
+	// Audience holds OPTIONAL logical names of the services where the issued
+	// token is intended to be used.
+	Audience []string
+	// Scopes are the OPTIONAL scopes of the requested token.
+	Scopes []string
+}
+
+// ExchangeToken performs a token exchange at the given token endpoint, using
+// the given client (or the default client if nil).
+//
+// The issued token type reported by the server is available from the result
+// as Extra("issued_token_type"). On an error response from the server, the
+// error is an [*oauth2.RetrieveError].
+func ExchangeToken(ctx context.Context, tokenEndpoint string, treq *TokenExchangeRequest, c *http.Client) (*oauth2.Token, error) {
+	if treq.SubjectToken == "" {
+		return nil, errors.New("token exchange requires a subject token")
+	}
+	if treq.ActorToken != "" && treq.ActorTokenType == "" {
+		return nil, errors.New("token exchange with an actor token requires an actor token type")
+	}
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	form := url.Values{
+		"grant_type":         {grantTypeTokenExchange},
+		"subject_token":      {treq.SubjectToken},
+		"subject_token_type": {treq.SubjectTokenType},
+	}
+	if treq.SubjectTokenType == "" {
+		form.Set("subject_token_type", TokenTypeAccessToken)
+	}
+	setIf := func(key, val string) {
+		if val != "" {
+			form.Set(key, val)
+		}
+	}
+	setIf("actor_token", treq.ActorToken)
+	setIf("actor_token_type", treq.ActorTokenType)
+	setIf("requested_token_type", treq.RequestedTokenType)
+	setIf("scope", strings.Join(treq.Scopes, " "))
+	for _, r := range treq.Resource {
+		form.Add("resource", r)
+	}
+	for _, a := range treq.Audience {
+		form.Add("audience", a)
+	}
+	if treq.ClientSecret == "" {
+		setIf("client_id", treq.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if treq.ClientSecret != "" {
+		// RFC 6749, section 2.3.1 requires form-encoding the credentials.
+		req.SetBasicAuth(url.QueryEscape(treq.ClientID), url.QueryEscape(treq.ClientSecret))
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token exchange response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		rerr := &oauth2.RetrieveError{Response: resp, Body: body}
+		var e struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			ErrorURI         string `json:"error_uri"`
+		}
+		if json.Unmarshal(body, &e) == nil {
+			rerr.ErrorCode = e.Error
+			rerr.ErrorDescription = e.ErrorDescription
+			rerr.ErrorURI = e.ErrorURI
+		}
+		return nil, rerr
+	}
+
+	// Section 2.2.1.
+	var tr struct {
+		AccessToken     string `json:"access_token"`
+		IssuedTokenType string `json:"issued_token_type"`
+		TokenType       string `json:"token_type"`
+		ExpiresIn       int64  `json:"expires_in"`
+		RefreshToken    string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("failed to decode token exchange response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("token exchange response is missing required 'access_token' field")
+	}
+	if tr.IssuedTokenType == "" {
+		return nil, errors.New("token exchange response is missing required 'issued_token_type' field")
+	}
+	var raw map[string]any
+	json.Unmarshal(body, &raw) // already known to be valid JSON
+	tok := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+		tok.ExpiresIn = tr.ExpiresIn
+	}
+	return tok.WithExtra(raw), nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestExchangeToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "gateway" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.Form.Get("grant_type") != grantTypeTokenExchange ||
+			r.Form.Get("subject_token_type") != TokenTypeAccessToken ||
+			!slices.Equal(r.Form["resource"], []string{"https://upstream.example.com/mcp"}) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request"})
+			return
+		}
+		if r.Form.Get("subject_token") != "user-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "bad subject"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "downstream-token",
+			"issued_token_type": TokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        60,
+		})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	treq := &TokenExchangeRequest{
+		ClientID:     "gateway",
+		ClientSecret: "s3cret",
+		SubjectToken: "user-token",
+		Resource:     []string{"https://upstream.example.com/mcp"},
+	}
+	tok, err := ExchangeToken(ctx, server.URL, treq, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "downstream-token" || tok.Expiry.IsZero() {
+		t.Errorf("got token %+v", tok)
+	}
+	if got := tok.Extra("issued_token_type"); got != TokenTypeAccessToken {
+		t.Errorf("issued_token_type = %v, want %q", got, TokenTypeAccessToken)
+	}
+
+	treq.SubjectToken = "other"
+	_, err = ExchangeToken(ctx, server.URL, treq, nil)
+	var rerr *oauth2.RetrieveError
+	if !errors.As(err, &rerr) || rerr.ErrorCode != "invalid_grant" || rerr.ErrorDescription != "bad subject" {
+		t.Errorf("got error %v, want invalid_grant RetrieveError", err)
+	}
+
+	if _, err := ExchangeToken(ctx, server.URL, &TokenExchangeRequest{}, nil); err == nil {
+		t.Error("got nil error with no subject token")
+	}
+}
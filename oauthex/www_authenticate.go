@@ -0,0 +1,76 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package oauthex
+
+import (
+	"strings"
+)
+
+// A BearerChallenge is a challenge for the Bearer authentication scheme, as
+// sent in a WWW-Authenticate header by a protected resource
+// (RFC 6750, section 3). Its String method formats it as a header value; see
+// [ParseWWWAuthenticate] for the inverse.
+type BearerChallenge struct {
+	// Realm is the OPTIONAL scope of protection.
+	Realm string
+	// ResourceMetadata is the URL of the protected resource metadata
+	// (RFC 9728, section 5.1).
+	ResourceMetadata string
+	// Scope holds the scopes necessary to access the resource.
+	Scope []string
+	// Error is the error code, such as "invalid_token" or "insufficient_scope".
+	// It should be empty if the request had no authentication information.
+	Error string
+	// ErrorDescription is a human-readable explanation of the error.
+	ErrorDescription string
+	// ErrorURI is the URI of a web page explaining the error.
+	ErrorURI string
+}
+
+// String returns c formatted as a WWW-Authenticate header value.
+// Parameters are omitted if empty. All values are sent as quoted strings,
+// escaped as required by RFC 9110, section 5.6.4.
+func (c *BearerChallenge) String() string {
+	var b strings.Builder
+	b.WriteString("Bearer")
+	sep := " "
+	param := func(name, value string) {
+		if value == "" {
+			return
+		}
+		b.WriteString(sep)
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(quoteString(value))
+		sep = ", "
+	}
+	param("realm", c.Realm)
+	param("resource_metadata", c.ResourceMetadata)
+	param("scope", strings.Join(c.Scope, " "))
+	param("error", c.Error)
+	param("error_description", c.ErrorDescription)
+	param("error_uri", c.ErrorURI)
+	return b.String()
+}
+
+// quoteString returns s as an HTTP quoted-string.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r', '\n':
+			// Not allowed in a header value, even escaped.
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
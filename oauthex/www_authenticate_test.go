@@ -0,0 +1,32 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package oauthex
+
+import "testing"
+
+func TestBearerChallengeString(t *testing.T) {
+	for _, tt := range []struct {
+		c    BearerChallenge
+		want string
+	}{
+		{BearerChallenge{}, `Bearer`},
+		{
+			BearerChallenge{ResourceMetadata: "https://example.com/.well-known/oauth-protected-resource"},
+			`Bearer resource_metadata="https://example.com/.well-known/oauth-protected-resource"`,
+		},
+		{
+			BearerChallenge{Realm: "example", Scope: []string{"read", "write"}, Error: "insufficient_scope"},
+			`Bearer realm="example", scope="read write", error="insufficient_scope"`,
+		},
+		{
+			BearerChallenge{Error: "invalid_token", ErrorDescription: `bad "token" \ here` + "\n"},
+			`Bearer error="invalid_token", error_description="bad \"token\" \\ here "`,
+		},
+	} {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("%+v.String() =\n%s\nwant\n%s", tt.c, got, tt.want)
+		}
+	}
+}
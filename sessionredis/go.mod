@@ -0,0 +1,21 @@
+module github.com/orkhanm/go-sdk/sessionredis
+
+go 1.23.0
+
+require (
+	github.com/alicebob/miniredis/v2 v2.35.0
+	github.com/google/go-cmp v0.7.0
+	github.com/orkhanm/go-sdk v0.0.0
+	github.com/redis/go-redis/v9 v9.17.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/google/jsonschema-go v0.3.0 // indirect
+	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/oauth2 v0.30.0 // indirect
+)
+
+replace github.com/orkhanm/go-sdk => ../
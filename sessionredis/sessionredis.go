@@ -0,0 +1,278 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package sessionredis implements [mcp.SessionStore] on Redis, so that
+// several instances of a server behind a load balancer can share the sessions
+// of their [mcp.StreamableHTTPHandler]s.
+//
+//	store := sessionredis.New(redis.NewClient(&redis.Options{Addr: "localhost:6379"}), nil)
+//	handler := mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+//		SessionStore: store,
+//	})
+//
+// Each session is stored in a Redis hash holding the JSON encoding of its
+// [mcp.StoredSessionInfo], optionally encrypted (see [Options.Encrypter]),
+// and, in a separate field, its reference count, so that [Store.UpdateRefs]
+// can update the count atomically without decoding the session. Session TTLs
+// are Redis key expirations.
+//
+// A Store also implements [mcp.SessionLocker], so that
+// [mcp.StreamableHTTPHandler.SessionLock] excludes all instances sharing the
+// store.
+package sessionredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/orkhanm/go-sdk/mcp"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is the default prefix of the Redis keys used by a [Store].
+const DefaultPrefix = "mcp:session:"
+
+// Options configures a [Store].
+type Options struct {
+	// Prefix is prepended to the Redis keys of sessions and session locks,
+	// to namespace them. If empty, [DefaultPrefix] is used.
+	Prefix string
+	// If non-nil, Encrypter encrypts the session data before it is written
+	// to Redis, and decrypts it when it is read, so that the client details
+	// it holds are not readable by everyone with access to Redis. For example,
+	// use [mcp.NewAESSessionEncrypter]. Reference counts and locks are not
+	// encrypted.
+	Encrypter mcp.SessionEncrypter
+}
+
+// A Store is an [mcp.SessionStore] and [mcp.SessionLocker] backed by Redis.
+// It is safe for concurrent use.
+type Store struct {
+	client    redis.UniversalClient
+	prefix    string
+	encrypter mcp.SessionEncrypter // may be nil
+}
+
+var (
+	_ mcp.SessionStore  = (*Store)(nil)
+	_ mcp.SessionLocker = (*Store)(nil)
+)
+
+// New returns a Store that keeps sessions in Redis using the given client,
+// which may be a [redis.Client], a [redis.ClusterClient] or any other
+// [redis.UniversalClient]. If opts is nil, default options are used.
+//
+// The caller remains responsible for closing the client.
+func New(client redis.UniversalClient, opts *Options) *Store {
+	s := &Store{client: client, prefix: DefaultPrefix}
+	if opts != nil {
+		if opts.Prefix != "" {
+			s.prefix = opts.Prefix
+		}
+		s.encrypter = opts.Encrypter
+	}
+	return s
+}
+
+// Fields of the session hash.
+const (
+	fieldInfo = "info"
+	fieldRefs = "refs"
+)
+
+func (s *Store) key(sessionID string) string {
+	return s.prefix + sessionID
+}
+
+func (s *Store) lockKey(sessionID string) string {
+	return s.prefix + "lock:" + sessionID
+}
+
+// Get implements [mcp.SessionStore.Get].
+func (s *Store) Get(ctx context.Context, sessionID string) (*mcp.StoredSessionInfo, error) {
+	vals, err := s.client.HMGet(ctx, s.key(sessionID), fieldInfo, fieldRefs).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis get session: %w", err)
+	}
+	data, ok := vals[0].(string)
+	if !ok {
+		return nil, mcp.ErrSessionNotFound
+	}
+	raw := []byte(data)
+	if s.encrypter != nil {
+		raw, err = s.encrypter.Decrypt(ctx, sessionID, raw)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting session: %w", err)
+		}
+	}
+	var info mcp.StoredSessionInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("decoding session: %w", err)
+	}
+	if refs, ok := vals[1].(string); ok {
+		info.Refs, err = strconv.Atoi(refs)
+		if err != nil {
+			return nil, fmt.Errorf("decoding session refs: %w", err)
+		}
+	}
+	return &info, nil
+}
+
+// putScript replaces the session hash and sets its expiration.
+//
+// KEYS[1] is the session key; ARGV is the encoded info, the refs and the TTL
+// in milliseconds, which is not positive if the session does not expire.
+var putScript = redis.NewScript(`
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], 'info', ARGV[1], 'refs', ARGV[2])
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// Put implements [mcp.SessionStore.Put].
+func (s *Store) Put(ctx context.Context, sessionID string, info *mcp.StoredSessionInfo, ttl time.Duration) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("encoding session: %w", err)
+	}
+	if s.encrypter != nil {
+		data, err = s.encrypter.Encrypt(ctx, sessionID, data)
+		if err != nil {
+			return fmt.Errorf("encrypting session: %w", err)
+		}
+	}
+	if err := putScript.Run(ctx, s.client, []string{s.key(sessionID)}, data, info.Refs, milliseconds(ttl)).Err(); err != nil {
+		return fmt.Errorf("redis put session: %w", err)
+	}
+	return nil
+}
+
+// Delete implements [mcp.SessionStore.Delete].
+// Deleting a session that does not exist is not an error.
+func (s *Store) Delete(ctx context.Context, sessionID string) error {
+	if err := s.client.Del(ctx, s.key(sessionID)).Err(); err != nil {
+		return fmt.Errorf("redis delete session: %w", err)
+	}
+	return nil
+}
+
+// updateRefsScript adds a delta to the reference count of a session, without
+// letting it drop below zero, and returns the new count, or -1 if the session
+// does not exist. HINCRBY and HSET preserve the key's expiration.
+//
+// KEYS[1] is the session key; ARGV[1] is the delta.
+var updateRefsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local refs = redis.call('HINCRBY', KEYS[1], 'refs', ARGV[1])
+if refs < 0 then
+	redis.call('HSET', KEYS[1], 'refs', 0)
+	refs = 0
+end
+return refs
+`)
+
+// UpdateRefs implements [mcp.SessionStore.UpdateRefs].
+// The update is atomic across all clients of the Redis server.
+func (s *Store) UpdateRefs(ctx context.Context, sessionID string, delta int) (int, error) {
+	refs, err := updateRefsScript.Run(ctx, s.client, []string{s.key(sessionID)}, delta).Int()
+	if err != nil {
+		return 0, fmt.Errorf("redis update session refs: %w", err)
+	}
+	if refs < 0 {
+		return 0, mcp.ErrSessionNotFound
+	}
+	return refs, nil
+}
+
+// refreshTTLScript sets or removes the expiration of a session, and reports
+// whether the session exists.
+//
+// KEYS[1] is the session key; ARGV[1] is the TTL in milliseconds, which is not
+// positive if the session should not expire.
+var refreshTTLScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+else
+	redis.call('PERSIST', KEYS[1])
+end
+return 1
+`)
+
+// RefreshTTL implements [mcp.SessionStore.RefreshTTL].
+func (s *Store) RefreshTTL(ctx context.Context, sessionID string, ttl time.Duration) error {
+	ok, err := refreshTTLScript.Run(ctx, s.client, []string{s.key(sessionID)}, milliseconds(ttl)).Bool()
+	if err != nil {
+		return fmt.Errorf("redis refresh session TTL: %w", err)
+	}
+	if !ok {
+		return mcp.ErrSessionNotFound
+	}
+	return nil
+}
+
+// tryLockScript acquires or extends the lock of a session for an owner, and
+// reports whether it succeeded.
+//
+// KEYS[1] is the lock key; ARGV is the owner and the TTL in milliseconds.
+var tryLockScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// TryLock implements [mcp.SessionLocker.TryLock].
+func (s *Store) TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	ms := milliseconds(ttl)
+	if ms <= 0 {
+		return false, errors.New("sessionredis: lock TTL must be positive")
+	}
+	ok, err := tryLockScript.Run(ctx, s.client, []string{s.lockKey(sessionID)}, owner, ms).Bool()
+	if err != nil {
+		return false, fmt.Errorf("redis lock session: %w", err)
+	}
+	return ok, nil
+}
+
+// unlockScript deletes the lock of a session if it is held by an owner.
+//
+// KEYS[1] is the lock key; ARGV[1] is the owner.
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+// Unlock implements [mcp.SessionLocker.Unlock].
+func (s *Store) Unlock(ctx context.Context, sessionID, owner string) error {
+	if err := unlockScript.Run(ctx, s.client, []string{s.lockKey(sessionID)}, owner).Err(); err != nil {
+		return fmt.Errorf("redis unlock session: %w", err)
+	}
+	return nil
+}
+
+// milliseconds converts a TTL to the milliseconds used by Redis, rounding
+// positive durations up so that they do not become zero.
+func milliseconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Millisecond - 1) / time.Millisecond)
+}
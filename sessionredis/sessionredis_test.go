@@ -0,0 +1,283 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package sessionredis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/orkhanm/go-sdk/mcp"
+	"github.com/orkhanm/go-sdk/sessionredis"
+	"github.com/redis/go-redis/v9"
+)
+
+func newStore(t *testing.T, opts *sessionredis.Options) (*sessionredis.Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return sessionredis.New(client, opts), mr
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newStore(t, &sessionredis.Options{Prefix: "test:"})
+
+	if _, err := store.Get(ctx, "s1"); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Fatalf("Get of missing session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+	info := &mcp.StoredSessionInfo{
+		SessionState: mcp.ServerSessionState{
+			InitializeParams: &mcp.InitializeParams{ProtocolVersion: "2025-06-18", ClientInfo: &mcp.Implementation{Name: "c"}},
+			LogLevel:         "info",
+		},
+		Refs:      1,
+		Timeout:   5 * time.Minute,
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Route:     "/a/",
+	}
+	if err := store.Put(ctx, "s1", info, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("test:s1") {
+		t.Error("session not stored under the key prefix")
+	}
+	got, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(info, got); diff != "" {
+		t.Errorf("Get mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reference counts are updated in place, and never drop below zero.
+	for _, test := range []struct {
+		delta, want int
+	}{{1, 2}, {-1, 1}, {-5, 0}, {3, 3}} {
+		refs, err := store.UpdateRefs(ctx, "s1", test.delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if refs != test.want {
+			t.Errorf("UpdateRefs(%d) = %d, want %d", test.delta, refs, test.want)
+		}
+	}
+	if got, err := store.Get(ctx, "s1"); err != nil || got.Refs != 3 {
+		t.Errorf("Get after UpdateRefs: got refs %v, err %v; want 3", got.Refs, err)
+	}
+	if _, err := store.UpdateRefs(ctx, "missing", 1); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Errorf("UpdateRefs of missing session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+	if mr.Exists("test:missing") {
+		t.Error("UpdateRefs created a missing session")
+	}
+
+	// UpdateRefs preserves the TTL, and RefreshTTL resets it.
+	if ttl := mr.TTL("test:s1"); ttl != time.Minute {
+		t.Errorf("TTL after UpdateRefs = %v, want %v", ttl, time.Minute)
+	}
+	if err := store.RefreshTTL(ctx, "s1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("test:s1"); ttl != time.Hour {
+		t.Errorf("TTL after RefreshTTL = %v, want %v", ttl, time.Hour)
+	}
+	if err := store.RefreshTTL(ctx, "s1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("test:s1"); ttl != 0 {
+		t.Errorf("TTL after RefreshTTL(0) = %v, want none", ttl)
+	}
+	if err := store.RefreshTTL(ctx, "missing", time.Hour); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Errorf("RefreshTTL of missing session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+
+	// Sessions expire after their TTL.
+	if err := store.Put(ctx, "s2", info, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(2 * time.Second)
+	if _, err := store.Get(ctx, "s2"); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Errorf("Get of expired session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+
+	// Put replaces a session, and Delete removes it.
+	if err := store.Put(ctx, "s1", &mcp.StoredSessionInfo{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Get(ctx, "s1"); err != nil || got.Refs != 0 || got.Route != "" {
+		t.Errorf("Get after replacing Put: got %+v, %v; want an empty session", got, err)
+	}
+	if err := store.Delete(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "s1"); err != nil {
+		t.Errorf("Delete of deleted session: %v", err)
+	}
+	if _, err := store.Get(ctx, "s1"); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Errorf("Get of deleted session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+}
+
+func TestStoreEncrypter(t *testing.T) {
+	ctx := context.Background()
+	enc, err := mcp.NewAESSessionEncrypter(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, mr := newStore(t, &sessionredis.Options{Encrypter: enc})
+	info := &mcp.StoredSessionInfo{
+		SessionState: mcp.ServerSessionState{
+			InitializeParams: &mcp.InitializeParams{ClientInfo: &mcp.Implementation{Name: "secret-client"}},
+		},
+	}
+	if err := store.Put(ctx, "s1", info, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if raw := mr.HGet(sessionredis.DefaultPrefix+"s1", "info"); raw == "" || strings.Contains(raw, "secret-client") {
+		t.Errorf("stored session data is not encrypted: %q", raw)
+	}
+	if _, err := store.UpdateRefs(ctx, "s1", 2); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SessionState.InitializeParams.ClientInfo.Name != "secret-client" || got.Refs != 2 {
+		t.Errorf("Get: got %+v, want the stored session with 2 refs", got)
+	}
+
+	// Data moved to another session cannot be decrypted.
+	mr.HSet(sessionredis.DefaultPrefix+"s2", "info", mr.HGet(sessionredis.DefaultPrefix+"s1", "info"))
+	if _, err := store.Get(ctx, "s2"); !errors.Is(err, mcp.ErrSessionDecrypt) {
+		t.Errorf("Get of moved session: got %v, want %v", err, mcp.ErrSessionDecrypt)
+	}
+	// A store without the encrypter cannot read the data.
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	plain := sessionredis.New(client, nil)
+	if _, err := plain.Get(ctx, "s1"); err == nil {
+		t.Error("Get without encrypter succeeded")
+	}
+}
+
+func TestStoreUpdateRefsConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newStore(t, nil)
+	if err := store.Put(ctx, "s", &mcp.StoredSessionInfo{}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.UpdateRefs(ctx, "s", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, err := store.Get(ctx, "s"); err != nil || got.Refs != 50 {
+		t.Errorf("after concurrent UpdateRefs: got %+v, %v; want 50 refs", got, err)
+	}
+}
+
+func TestStoreLock(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newStore(t, nil)
+
+	if ok, err := store.TryLock(ctx, "s", "a", time.Second); err != nil || !ok {
+		t.Fatalf("TryLock of free lock: got %v, %v", ok, err)
+	}
+	if ok, _ := store.TryLock(ctx, "s", "a", time.Second); !ok {
+		t.Error("TryLock by the owner failed")
+	}
+	if ok, _ := store.TryLock(ctx, "s", "b", time.Second); ok {
+		t.Fatal("TryLock of held lock succeeded")
+	}
+	// Only the owner can release the lock.
+	if err := store.Unlock(ctx, "s", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := store.TryLock(ctx, "s", "b", time.Second); ok {
+		t.Fatal("lock released by another owner")
+	}
+	if err := store.Unlock(ctx, "s", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := store.TryLock(ctx, "s", "b", time.Second); !ok {
+		t.Fatal("TryLock of released lock failed")
+	}
+	// Locks expire.
+	mr.FastForward(2 * time.Second)
+	if ok, _ := store.TryLock(ctx, "s", "c", time.Second); !ok {
+		t.Fatal("TryLock of expired lock failed")
+	}
+	// Locks do not collide with sessions.
+	if _, err := store.Get(ctx, "s"); !errors.Is(err, mcp.ErrSessionNotFound) {
+		t.Errorf("Get of locked session: got %v, want %v", err, mcp.ErrSessionNotFound)
+	}
+}
+
+// TestStoreHandlers checks that a session created by one handler can be
+// served by another handler sharing the store.
+func TestStoreHandlers(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newStore(t, nil)
+	newHandler := func(name string) http.Handler {
+		server := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "v1"}, nil)
+		mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil, nil
+		})
+		return mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, &mcp.StreamableHTTPOptions{
+			SessionStore: store,
+		})
+	}
+	// Route requests to the current handler, as a load balancer would.
+	var current atomic.Pointer[http.Handler]
+	h1, h2 := newHandler("h1"), newHandler("h2")
+	current.Store(&h1)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		(*current.Load()).ServeHTTP(w, req)
+	}))
+	defer httpServer.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v1"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	for _, want := range []string{"h1", "h2"} {
+		if want == "h2" {
+			current.Store(&h2)
+		}
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Content[0].(*mcp.TextContent).Text; got != want {
+			t.Errorf("CallTool: got %q, want %q", got, want)
+		}
+	}
+	stored, err := store.Get(ctx, cs.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SessionState.InitializeParams == nil {
+		t.Error("stored session is not initialized")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package wiretest provides a corpus of canonical MCP wire messages, and
+// helpers to compare the messages of an implementation against it.
+//
+// For each protocol version, the corpus holds a JSON-RPC message for the
+// request or notification of every method, and for the result of every
+// request, as produced by this SDK. Implementers can use it to check that
+// their messages are compatible at the JSON level:
+//
+//	diff, err := wiretest.Diff("2025-06-18", "tools/call", wiretest.Result, msg)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if diff != "" {
+//		t.Errorf("tools/call result mismatch:\n%s", diff)
+//	}
+//
+// The corpus is generated by the tests of the mcp package.
+package wiretest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//go:embed corpus
+var corpus embed.FS
+
+// A Kind is the kind of a message in the corpus.
+type Kind string
+
+const (
+	// Request is the kind of a request or notification message.
+	Request Kind = "request"
+	// Result is the kind of a response message holding a result.
+	Result Kind = "result"
+)
+
+// Versions returns the protocol versions covered by the corpus, latest first.
+func Versions() []string {
+	entries, _ := corpus.ReadDir("corpus")
+	var vs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			vs = append(vs, e.Name())
+		}
+	}
+	slices.Sort(vs)
+	slices.Reverse(vs)
+	return vs
+}
+
+// Methods returns the methods covered by the corpus for the given protocol
+// version, in sorted order.
+func Methods(version string) []string {
+	root := path.Join("corpus", version)
+	var methods []string
+	fs.WalkDir(corpus, root, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && path.Base(p) == string(Request)+".json" {
+			methods = append(methods, strings.TrimPrefix(path.Dir(p), root+"/"))
+		}
+		return nil
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// Message returns the canonical message of the given kind for the method in
+// the given protocol version. Request messages have ID 1, unless they are
+// notifications. It returns an error if there is no such message.
+func Message(version, method string, kind Kind) ([]byte, error) {
+	data, err := corpus.ReadFile(path.Join("corpus", version, method, string(kind)+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("wiretest: no %s message for %q in version %q", kind, method, version)
+	}
+	return data, nil
+}
+
+// Diff compares got, an encoded JSON-RPC message, with the canonical message
+// of the given kind for the method in the given protocol version. It returns
+// a description of the differences, one per line, or the empty string if the
+// messages are equivalent.
+//
+// See [DiffMessages] for how messages are compared.
+func Diff(version, method string, kind Kind, got []byte) (string, error) {
+	want, err := Message(version, method, kind)
+	if err != nil {
+		return "", err
+	}
+	return DiffMessages(want, got)
+}
+
+// DiffMessages compares two encoded JSON-RPC messages, returning a
+// description of the differences, one per line, or the empty string if they
+// are equivalent.
+//
+// The messages are compared as JSON values, so the order of object members
+// and insignificant whitespace don't matter. Request IDs are not compared,
+// except for their presence. Since MCP never requires JSON null, a null in
+// got is reported as a difference even if want holds null at the same place.
+func DiffMessages(want, got []byte) (string, error) {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return "", fmt.Errorf("wiretest: want: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return "", fmt.Errorf("wiretest: got: %w", err)
+	}
+	var d differ
+	d.diff("", w, g)
+	return strings.Join(d.lines, "\n"), nil
+}
+
+type differ struct {
+	lines []string
+}
+
+func (d *differ) addf(format string, args ...any) {
+	d.lines = append(d.lines, fmt.Sprintf(format, args...))
+}
+
+func (d *differ) diff(p string, want, got any) {
+	if got == nil {
+		d.addf("%s: got JSON null", pathOrRoot(p))
+		return
+	}
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			d.addf("%s: got %s, want object", pathOrRoot(p), describe(got))
+			return
+		}
+		var keys []string
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kp := p + "." + k
+			if p == "" {
+				kp = k
+			}
+			wv, wok := w[k]
+			gv, gok := g[k]
+			switch {
+			case !gok:
+				d.addf("%s: missing", kp)
+			case !wok:
+				d.addf("%s: unexpected member", kp)
+			case kp == "id":
+				// IDs are not compared.
+			default:
+				d.diff(kp, wv, gv)
+			}
+		}
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			d.addf("%s: got %s, want array", pathOrRoot(p), describe(got))
+			return
+		}
+		if len(g) != len(w) {
+			d.addf("%s: got %d elements, want %d", pathOrRoot(p), len(g), len(w))
+		}
+		for i := range min(len(w), len(g)) {
+			d.diff(fmt.Sprintf("%s[%d]", p, i), w[i], g[i])
+		}
+	default:
+		if want != got {
+			d.addf("%s: got %s, want %s", pathOrRoot(p), describe(got), describe(want))
+		}
+	}
+}
+
+func pathOrRoot(p string) string {
+	if p == "" {
+		return "(message)"
+	}
+	return p
+}
+
+// describe returns a short description of a decoded JSON value.
+func describe(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case nil:
+		return "null"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
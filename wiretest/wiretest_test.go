@@ -0,0 +1,95 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package wiretest_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/orkhanm/go-sdk/wiretest"
+)
+
+func TestCorpus(t *testing.T) {
+	versions := wiretest.Versions()
+	if len(versions) == 0 || versions[0] != "2025-06-18" {
+		t.Fatalf("Versions() = %v, want latest first", versions)
+	}
+	for _, v := range versions {
+		methods := wiretest.Methods(v)
+		if !slices.Contains(methods, "tools/call") || !slices.Contains(methods, "notifications/initialized") {
+			t.Errorf("Methods(%q) = %v, missing methods", v, methods)
+		}
+		// Each message is equivalent to itself.
+		msg, err := wiretest.Message(v, "tools/call", wiretest.Result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff, err := wiretest.Diff(v, "tools/call", wiretest.Result, msg); err != nil || diff != "" {
+			t.Errorf("Diff of canonical message: %q, %v", diff, err)
+		}
+	}
+	if _, err := wiretest.Message("2024-11-05", "elicitation/create", wiretest.Request); err == nil {
+		t.Error("elicitation/create is in the corpus for 2024-11-05")
+	}
+	if _, err := wiretest.Message("2025-06-18", "notifications/initialized", wiretest.Result); err == nil {
+		t.Error("notification has a result")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		got  string
+		want []string // substrings of the diff, one per line
+	}{
+		{
+			name: "different",
+			got:  `{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"bye"}],"isError":true}}`,
+			want: []string{
+				`result.content[0].text: got "bye", want "hello"`,
+				`result.content: got 1 elements, want 3`,
+				`result.isError: unexpected member`,
+				`result.structuredContent: missing`,
+			},
+		},
+		{
+			name: "null",
+			got:  `{"jsonrpc":"2.0","id":1,"result":{"content":null,"structuredContent":{"greeting":"hello"}}}`,
+			want: []string{`result.content: got JSON null`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := wiretest.Diff("2025-06-18", "tools/call", wiretest.Result, []byte(test.got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []string
+			if diff != "" {
+				lines = strings.Split(diff, "\n")
+			}
+			if len(lines) != len(test.want) {
+				t.Fatalf("got diff:\n%s\nwant %d lines", diff, len(test.want))
+			}
+			for _, w := range test.want {
+				if !strings.Contains(diff, w) {
+					t.Errorf("diff does not contain %q:\n%s", w, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffMessagesIgnoresOrderAndIDs(t *testing.T) {
+	diff, err := wiretest.DiffMessages(
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{}}`),
+		[]byte(`{"params":{},"method":"ping","id":"x","jsonrpc":"2.0"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("got diff:\n%s", diff)
+	}
+}